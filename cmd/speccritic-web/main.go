@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,13 +15,26 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "purge" {
+		os.Exit(runPurge(os.Args[2:]))
+	}
+
 	config := web.DefaultConfig()
 	flag.StringVar(&config.Addr, "addr", config.Addr, "listen address")
 	flag.DurationVar(&config.RequestTimeout, "request-timeout", config.RequestTimeout, "request timeout")
 	flag.Int64Var(&config.MaxUploadBytes, "max-upload-bytes", config.MaxUploadBytes, "maximum upload size in bytes")
+	flag.IntVar(&config.MaxBatchFiles, "max-batch-files", config.MaxBatchFiles, "maximum spec files per upload")
 	flag.IntVar(&config.MaxRetainedChecks, "max-retained-checks", config.MaxRetainedChecks, "maximum retained checks")
 	flag.DurationVar(&config.RetainedCheckTTL, "retained-check-ttl", config.RetainedCheckTTL, "retained check TTL")
+	flag.IntVar(&config.CheckWorkers, "check-workers", config.CheckWorkers, "checks run concurrently")
+	flag.IntVar(&config.MaxQueuedChecks, "max-queued-checks", config.MaxQueuedChecks, "maximum checks waiting for a worker")
+	flag.StringVar(&config.StoreDir, "store-dir", config.StoreDir, "persist retained checks in this directory instead of memory")
 	flag.Parse()
+	if err := applyStoreRetention(&config, setFlags(flag.CommandLine)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.APITokens = apiTokens(os.Getenv("SPECCRITIC_WEB_API_TOKENS"))
 
 	app, err := web.NewServer(config)
 	if err != nil {
@@ -49,6 +63,7 @@ func main() {
 	case <-ctx.Done():
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
+		app.Close()
 		if err := server.Shutdown(shutdownCtx); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: shutdown failed: %v\n", err)
 			os.Exit(1)
@@ -60,3 +75,91 @@ func main() {
 		}
 	}
 }
+
+// apiTokens splits a comma-separated token list, ignoring blank entries.
+func apiTokens(raw string) []string {
+	var tokens []string
+	for _, token := range strings.Split(raw, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// setFlags returns the names of the flags given on the command line.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// applyStoreRetention fills the retention settings that were not given as
+// flags from the store directory's recorded setting, or from the store
+// defaults for a new directory, so a restart does not expire stored checks
+// under the short in-memory defaults.
+func applyStoreRetention(config *web.Config, set map[string]bool) error {
+	if config.StoreDir == "" {
+		return nil
+	}
+	retention, ok, err := web.LoadRetention(config.StoreDir)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		retention = web.DefaultStoreRetention()
+	}
+	if !set["max-retained-checks"] {
+		config.MaxRetainedChecks = retention.MaxRetainedChecks
+	}
+	if !set["retained-check-ttl"] {
+		config.RetainedCheckTTL = retention.RetainedCheckTTL
+	}
+	return nil
+}
+
+// runPurge deletes stored checks from a -store-dir directory: expired checks
+// by default, or every check with -all. Expiry uses -retained-check-ttl, or
+// the retention the server recorded in the directory.
+func runPurge(args []string) int {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	dir := fs.String("store-dir", "", "directory holding retained checks")
+	ttl := fs.Duration("retained-check-ttl", 0, "retained check TTL; older checks are purged (default: the server's recorded setting)")
+	all := fs.Bool("all", false, "purge every retained check, not only expired ones")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "Error: purge requires -store-dir")
+		return 2
+	}
+	retention, ok, err := web.LoadRetention(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *ttl > 0 {
+		retention.RetainedCheckTTL = *ttl
+	} else if !ok && !*all {
+		fmt.Fprintf(os.Stderr, "Error: %s has no recorded retention setting; pass -retained-check-ttl\n", *dir)
+		return 2
+	}
+	if retention.RetainedCheckTTL <= 0 {
+		retention.RetainedCheckTTL = web.DefaultStoreRetention().RetainedCheckTTL
+	}
+	if retention.MaxRetainedChecks <= 0 {
+		retention.MaxRetainedChecks = web.DefaultStoreRetention().MaxRetainedChecks
+	}
+	store, err := web.NewFileStore(*dir, retention.MaxRetainedChecks, retention.RetainedCheckTTL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	removed, err := store.Purge(*all)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "INFO: purged %d check(s) from %s\n", removed, *dir)
+	return 0
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/speccritic/internal/chunk"
+	"github.com/dshills/speccritic/internal/patch"
+	"github.com/dshills/speccritic/internal/preflight"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+// applyFlags holds the parsed flags for the apply command.
+type applyFlags struct {
+	from           string
+	out            string
+	only           []string
+	completionOnly bool
+	interactive    bool
+	profileName    string
+}
+
+func newApplyCommand() *cobra.Command {
+	var flags applyFlags
+	cmd := &cobra.Command{
+		Use:   "apply <spec-file>",
+		Short: "Apply patches from a report or patch file to a spec",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runApply(args[0], flags, cmd.InOrStdin(), cmd.ErrOrStderr())
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&flags.from, "from", "", "JSON report or --patch-out file with the patches to apply (required)")
+	f.StringVar(&flags.out, "out", "", "Write the patched spec to file instead of updating the spec in place")
+	f.StringSliceVar(&flags.only, "only", nil, "Apply only patches for these issue IDs (comma-separated)")
+	f.BoolVar(&flags.completionOnly, "completion-only", false, "Apply only completion patches")
+	f.BoolVar(&flags.interactive, "interactive", false, "Prompt to accept or reject each patch")
+	f.StringVar(&flags.profileName, "profile", "", "Preflight profile for the post-apply check (default: the report's profile, else general)")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+// applyCandidate is a patch read from a report or patch file.
+type applyCandidate struct {
+	patch      schema.Patch
+	completion bool
+}
+
+func runApply(specPath string, flags applyFlags, in io.Reader, w io.Writer) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return codeError(3, "reading spec file: %s", err)
+	}
+	original := string(data)
+	candidates, profileName, err := loadApplyCandidates(flags.from)
+	if err != nil {
+		return err
+	}
+	if flags.profileName != "" {
+		profileName = flags.profileName
+	}
+	candidates, err = selectApplyCandidates(candidates, specPath, flags, w)
+	if err != nil {
+		return err
+	}
+
+	patches := make([]schema.Patch, len(candidates))
+	for i, c := range candidates {
+		patches[i] = c.patch
+	}
+	edits, err := patch.LocateUnique(original, patches)
+	if err != nil {
+		return codeError(3, "%s; re-run speccritic check to refresh the patches", err)
+	}
+	if flags.interactive {
+		if edits, err = promptEdits(bufio.NewReader(in), w, original, edits); err != nil {
+			return codeError(3, "reading answers: %s", err)
+		}
+		if len(edits) == 0 {
+			fmt.Fprintln(w, "No patches accepted; spec unchanged.")
+			return nil
+		}
+	}
+	patched, err := patch.Apply(original, edits)
+	if err != nil {
+		var conflict *patch.ConflictError
+		if errors.As(err, &conflict) {
+			return codeError(3, "%s; select one with --only", err)
+		}
+		return codeError(3, "applying patches: %s", err)
+	}
+
+	out := flags.out
+	if out == "" {
+		out = specPath
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(specPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(out, []byte(patched), mode); err != nil {
+		return codeError(3, "writing patched spec: %s", err)
+	}
+
+	for _, e := range edits {
+		fmt.Fprintf(w, "Applied patch for %s at line %d\n", e.Patch.IssueID, lineOf(original, e.Start))
+	}
+	fmt.Fprintf(w, "Wrote %s\n", out)
+	return reportPreflightChange(w, specPath, original, patched, profileName)
+}
+
+// loadApplyCandidates reads patches from a JSON report or a patch file and
+// returns the report's profile, if any.
+func loadApplyCandidates(path string) ([]applyCandidate, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", codeError(3, "reading patches: %s", err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var report schema.Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, "", codeError(3, "parsing report %s: %s", path, err)
+		}
+		if report.Tool != "speccritic" {
+			return nil, "", codeError(3, "%s is not a SpecCritic JSON report", path)
+		}
+		completion := make(map[string]bool)
+		for _, issue := range report.Issues {
+			for _, tag := range issue.Tags {
+				if tag == "completion-suggested" {
+					completion[issue.ID] = true
+				}
+			}
+		}
+		candidates := make([]applyCandidate, len(report.Patches))
+		for i, p := range report.Patches {
+			candidates[i] = applyCandidate{patch: p, completion: completion[p.IssueID]}
+		}
+		return candidates, report.Input.Profile, nil
+	}
+	entries, err := patch.ParseDiff(string(data))
+	if err != nil {
+		return nil, "", codeError(3, "parsing %s: %s", path, err)
+	}
+	candidates := make([]applyCandidate, len(entries))
+	for i, e := range entries {
+		candidates[i] = applyCandidate{patch: e.Patch, completion: e.Completion}
+	}
+	return candidates, "", nil
+}
+
+// selectApplyCandidates keeps the patches for specPath that match --only and
+// --completion-only. Redaction-affected patches are skipped with a warning.
+func selectApplyCandidates(candidates []applyCandidate, specPath string, flags applyFlags, w io.Writer) ([]applyCandidate, error) {
+	only := make(map[string]bool, len(flags.only))
+	for _, id := range flags.only {
+		if id = strings.TrimSpace(id); id != "" {
+			only[id] = true
+		}
+	}
+	found := make(map[string]bool)
+	var out []applyCandidate
+	for _, c := range candidates {
+		if !patchTargets(c.patch.Path, specPath) {
+			continue
+		}
+		if len(only) > 0 && !only[c.patch.IssueID] {
+			continue
+		}
+		if flags.completionOnly && !c.completion {
+			continue
+		}
+		found[c.patch.IssueID] = true
+		if patch.Redacted(c.patch) {
+			fmt.Fprintf(w, "WARN: skipping patch for %s: it touches redacted text\n", c.patch.IssueID)
+			continue
+		}
+		out = append(out, c)
+	}
+	for _, id := range flags.only {
+		if id = strings.TrimSpace(id); id != "" && !found[id] {
+			return nil, codeError(3, "no patch for %s", id)
+		}
+	}
+	if len(out) == 0 {
+		return nil, codeError(3, "no patches to apply")
+	}
+	return out, nil
+}
+
+// patchTargets reports whether a patch recorded against path applies to
+// specPath. Patches from single-file reviews have no path; patches from a
+// directory review carry the file path relative to the reviewed directory.
+func patchTargets(path, specPath string) bool {
+	if path == "" {
+		return true
+	}
+	path = filepath.Clean(filepath.FromSlash(path))
+	specPath = filepath.Clean(specPath)
+	return specPath == path || strings.HasSuffix(specPath, string(filepath.Separator)+path)
+}
+
+// promptEdits shows each patch in spec order and keeps the accepted ones.
+func promptEdits(r *bufio.Reader, w io.Writer, original string, edits []patch.Edit) ([]patch.Edit, error) {
+	var accepted []patch.Edit
+	for _, e := range edits {
+		fmt.Fprintf(w, "\nPatch for %s at line %d:\n", e.Patch.IssueID, lineOf(original, e.Start))
+		writePrefixed(w, "- ", e.Patch.Before)
+		writePrefixed(w, "+ ", e.Patch.After)
+		answer, err := promptLine(r, w, "Apply this patch? [y/N]")
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+			accepted = append(accepted, e)
+		}
+	}
+	return accepted, nil
+}
+
+func writePrefixed(w io.Writer, prefix, text string) {
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
+
+func lineOf(text string, offset int) int {
+	return strings.Count(text[:offset], "\n") + 1
+}
+
+// reportPreflightChange runs preflight on the spec before and after the
+// patches and prints which findings were resolved or introduced.
+func reportPreflightChange(w io.Writer, specPath, original, patched, profileName string) error {
+	cfg := preflight.Config{Enabled: true, Mode: preflight.ModeWarn, Profile: profileName}
+	before, err := preflight.Run(spec.New(specPath, original), cfg)
+	if err != nil {
+		return codeError(3, "preflight: %s", err)
+	}
+	after, err := preflight.Run(spec.New(specPath, patched), cfg)
+	if err != nil {
+		return codeError(3, "preflight: %s", err)
+	}
+	beforeKeys := preflightKeys(before.Issues, original)
+	afterKeys := preflightKeys(after.Issues, patched)
+	fmt.Fprintf(w, "Preflight: %d findings before, %d after\n", len(before.Issues), len(after.Issues))
+	// Findings are matched one for one, so a repeated finding that was
+	// fixed once still counts as resolved.
+	remaining := countKeys(afterKeys)
+	for i, issue := range before.Issues {
+		if remaining[beforeKeys[i]] > 0 {
+			remaining[beforeKeys[i]]--
+			continue
+		}
+		fmt.Fprintf(w, "  resolved %s: %s\n", issue.ID, issue.Title)
+	}
+	remaining = countKeys(beforeKeys)
+	for i, issue := range after.Issues {
+		if remaining[afterKeys[i]] > 0 {
+			remaining[afterKeys[i]]--
+			continue
+		}
+		fmt.Fprintf(w, "  new %s (line %d): %s\n", issue.ID, firstEvidenceLine(issue), issue.Title)
+	}
+	return nil
+}
+
+func preflightKeys(issues []schema.Issue, text string) []string {
+	lines := spec.Lines(text)
+	sections := chunk.BuildSections(lines, chunk.ExtractHeadings(lines))
+	keys := make([]string, len(issues))
+	for i, issue := range issues {
+		keys[i] = preflightKey(issue, sections)
+	}
+	return keys
+}
+
+func countKeys(keys []string) map[string]int {
+	out := make(map[string]int, len(keys))
+	for _, key := range keys {
+		out[key]++
+	}
+	return out
+}
+
+// preflightKey identifies a finding by rule, section and tags. It leaves out
+// the line and the quoted text, so a finding on a line the patch edited or
+// moved is not reported as both resolved and new.
+func preflightKey(issue schema.Issue, sections []chunk.Section) string {
+	var section []string
+	if line := firstEvidenceLine(issue); line > 0 {
+		// Sections are in heading order, so the last match is the innermost.
+		for _, candidate := range sections {
+			if candidate.LineStart <= line && line <= candidate.LineEnd {
+				section = candidate.HeadingPath
+			}
+		}
+	}
+	tags := append([]string(nil), issue.Tags...)
+	sort.Strings(tags)
+	return strings.Join([]string{issue.ID, strings.Join(section, " > "), strings.Join(tags, ",")}, "\x00")
+}
+
+func firstEvidenceLine(issue schema.Issue) int {
+	if len(issue.Evidence) == 0 {
+		return 0
+	}
+	return issue.Evidence[0].LineStart
+}
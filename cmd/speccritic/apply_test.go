@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/patch"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const applySpec = "# Spec\n\nThe API must respond quickly.\n\nThe retry limit is TBD.\n"
+
+var applyPatches = []schema.Patch{
+	{IssueID: "ISSUE-0001", Before: "The API must respond quickly.", After: "The API must respond within 200 ms at p99."},
+	{IssueID: "ISSUE-0002", Before: "The retry limit is TBD.", After: "The retry limit is 3 attempts."},
+}
+
+func writeApplyReport(t *testing.T, patches []schema.Patch) string {
+	t.Helper()
+	report := schema.Report{
+		Tool:    "speccritic",
+		Input:   schema.Input{SpecFile: "SPEC.md", Profile: "general"},
+		Issues:  []schema.Issue{{ID: "ISSUE-0001"}, {ID: "ISSUE-0002", Tags: []string{"completion-suggested"}}},
+		Patches: patches,
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("marshal report: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRunApplyReportInPlaceAndReportsPreflight(t *testing.T) {
+	specPath := writeTempSpec(t, applySpec)
+	var out strings.Builder
+	if err := runApply(specPath, applyFlags{from: writeApplyReport(t, applyPatches)}, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("runApply: %v", err)
+	}
+	want := "# Spec\n\nThe API must respond within 200 ms at p99.\n\nThe retry limit is 3 attempts.\n"
+	if got := readFile(t, specPath); got != want {
+		t.Fatalf("spec = %q, want %q", got, want)
+	}
+	for _, msg := range []string{"Applied patch for ISSUE-0001 at line 3", "Applied patch for ISSUE-0002 at line 5", "Preflight:", "resolved PREFLIGHT-"} {
+		if !strings.Contains(out.String(), msg) {
+			t.Fatalf("output missing %q:\n%s", msg, out.String())
+		}
+	}
+}
+
+func TestRunApplyPatchFileSelectionToOut(t *testing.T) {
+	specPath := writeTempSpec(t, applySpec)
+	issues := []schema.Issue{{ID: "ISSUE-0002", Tags: []string{"completion-suggested"}}}
+	diffPath := filepath.Join(t.TempDir(), "spec.diff")
+	if err := os.WriteFile(diffPath, []byte(patch.GenerateDiffWithIssues(applySpec, applyPatches, issues, nil)), 0o644); err != nil {
+		t.Fatalf("write diff: %v", err)
+	}
+
+	for name, flags := range map[string]applyFlags{
+		"only":            {from: diffPath, only: []string{"ISSUE-0002"}},
+		"completion-only": {from: diffPath, completionOnly: true},
+	} {
+		flags.out = filepath.Join(t.TempDir(), "SPEC.patched.md")
+		if err := runApply(specPath, flags, strings.NewReader(""), io.Discard); err != nil {
+			t.Fatalf("%s: runApply: %v", name, err)
+		}
+		got := readFile(t, flags.out)
+		if !strings.Contains(got, "3 attempts") || !strings.Contains(got, "respond quickly") {
+			t.Fatalf("%s: patched spec = %q", name, got)
+		}
+	}
+	if got := readFile(t, specPath); got != applySpec {
+		t.Fatalf("spec modified with --out: %q", got)
+	}
+}
+
+func TestRunApplyInteractive(t *testing.T) {
+	specPath := writeTempSpec(t, applySpec)
+	var out strings.Builder
+	if err := runApply(specPath, applyFlags{from: writeApplyReport(t, applyPatches), interactive: true}, strings.NewReader("n\ny\n"), &out); err != nil {
+		t.Fatalf("runApply: %v", err)
+	}
+	got := readFile(t, specPath)
+	if !strings.Contains(got, "respond quickly") || !strings.Contains(got, "3 attempts") {
+		t.Fatalf("spec = %q", got)
+	}
+	if !strings.Contains(out.String(), "- The API must respond quickly.\n+ The API must respond within 200 ms at p99.\n") {
+		t.Fatalf("prompt missing before/after:\n%s", out.String())
+	}
+}
+
+func TestRunApplyFailsCleanly(t *testing.T) {
+	for name, tc := range map[string]struct {
+		spec    string
+		patches []schema.Patch
+		flags   applyFlags
+	}{
+		"stale":     {"# Spec\n\nRewritten.\n", applyPatches, applyFlags{}},
+		"ambiguous": {applySpec + "\nThe retry limit is TBD.\n", applyPatches, applyFlags{}},
+		"unknown":   {applySpec, applyPatches, applyFlags{only: []string{"ISSUE-0009"}}},
+		"conflict":  {applySpec, append([]schema.Patch{{IssueID: "ISSUE-0003", Before: "respond quickly.\n\nThe retry", After: "x"}}, applyPatches...), applyFlags{}},
+	} {
+		specPath := writeTempSpec(t, tc.spec)
+		flags := tc.flags
+		flags.from = writeApplyReport(t, tc.patches)
+		err := runApply(specPath, flags, strings.NewReader(""), io.Discard)
+		var ee *exitErr
+		if !asExitErr(err, &ee) || ee.code != 3 {
+			t.Fatalf("%s: err = %v, want exit 3", name, err)
+		}
+		if got := readFile(t, specPath); got != tc.spec {
+			t.Fatalf("%s: spec modified: %q", name, got)
+		}
+	}
+}
+
+func TestReportPreflightChangeMatchesFindingsOnEditedLines(t *testing.T) {
+	original := "# Spec\n\n## Limits\n\nThe API must respond quickly and the retry limit is TBD.\n"
+	patched := "# Spec\n\n## Limits\n\nThe API must respond quickly and the retry limit is 3 attempts.\n"
+	var out strings.Builder
+	if err := reportPreflightChange(&out, "SPEC.md", original, patched, "general"); err != nil {
+		t.Fatalf("reportPreflightChange: %v", err)
+	}
+	if !strings.Contains(out.String(), "resolved PREFLIGHT-TODO-001") {
+		t.Fatalf("output missing resolved TODO:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "new ") || strings.Contains(out.String(), "resolved PREFLIGHT-VAGUE") {
+		t.Fatalf("unchanged finding on the edited line reported as changed:\n%s", out.String())
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/patch"
+	"github.com/dshills/speccritic/internal/render"
+	"github.com/dshills/speccritic/internal/review"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+// runBatchCheck checks every spec matched by args and writes one aggregate
+// report. Per-spec failures are recorded in the aggregate and turned into a
+// single exit code after all output is written.
+func runBatchCheck(args []string, flags checkFlags) error {
+	if err := validateFlags(flags); err != nil {
+		return codeError(3, "invalid flags: %s", err)
+	}
+	if err := validateBatchFlags(flags); err != nil {
+		return codeError(3, "invalid flags: %s", err)
+	}
+	paths, err := spec.ExpandPaths(args)
+	if err != nil {
+		return codeError(3, "%s", err)
+	}
+
+	reqs := make([]app.CheckRequest, len(paths))
+	for i, path := range paths {
+		reqs[i] = checkRequest(path, flags)
+	}
+	logVerbose(flags.verbose, "Checking %d specs (concurrency: %d)", len(reqs), flags.batchConcurrency)
+	items := app.NewChecker().CheckBatch(cmdContext(), reqs, app.BatchConfig{Concurrency: flags.batchConcurrency})
+
+	// Severity filtering is output-only, matching single-spec runs.
+	severityFilter := parseSeverityThreshold(flags.severityThreshold)
+	var patches strings.Builder
+	for i := range items {
+		if items[i].Err != nil || items[i].Result == nil {
+			continue
+		}
+		report := cloneReport(items[i].Result.Report)
+		report.Issues = review.FilterBySeverity(report.Issues, severityFilter)
+		report.Questions = review.FilterQuestionsBySeverity(report.Questions, severityFilter)
+		result := *items[i].Result
+		result.Report = report
+		items[i].Result = &result
+		switch {
+		case result.PatchDiff == "":
+		case flags.patchFormat == patch.FormatUnified:
+			// Unified diffs name their files in the ---/+++ headers.
+			patches.WriteString(result.PatchDiff)
+		default:
+			fmt.Fprintf(&patches, "# spec %s\n%s", items[i].Request.SpecPath, result.PatchDiff)
+		}
+	}
+	batch := app.BuildBatchReport(version, items)
+
+	if flags.patchOut != "" {
+		logVerbose(flags.verbose, "Generating patches → %s", flags.patchOut)
+		if err := os.WriteFile(flags.patchOut, []byte(patches.String()), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: patch write failed: %s\n", err)
+		}
+	}
+	if flags.outDir != "" {
+		if err := writeBatchReports(flags.outDir, flags.format, batch); err != nil {
+			return err
+		}
+	}
+
+	renderer, err := render.NewBatchRenderer(flags.format)
+	if err != nil {
+		return codeError(3, "invalid format: %s", err)
+	}
+	outputBytes, err := renderer.RenderBatch(batch)
+	if err != nil {
+		return codeError(3, "rendering output: %s", err)
+	}
+	if err := writeOutput(flags.out, outputBytes); err != nil {
+		return err
+	}
+
+	if batch.Summary.FailedCount > 0 {
+		for _, item := range items {
+			if item.Err != nil {
+				failure := mapAppError(item.Err).(*exitErr)
+				return codeError(failure.code, "%d of %d specs failed; %s: %s", batch.Summary.FailedCount, batch.Summary.SpecCount, item.Request.SpecPath, failure.msg)
+			}
+		}
+	}
+	if flags.failOn != "" {
+		verdictThreshold := schema.Verdict(flags.failOn)
+		if schema.VerdictOrdinal(batch.Summary.Verdict) >= schema.VerdictOrdinal(verdictThreshold) {
+			return codeError(2, "aggregate verdict %s meets or exceeds --fail-on threshold %s", batch.Summary.Verdict, verdictThreshold)
+		}
+	}
+	return nil
+}
+
+// validateBatchFlags rejects flags that name a single previous report, since
+// they cannot apply to several specs at once.
+func validateBatchFlags(flags checkFlags) error {
+	if flags.batchConcurrency <= 0 {
+		return fmt.Errorf("--batch-concurrency must be > 0, got %d", flags.batchConcurrency)
+	}
+	if flags.incrementalFrom != "" || flags.incrementalBase != "" {
+		return fmt.Errorf("--incremental-from and --incremental-base cannot be used with multiple specs")
+	}
+	if flags.convergenceFrom != "" {
+		return fmt.Errorf("--convergence-from cannot be used with multiple specs")
+	}
+	if flags.convergenceMode == "on" {
+		return fmt.Errorf("--convergence-mode=on cannot be used with multiple specs")
+	}
+	return nil
+}
+
+// writeBatchReports writes each completed spec's report under dir, mirroring
+// the spec's relative path with the output format's extension appended.
+func writeBatchReports(dir, format string, batch *schema.BatchReport) error {
+	renderer, err := render.NewRenderer(format)
+	if err != nil {
+		return codeError(3, "invalid format: %s", err)
+	}
+	for _, entry := range batch.Specs {
+		if entry.Report == nil {
+			continue
+		}
+		data, err := renderer.Render(entry.Report)
+		if err != nil {
+			return codeError(3, "rendering %s: %s", entry.SpecFile, err)
+		}
+		path := filepath.Join(dir, batchReportName(entry.SpecFile)+"."+format)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return codeError(3, "creating output directory: %s", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return codeError(3, "writing output file: %s", err)
+		}
+	}
+	return nil
+}
+
+// batchReportName returns a relative path for a spec's report that cannot
+// escape the output directory.
+func batchReportName(specPath string) string {
+	cleaned := filepath.ToSlash(filepath.Clean(specPath))
+	cleaned = strings.TrimLeft(strings.TrimPrefix(cleaned, filepath.VolumeName(cleaned)), "/")
+	parts := strings.Split(cleaned, "/")
+	for i, part := range parts {
+		if part == ".." || part == "." || part == "" {
+			parts[i] = "_"
+		}
+	}
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
+
+// writeOutput writes rendered output to path, or to stdout when path is empty.
+func writeOutput(path string, data []byte) error {
+	if path != "" {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return codeError(3, "writing output file: %s", err)
+		}
+		return nil
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		return codeError(3, "writing output: %s", err)
+	}
+	// Ensure output ends with a newline for terminal friendliness.
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		_, _ = fmt.Fprintln(os.Stdout)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func writeBatchSpecs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for path, text := range map[string]string{
+		"billing/SPEC.md": "TODO define billing.\n",
+		"search/SPEC.md":  "TODO define search.\n",
+		"search/notes.md": "not a spec\n",
+	} {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(text), 0o644); err != nil {
+			t.Fatalf("write spec: %v", err)
+		}
+	}
+	return root
+}
+
+func readBatchReport(t *testing.T, path string) schema.BatchReport {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read batch report: %v", err)
+	}
+	var report schema.BatchReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decode batch report: %v\n%s", err, data)
+	}
+	return report
+}
+
+func TestRunBatchCheckGlobAggregatesReports(t *testing.T) {
+	root := writeBatchSpecs(t)
+	outDir := filepath.Join(t.TempDir(), "reports")
+	flags := runCheckFlags()
+	flags.preflight = true
+	flags.preflightMode = "only"
+	flags.batchConcurrency = 2
+	flags.out = filepath.Join(t.TempDir(), "batch.json")
+	flags.outDir = outDir
+
+	if err := runBatchCheck([]string{filepath.Join(root, "**", "SPEC.md")}, flags); err != nil {
+		t.Fatalf("runBatchCheck: %v", err)
+	}
+	batch := readBatchReport(t, flags.out)
+	if batch.Summary.SpecCount != 2 || batch.Summary.FailedCount != 0 {
+		t.Fatalf("summary = %+v", batch.Summary)
+	}
+	if batch.Summary.Verdict != schema.VerdictInvalid {
+		t.Fatalf("verdict = %s, want INVALID", batch.Summary.Verdict)
+	}
+	if !strings.HasSuffix(batch.Specs[0].SpecFile, filepath.Join("billing", "SPEC.md")) || batch.Specs[0].Report == nil {
+		t.Fatalf("first entry = %+v", batch.Specs[0])
+	}
+	report := readJSONReport(t, filepath.Join(outDir, batchReportName(batch.Specs[1].SpecFile)+".json"))
+	if report.Input.SpecFile != batch.Specs[1].SpecFile {
+		t.Fatalf("per-spec report spec file = %q", report.Input.SpecFile)
+	}
+}
+
+func TestRunBatchCheckFailOnAggregateVerdict(t *testing.T) {
+	root := writeBatchSpecs(t)
+	flags := runCheckFlags()
+	flags.preflight = true
+	flags.preflightMode = "only"
+	flags.batchConcurrency = 2
+	flags.failOn = "INVALID"
+	flags.out = filepath.Join(t.TempDir(), "batch.json")
+
+	err := runBatchCheck([]string{filepath.Join(root, "billing", "SPEC.md"), filepath.Join(root, "search", "SPEC.md")}, flags)
+	var ee *exitErr
+	if !asExitErr(err, &ee) || ee.code != 2 {
+		t.Fatalf("expected exit code 2, got %v", err)
+	}
+}
+
+func TestRunBatchCheckMissingSpecRecordsFailure(t *testing.T) {
+	root := writeBatchSpecs(t)
+	flags := runCheckFlags()
+	flags.preflight = true
+	flags.preflightMode = "only"
+	flags.batchConcurrency = 2
+	flags.out = filepath.Join(t.TempDir(), "batch.json")
+
+	err := runBatchCheck([]string{filepath.Join(root, "billing", "SPEC.md"), filepath.Join(root, "missing.md")}, flags)
+	var ee *exitErr
+	if !asExitErr(err, &ee) || ee.code != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+	batch := readBatchReport(t, flags.out)
+	if batch.Summary.FailedCount != 1 || batch.Specs[1].Error == "" {
+		t.Fatalf("batch = %+v", batch)
+	}
+}
+
+func TestRunBatchCheckRejectsSingleReportFlags(t *testing.T) {
+	flags := runCheckFlags()
+	flags.batchConcurrency = 2
+	flags.convergenceFrom = "previous.json"
+	err := runBatchCheck([]string{"a.md", "b.md"}, flags)
+	var ee *exitErr
+	if !asExitErr(err, &ee) || ee.code != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+}
+
+func TestBatchReportNameStaysInsideOutputDir(t *testing.T) {
+	for _, path := range []string{"../outside/SPEC.md", "/abs/SPEC.md", "specs/./SPEC.md"} {
+		name := batchReportName(path)
+		if strings.HasPrefix(name, "..") || filepath.IsAbs(name) {
+			t.Fatalf("batchReportName(%q) = %q escapes output dir", path, name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/speccritic/internal/completion"
+)
+
+// initFlags holds the parsed flags for the init command.
+type initFlags struct {
+	profileName  string
+	templatePath string
+	title        string
+	interactive  bool
+	force        bool
+}
+
+func newInitCommand() *cobra.Command {
+	var flags initFlags
+	cmd := &cobra.Command{
+		Use:   "init <spec-file>",
+		Short: "Scaffold a new specification from a profile template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInit(args[0], flags, cmd.InOrStdin(), cmd.ErrOrStderr())
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&flags.profileName, "profile", "general", "Template profile: general, backend-api, regulated-system, or event-driven")
+	f.StringVar(&flags.templatePath, "template", "", "Markdown template file to scaffold from instead of a built-in profile")
+	f.StringVar(&flags.title, "title", "", "Specification title")
+	f.BoolVar(&flags.interactive, "interactive", false, "Prompt for title, actors, and open decisions")
+	f.BoolVar(&flags.force, "force", false, "Overwrite an existing spec file")
+	return cmd
+}
+
+func runInit(specPath string, flags initFlags, in io.Reader, prompt io.Writer) error {
+	if !flags.force {
+		if _, err := os.Stat(specPath); err == nil {
+			return codeError(3, "%s already exists; use --force to overwrite", specPath)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return codeError(3, "checking spec file: %s", err)
+		}
+	}
+
+	tmpl, err := loadInitTemplate(flags)
+	if err != nil {
+		return err
+	}
+	input := completion.ScaffoldInput{Title: flags.title, Template: tmpl}
+	if flags.interactive {
+		if err := promptScaffold(bufio.NewReader(in), prompt, &input); err != nil {
+			return codeError(3, "reading answers: %s", err)
+		}
+	}
+
+	if err := os.WriteFile(specPath, []byte(completion.RenderScaffold(input)), 0o644); err != nil {
+		return codeError(3, "writing spec file: %s", err)
+	}
+	return nil
+}
+
+func loadInitTemplate(flags initFlags) (*completion.Template, error) {
+	if flags.templatePath == "" {
+		tmpl, err := completion.GetTemplate(flags.profileName, flags.profileName)
+		if err != nil {
+			return nil, codeError(3, "invalid profile: %s", err)
+		}
+		return tmpl, nil
+	}
+	data, err := os.ReadFile(flags.templatePath)
+	if err != nil {
+		return nil, codeError(3, "reading template: %s", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(flags.templatePath), filepath.Ext(flags.templatePath))
+	tmpl, err := completion.ParseTemplate(name, string(data))
+	if err != nil {
+		return nil, codeError(3, "invalid template: %s", err)
+	}
+	return tmpl, nil
+}
+
+// promptScaffold asks for the title, actors, and open decisions. An empty
+// title answer keeps the --title value; open decisions end at a blank line.
+func promptScaffold(r *bufio.Reader, w io.Writer, input *completion.ScaffoldInput) error {
+	title, err := promptLine(r, w, "Title")
+	if err != nil {
+		return err
+	}
+	if title != "" {
+		input.Title = title
+	}
+	actors, err := promptLine(r, w, "Actors (comma separated)")
+	if err != nil {
+		return err
+	}
+	if actors != "" {
+		input.Actors = strings.Split(actors, ",")
+	}
+	fmt.Fprintln(w, "Open decisions (one per line, blank line to finish):")
+	for {
+		decision, err := promptLine(r, w, "-")
+		if err != nil {
+			return err
+		}
+		if decision == "" {
+			return nil
+		}
+		input.OpenDecisions = append(input.OpenDecisions, decision)
+	}
+}
+
+func promptLine(r *bufio.Reader, w io.Writer, label string) (string, error) {
+	fmt.Fprintf(w, "%s: ", label)
+	line, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
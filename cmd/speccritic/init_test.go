@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInitProfileScaffoldPassesPreflight(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SPEC.md")
+	if err := runInit(path, initFlags{profileName: "backend-api", title: "Upload API"}, strings.NewReader(""), io.Discard); err != nil {
+		t.Fatalf("runInit: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read scaffold: %v", err)
+	}
+	for _, want := range []string{"# Upload API\n", "## Purpose", "## Endpoints", "## Rate Limits and Abuse Handling", "## Acceptance Criteria"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("scaffold missing %q:\n%s", want, data)
+		}
+	}
+
+	flags := runCheckFlags()
+	flags.profileName = "backend-api"
+	flags.preflight = true
+	flags.preflightMode = "only"
+	flags.out = filepath.Join(t.TempDir(), "out.json")
+	if err := runCheck(path, flags); err != nil {
+		t.Fatalf("runCheck: %v", err)
+	}
+	report := readJSONReport(t, flags.out)
+	for _, issue := range report.Issues {
+		if strings.HasPrefix(issue.ID, "PREFLIGHT-STRUCTURE-") {
+			t.Fatalf("scaffold triggered structural finding %s: %s", issue.ID, issue.Title)
+		}
+	}
+}
+
+func TestRunInitRefusesOverwriteWithoutForce(t *testing.T) {
+	path := writeTempSpec(t, "# Existing\n")
+	err := runInit(path, initFlags{profileName: "general"}, strings.NewReader(""), io.Discard)
+	var ee *exitErr
+	if !asExitErr(err, &ee) || ee.code != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "# Existing\n" {
+		t.Fatalf("existing spec was modified: %q", data)
+	}
+
+	if err := runInit(path, initFlags{profileName: "general", force: true}, strings.NewReader(""), io.Discard); err != nil {
+		t.Fatalf("runInit --force: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "## Purpose") {
+		t.Fatalf("forced scaffold not written:\n%s", data)
+	}
+}
+
+func TestRunInitInteractive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SPEC.md")
+	answers := "Billing Export\nFinance analyst, Scheduler\nChoose export file format\nPick retention window\n\n"
+	if err := runInit(path, initFlags{profileName: "general", interactive: true}, strings.NewReader(answers), io.Discard); err != nil {
+		t.Fatalf("runInit: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{
+		"# Billing Export\n",
+		"- Finance analyst\n- Scheduler\n",
+		"- OPEN DECISION: Choose export file format\n",
+		"- OPEN DECISION: Pick retention window\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("scaffold missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestRunInitCustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "team.md")
+	if err := os.WriteFile(templatePath, []byte("# Team Template\n\n## Rollout Plan\n\nOPEN DECISION: Define rollout stages.\n"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	path := filepath.Join(dir, "SPEC.md")
+	if err := runInit(path, initFlags{templatePath: templatePath}, strings.NewReader(""), io.Discard); err != nil {
+		t.Fatalf("runInit: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "## Rollout Plan\n\nOPEN DECISION: Define rollout stages.\n") {
+		t.Fatalf("custom section missing:\n%s", data)
+	}
+}
+
+func TestRunInitUnknownProfileExitsCode3(t *testing.T) {
+	err := runInit(filepath.Join(t.TempDir(), "SPEC.md"), initFlags{profileName: "mystery"}, strings.NewReader(""), io.Discard)
+	var ee *exitErr
+	if !asExitErr(err, &ee) || ee.code != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+}
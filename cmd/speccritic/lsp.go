@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/speccritic/internal/lsp"
+)
+
+func newLSPCommand() *cobra.Command {
+	config := lsp.DefaultConfig()
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Serve findings to editors over the Language Server Protocol on stdio",
+		Long: "Serve findings to editors over the Language Server Protocol on stdio. " +
+			"Preflight findings are published as diagnostics on every change, the LLM review runs on save, " +
+			"and patches are offered as code actions.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if config.Temperature < 0 || config.Temperature > 2 {
+				return codeError(3, "invalid flags: --temperature must be between 0.0 and 2.0, got %g", config.Temperature)
+			}
+			if config.MaxTokens <= 0 {
+				return codeError(3, "invalid flags: --max-tokens must be > 0, got %d", config.MaxTokens)
+			}
+			config.Version = version
+			config.ErrWriter = os.Stderr
+			if err := lsp.NewServer(config).Serve(cmdContext(), cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+				return codeError(1, "%s", err)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&config.Profile, "profile", config.Profile, "Specification profile")
+	f.BoolVar(&config.Strict, "strict", false, "Enable strict mode (silence = ambiguity)")
+	f.StringArrayVar(&config.ContextPaths, "context", nil, "Context file paths (may be repeated)")
+	f.StringVar(&config.PreflightProfile, "preflight-profile", "", "Override preflight rule profile")
+	f.StringVar(&config.LLMProvider, "llm-provider", "", "LLM provider override: anthropic, openai, or gemini")
+	f.StringVar(&config.LLMModel, "llm-model", "", "LLM model override")
+	f.Float64Var(&config.Temperature, "temperature", config.Temperature, "LLM temperature")
+	f.IntVar(&config.MaxTokens, "max-tokens", config.MaxTokens, "Maximum response tokens")
+	f.BoolVar(&config.CompletionSuggestions, "completion-suggestions", config.CompletionSuggestions, "Offer completion patches as code actions")
+	f.BoolVar(&config.ReviewOnSave, "review-on-save", config.ReviewOnSave, "Run the LLM review when a document is saved")
+	return cmd
+}
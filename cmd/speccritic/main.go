@@ -14,9 +14,11 @@ import (
 	"github.com/dshills/speccritic/internal/chunk"
 	"github.com/dshills/speccritic/internal/convergence"
 	"github.com/dshills/speccritic/internal/incremental"
+	"github.com/dshills/speccritic/internal/patch"
 	"github.com/dshills/speccritic/internal/render"
 	"github.com/dshills/speccritic/internal/review"
 	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
 )
 
 // version is set at build time via -ldflags "-X main.version=x.y.z".
@@ -45,6 +47,7 @@ type checkFlags struct {
 	failOn                          string
 	severityThreshold               string
 	patchOut                        string
+	patchFormat                     string
 	llmProvider                     string
 	llmModel                        string
 	temperature                     float64
@@ -56,6 +59,10 @@ type checkFlags struct {
 	preflightMode                   string
 	preflightProfile                string
 	preflightIgnore                 []string
+	preflightFix                    []string
+	requirementIDPatterns           []string
+	termSynonyms                    []string
+	systemActors                    []string
 	chunking                        string
 	chunkLines                      int
 	chunkOverlap                    int
@@ -71,6 +78,8 @@ type checkFlags struct {
 	incrementalContextLines         int
 	incrementalStrictReuse          bool
 	incrementalReport               bool
+	answersPath                     string
+	triagePath                      string
 	convergenceFrom                 string
 	convergenceMode                 string
 	convergenceStrict               bool
@@ -80,6 +89,11 @@ type checkFlags struct {
 	completionTemplate              string
 	completionMaxPatches            int
 	completionOpenDecisions         bool
+	batchConcurrency                int
+	outDir                          string
+	specDir                         string
+	sections                        []string
+	excludeSections                 []string
 	envErrors                       []string
 }
 
@@ -93,12 +107,26 @@ func main() {
 
 	var flags checkFlags
 	checkCmd := &cobra.Command{
-		Use:   "check <spec-file>",
-		Short: "Analyze a specification and produce a review",
-		Args:  cobra.ExactArgs(1),
+		Use:   "check [<spec-file>...]",
+		Short: "Analyze one or more specifications and produce a review",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if flags.specDir != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("--spec-dir cannot be combined with spec file arguments")
+				}
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			applyEnvDefaults(cmd, &flags)
-			return runCheck(args[0], flags)
+			if flags.specDir != "" {
+				return runCheck("", flags)
+			}
+			if len(args) == 1 && !spec.HasGlobMeta(args[0]) {
+				return runCheck(args[0], flags)
+			}
+			return runBatchCheck(args, flags)
 		},
 	}
 
@@ -110,7 +138,8 @@ func main() {
 	f.BoolVar(&flags.strict, "strict", false, "Enable strict mode (silence = ambiguity)")
 	f.StringVar(&flags.failOn, "fail-on", "", "Exit 2 if verdict >= this level (VALID_WITH_GAPS or INVALID)")
 	f.StringVar(&flags.severityThreshold, "severity-threshold", "info", "Minimum severity to emit: info, warn, or critical")
-	f.StringVar(&flags.patchOut, "patch-out", "", "Write suggested patches in diff-match-patch format to this file")
+	f.StringVar(&flags.patchOut, "patch-out", "", "Write suggested patches to this file (format set by --patch-format)")
+	f.StringVar(&flags.patchFormat, "patch-format", patch.FormatDMP, "Patch file format for --patch-out: dmp or unified")
 	f.StringVar(&flags.llmProvider, "llm-provider", "", "LLM provider override: anthropic, openai, or gemini")
 	f.StringVar(&flags.llmModel, "llm-model", "", "LLM model override")
 	f.Float64Var(&flags.temperature, "temperature", 0.2, "LLM temperature")
@@ -122,6 +151,10 @@ func main() {
 	f.StringVar(&flags.preflightMode, "preflight-mode", "warn", "Preflight mode: warn, gate, or only")
 	f.StringVar(&flags.preflightProfile, "preflight-profile", "", "Override preflight rule profile")
 	f.StringArrayVar(&flags.preflightIgnore, "preflight-ignore", nil, "Preflight rule ID to suppress (may be repeated)")
+	f.StringArrayVar(&flags.preflightFix, "preflight-fix", nil, "Preflight rule ID whose autofixes are emitted as patches (may be repeated)")
+	f.StringArrayVar(&flags.requirementIDPatterns, "requirement-id-pattern", nil, "Regular expression matching requirement IDs; replaces the default REQ-001 style pattern (may be repeated)")
+	f.StringArrayVar(&flags.termSynonyms, "term-synonyms", nil, "Comma-separated terms that name the same concept, checked for terminology drift (may be repeated)")
+	f.StringArrayVar(&flags.systemActors, "system-actor", nil, "Component name that counts as the agent of a passive requirement (may be repeated)")
 	f.StringVar(&flags.chunking, "chunking", "auto", "Chunking mode: auto, on, or off")
 	f.IntVar(&flags.chunkLines, "chunk-lines", 180, "Target maximum source lines per chunk before overlap")
 	f.IntVar(&flags.chunkOverlap, "chunk-overlap", 20, "Neighboring lines included before and after each chunk for context")
@@ -137,6 +170,8 @@ func main() {
 	f.IntVar(&flags.incrementalContextLines, "incremental-context-lines", 20, "Neighboring unchanged lines included around each incremental review range")
 	f.BoolVar(&flags.incrementalStrictReuse, "incremental-strict-reuse", true, "Reuse prior findings only when evidence remaps safely")
 	f.BoolVar(&flags.incrementalReport, "incremental-report", false, "Include optional meta.incremental details in JSON output")
+	f.StringVar(&flags.answersPath, "answers", "", "Questionnaire from 'speccritic questions export' with answers to feed into the review")
+	f.StringVar(&flags.triagePath, "triage", "", "Triage decisions exported from the web UI; accepted and dismissed findings are suppressed")
 	f.StringVar(&flags.convergenceFrom, "convergence-from", "", "Path to previous SpecCritic JSON report for convergence tracking")
 	f.StringVar(&flags.convergenceMode, "convergence-mode", "auto", "Convergence mode: auto, on, or off")
 	f.BoolVar(&flags.convergenceStrict, "convergence-strict", false, "Require strict convergence compatibility checks")
@@ -146,8 +181,13 @@ func main() {
 	f.StringVar(&flags.completionTemplate, "completion-template", "profile", "Completion template: profile, general, backend-api, regulated-system, or event-driven")
 	f.IntVar(&flags.completionMaxPatches, "completion-max-patches", 8, "Maximum completion patches to emit")
 	f.BoolVar(&flags.completionOpenDecisions, "completion-open-decisions", true, "Insert OPEN DECISION placeholders instead of inventing unstated behavior")
+	f.IntVar(&flags.batchConcurrency, "batch-concurrency", app.DefaultBatchConcurrency, "Maximum specs and LLM calls in flight when checking multiple specs")
+	f.StringVar(&flags.outDir, "out-dir", "", "Write one report per spec into this directory when checking multiple specs")
+	f.StringArrayVar(&flags.sections, "section", nil, "Review only sections whose heading matches this pattern (may be repeated)")
+	f.StringArrayVar(&flags.excludeSections, "exclude-section", nil, "Skip sections whose heading matches this pattern (may be repeated)")
+	f.StringVar(&flags.specDir, "spec-dir", "", "Check every .md file in this directory as one multi-file spec")
 
-	root.AddCommand(checkCmd)
+	root.AddCommand(checkCmd, newInitCommand(), newQuestionsCommand(), newApplyCommand(), newLSPCommand(), newMCPCommand())
 
 	if err := root.Execute(); err != nil {
 		var ee *exitErr
@@ -166,9 +206,64 @@ func runCheck(specPath string, flags checkFlags) error {
 		return codeError(3, "invalid flags: %s", err)
 	}
 
-	result, err := app.NewChecker().Check(cmdContext(), app.CheckRequest{
+	result, err := app.NewChecker().Check(cmdContext(), checkRequest(specPath, flags))
+	if err != nil {
+		return mapAppError(err)
+	}
+	report := cloneReport(result.Report)
+
+	// --- Step 14: Apply severity threshold filter (output only, does not affect score/counts) ---
+	severityFilter := parseSeverityThreshold(flags.severityThreshold)
+	report.Issues = review.FilterBySeverity(report.Issues, severityFilter)
+	report.Questions = review.FilterQuestionsBySeverity(report.Questions, severityFilter)
+
+	// --- Step 15: Write patches ---
+	if flags.patchOut != "" {
+		logVerbose(flags.verbose, "Generating patches → %s", flags.patchOut)
+		if err := os.WriteFile(flags.patchOut, []byte(result.PatchDiff), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: patch write failed: %s\n", err)
+			// Continue — patches are advisory per SPEC.md §12
+		}
+	}
+
+	// --- Step 16: Render output ---
+	logVerbose(flags.verbose, "Rendering output (format: %s)", flags.format)
+	renderer, err := render.NewRenderer(flags.format)
+	if err != nil {
+		return codeError(3, "invalid format: %s", err)
+	}
+	outputBytes, err := renderer.Render(report)
+	if err != nil {
+		return codeError(3, "rendering output: %s", err)
+	}
+
+	// --- Step 17: Write output ---
+	if err := writeOutput(flags.out, outputBytes); err != nil {
+		return err
+	}
+
+	// --- Step 18: Evaluate --fail-on ---
+	if flags.failOn != "" {
+		verdictThreshold := schema.Verdict(flags.failOn)
+		verdict := report.Summary.Verdict
+		if schema.VerdictOrdinal(verdict) >= schema.VerdictOrdinal(verdictThreshold) {
+			return codeError(2, "verdict %s meets or exceeds --fail-on threshold %s", verdict, verdictThreshold)
+		}
+	}
+
+	return nil
+}
+
+// checkRequest builds the application request for one spec from CLI flags.
+func checkRequest(specPath string, flags checkFlags) app.CheckRequest {
+	return app.CheckRequest{
 		Version:                         version,
 		SpecPath:                        specPath,
+		SpecDir:                         flags.specDir,
+		AnswersPath:                     flags.answersPath,
+		TriagePath:                      flags.triagePath,
+		Sections:                        flags.sections,
+		ExcludeSections:                 flags.excludeSections,
 		ContextPaths:                    flags.contextFiles,
 		Profile:                         flags.profileName,
 		Strict:                          flags.strict,
@@ -184,6 +279,10 @@ func runCheck(specPath string, flags checkFlags) error {
 		PreflightMode:                   flags.preflightMode,
 		PreflightProfile:                flags.preflightProfile,
 		PreflightIgnore:                 flags.preflightIgnore,
+		PreflightFix:                    flags.preflightFix,
+		RequirementIDPatterns:           flags.requirementIDPatterns,
+		TermSynonyms:                    parseTermSynonyms(flags.termSynonyms),
+		SystemActors:                    flags.systemActors,
 		Chunking:                        flags.chunking,
 		ChunkLines:                      flags.chunkLines,
 		ChunkOverlap:                    flags.chunkOverlap,
@@ -208,64 +307,25 @@ func runCheck(specPath string, flags checkFlags) error {
 		CompletionTemplate:              flags.completionTemplate,
 		CompletionMaxPatches:            flags.completionMaxPatches,
 		CompletionOpenDecisions:         flags.completionOpenDecisions,
+		PatchFormat:                     flags.patchFormat,
 		Source:                          app.SourceCLI,
 		ErrWriter:                       os.Stderr,
-	})
-	if err != nil {
-		return mapAppError(err)
-	}
-	report := cloneReport(result.Report)
-
-	// --- Step 14: Apply severity threshold filter (output only, does not affect score/counts) ---
-	severityFilter := parseSeverityThreshold(flags.severityThreshold)
-	report.Issues = review.FilterBySeverity(report.Issues, severityFilter)
-	report.Questions = review.FilterQuestionsBySeverity(report.Questions, severityFilter)
-
-	// --- Step 15: Write patches ---
-	if flags.patchOut != "" {
-		logVerbose(flags.verbose, "Generating patches → %s", flags.patchOut)
-		if err := os.WriteFile(flags.patchOut, []byte(result.PatchDiff), 0o644); err != nil {
-			fmt.Fprintf(os.Stderr, "WARN: patch write failed: %s\n", err)
-			// Continue — patches are advisory per SPEC.md §12
-		}
-	}
-
-	// --- Step 16: Render output ---
-	logVerbose(flags.verbose, "Rendering output (format: %s)", flags.format)
-	renderer, err := render.NewRenderer(flags.format)
-	if err != nil {
-		return codeError(3, "invalid format: %s", err)
-	}
-	outputBytes, err := renderer.Render(report)
-	if err != nil {
-		return codeError(3, "rendering output: %s", err)
-	}
-
-	// --- Step 17: Write output ---
-	if flags.out != "" {
-		if err := os.WriteFile(flags.out, outputBytes, 0o644); err != nil {
-			return codeError(3, "writing output file: %s", err)
-		}
-	} else {
-		if _, err := os.Stdout.Write(outputBytes); err != nil {
-			return codeError(3, "writing output: %s", err)
-		}
-		// Ensure output ends with a newline for terminal friendliness.
-		if len(outputBytes) > 0 && outputBytes[len(outputBytes)-1] != '\n' {
-			_, _ = fmt.Fprintln(os.Stdout)
-		}
 	}
+}
 
-	// --- Step 18: Evaluate --fail-on ---
-	if flags.failOn != "" {
-		verdictThreshold := schema.Verdict(flags.failOn)
-		verdict := report.Summary.Verdict
-		if schema.VerdictOrdinal(verdict) >= schema.VerdictOrdinal(verdictThreshold) {
-			return codeError(2, "verdict %s meets or exceeds --fail-on threshold %s", verdict, verdictThreshold)
+// parseTermSynonyms splits each --term-synonyms value into its terms.
+func parseTermSynonyms(values []string) [][]string {
+	groups := make([][]string, 0, len(values))
+	for _, value := range values {
+		var group []string
+		for _, term := range strings.Split(value, ",") {
+			if term = strings.TrimSpace(term); term != "" {
+				group = append(group, term)
+			}
 		}
+		groups = append(groups, group)
 	}
-
-	return nil
+	return groups
 }
 
 func cmdContext() context.Context {
@@ -330,6 +390,11 @@ func validateFlags(flags checkFlags) error {
 	if flags.maxTokens <= 0 {
 		return fmt.Errorf("--max-tokens must be > 0, got %d", flags.maxTokens)
 	}
+	for i, group := range parseTermSynonyms(flags.termSynonyms) {
+		if len(group) < 2 {
+			return fmt.Errorf("--term-synonyms needs at least two comma-separated terms, got %q", flags.termSynonyms[i])
+		}
+	}
 	if err := chunk.ValidateConfig(chunk.WithDefaults(chunk.Config{
 		Mode:                   chunk.Mode(flags.chunking),
 		ChunkLines:             flags.chunkLines,
@@ -376,6 +441,9 @@ func validateFlags(flags checkFlags) error {
 	default:
 		return fmt.Errorf("--preflight-mode must be warn, gate, or only, got %q", flags.preflightMode)
 	}
+	if len(flags.preflightFix) > 0 && !flags.preflight {
+		return fmt.Errorf("--preflight-fix requires --preflight")
+	}
 	switch flags.completionMode {
 	case schema.CompletionModeAuto, schema.CompletionModeOn, schema.CompletionModeOff:
 	default:
@@ -387,6 +455,11 @@ func validateFlags(flags checkFlags) error {
 	if flags.completionMaxPatches < 0 {
 		return fmt.Errorf("--completion-max-patches must be >= 0, got %d", flags.completionMaxPatches)
 	}
+	switch flags.patchFormat {
+	case patch.FormatDMP, patch.FormatUnified:
+	default:
+		return fmt.Errorf("--patch-format must be dmp or unified, got %q", flags.patchFormat)
+	}
 
 	return nil
 }
@@ -538,6 +611,7 @@ func applyEnvDefaults(cmd *cobra.Command, flags *checkFlags) {
 	envStr("completion-template", "SPECCRITIC_COMPLETION_TEMPLATE", &flags.completionTemplate)
 	envIntStrict("completion-max-patches", "SPECCRITIC_COMPLETION_MAX_PATCHES", &flags.completionMaxPatches)
 	envBoolStrict("completion-open-decisions", "SPECCRITIC_COMPLETION_OPEN_DECISIONS", &flags.completionOpenDecisions)
+	envIntStrict("batch-concurrency", "SPECCRITIC_BATCH_CONCURRENCY", &flags.batchConcurrency)
 }
 
 // logVerbose writes a timestamped message to stderr when verbose mode is enabled.
@@ -97,6 +97,7 @@ func runCheckFlags() checkFlags {
 		completionTemplate:      "profile",
 		completionMaxPatches:    8,
 		completionOpenDecisions: true,
+		patchFormat:             "dmp",
 	}
 }
 
@@ -299,6 +300,72 @@ func TestRunCheck_PatchOut(t *testing.T) {
 	}
 }
 
+func TestRunCheck_PatchOutUnified(t *testing.T) {
+	setTestEnv(t)
+	const patchFixture = `{
+  "id": "msg_patch",
+  "model": "claude-sonnet-4-20250514",
+  "content": [{"type": "text", "text": "{\"issues\":[{\"id\":\"ISSUE-0001\",\"severity\":\"CRITICAL\",\"category\":\"NON_TESTABLE_REQUIREMENT\",\"title\":\"Vague\",\"description\":\"vague\",\"evidence\":[{\"path\":\"bad_spec.md\",\"line_start\":5,\"line_end\":5,\"quote\":\"fast\"}],\"impact\":\"x\",\"recommendation\":\"y\",\"blocking\":true,\"tags\":[]}],\"questions\":[],\"patches\":[{\"issue_id\":\"ISSUE-0001\",\"before\":\"This system must perform well and be fast.\",\"after\":\"This system SHALL respond with P99 latency ≤ 200 ms.\"}]}"}],
+  "stop_reason": "end_turn"
+}`
+	setupMockAnthropicServer(t, []byte(patchFixture))
+
+	flags := runCheckFlags()
+	flags.patchOut = filepath.Join(t.TempDir(), "spec.diff")
+	flags.patchFormat = "unified"
+	path := specPath("bad_spec.md")
+	if err := runCheck(path, flags); err != nil {
+		t.Fatalf("runCheck: %v", err)
+	}
+
+	data, err := os.ReadFile(flags.patchOut)
+	if err != nil {
+		t.Fatalf("patch file not created: %v", err)
+	}
+	name := filepath.ToSlash(path)
+	for _, want := range []string{"--- a/" + name + "\n+++ b/" + name + "\n@@ -", "-This system must perform well and be fast.\n", "+This system SHALL respond with P99 latency ≤ 200 ms.\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("unified diff missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestValidateFlags_PatchFormat(t *testing.T) {
+	flags := runCheckFlags()
+	flags.patchFormat = "git"
+	if err := validateFlags(flags); err == nil || !strings.Contains(err.Error(), "--patch-format") {
+		t.Fatalf("err = %v, want --patch-format error", err)
+	}
+}
+
+func TestValidateFlags_TermSynonyms(t *testing.T) {
+	flags := runCheckFlags()
+	flags.termSynonyms = []string{"user account, customer profile"}
+	if err := validateFlags(flags); err != nil {
+		t.Fatalf("validateFlags: %v", err)
+	}
+	if got := parseTermSynonyms(flags.termSynonyms); len(got) != 1 || len(got[0]) != 2 || got[0][1] != "customer profile" {
+		t.Fatalf("groups = %q", got)
+	}
+	flags.termSynonyms = []string{"account,"}
+	if err := validateFlags(flags); err == nil || !strings.Contains(err.Error(), "--term-synonyms") {
+		t.Fatalf("err = %v, want --term-synonyms error", err)
+	}
+}
+
+func TestValidateFlags_PreflightFixRequiresPreflight(t *testing.T) {
+	flags := runCheckFlags()
+	flags.preflight = true
+	flags.preflightFix = []string{"PREFLIGHT-TODO-001"}
+	if err := validateFlags(flags); err != nil {
+		t.Fatalf("validateFlags: %v", err)
+	}
+	flags.preflight = false
+	if err := validateFlags(flags); err == nil || !strings.Contains(err.Error(), "--preflight-fix") {
+		t.Fatalf("err = %v, want --preflight-fix error", err)
+	}
+}
+
 func TestRunCheck_Debug_DoesNotFail(t *testing.T) {
 	setTestEnv(t)
 	setupMockAnthropicServer(t, readFixture(t, "anthropic_response_good.json"))
@@ -697,3 +764,72 @@ func reportHasIssue(issues []schema.Issue, id string) bool {
 	}
 	return false
 }
+
+func TestRunCheckSpecDirReportsPerFileEvidence(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "01-intro.md"), []byte("# Intro\nThe system stores orders.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "02-auth.md"), []byte("## Auth\nTODO define login.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flags := runCheckFlags()
+	flags.preflight = true
+	flags.preflightMode = "only"
+	flags.specDir = dir
+	flags.out = filepath.Join(t.TempDir(), "report.json")
+
+	if err := runCheck("", flags); err != nil {
+		t.Fatalf("runCheck: %v", err)
+	}
+	report := readJSONReport(t, flags.out)
+	if report.Input.SpecFile != dir {
+		t.Fatalf("spec file = %q, want %q", report.Input.SpecFile, dir)
+	}
+	for _, issue := range report.Issues {
+		if issue.ID != "PREFLIGHT-TODO-001" {
+			continue
+		}
+		ev := issue.Evidence[0]
+		if ev.Path != filepath.Join(dir, "02-auth.md") || ev.LineStart != 2 {
+			t.Fatalf("evidence = %+v, want 02-auth.md:2", ev)
+		}
+		return
+	}
+	t.Fatalf("issues = %#v, want PREFLIGHT-TODO-001", report.Issues)
+}
+
+func TestRunQuestionsExportWritesQuestionnaire(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "report.json")
+	report := schema.Report{
+		Tool:      "speccritic",
+		Input:     schema.Input{SpecFile: "SPEC.md"},
+		Questions: []schema.Question{{ID: "Q-0001", Severity: schema.SeverityWarn, Question: "Who approves refunds?"}},
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(reportPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "QUESTIONS.md")
+	if err := runQuestionsExport(reportPath, out); err != nil {
+		t.Fatalf("runQuestionsExport: %v", err)
+	}
+	written, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), "## Q-0001 (WARN)") || !strings.Contains(string(written), "**Question:** Who approves refunds?") {
+		t.Fatalf("questionnaire = %s", written)
+	}
+	if err := os.WriteFile(reportPath, []byte(`{"tool":"other"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var ee *exitErr
+	if err := runQuestionsExport(reportPath, out); !asExitErr(err, &ee) || ee.code != 3 {
+		t.Fatalf("err = %v, want exit code 3", err)
+	}
+}
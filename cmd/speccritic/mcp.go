@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/speccritic/internal/mcp"
+)
+
+func newMCPCommand() *cobra.Command {
+	config := mcp.DefaultConfig()
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve SpecCritic tools to coding agents over the Model Context Protocol on stdio",
+		Long: "Serve SpecCritic tools to coding agents over the Model Context Protocol on stdio. " +
+			"Tools run preflight and full checks, read issues and questions, and apply patches; " +
+			"each spec's last review is kept so later checks run incrementally.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if config.Temperature < 0 || config.Temperature > 2 {
+				return codeError(3, "invalid flags: --temperature must be between 0.0 and 2.0, got %g", config.Temperature)
+			}
+			if config.MaxTokens <= 0 {
+				return codeError(3, "invalid flags: --max-tokens must be > 0, got %d", config.MaxTokens)
+			}
+			config.Version = version
+			config.ErrWriter = os.Stderr
+			if err := mcp.NewServer(config).Serve(cmdContext(), cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+				return codeError(1, "%s", err)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&config.Profile, "profile", config.Profile, "Default specification profile")
+	f.BoolVar(&config.Strict, "strict", false, "Enable strict mode by default (silence = ambiguity)")
+	f.StringArrayVar(&config.ContextPaths, "context", nil, "Context file paths (may be repeated)")
+	f.StringVar(&config.PreflightProfile, "preflight-profile", "", "Override preflight rule profile")
+	f.StringVar(&config.LLMProvider, "llm-provider", "", "LLM provider override: anthropic, openai, or gemini")
+	f.StringVar(&config.LLMModel, "llm-model", "", "LLM model override")
+	f.Float64Var(&config.Temperature, "temperature", config.Temperature, "LLM temperature")
+	f.IntVar(&config.MaxTokens, "max-tokens", config.MaxTokens, "Maximum response tokens")
+	f.BoolVar(&config.CompletionSuggestions, "completion-suggestions", false, "Include profile-specific completion patches in reports")
+	return cmd
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/speccritic/internal/answers"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func newQuestionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "questions",
+		Short: "Work with clarification questions from a review",
+	}
+	var out string
+	export := &cobra.Command{
+		Use:   "export <report.json>",
+		Short: "Write a report's questions to an editable Markdown questionnaire",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQuestionsExport(args[0], out)
+		},
+	}
+	export.Flags().StringVar(&out, "out", "", "Write the questionnaire to file instead of stdout")
+	cmd.AddCommand(export)
+	return cmd
+}
+
+func runQuestionsExport(reportPath, out string) error {
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return codeError(3, "reading report: %s", err)
+	}
+	var report schema.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return codeError(3, "parsing report %s: %s", reportPath, err)
+	}
+	if report.Tool != "speccritic" {
+		return codeError(3, "%s is not a SpecCritic JSON report", reportPath)
+	}
+	return writeOutput(out, answers.Export(&report))
+}
@@ -0,0 +1,236 @@
+// Package answers turns clarification questions into an editable Markdown
+// questionnaire and feeds the answers back into later reviews.
+package answers
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/convergence"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const (
+	answerHeading = "### Answer"
+	answerHint    = "<!-- Write the answer below. Leave it empty while the question is open. -->"
+	clarifyOpen   = "<authoritative_clarifications>"
+	clarifyClose  = "</authoritative_clarifications>"
+)
+
+var (
+	questionHeading = regexp.MustCompile(`^## (Q-\d+)(?: \((CRITICAL|WARN|INFO)\))?\s*$`)
+	evidenceLine    = regexp.MustCompile(`^- (.+):L(\d+)-L(\d+)$`)
+	htmlComment     = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// Entry is one question in a questionnaire with its answer, if any.
+type Entry struct {
+	Question schema.Question
+	Answer   string
+}
+
+// Export renders the report's questions as a Markdown questionnaire. Open
+// questions get an empty answer block; questions already answered in the
+// report keep their answer so the file can be reused across runs.
+func Export(report *schema.Report) []byte {
+	var b strings.Builder
+	b.WriteString("# SpecCritic Questions\n\n")
+	if report != nil {
+		fmt.Fprintf(&b, "Spec: %s (%s)\n\n", report.Input.SpecFile, report.Input.SpecHash)
+	}
+	b.WriteString("Answer each question under its Answer heading, then pass this file to `speccritic check --answers`.\n")
+	if report == nil {
+		return []byte(b.String())
+	}
+	for _, q := range report.Questions {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", q.ID, q.Severity)
+		fmt.Fprintf(&b, "**Question:** %s\n\n", oneLine(q.Question))
+		if q.WhyNeeded != "" {
+			fmt.Fprintf(&b, "**Why needed:** %s\n\n", oneLine(q.WhyNeeded))
+		}
+		if len(q.Evidence) > 0 {
+			b.WriteString("**Evidence:**\n\n")
+			for _, ev := range q.Evidence {
+				fmt.Fprintf(&b, "- %s:L%d-L%d\n", ev.Path, ev.LineStart, ev.LineEnd)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(answerHeading + "\n\n" + answerHint + "\n")
+		if q.Answer != "" {
+			b.WriteString("\n" + strings.TrimSpace(q.Answer) + "\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// Load reads and parses a questionnaire file.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading answers file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse reads a questionnaire written by Export. Only the question headings,
+// question text, evidence list, and answer blocks are significant.
+func Parse(data []byte) ([]Entry, error) {
+	var entries []Entry
+	var current *Entry
+	var answer []string
+	inAnswer := false
+	flush := func() {
+		if current == nil {
+			return
+		}
+		text := htmlComment.ReplaceAllString(strings.Join(answer, "\n"), "")
+		current.Answer = strings.TrimSpace(text)
+		entries = append(entries, *current)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if m := questionHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &Entry{Question: schema.Question{ID: m[1], Severity: schema.Severity(m[2])}}
+			answer = nil
+			inAnswer = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if inAnswer {
+			answer = append(answer, line)
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == answerHeading:
+			inAnswer = true
+		case strings.HasPrefix(trimmed, "**Question:**"):
+			current.Question.Question = strings.TrimSpace(strings.TrimPrefix(trimmed, "**Question:**"))
+		case strings.HasPrefix(trimmed, "**Why needed:**"):
+			current.Question.WhyNeeded = strings.TrimSpace(strings.TrimPrefix(trimmed, "**Why needed:**"))
+		default:
+			if m := evidenceLine.FindStringSubmatch(trimmed); m != nil {
+				start, _ := strconv.Atoi(m[2])
+				end, _ := strconv.Atoi(m[3])
+				current.Question.Evidence = append(current.Question.Evidence, schema.Evidence{Path: m[1], LineStart: start, LineEnd: end})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parsing answers file: %w", err)
+	}
+	flush()
+	for _, entry := range entries {
+		if entry.Answer != "" && strings.TrimSpace(entry.Question.Question) == "" {
+			return nil, fmt.Errorf("answers file: %s has an answer but no question text", entry.Question.ID)
+		}
+	}
+	return entries, nil
+}
+
+// Answered returns the entries that have a non-empty answer.
+func Answered(entries []Entry) []Entry {
+	var out []Entry
+	for _, entry := range entries {
+		if entry.Answer != "" {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// PromptContext renders answered questions as a prompt block the model must
+// treat as part of the spec.
+func PromptContext(entries []Entry) string {
+	answered := Answered(entries)
+	if len(answered) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(clarifyOpen + "\n")
+	sb.WriteString("The spec owner answered these clarification questions. Treat each answer as authoritative and as part of the spec. Do not ask these questions again or report the gaps they close; do report conflicts between an answer and the spec text.\n")
+	for _, entry := range answered {
+		fmt.Fprintf(&sb, "- %s: %s\n  Answer: %s\n", entry.Question.ID, promptText(entry.Question.Question), promptText(entry.Answer))
+	}
+	sb.WriteString(clarifyClose + "\n")
+	return sb.String()
+}
+
+// Apply marks questions answered by entries. A current question that matches
+// an answered entry takes its answer; answered entries the model did not ask
+// again are appended as answered questions so the report keeps the record.
+// Evidence beyond lineCount is dropped from appended questions.
+func Apply(questions []schema.Question, entries []Entry, lineCount int) []schema.Question {
+	answered := Answered(entries)
+	if len(answered) == 0 {
+		return questions
+	}
+	out := append([]schema.Question(nil), questions...)
+	previous := make([]schema.Question, len(answered))
+	for i, entry := range answered {
+		previous[i] = entry.Question
+	}
+	matched := make(map[int]bool, len(answered))
+	for _, match := range convergence.MatchFindings(convergence.TrackQuestions(previous), convergence.TrackQuestions(out)) {
+		entry := answered[match.Previous.SourceIndex]
+		out[match.Current.SourceIndex].Status = schema.QuestionStatusAnswered
+		out[match.Current.SourceIndex].Answer = entry.Answer
+		matched[match.Previous.SourceIndex] = true
+	}
+	next := nextQuestionNumber(out)
+	for i, entry := range answered {
+		if matched[i] {
+			continue
+		}
+		q := entry.Question
+		q.ID = fmt.Sprintf("Q-%04d", next)
+		next++
+		if q.Severity == "" {
+			q.Severity = schema.SeverityInfo
+		}
+		q.Status = schema.QuestionStatusAnswered
+		q.Answer = entry.Answer
+		q.Evidence = evidenceWithin(q.Evidence, lineCount)
+		out = append(out, q)
+	}
+	return out
+}
+
+func nextQuestionNumber(questions []schema.Question) int {
+	next := 1
+	for _, q := range questions {
+		n, err := strconv.Atoi(strings.TrimPrefix(q.ID, "Q-"))
+		if err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
+
+func evidenceWithin(evidence []schema.Evidence, lineCount int) []schema.Evidence {
+	out := make([]schema.Evidence, 0, len(evidence))
+	for _, ev := range evidence {
+		if ev.LineStart >= 1 && ev.LineEnd >= ev.LineStart && ev.LineEnd <= lineCount {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func promptText(text string) string {
+	return strings.ReplaceAll(oneLine(text), clarifyClose, "")
+}
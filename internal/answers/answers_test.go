@@ -0,0 +1,74 @@
+package answers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestExportParseRoundTrip(t *testing.T) {
+	report := &schema.Report{
+		Tool:  "speccritic",
+		Input: schema.Input{SpecFile: "SPEC.md", SpecHash: "sha256:abc"},
+		Questions: []schema.Question{
+			{ID: "Q-0001", Severity: schema.SeverityCritical, Question: "What is the login timeout?", WhyNeeded: "Clients need it.", Evidence: []schema.Evidence{{Path: "SPEC.md", LineStart: 4, LineEnd: 5}}},
+			{ID: "Q-0002", Severity: schema.SeverityWarn, Question: "Who can delete orders?"},
+		},
+	}
+	exported := string(Export(report))
+	if !strings.Contains(exported, "## Q-0001 (CRITICAL)") || !strings.Contains(exported, "- SPEC.md:L4-L5") {
+		t.Fatalf("export = %s", exported)
+	}
+	edited := strings.Replace(exported, answerHint+"\n\n## Q-0002", answerHint+"\n\nSessions expire after 15 minutes.\nNo refresh.\n\n## Q-0002", 1)
+
+	entries, err := Parse([]byte(edited))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %#v", entries)
+	}
+	first := entries[0]
+	if first.Question.Question != "What is the login timeout?" || first.Question.Severity != schema.SeverityCritical {
+		t.Fatalf("question = %#v", first.Question)
+	}
+	if first.Answer != "Sessions expire after 15 minutes.\nNo refresh." {
+		t.Fatalf("answer = %q", first.Answer)
+	}
+	if len(first.Question.Evidence) != 1 || first.Question.Evidence[0].LineEnd != 5 {
+		t.Fatalf("evidence = %#v", first.Question.Evidence)
+	}
+	if entries[1].Answer != "" || len(Answered(entries)) != 1 {
+		t.Fatalf("second entry should be open: %#v", entries[1])
+	}
+	prompt := PromptContext(entries)
+	if !strings.Contains(prompt, "Q-0001: What is the login timeout?") || strings.Contains(prompt, "Q-0002") {
+		t.Fatalf("prompt = %s", prompt)
+	}
+}
+
+func TestApplyMarksMatchedAndAppendsUnaskedAnswers(t *testing.T) {
+	entries := []Entry{
+		{Question: schema.Question{ID: "Q-0001", Severity: schema.SeverityCritical, Question: "What is the login timeout?", Evidence: []schema.Evidence{{Path: "SPEC.md", LineStart: 4, LineEnd: 4}}}, Answer: "15 minutes."},
+		{Question: schema.Question{ID: "Q-0002", Severity: schema.SeverityWarn, Question: "Who can delete orders?", Evidence: []schema.Evidence{{Path: "SPEC.md", LineStart: 90, LineEnd: 91}}}, Answer: "Admins only."},
+	}
+	current := []schema.Question{
+		{ID: "Q-0001", Severity: schema.SeverityCritical, Question: "What is the login timeout?", Evidence: []schema.Evidence{{Path: "SPEC.md", LineStart: 4, LineEnd: 4}}},
+		{ID: "Q-0002", Severity: schema.SeverityInfo, Question: "Which currency is used?"},
+	}
+	got := Apply(current, entries, 20)
+	if len(got) != 3 {
+		t.Fatalf("questions = %#v", got)
+	}
+	if got[0].Status != schema.QuestionStatusAnswered || got[0].Answer != "15 minutes." {
+		t.Fatalf("matched question = %#v", got[0])
+	}
+	if got[1].Status != "" {
+		t.Fatalf("unrelated question should stay open: %#v", got[1])
+	}
+	appended := got[2]
+	if appended.ID != "Q-0003" || appended.Status != schema.QuestionStatusAnswered || len(appended.Evidence) != 0 {
+		t.Fatalf("appended question = %#v", appended)
+	}
+}
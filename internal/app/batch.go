@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dshills/speccritic/internal/llm"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// DefaultBatchConcurrency bounds concurrent spec checks when a batch config
+// does not set one.
+const DefaultBatchConcurrency = 4
+
+// BatchConfig controls a multi-spec check run.
+type BatchConfig struct {
+	// Concurrency bounds both the number of specs checked at once and the
+	// number of LLM calls in flight across the whole batch.
+	Concurrency int
+}
+
+// BatchItem is the outcome of one request in a batch run.
+type BatchItem struct {
+	Request CheckRequest
+	Result  *CheckResult
+	Err     error
+}
+
+// CheckFunc runs one check; Checker.Check satisfies it.
+type CheckFunc func(ctx context.Context, req CheckRequest) (*CheckResult, error)
+
+// CheckBatch checks every request with bounded concurrency. All requests share
+// one provider per model and one limiter on in-flight LLM calls, so chunked
+// specs cannot multiply the provider load. Items are returned in request order.
+func (c *Checker) CheckBatch(ctx context.Context, reqs []CheckRequest, cfg BatchConfig) []BatchItem {
+	cfg = batchConfigWithDefaults(cfg)
+	shared := &Checker{NewProvider: newSharedProviderFactory(c.NewProvider, cfg.Concurrency)}
+	return RunBatch(ctx, shared.Check, reqs, cfg)
+}
+
+// RunBatch runs check for every request with at most cfg.Concurrency checks in
+// flight. A failed request is recorded in its item and does not stop the batch.
+func RunBatch(ctx context.Context, check CheckFunc, reqs []CheckRequest, cfg BatchConfig) []BatchItem {
+	cfg = batchConfigWithDefaults(cfg)
+	items := make([]BatchItem, len(reqs))
+	sem := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		items[i].Request = req
+		wg.Add(1)
+		go func(i int, req CheckRequest) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				items[i].Err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			items[i].Result, items[i].Err = check(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+	return items
+}
+
+// BuildBatchReport aggregates batch items into a single report. The aggregate
+// verdict is the worst verdict among completed specs.
+func BuildBatchReport(version string, items []BatchItem) *schema.BatchReport {
+	report := &schema.BatchReport{
+		Tool:    "speccritic",
+		Version: version,
+		Summary: schema.BatchSummary{Verdict: schema.VerdictValid, SpecCount: len(items)},
+		Specs:   make([]schema.BatchEntry, 0, len(items)),
+	}
+	scoreTotal, completed := 0, 0
+	for _, item := range items {
+		entry := schema.BatchEntry{SpecFile: specLabel(item.Request)}
+		if item.Err != nil || item.Result == nil || item.Result.Report == nil {
+			entry.Error = "check failed"
+			if item.Err != nil {
+				entry.Error = item.Err.Error()
+			}
+			report.Summary.FailedCount++
+			report.Specs = append(report.Specs, entry)
+			continue
+		}
+		summary := item.Result.Report.Summary
+		entry.Verdict = summary.Verdict
+		entry.Score = summary.Score
+		entry.Report = item.Result.Report
+		report.Specs = append(report.Specs, entry)
+
+		if schema.VerdictOrdinal(summary.Verdict) > schema.VerdictOrdinal(report.Summary.Verdict) {
+			report.Summary.Verdict = summary.Verdict
+		}
+		if completed == 0 || summary.Score < report.Summary.MinScore {
+			report.Summary.MinScore = summary.Score
+		}
+		scoreTotal += summary.Score
+		completed++
+		report.Summary.CriticalCount += summary.CriticalCount
+		report.Summary.WarnCount += summary.WarnCount
+		report.Summary.InfoCount += summary.InfoCount
+	}
+	if completed > 0 {
+		report.Summary.AverageScore = scoreTotal / completed
+	}
+	return report
+}
+
+func batchConfigWithDefaults(cfg BatchConfig) BatchConfig {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultBatchConcurrency
+	}
+	return cfg
+}
+
+// newSharedProviderFactory memoizes providers by model string and wraps them
+// in a limiter shared by every provider the factory returns.
+func newSharedProviderFactory(next ProviderFactory, limit int) ProviderFactory {
+	if next == nil {
+		next = llm.NewProvider
+	}
+	var mu sync.Mutex
+	providers := make(map[string]llm.Provider)
+	sem := make(chan struct{}, limit)
+	return func(providerModel string) (llm.Provider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if provider, ok := providers[providerModel]; ok {
+			return provider, nil
+		}
+		provider, err := next(providerModel)
+		if err != nil {
+			return nil, err
+		}
+		limited := &limitedProvider{next: provider, sem: sem}
+		providers[providerModel] = limited
+		return limited, nil
+	}
+}
+
+type limitedProvider struct {
+	next llm.Provider
+	sem  chan struct{}
+}
+
+func (p *limitedProvider) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.sem }()
+	return p.next.Complete(ctx, req)
+}
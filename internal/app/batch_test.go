@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dshills/speccritic/internal/llm"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+type countingProvider struct {
+	content  string
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+	calls    atomic.Int32
+}
+
+func (p *countingProvider) Complete(_ context.Context, _ *llm.Request) (*llm.Response, error) {
+	p.calls.Add(1)
+	current := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		seen := p.maxSeen.Load()
+		if current <= seen || p.maxSeen.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return &llm.Response{Content: p.content, Model: "fake:model"}, nil
+}
+
+func TestCheckBatchSharesProviderAndBoundsCalls(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &countingProvider{content: `{"issues":[],"questions":[],"patches":[]}`}
+	var mu sync.Mutex
+	created := 0
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		created++
+		return provider, nil
+	}}
+
+	reqs := make([]CheckRequest, 6)
+	for i := range reqs {
+		reqs[i] = CheckRequest{
+			SpecName:          "SPEC.md",
+			SpecText:          "The system must do one thing.\n",
+			Profile:           "general",
+			SeverityThreshold: "info",
+			Temperature:       0.2,
+			MaxTokens:         1000,
+			Source:            SourceWeb,
+		}
+	}
+	items := checker.CheckBatch(context.Background(), reqs, BatchConfig{Concurrency: 2})
+	if len(items) != len(reqs) {
+		t.Fatalf("items = %d, want %d", len(items), len(reqs))
+	}
+	for i, item := range items {
+		if item.Err != nil {
+			t.Fatalf("item %d error: %v", i, item.Err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("providers created = %d, want 1", created)
+	}
+	if got := provider.calls.Load(); got != int32(len(reqs)) {
+		t.Fatalf("calls = %d, want %d", got, len(reqs))
+	}
+	if got := provider.maxSeen.Load(); got > 2 {
+		t.Fatalf("max in-flight calls = %d, want <= 2", got)
+	}
+}
+
+func TestBuildBatchReportAggregates(t *testing.T) {
+	report := func(verdict schema.Verdict, score, critical, warn int) *CheckResult {
+		return &CheckResult{Report: &schema.Report{Summary: schema.Summary{
+			Verdict:       verdict,
+			Score:         score,
+			CriticalCount: critical,
+			WarnCount:     warn,
+		}}}
+	}
+	items := []BatchItem{
+		{Request: CheckRequest{SpecPath: "a/SPEC.md"}, Result: report(schema.VerdictValid, 100, 0, 0)},
+		{Request: CheckRequest{SpecPath: "b/SPEC.md"}, Result: report(schema.VerdictInvalid, 60, 2, 0)},
+		{Request: CheckRequest{SpecPath: "c/SPEC.md"}, Result: report(schema.VerdictValidWithGaps, 86, 0, 2)},
+		{Request: CheckRequest{SpecPath: "d/SPEC.md"}, Err: errors.New("reading spec file: missing")},
+	}
+	batch := BuildBatchReport("test", items)
+	if batch.Summary.Verdict != schema.VerdictInvalid {
+		t.Fatalf("verdict = %s, want INVALID", batch.Summary.Verdict)
+	}
+	if batch.Summary.SpecCount != 4 || batch.Summary.FailedCount != 1 {
+		t.Fatalf("counts = %+v", batch.Summary)
+	}
+	if batch.Summary.MinScore != 60 || batch.Summary.AverageScore != 82 {
+		t.Fatalf("scores = %+v", batch.Summary)
+	}
+	if batch.Summary.CriticalCount != 2 || batch.Summary.WarnCount != 2 {
+		t.Fatalf("severity counts = %+v", batch.Summary)
+	}
+	if batch.Specs[3].SpecFile != "d/SPEC.md" || batch.Specs[3].Error == "" || batch.Specs[3].Report != nil {
+		t.Fatalf("failed entry = %+v", batch.Specs[3])
+	}
+	if batch.Specs[1].Verdict != schema.VerdictInvalid || batch.Specs[1].Score != 60 {
+		t.Fatalf("entry = %+v", batch.Specs[1])
+	}
+}
+
+func TestRunBatchCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	items := RunBatch(ctx, func(ctx context.Context, req CheckRequest) (*CheckResult, error) {
+		return nil, ctx.Err()
+	}, []CheckRequest{{SpecPath: "a"}, {SpecPath: "b"}}, BatchConfig{Concurrency: 1})
+	for _, item := range items {
+		if !errors.Is(item.Err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", item.Err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/dshills/speccritic/internal/answers"
 	"github.com/dshills/speccritic/internal/chunk"
 	"github.com/dshills/speccritic/internal/completion"
 	ctxpkg "github.com/dshills/speccritic/internal/context"
@@ -23,6 +24,7 @@ import (
 	"github.com/dshills/speccritic/internal/schema"
 	"github.com/dshills/speccritic/internal/schema/validate"
 	"github.com/dshills/speccritic/internal/spec"
+	"github.com/dshills/speccritic/internal/triage"
 )
 
 type ErrorKind int
@@ -63,6 +65,9 @@ type CheckRequest struct {
 	SpecPath                        string
 	SpecName                        string
 	SpecText                        string
+	SpecDir                         string
+	Sections                        []string
+	ExcludeSections                 []string
 	ContextPaths                    []string
 	ContextDocuments                []ContextDocument
 	Profile                         string
@@ -79,6 +84,10 @@ type CheckRequest struct {
 	PreflightMode                   string
 	PreflightProfile                string
 	PreflightIgnore                 []string
+	PreflightFix                    []string
+	RequirementIDPatterns           []string
+	TermSynonyms                    [][]string
+	SystemActors                    []string
 	Chunking                        string
 	ChunkLines                      int
 	ChunkOverlap                    int
@@ -96,6 +105,10 @@ type CheckRequest struct {
 	IncrementalContextLines         int
 	IncrementalStrictReuse          bool
 	IncrementalReport               bool
+	AnswersPath                     string
+	AnswersText                     string
+	TriagePath                      string
+	TriageText                      string
 	ConvergenceFrom                 string
 	ConvergenceFromText             string
 	ConvergenceMode                 string
@@ -106,8 +119,12 @@ type CheckRequest struct {
 	CompletionTemplate              string
 	CompletionMaxPatches            int
 	CompletionOpenDecisions         bool
+	PatchFormat                     string
 	Source                          Source
 	ErrWriter                       io.Writer
+	// Progress, when set, is called as the check moves through its stages.
+	// Chunk notifications may arrive from concurrent goroutines.
+	Progress func(Progress)
 }
 
 type CheckResult struct {
@@ -137,30 +154,63 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 		errw = io.Discard
 	}
 
+	answerEntries, err := loadAnswers(req)
+	if err != nil {
+		return nil, appError(ErrorInput, err)
+	}
+	triageFile, err := loadTriage(req)
+	if err != nil {
+		return nil, appError(ErrorInput, err)
+	}
+
 	logVerbose(errw, req.Verbose, "Loading spec: %s", specLabel(req))
 	s, err := loadSpec(req)
 	if err != nil {
 		return nil, appError(ErrorInput, fmt.Errorf("loading spec: %w", err))
 	}
+	if s.IsComposite() && req.IncrementalMode != "off" && (req.IncrementalFrom != "" || req.IncrementalFromText != "" || req.IncrementalMode == "on") {
+		return nil, appError(ErrorInput, fmt.Errorf("incremental review does not support multi-file specs"))
+	}
 	originalRaw := s.Raw
 
 	s.Numbered = redact.Redact(s.Numbered)
 	s.Raw = redact.Redact(s.Raw)
 	redactedSpec := s.Raw != originalRaw
 
-	preflightIssues, preflightOnly, err := runPreflight(s, req, errw)
+	scope, err := chunk.ResolveScope(spec.Lines(s.Raw), scopeConfigFromRequest(req))
+	if err != nil {
+		return nil, appError(ErrorInput, err)
+	}
+
+	logVerbose(errw, req.Verbose, "Loading %d context file(s)", len(req.ContextPaths)+len(req.ContextDocuments))
+	contextFiles, err := loadContext(req)
+	if err != nil {
+		return nil, appError(ErrorInput, fmt.Errorf("loading context files: %w", err))
+	}
+
+	preflightResult, preflightOnly, err := runPreflight(s, req, contextFiles, errw)
 	if err != nil {
 		return nil, appError(ErrorInput, err)
 	}
+	preflightIssues := issuesInScope(preflightResult.Issues, scope)
+	if req.Preflight {
+		reportProgress(req, Progress{Stage: ProgressPreflight})
+	}
 	if preflightOnly {
 		report := buildReport(req, s, preflightIssues, nil, nil, "preflight")
-		if err := c.applyConvergence(req, report, convergence.CoveragePreflightOnly, errw); err != nil {
+		setReportScope(report, req, s, scope)
+		applyAnswers(report, answerEntries, s.LineCount)
+		applyTriage(report, triageFile)
+		applyPreflightMeta(req, report, preflightResult, errw)
+		applyPreflightPatches(s, report, preflightResult, scope)
+		if err := c.applyConvergence(req, report, triageFile, convergence.CoveragePreflightOnly, errw); err != nil {
 			return nil, appError(ErrorInput, err)
 		}
 		if err := c.applyCompletion(req, s, report); err != nil {
 			return nil, appError(ErrorInput, err)
 		}
-		patchDiff := patchDiffForReport(originalRaw, report, redactedSpec, errw)
+		localizeReport(s, report)
+		patchDiff := patchDiffForReport(originalRaw, s, report, redactedSpec, req.PatchFormat, errw)
 		return &CheckResult{
 			Report:       report,
 			PatchDiff:    patchDiff,
@@ -176,12 +226,6 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 	}
 	modelStr := llmProvider + ":" + llmModel
 
-	logVerbose(errw, req.Verbose, "Loading %d context file(s)", len(req.ContextPaths)+len(req.ContextDocuments))
-	contextFiles, err := loadContext(req)
-	if err != nil {
-		return nil, appError(ErrorInput, fmt.Errorf("loading context files: %w", err))
-	}
-
 	logVerbose(errw, req.Verbose, "Loading profile: %s", req.Profile)
 	prof, err := profile.Get(req.Profile)
 	if err != nil {
@@ -189,6 +233,9 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 	}
 
 	sysPrompt := llm.BuildSystemPrompt(prof, req.Strict)
+	if clarifications := answers.PromptContext(answerEntries); clarifications != "" {
+		sysPrompt += "\n\n" + clarifications
+	}
 	userPrefix, userSpec := llm.BuildUserPrompt(s, contextFiles)
 	preflightContext, knownPreflightIDs := buildPreflightPromptContext(preflightIssues)
 	if preflightContext != "" {
@@ -219,6 +266,7 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 	}
 
 	if (req.IncrementalFrom != "" || req.IncrementalFromText != "" || req.IncrementalMode == "on") && req.IncrementalMode != "off" {
+		reportProgress(req, Progress{Stage: ProgressReview})
 		result, handled, err := c.checkIncremental(ctx, provider, req, s, originalRaw, preflightIssues, sysPrompt, errw)
 		if err != nil {
 			var appErr *Error
@@ -228,13 +276,17 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 			return nil, appError(ErrorInput, err)
 		}
 		if handled {
-			if err := c.applyConvergence(req, result.Report, convergence.CoverageIncremental, errw); err != nil {
+			applyAnswers(result.Report, answerEntries, s.LineCount)
+			applyTriage(result.Report, triageFile)
+			applyPreflightMeta(req, result.Report, preflightResult, errw)
+			applyPreflightPatches(s, result.Report, preflightResult, scope)
+			if err := c.applyConvergence(req, result.Report, triageFile, convergence.CoverageIncremental, errw); err != nil {
 				return nil, appError(ErrorInput, err)
 			}
 			if err := c.applyCompletion(req, s, result.Report); err != nil {
 				return nil, appError(ErrorInput, err)
 			}
-			result.PatchDiff = patchDiffForReport(originalRaw, result.Report, redactedSpec, errw)
+			result.PatchDiff = patchDiffForReport(originalRaw, s, result.Report, redactedSpec, req.PatchFormat, errw)
 			return result, nil
 		}
 		logVerbose(errw, req.Verbose, "Incremental review fell back to full review")
@@ -243,19 +295,34 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 	logVerbose(errw, req.Verbose, "Calling LLM: %s", modelStr)
 	chunkCfg := chunkConfigFromRequest(req)
 	estimatedPromptTokens := estimatePromptTokens(llmReq)
-	if chunk.ShouldChunk(s.LineCount, estimatedPromptTokens, chunkCfg) {
+	if len(scope) > 0 {
+		// Scoped reviews always use section prompts: only in-scope ranges are
+		// reviewed, and the rest of the spec is context.
+		chunkCfg.Scope = scope
+		logVerbose(errw, req.Verbose, "Using scoped review: %d range(s)", len(scope))
+	}
+	if len(scope) > 0 || chunk.ShouldChunk(s.LineCount, estimatedPromptTokens, chunkCfg) {
 		logVerbose(errw, req.Verbose, "Using chunked review: %d lines, estimated prompt tokens %d", s.LineCount, estimatedPromptTokens)
 		report, responseModel, err := c.checkChunked(ctx, provider, req, s, contextFiles, preflightIssues, sysPrompt, preflightContext, chunkCfg, errw)
 		if err != nil {
 			return nil, appError(ErrorModelOutput, err)
 		}
-		if err := c.applyConvergence(req, report, convergence.CoverageFull, errw); err != nil {
+		if len(scope) > 0 {
+			restrictReportToScope(report, s, scope)
+			setReportScope(report, req, s, scope)
+		}
+		applyAnswers(report, answerEntries, s.LineCount)
+		applyTriage(report, triageFile)
+		applyPreflightMeta(req, report, preflightResult, errw)
+		applyPreflightPatches(s, report, preflightResult, scope)
+		if err := c.applyConvergence(req, report, triageFile, convergence.CoverageFull, errw); err != nil {
 			return nil, appError(ErrorInput, err)
 		}
 		if err := c.applyCompletion(req, s, report); err != nil {
 			return nil, appError(ErrorInput, err)
 		}
-		patchDiff := patchDiffForReport(originalRaw, report, redactedSpec, errw)
+		localizeReport(s, report)
+		patchDiff := patchDiffForReport(originalRaw, s, report, redactedSpec, req.PatchFormat, errw)
 		return &CheckResult{
 			Report:       report,
 			PatchDiff:    patchDiff,
@@ -265,6 +332,7 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 		}, nil
 	}
 
+	reportProgress(req, Progress{Stage: ProgressReview})
 	report, responseModel, err := callWithRetry(ctx, provider, llmReq, s.LineCount, req.Verbose, errw)
 	if err != nil {
 		return nil, appError(ErrorModelOutput, err)
@@ -273,13 +341,18 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 	report.Patches = safeReportPatches(s.Raw, report.Issues, report.Patches)
 
 	report = buildReport(req, s, report.Issues, report.Questions, report.Patches, responseModel)
-	if err := c.applyConvergence(req, report, convergence.CoverageFull, errw); err != nil {
+	applyAnswers(report, answerEntries, s.LineCount)
+	applyTriage(report, triageFile)
+	applyPreflightMeta(req, report, preflightResult, errw)
+	applyPreflightPatches(s, report, preflightResult, scope)
+	if err := c.applyConvergence(req, report, triageFile, convergence.CoverageFull, errw); err != nil {
 		return nil, appError(ErrorInput, err)
 	}
 	if err := c.applyCompletion(req, s, report); err != nil {
 		return nil, appError(ErrorInput, err)
 	}
-	patchDiff := patchDiffForReport(originalRaw, report, redactedSpec, errw)
+	localizeReport(s, report)
+	patchDiff := patchDiffForReport(originalRaw, s, report, redactedSpec, req.PatchFormat, errw)
 
 	return &CheckResult{
 		Report:       report,
@@ -290,8 +363,13 @@ func (c *Checker) Check(ctx context.Context, req CheckRequest) (*CheckResult, er
 	}, nil
 }
 
-func (c *Checker) applyConvergence(req CheckRequest, report *schema.Report, coverage convergence.ReviewCoverage, errw io.Writer) error {
+func (c *Checker) applyConvergence(req CheckRequest, report *schema.Report, triageFile *triage.File, coverage convergence.ReviewCoverage, errw io.Writer) error {
 	cfg := convergenceConfigFromRequest(req, coverage)
+	if triageFile != nil {
+		// Triage has already removed these findings from report; leaving them
+		// in the previous report would show them as resolved.
+		cfg.Suppressed = triage.SuppressedFingerprints(triageFile.Decisions)
+	}
 	if cfg.Mode == convergence.ModeOff {
 		return nil
 	}
@@ -304,6 +382,10 @@ func (c *Checker) applyConvergence(req CheckRequest, report *schema.Report, cove
 	if !cfg.Report {
 		return nil
 	}
+	if report.Input.Scope != nil {
+		cfg.Scope = report.Input.Scope.Ranges
+	}
+	reportProgress(req, Progress{Stage: ProgressConvergence})
 	var prev *convergence.PreviousReport
 	var loadErr error
 	if req.ConvergenceFromText != "" {
@@ -474,7 +556,7 @@ func (c *Checker) checkIncremental(ctx context.Context, provider llm.Provider, r
 	redactedSpec := s.Raw != originalRaw
 	return &CheckResult{
 		Report:       report,
-		PatchDiff:    patchDiffForReport(originalRaw, report, redactedSpec, errw),
+		PatchDiff:    patchDiffForReport(originalRaw, s, report, redactedSpec, req.PatchFormat, errw),
 		OriginalSpec: originalRaw,
 		LineCount:    s.LineCount,
 		Model:        model,
@@ -533,11 +615,118 @@ func changedLineRatio(plan incremental.Plan, lineCount int) float64 {
 	return ratio
 }
 
-func patchDiffForReport(originalRaw string, report *schema.Report, redactedSpec bool, errw io.Writer) string {
+func patchDiffForReport(originalRaw string, s *spec.Spec, report *schema.Report, redactedSpec bool, format string, errw io.Writer) string {
 	if redactedSpec {
 		return ""
 	}
-	return patch.GenerateDiffWithIssues(originalRaw, report.Patches, report.Issues, errw)
+	if format == patch.FormatUnified {
+		return unifiedDiffForReport(originalRaw, s, report, errw)
+	}
+	if !s.IsComposite() {
+		return patch.GenerateDiffWithIssues(originalRaw, report.Patches, report.Issues, errw)
+	}
+	var out strings.Builder
+	for _, file := range s.Files {
+		var patches []schema.Patch
+		for _, p := range report.Patches {
+			if p.Path == file.Path {
+				patches = append(patches, p)
+			}
+		}
+		diff := patch.GenerateDiffWithIssues(file.Raw, patches, report.Issues, errw)
+		if diff == "" {
+			continue
+		}
+		fmt.Fprintf(&out, "# file %s\n", file.Path)
+		out.WriteString(diff)
+	}
+	return out.String()
+}
+
+// unifiedDiffForReport renders the report's patches as a unified diff. The
+// a/ and b/ paths of a composite spec name each file, so a single patch file
+// covers the whole directory.
+func unifiedDiffForReport(originalRaw string, s *spec.Spec, report *schema.Report, errw io.Writer) string {
+	if !s.IsComposite() {
+		return patch.GenerateUnified(s.Path, originalRaw, report.Patches, errw)
+	}
+	var out strings.Builder
+	for _, file := range s.Files {
+		var patches []schema.Patch
+		for _, p := range report.Patches {
+			if p.Path == file.Path {
+				patches = append(patches, p)
+			}
+		}
+		out.WriteString(patch.GenerateUnified(file.Path, file.Raw, patches, errw))
+	}
+	return out.String()
+}
+
+// localizeReport rewrites evidence and patches from composite line numbers to
+// the files they came from. Evidence that spans a file boundary is clipped to
+// the first file, and patches whose before text is not unique within a single
+// file are dropped. Single-file specs are left unchanged.
+func localizeReport(s *spec.Spec, report *schema.Report) {
+	if !s.IsComposite() {
+		return
+	}
+	for i := range report.Issues {
+		report.Issues[i].Evidence = localizeEvidence(s, report.Issues[i].Evidence)
+	}
+	for i := range report.Questions {
+		report.Questions[i].Evidence = localizeEvidence(s, report.Questions[i].Evidence)
+	}
+	for i := range report.Meta.Requirements {
+		requirement := &report.Meta.Requirements[i]
+		requirement.Definition = localizeEvidence(s, []schema.Evidence{requirement.Definition})[0]
+		requirement.References = localizeEvidence(s, requirement.References)
+	}
+	patches := make([]schema.Patch, 0, len(report.Patches))
+	for _, p := range report.Patches {
+		path, ok := patchFile(s.Files, p.Before)
+		if !ok {
+			continue
+		}
+		p.Path = path
+		patches = append(patches, p)
+	}
+	report.Patches = patches
+}
+
+func localizeEvidence(s *spec.Spec, evidence []schema.Evidence) []schema.Evidence {
+	for i, ev := range evidence {
+		path, start, ok := s.Locate(ev.LineStart)
+		if !ok {
+			continue
+		}
+		end := ev.LineEnd
+		if sourceEnd := s.SourceEnd(ev.LineStart); end > sourceEnd {
+			end = sourceEnd
+		}
+		if end < ev.LineStart {
+			end = ev.LineStart
+		}
+		evidence[i].Path = path
+		evidence[i].LineStart = start
+		evidence[i].LineEnd = start + end - ev.LineStart
+	}
+	return evidence
+}
+
+func patchFile(files []spec.File, before string) (string, bool) {
+	if strings.TrimSpace(before) == "" {
+		return "", false
+	}
+	path, matches := "", 0
+	for _, file := range files {
+		n := strings.Count(strings.Join(spec.Lines(file.Raw), "\n"), before)
+		if n > 0 {
+			path = file.Path
+			matches += n
+		}
+	}
+	return path, matches == 1
 }
 
 func safeReportPatches(raw string, issues []schema.Issue, patches []schema.Patch) []schema.Patch {
@@ -625,6 +814,9 @@ func (c *Checker) checkChunked(ctx context.Context, provider llm.Provider, req C
 		Concurrency:      cfg.ChunkConcurrency,
 		Verbose:          req.Verbose,
 		ErrWriter:        errw,
+		OnChunkDone: func(done, total int) {
+			reportProgress(req, Progress{Stage: ProgressChunk, Done: done, Total: total})
+		},
 	})
 	if err != nil {
 		return nil, "", err
@@ -635,13 +827,17 @@ func (c *Checker) checkChunked(ctx context.Context, provider llm.Provider, req C
 		OriginalSpec: s.Raw,
 	})
 	model := firstChunkModel(results)
-	synthesis, synthesisModel, err := chunk.RunSynthesis(ctx, provider, s, plan, results, preflightIssues, merged, chunk.SynthesisConfig{
+	synthesisCfg := chunk.SynthesisConfig{
 		SystemPrompt:  sysPrompt,
 		Temperature:   req.Temperature,
 		MaxTokens:     req.MaxTokens,
 		LineThreshold: cfg.SynthesisLineThreshold,
-		Enabled:       true,
-	})
+		Enabled:       len(cfg.Scope) == 0,
+	}
+	if synthesisCfg.Enabled && chunk.ShouldRunSynthesis(true, s.LineCount, len(merged.Issues)+len(merged.Questions)+len(preflightIssues), synthesisCfg.LineThreshold) {
+		reportProgress(req, Progress{Stage: ProgressSynthesis})
+	}
+	synthesis, synthesisModel, err := chunk.RunSynthesis(ctx, provider, s, plan, results, preflightIssues, merged, synthesisCfg)
 	if err != nil {
 		return nil, "", err
 	}
@@ -660,9 +856,9 @@ func (c *Checker) checkChunked(ctx context.Context, provider llm.Provider, req C
 	return report, model, nil
 }
 
-func runPreflight(s *spec.Spec, req CheckRequest, errw io.Writer) ([]schema.Issue, bool, error) {
+func runPreflight(s *spec.Spec, req CheckRequest, contextFiles []ctxpkg.ContextFile, errw io.Writer) (preflight.Result, bool, error) {
 	if !req.Preflight {
-		return nil, false, nil
+		return preflight.Result{}, false, nil
 	}
 	mode := preflight.Mode(req.PreflightMode)
 	if mode == "" {
@@ -679,22 +875,77 @@ func runPreflight(s *spec.Spec, req CheckRequest, errw io.Writer) ([]schema.Issu
 		Profile:   profileName,
 		Strict:    req.Strict,
 		IgnoreIDs: req.PreflightIgnore,
+		Fix:       req.PreflightFix,
+		// Requirement IDs are matched with the default pattern unless the
+		// request configures its own.
+		RequirementIDPatterns: req.RequirementIDPatterns,
+		Synonyms:              req.TermSynonyms,
+		SystemActors:          req.SystemActors,
+		Context:               contextFiles,
 	})
 	if err != nil {
-		return nil, false, err
+		return preflight.Result{}, false, err
 	}
 	switch mode {
 	case preflight.ModeOnly:
-		return result.Issues, true, nil
+		return result, true, nil
 	case preflight.ModeGate:
-		return result.Issues, hasBlockingIssue(result.Issues), nil
+		return result, hasBlockingIssue(result.Issues), nil
 	case preflight.ModeWarn:
-		return result.Issues, false, nil
+		return result, false, nil
 	default:
-		return nil, false, fmt.Errorf("invalid preflight mode %q", req.PreflightMode)
+		return preflight.Result{}, false, fmt.Errorf("invalid preflight mode %q", req.PreflightMode)
 	}
 }
 
+// applyPreflightMeta copies preflight's document metrics into the report.
+// When preflight ran and found requirement IDs, question blocks that name
+// neither a reported issue nor a cataloged requirement are reported with a
+// warning; the blocks themselves are left as the model wrote them.
+func applyPreflightMeta(req CheckRequest, report *schema.Report, result preflight.Result, errw io.Writer) {
+	report.Meta.Normative = result.Normative
+	report.Meta.Requirements = result.Requirements
+	if !req.Preflight || len(result.Requirements) == 0 {
+		return
+	}
+	known := make(map[string]bool, len(report.Issues)+len(result.Requirements))
+	for _, issue := range report.Issues {
+		known[issue.ID] = true
+	}
+	for _, requirement := range result.Requirements {
+		known[requirement.ID] = true
+	}
+	for _, question := range report.Questions {
+		for _, id := range question.Blocks {
+			if !known[id] {
+				fmt.Fprintf(errw, "WARN: question %s blocks unknown ID %s\n", question.ID, id)
+			}
+		}
+	}
+}
+
+// applyPreflightPatches adds the requested preflight autofixes to the report
+// ahead of its other patches, so completion and patch output see them. Fixes
+// outside the review scope or for issues no longer in the report are dropped.
+func applyPreflightPatches(s *spec.Spec, report *schema.Report, result preflight.Result, scope []chunk.Section) {
+	if len(result.Patches) == 0 {
+		return
+	}
+	patches := make([]schema.Patch, 0, len(result.Patches)+len(report.Patches))
+	for _, p := range result.Patches {
+		rng, ok := exactUniquePatchRange(s.Raw, p.Before)
+		if !ok {
+			continue
+		}
+		line := strings.Count(s.Raw[:rng.start], "\n") + 1
+		if len(scope) > 0 && !chunk.InScope(scope, line, line) {
+			continue
+		}
+		patches = append(patches, p)
+	}
+	report.Patches = safeReportPatches(s.Raw, report.Issues, append(patches, report.Patches...))
+}
+
 func hasBlockingIssue(issues []schema.Issue) bool {
 	for _, issue := range issues {
 		if issue.Blocking {
@@ -704,6 +955,160 @@ func hasBlockingIssue(issues []schema.Issue) bool {
 	return false
 }
 
+func loadAnswers(req CheckRequest) ([]answers.Entry, error) {
+	switch {
+	case req.AnswersText != "":
+		return answers.Parse([]byte(req.AnswersText))
+	case req.AnswersPath != "":
+		return answers.Load(req.AnswersPath)
+	default:
+		return nil, nil
+	}
+}
+
+// applyAnswers marks questions resolved by the answers file and rescores the
+// report, since answered questions no longer count against the spec.
+func applyAnswers(report *schema.Report, entries []answers.Entry, lineCount int) {
+	if len(answers.Answered(entries)) == 0 {
+		return
+	}
+	report.Questions = answers.Apply(report.Questions, entries, lineCount)
+	report.Summary.Score = review.Score(report.Issues, report.Questions)
+	report.Summary.Verdict = review.Verdict(report.Issues, report.Questions)
+}
+
+func loadTriage(req CheckRequest) (*triage.File, error) {
+	switch {
+	case req.TriageText != "":
+		return triage.Parse([]byte(req.TriageText))
+	case req.TriagePath != "":
+		return triage.Load(req.TriagePath)
+	default:
+		return nil, nil
+	}
+}
+
+// applyTriage drops findings the triage file accepted or dismissed, tags
+// deferred and needs-fix findings, and rescores the report.
+func applyTriage(report *schema.Report, f *triage.File) {
+	if f == nil {
+		return
+	}
+	result := triage.Apply(report, f.Decisions)
+	report.Meta.Triage = &schema.TriageMeta{
+		Decisions:  len(f.Decisions),
+		Matched:    result.Matched,
+		Suppressed: result.Suppressed,
+	}
+	if len(result.Suppressed) == 0 {
+		return
+	}
+	critical, warn, info := review.Counts(report.Issues)
+	report.Summary.CriticalCount = critical
+	report.Summary.WarnCount = warn
+	report.Summary.InfoCount = info
+	report.Summary.Score = review.Score(report.Issues, report.Questions)
+	report.Summary.Verdict = review.Verdict(report.Issues, report.Questions)
+}
+
+func scopeConfigFromRequest(req CheckRequest) chunk.ScopeConfig {
+	return chunk.ScopeConfig{Sections: req.Sections, ExcludeSections: req.ExcludeSections}
+}
+
+// restrictReportToScope drops findings outside the review scope in place, so
+// the chunked report keeps its metadata, and rescores what remains.
+func restrictReportToScope(report *schema.Report, s *spec.Spec, scope []chunk.Section) {
+	report.Issues = issuesInScope(report.Issues, scope)
+	report.Questions = questionsInScope(report.Questions, scope)
+	report.Patches = safeReportPatches(s.Raw, report.Issues, report.Patches)
+	critical, warn, info := review.Counts(report.Issues)
+	report.Summary.CriticalCount = critical
+	report.Summary.WarnCount = warn
+	report.Summary.InfoCount = info
+	report.Summary.Score = review.Score(report.Issues, report.Questions)
+	report.Summary.Verdict = review.Verdict(report.Issues, report.Questions)
+}
+
+// setReportScope records the reviewed ranges so later convergence runs do not
+// treat findings outside them as resolved. Ranges use the same coordinates as
+// reported evidence: for multi-file specs they are split per file.
+func setReportScope(report *schema.Report, req CheckRequest, s *spec.Spec, scope []chunk.Section) {
+	if len(scope) == 0 {
+		return
+	}
+	rs := &schema.ReviewScope{
+		Sections:        req.Sections,
+		ExcludeSections: req.ExcludeSections,
+		Ranges:          make([]schema.ScopeRange, 0, len(scope)),
+	}
+	for _, section := range scope {
+		rs.Ranges = append(rs.Ranges, localizeScopeRange(s, schema.ScopeRange{
+			LineStart:   section.LineStart,
+			LineEnd:     section.LineEnd,
+			HeadingPath: section.HeadingPath,
+		})...)
+	}
+	report.Input.Scope = rs
+}
+
+// localizeScopeRange splits a composite line range into one range per source
+// run, in file line numbers.
+func localizeScopeRange(s *spec.Spec, rng schema.ScopeRange) []schema.ScopeRange {
+	if !s.IsComposite() {
+		return []schema.ScopeRange{rng}
+	}
+	var out []schema.ScopeRange
+	for _, src := range s.Sources {
+		start := max(rng.LineStart, src.Line)
+		end := min(rng.LineEnd, src.Line+src.LineCount-1)
+		if start > end {
+			continue
+		}
+		out = append(out, schema.ScopeRange{
+			Path:        src.Path,
+			LineStart:   src.FileLine + start - src.Line,
+			LineEnd:     src.FileLine + end - src.Line,
+			HeadingPath: rng.HeadingPath,
+		})
+	}
+	return out
+}
+
+func issuesInScope(issues []schema.Issue, scope []chunk.Section) []schema.Issue {
+	if len(scope) == 0 {
+		return issues
+	}
+	out := make([]schema.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if evidenceInScope(issue.Evidence, scope) {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+func questionsInScope(questions []schema.Question, scope []chunk.Section) []schema.Question {
+	if len(scope) == 0 {
+		return questions
+	}
+	out := make([]schema.Question, 0, len(questions))
+	for _, question := range questions {
+		if evidenceInScope(question.Evidence, scope) {
+			out = append(out, question)
+		}
+	}
+	return out
+}
+
+func evidenceInScope(evidence []schema.Evidence, scope []chunk.Section) bool {
+	for _, ev := range evidence {
+		if chunk.InScope(scope, ev.LineStart, ev.LineEnd) {
+			return true
+		}
+	}
+	return false
+}
+
 func chunkConfigFromRequest(req CheckRequest) chunk.Config {
 	return chunk.WithDefaults(chunk.Config{
 		Mode:                   chunk.Mode(req.Chunking),
@@ -979,19 +1384,42 @@ func validateRequest(req CheckRequest) error {
 	if err := validateCompletionRequest(req); err != nil {
 		return err
 	}
+	switch req.PatchFormat {
+	case "", patch.FormatDMP, patch.FormatUnified:
+	default:
+		return fmt.Errorf("patch format %q must be %s or %s", req.PatchFormat, patch.FormatDMP, patch.FormatUnified)
+	}
+	if (len(req.Sections) > 0 || len(req.ExcludeSections) > 0) && req.IncrementalMode != "off" && (req.IncrementalFrom != "" || req.IncrementalFromText != "" || req.IncrementalMode == "on") {
+		return fmt.Errorf("section scope cannot be combined with incremental review")
+	}
 	if req.Source == SourceWeb {
 		if req.SpecPath != "" {
 			return fmt.Errorf("web checks must not use SpecPath")
 		}
+		if req.SpecDir != "" {
+			return fmt.Errorf("web checks must not use SpecDir")
+		}
 		if len(req.ContextPaths) > 0 {
 			return fmt.Errorf("web checks must not use ContextPaths")
 		}
+		if req.AnswersPath != "" {
+			return fmt.Errorf("web checks must not use AnswersPath")
+		}
+		if req.TriagePath != "" {
+			return fmt.Errorf("web checks must not use TriagePath")
+		}
 	}
-	if req.SpecPath == "" && req.SpecText == "" {
-		return fmt.Errorf("spec path or spec text is required")
+	if req.SpecPath == "" && req.SpecText == "" && req.SpecDir == "" {
+		return fmt.Errorf("spec path, spec directory, or spec text is required")
 	}
-	if req.SpecPath != "" && req.SpecText != "" {
-		return fmt.Errorf("spec path and spec text are mutually exclusive")
+	sources := 0
+	for _, set := range []bool{req.SpecPath != "", req.SpecText != "", req.SpecDir != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("spec path, spec directory, and spec text are mutually exclusive")
 	}
 	return nil
 }
@@ -1092,8 +1520,11 @@ func resolveModel(req CheckRequest, errw io.Writer) (string, string, error) {
 }
 
 func loadSpec(req CheckRequest) (*spec.Spec, error) {
+	if req.SpecDir != "" {
+		return spec.LoadDir(req.SpecDir)
+	}
 	if req.SpecPath != "" {
-		return spec.Load(req.SpecPath)
+		return spec.LoadComposite(req.SpecPath)
 	}
 	name := req.SpecName
 	if name == "" {
@@ -1124,6 +1555,9 @@ func loadContext(req CheckRequest) ([]ctxpkg.ContextFile, error) {
 }
 
 func specLabel(req CheckRequest) string {
+	if req.SpecDir != "" {
+		return req.SpecDir
+	}
 	if req.SpecPath != "" {
 		return req.SpecPath
 	}
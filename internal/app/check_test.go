@@ -1,16 +1,23 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/dshills/speccritic/internal/llm"
+	"github.com/dshills/speccritic/internal/patch"
 	"github.com/dshills/speccritic/internal/schema"
 	"github.com/dshills/speccritic/internal/spec"
+	"github.com/dshills/speccritic/internal/triage"
 )
 
 type fakeProvider struct {
@@ -405,6 +412,115 @@ func TestCheckerPreflightOnlyAddsConvergenceMetadata(t *testing.T) {
 	}
 }
 
+func TestCheckerPreflightResolvesLinksAgainstContextFiles(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "")
+
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) {
+		return nil, errors.New("provider should not be called")
+	}}
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Upload\n\nErrors follow [the catalog](docs/errors.md) and [quotas](quotas.md).\n",
+		ContextDocuments:  []ContextDocument{{Name: "errors.md", Text: "# Errors\n"}},
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Preflight:         true,
+		PreflightMode:     "only",
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	var missing []string
+	for _, issue := range result.Report.Issues {
+		if issue.ID == "PREFLIGHT-XREF-004" {
+			missing = append(missing, issue.Description)
+		}
+	}
+	if len(missing) != 1 || !strings.HasPrefix(missing[0], "quotas.md ") {
+		t.Fatalf("missing context findings = %#v", missing)
+	}
+}
+
+func TestCheckerPreflightOnlyEmitsRequestedAutofixes(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "")
+
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) {
+		return nil, errors.New("provider should not be called")
+	}}
+	req := CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Upload\n\nThe key words MUST and SHOULD are interpreted as described in RFC 2119.\n\nThe service must store each file.\n\nRetention: TBD\n\n```text\nTBD\n```\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Preflight:         true,
+		PreflightMode:     "only",
+		PreflightFix:      []string{"PREFLIGHT-RFC2119-001", "PREFLIGHT-TODO-001"},
+		PatchFormat:       patch.FormatUnified,
+		Source:            SourceCLI,
+	}
+	result, err := checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(result.Report.Patches) != 2 {
+		t.Fatalf("patches = %#v", result.Report.Patches)
+	}
+	for _, want := range []string{"+The service MUST store each file.", "+Retention: OPEN DECISION"} {
+		if !strings.Contains(result.PatchDiff, want) {
+			t.Fatalf("patch diff missing %q:\n%s", want, result.PatchDiff)
+		}
+	}
+
+	req.PreflightFix = nil
+	result, err = checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(result.Report.Patches) != 0 || result.PatchDiff != "" {
+		t.Fatalf("patches = %#v, diff = %q; want none without opt-in", result.Report.Patches, result.PatchDiff)
+	}
+}
+
+func TestCheckerPreflightAddsNormativeCounts(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "")
+
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) {
+		return nil, errors.New("provider should not be called")
+	}}
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Upload\n\n## Limits\n\nFiles MUST NOT exceed 10 MB and MUST be scanned.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Preflight:         true,
+		PreflightMode:     "only",
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	meta := result.Report.Meta.Normative
+	if meta == nil || meta.Counts["MUST"] != 1 || meta.Counts["MUST NOT"] != 1 {
+		t.Fatalf("normative meta = %#v", meta)
+	}
+	if len(meta.Sections) != 1 || meta.Sections[0].Heading != "Limits" {
+		t.Fatalf("sections = %#v", meta.Sections)
+	}
+}
+
 func TestCheckerPreflightOnlyAddsCompletion(t *testing.T) {
 	t.Setenv("SPECCRITIC_LLM_PROVIDER", "")
 	t.Setenv("SPECCRITIC_LLM_MODEL", "")
@@ -705,6 +821,56 @@ func TestCheckerForcedChunkingUsesChunkPath(t *testing.T) {
 	}
 }
 
+func TestCheckerReportsChunkProgress(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &chunkAwareProvider{}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	var mu sync.Mutex
+	var events []Progress
+	_, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          completeSpecWithRequirement("The service must upload files."),
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Preflight:         true,
+		PreflightMode:     "warn",
+		Chunking:          "on",
+		ChunkLines:        4,
+		ChunkConcurrency:  2,
+		Source:            SourceCLI,
+		Progress: func(p Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, p)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(events) < 2 || events[0].Stage != ProgressPreflight {
+		t.Fatalf("progress = %+v, want preflight first", events)
+	}
+	var chunks []Progress
+	for _, p := range events {
+		if p.Stage == ProgressChunk {
+			chunks = append(chunks, p)
+		}
+	}
+	if len(chunks) == 0 || len(chunks) != chunks[0].Total {
+		t.Fatalf("chunk progress = %+v, want one event per chunk", chunks)
+	}
+	for i, p := range chunks {
+		if p.Done != i+1 {
+			t.Fatalf("chunk progress %d done = %d, want %d", i, p.Done, i+1)
+		}
+	}
+}
+
 func TestCheckerAutoChunkingUsesLineThreshold(t *testing.T) {
 	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
 	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
@@ -923,6 +1089,15 @@ func hasIssue(issues []schema.Issue, id string) bool {
 	return false
 }
 
+func findIssueByID(issues []schema.Issue, id string) *schema.Issue {
+	for i := range issues {
+		if issues[i].ID == id {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
 func hasIssueTag(tags []string, want string) bool {
 	for _, tag := range tags {
 		if tag == want {
@@ -1102,3 +1277,357 @@ func completeSpecWithRequirement(requirement string) string {
 		"Each requirement has an objective test.",
 	}, "\n")
 }
+
+func TestCheckerMapsCompositeEvidenceAndPatchesToFiles(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "SPEC.md")
+	authPath := filepath.Join(dir, "auth.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n<!-- include: auth.md -->\n## Tail\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(authPath, []byte("## Auth\nUsers log in quickly.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &fakeProvider{content: `{"issues":[{"id":"ISSUE-0001","severity":"WARN","category":"AMBIGUOUS_BEHAVIOR","title":"Vague","description":"d","evidence":[{"path":"SPEC.md","line_start":3,"line_end":4,"quote":"Users log in quickly."}],"impact":"i","recommendation":"r","blocking":false,"tags":[]}],"questions":[],"patches":[{"issue_id":"ISSUE-0001","before":"Users log in quickly.","after":"Users log in within 2 seconds."}]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecPath:          specPath,
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	ev := result.Report.Issues[0].Evidence[0]
+	if ev.Path != authPath || ev.LineStart != 2 || ev.LineEnd != 2 {
+		t.Fatalf("evidence = %+v, want %s:2-2", ev, authPath)
+	}
+	if len(result.Report.Patches) != 1 || result.Report.Patches[0].Path != authPath {
+		t.Fatalf("patches = %#v", result.Report.Patches)
+	}
+	if !strings.HasPrefix(result.PatchDiff, "# file "+authPath+"\n") {
+		t.Fatalf("patch diff = %q", result.PatchDiff)
+	}
+}
+
+func TestCheckerRejectsIncrementalCompositeSpec(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("# A\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.md"), []byte("# B\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewChecker().Check(context.Background(), CheckRequest{
+		SpecDir:                         dir,
+		Profile:                         "general",
+		IncrementalMode:                 "on",
+		IncrementalFrom:                 filepath.Join(dir, "previous.json"),
+		IncrementalMaxChangeRatio:       0.35,
+		IncrementalMaxRemapFailureRatio: 0.25,
+		Source:                          SourceCLI,
+	})
+	var appErr *Error
+	if !errors.As(err, &appErr) || appErr.Kind != ErrorInput || !strings.Contains(err.Error(), "multi-file") {
+		t.Fatalf("err = %v, want multi-file input error", err)
+	}
+}
+
+func TestCheckerSectionScopeFiltersPreflightAndRecordsRanges(t *testing.T) {
+	result, err := NewChecker().Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Spec\n## Authentication\nTODO define login.\n## Appendix A\nTODO sample payloads.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Preflight:         true,
+		PreflightMode:     "only",
+		ExcludeSections:   []string{"Appendix*"},
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	for _, issue := range result.Report.Issues {
+		for _, ev := range issue.Evidence {
+			if ev.LineStart >= 4 {
+				t.Fatalf("out-of-scope evidence reported: %#v", issue)
+			}
+		}
+	}
+	if !hasIssue(result.Report.Issues, "PREFLIGHT-TODO-001") {
+		t.Fatalf("issues = %#v, want in-scope TODO", result.Report.Issues)
+	}
+	scope := result.Report.Input.Scope
+	if scope == nil || len(scope.Ranges) != 1 || scope.Ranges[0].LineStart != 1 || scope.Ranges[0].LineEnd != 3 {
+		t.Fatalf("scope = %#v, want lines 1-3", scope)
+	}
+}
+
+func TestCheckerSectionScopeRecordsFileRangesForCompositeSpecs(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "SPEC.md")
+	authPath := filepath.Join(dir, "auth.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n## Intro\nText.\n<!-- include: auth.md -->\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(authPath, []byte("## Auth\nTODO define login.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewChecker().Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecPath:          specPath,
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Preflight:         true,
+		PreflightMode:     "only",
+		Sections:          []string{"Auth"},
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	scope := result.Report.Input.Scope
+	if scope == nil || len(scope.Ranges) != 1 {
+		t.Fatalf("scope = %#v", scope)
+	}
+	if rng := scope.Ranges[0]; rng.Path != authPath || rng.LineStart != 1 || rng.LineEnd != 2 {
+		t.Fatalf("range = %#v, want %s:1-2", rng, authPath)
+	}
+	todo := findIssueByID(result.Report.Issues, "PREFLIGHT-TODO-001")
+	if todo == nil || todo.Evidence[0].Path != authPath || todo.Evidence[0].LineStart != 2 {
+		t.Fatalf("issues = %#v, want TODO at %s:2", result.Report.Issues, authPath)
+	}
+}
+
+func TestCheckerSectionScopeSendsOnlySelectedSections(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &fakeProvider{content: `{"issues":[{"id":"ISSUE-0001","severity":"WARN","category":"AMBIGUOUS_BEHAVIOR","title":"Vague","description":"d","evidence":[{"path":"SPEC.md","line_start":3,"line_end":3,"quote":"Users log in."}],"impact":"i","recommendation":"r","blocking":false,"tags":["chunk:CHUNK-0001-L2-L3"]}],"questions":[],"patches":[],"meta":{"chunk_summary":"summary"}}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Spec\n## Authentication\nUsers log in.\n## Billing\nUsers pay.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Chunking:          "off",
+		Sections:          []string{"authentication"},
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(provider.reqs) != 1 {
+		t.Fatalf("provider calls = %d, want 1 scoped call", len(provider.reqs))
+	}
+	if len(result.Report.Issues) != 1 {
+		t.Fatalf("issues = %#v", result.Report.Issues)
+	}
+	if scope := result.Report.Input.Scope; scope == nil || scope.Ranges[0].LineStart != 2 || scope.Ranges[0].LineEnd != 3 {
+		t.Fatalf("scope = %#v, want lines 2-3", scope)
+	}
+	if _, err := checker.Check(context.Background(), CheckRequest{
+		SpecName: "SPEC.md",
+		SpecText: "# Spec\n",
+		Profile:  "general",
+		Sections: []string{"Missing"},
+		Source:   SourceCLI,
+	}); err == nil || !strings.Contains(err.Error(), "matched no headings") {
+		t.Fatalf("err = %v, want unmatched section error", err)
+	}
+}
+
+func TestCheckerAnswersInjectClarificationsAndMarkQuestions(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &fakeProvider{content: `{"issues":[],"questions":[{"id":"Q-0001","severity":"CRITICAL","question":"What is the login timeout?","why_needed":"w","blocks":[],"evidence":[{"path":"SPEC.md","line_start":1,"line_end":1,"quote":"Users log in."}]}],"patches":[]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	answersText := "# SpecCritic Questions\n\n## Q-0001 (CRITICAL)\n\n**Question:** What is the login timeout?\n\n**Evidence:**\n\n- SPEC.md:L1-L1\n\n### Answer\n\nSessions expire after 15 minutes.\n"
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "Users log in.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		AnswersText:       answersText,
+		Source:            SourceWeb,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if !strings.Contains(provider.reqs[0].SystemPrompt, "Sessions expire after 15 minutes.") {
+		t.Fatal("system prompt missing authoritative clarification")
+	}
+	q := result.Report.Questions[0]
+	if q.Status != schema.QuestionStatusAnswered || q.Answer != "Sessions expire after 15 minutes." {
+		t.Fatalf("question = %#v", q)
+	}
+	if result.Report.Summary.Score != 100 || result.Report.Summary.Verdict != schema.VerdictValid {
+		t.Fatalf("summary = %#v, want answered question ignored", result.Report.Summary)
+	}
+}
+
+func TestCheckerWarnsAboutQuestionBlocksOutsideRequirementCatalog(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &fakeProvider{content: `{"issues":[],"questions":[{"id":"Q-0001","severity":"WARN","question":"Which formats?","why_needed":"w","blocks":["REQ-001","REQ-009","PREFLIGHT-TODO-001"],"evidence":[{"path":"SPEC.md","line_start":3,"line_end":3,"quote":"REQ-001"}]}],"patches":[]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	var errw bytes.Buffer
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Upload\n\n- REQ-001: The service accepts TODO formats.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Preflight:         true,
+		PreflightMode:     "warn",
+		ErrWriter:         &errw,
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if got := strings.Join(result.Report.Questions[0].Blocks, ","); got != "REQ-001,REQ-009,PREFLIGHT-TODO-001" {
+		t.Fatalf("blocks = %s", got)
+	}
+	if !strings.Contains(errw.String(), "Q-0001 blocks unknown ID REQ-009") {
+		t.Fatalf("stderr = %q", errw.String())
+	}
+	catalog := result.Report.Meta.Requirements
+	if len(catalog) != 1 || catalog[0].ID != "REQ-001" || catalog[0].Section != "Upload" || catalog[0].Definition.LineStart != 3 {
+		t.Fatalf("requirements = %#v", catalog)
+	}
+}
+
+func TestCheckerKeepsQuestionBlocksWithoutRequirementCatalog(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &fakeProvider{content: `{"issues":[],"questions":[{"id":"Q-0001","severity":"WARN","question":"Which formats?","why_needed":"w","blocks":["REQ-001"],"evidence":[{"path":"SPEC.md","line_start":3,"line_end":3,"quote":"formats"}]}],"patches":[]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	var errw bytes.Buffer
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "# Upload\n\nThe service accepts TODO formats.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		Preflight:         true,
+		PreflightMode:     "warn",
+		ErrWriter:         &errw,
+		Source:            SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if got := strings.Join(result.Report.Questions[0].Blocks, ","); got != "REQ-001" {
+		t.Fatalf("blocks = %s", got)
+	}
+	if strings.Contains(errw.String(), "blocks unknown ID") {
+		t.Fatalf("stderr = %q", errw.String())
+	}
+}
+
+func TestCheckerTriageSuppressesAcceptedFindings(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &fakeProvider{content: `{"issues":[` +
+		`{"id":"ISSUE-0001","severity":"CRITICAL","category":"NON_TESTABLE_REQUIREMENT","title":"Vague login","description":"d","evidence":[{"path":"SPEC.md","line_start":1,"line_end":1,"quote":"Users log in."}],"impact":"i","recommendation":"r","blocking":true,"tags":[]},` +
+		`{"id":"ISSUE-0002","severity":"WARN","category":"NON_TESTABLE_REQUIREMENT","title":"Vague logout","description":"d","evidence":[{"path":"SPEC.md","line_start":2,"line_end":2,"quote":"Users log out."}],"impact":"i","recommendation":"r","blocking":false,"tags":[]}` +
+		`],"questions":[],"patches":[]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	file := triage.NewFile(nil, []triage.Entry{
+		triage.IssueEntry(schema.Issue{ID: "ISSUE-0009", Severity: schema.SeverityCritical, Category: schema.CategoryNonTestableRequirement, Title: "Vague login", Evidence: []schema.Evidence{{Quote: "Users log in."}}}, triage.DecisionDismissed, "", time.Now()),
+		triage.IssueEntry(schema.Issue{ID: "ISSUE-0008", Severity: schema.SeverityWarn, Category: schema.CategoryNonTestableRequirement, Title: "Vague logout", Evidence: []schema.Evidence{{Quote: "Users log out."}}}, triage.DecisionNeedsFix, "", time.Now()),
+	})
+	triageText, err := triage.Marshal(file)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:           "test",
+		SpecName:          "SPEC.md",
+		SpecText:          "Users log in.\nUsers log out.\n",
+		Profile:           "general",
+		SeverityThreshold: "info",
+		Temperature:       0.2,
+		MaxTokens:         1000,
+		TriageText:        string(triageText),
+		Source:            SourceWeb,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	report := result.Report
+	if len(report.Issues) != 1 || report.Issues[0].ID != "ISSUE-0002" || !slices.Contains(report.Issues[0].Tags, "triage:needs_fix") {
+		t.Fatalf("issues = %#v", report.Issues)
+	}
+	if report.Summary.CriticalCount != 0 || report.Summary.WarnCount != 1 || report.Summary.Verdict == schema.VerdictInvalid {
+		t.Fatalf("summary = %#v, want dismissed finding ignored", report.Summary)
+	}
+	if meta := report.Meta.Triage; meta == nil || meta.Decisions != 2 || meta.Matched != 2 || len(meta.Suppressed) != 1 || meta.Suppressed[0] != "ISSUE-0001" {
+		t.Fatalf("triage meta = %#v", report.Meta.Triage)
+	}
+}
+
+func TestCheckerTriageSuppressedFindingsAreNotResolved(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+
+	provider := &fakeProvider{content: `{"issues":[],"questions":[],"patches":[]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	prior := schema.Issue{
+		ID:       "ISSUE-0001",
+		Severity: schema.SeverityCritical,
+		Category: schema.CategoryAmbiguousBehavior,
+		Title:    "Prior issue",
+		Evidence: []schema.Evidence{{Path: "SPEC.md", LineStart: 1, LineEnd: 1, Quote: "old"}},
+		Tags:     []string{},
+	}
+	triageText, err := triage.Marshal(triage.NewFile(nil, []triage.Entry{triage.IssueEntry(prior, triage.DecisionDismissed, "", time.Now())}))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	result, err := checker.Check(context.Background(), CheckRequest{
+		Version:             "test",
+		SpecName:            "SPEC.md",
+		SpecText:            "Users log in.\n",
+		Profile:             "general",
+		SeverityThreshold:   "info",
+		Temperature:         0.2,
+		MaxTokens:           1000,
+		TriageText:          string(triageText),
+		ConvergenceFromText: previousConvergenceReportJSON(),
+		ConvergenceMode:     "auto",
+		ConvergenceReport:   true,
+		Source:              SourceCLI,
+	})
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	meta := result.Report.Meta.Convergence
+	if meta == nil || meta.Previous.Resolved != 0 {
+		t.Fatalf("convergence meta = %#v, want dismissed finding left out", meta)
+	}
+}
@@ -0,0 +1,26 @@
+package app
+
+// ProgressStage names a step of a check reported through CheckRequest.Progress.
+type ProgressStage string
+
+const (
+	ProgressPreflight   ProgressStage = "preflight"
+	ProgressReview      ProgressStage = "review"
+	ProgressChunk       ProgressStage = "chunk"
+	ProgressSynthesis   ProgressStage = "synthesis"
+	ProgressConvergence ProgressStage = "convergence"
+)
+
+// Progress is one progress notification. Done and Total count completed
+// chunks for ProgressChunk and are zero for other stages.
+type Progress struct {
+	Stage ProgressStage `json:"stage"`
+	Done  int           `json:"done,omitempty"`
+	Total int           `json:"total,omitempty"`
+}
+
+func reportProgress(req CheckRequest, p Progress) {
+	if req.Progress != nil {
+		req.Progress(p)
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"io"
+
+	"github.com/dshills/speccritic/internal/chunk"
+	"github.com/dshills/speccritic/internal/incremental"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// DefaultRequest returns the check request the web UI, the MCP server and
+// the language server start from: preflight in warn mode, automatic
+// chunking and incremental review with default limits, and completion
+// limited to the profile template. Callers set the spec, model and any
+// overrides.
+func DefaultRequest(source Source) CheckRequest {
+	incrementalDefaults := incremental.DefaultConfig()
+	return CheckRequest{
+		Profile:                         "general",
+		SeverityThreshold:               "info",
+		Temperature:                     0.2,
+		MaxTokens:                       8192,
+		Preflight:                       true,
+		PreflightMode:                   "warn",
+		Chunking:                        string(chunk.ModeAuto),
+		ChunkLines:                      chunk.DefaultChunkLines,
+		ChunkOverlap:                    chunk.DefaultChunkOverlap,
+		ChunkMinLines:                   chunk.DefaultChunkMinLines,
+		ChunkTokenThreshold:             chunk.DefaultChunkTokenThreshold,
+		ChunkConcurrency:                chunk.DefaultChunkConcurrency,
+		SynthesisLineThreshold:          chunk.DefaultSynthesisLineThreshold,
+		IncrementalMode:                 string(incremental.ModeAuto),
+		IncrementalMaxChangeRatio:       incrementalDefaults.MaxChangeRatio,
+		IncrementalMaxRemapFailureRatio: incrementalDefaults.MaxRemapFailureRatio,
+		IncrementalContextLines:         incrementalDefaults.ContextLines,
+		IncrementalStrictReuse:          true,
+		CompletionTemplate:              schema.CompletionTemplateProfile,
+		CompletionMaxPatches:            8,
+		CompletionOpenDecisions:         true,
+		Source:                          source,
+		ErrWriter:                       io.Discard,
+	}
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/llm"
+)
+
+func TestDefaultRequestRunsAFullCheck(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+	provider := &fakeProvider{content: `{"issues":[],"questions":[],"patches":[]}`}
+	checker := &Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+
+	req := DefaultRequest(SourceCLI)
+	req.Version = "test"
+	req.SpecName = "SPEC.md"
+	req.SpecText = "# Spec\n\nThe retry limit is TBD.\n"
+	result, err := checker.Check(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(provider.reqs) != 1 {
+		t.Fatalf("provider calls = %d, want 1", len(provider.reqs))
+	}
+	if !hasIssue(result.Report.Issues, "PREFLIGHT-TODO-001") {
+		t.Fatalf("issues = %#v, want PREFLIGHT-TODO-001", result.Report.Issues)
+	}
+}
@@ -33,6 +33,8 @@ type Config struct {
 	ChunkTokenThreshold    int
 	ChunkConcurrency       int
 	SynthesisLineThreshold int
+	// Scope restricts planned chunks to these line ranges when set.
+	Scope []Section
 }
 
 type Heading struct {
@@ -132,6 +134,7 @@ func PlanSpec(s *spec.Spec, cfg Config) (Plan, error) {
 	if len(candidates) == 0 {
 		candidates = sections
 	}
+	candidates = splitAtFileBoundaries(clipToScope(candidates, cfg.Scope), s.FileBoundaries())
 	chunks := buildChunks(s.Path, lines, candidates, cfg)
 	return Plan{
 		Chunks:    chunks,
@@ -248,6 +251,26 @@ func candidateSections(lines []string, headings []Heading, target int) []Section
 	return out
 }
 
+// splitAtFileBoundaries cuts candidate sections where a multi-file spec moves
+// to a different source file, so no chunk spans two files.
+func splitAtFileBoundaries(sections []Section, boundaries []int) []Section {
+	if len(boundaries) == 0 {
+		return sections
+	}
+	out := make([]Section, 0, len(sections)+len(boundaries))
+	for _, section := range sections {
+		start := section.LineStart
+		for _, boundary := range boundaries {
+			if boundary > start && boundary <= section.LineEnd {
+				out = append(out, sectionWithRange(section, start, boundary-1))
+				start = boundary
+			}
+		}
+		out = append(out, sectionWithRange(section, start, section.LineEnd))
+	}
+	return out
+}
+
 func splitByNested(lines []string, headings []Heading, section Section, target int) []Section {
 	if section.LineEnd-section.LineStart+1 <= target*2 {
 		return []Section{section}
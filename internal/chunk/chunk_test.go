@@ -150,3 +150,57 @@ func TestShouldChunk(t *testing.T) {
 		t.Fatal("off mode should not chunk")
 	}
 }
+
+func TestPlanSpecSplitsAtFileBoundaries(t *testing.T) {
+	s := spec.New("specs", "# Title\none\ntwo\nthree\nfour\n")
+	s.Sources = []spec.Source{
+		{Path: "a.md", Line: 1, FileLine: 1, LineCount: 3},
+		{Path: "b.md", Line: 4, FileLine: 1, LineCount: 2},
+	}
+	plan, err := PlanSpec(s, Config{ChunkLines: 50, ChunkOverlap: 0, ChunkMinLines: 0, ChunkTokenThreshold: 1, ChunkConcurrency: 1})
+	if err != nil {
+		t.Fatalf("PlanSpec: %v", err)
+	}
+	if len(plan.Chunks) != 2 {
+		t.Fatalf("chunks = %#v, want split at line 4", plan.Chunks)
+	}
+	if plan.Chunks[0].LineEnd != 3 || plan.Chunks[1].LineStart != 4 {
+		t.Fatalf("ranges = %d-%d, %d-%d", plan.Chunks[0].LineStart, plan.Chunks[0].LineEnd, plan.Chunks[1].LineStart, plan.Chunks[1].LineEnd)
+	}
+}
+
+func TestResolveScopeSelectsAndExcludesSections(t *testing.T) {
+	lines := spec.Lines("# Spec\n## API\n### Auth\nlogin\n### Billing\npay\n## Appendix A\npayload\n")
+	scope, err := ResolveScope(lines, ScopeConfig{Sections: []string{"api"}, ExcludeSections: []string{"API > Bill*"}})
+	if err != nil {
+		t.Fatalf("ResolveScope: %v", err)
+	}
+	if len(scope) != 1 || scope[0].LineStart != 2 || scope[0].LineEnd != 4 {
+		t.Fatalf("scope = %#v, want lines 2-4", scope)
+	}
+	if got := strings.Join(scope[0].HeadingPath, " > "); got != "Spec > API" {
+		t.Fatalf("heading path = %q", got)
+	}
+
+	scope, err = ResolveScope(lines, ScopeConfig{ExcludeSections: []string{"Appendix*"}})
+	if err != nil {
+		t.Fatalf("ResolveScope: %v", err)
+	}
+	if len(scope) != 1 || scope[0].LineStart != 1 || scope[0].LineEnd != 6 {
+		t.Fatalf("scope = %#v, want lines 1-6", scope)
+	}
+	if _, err := ResolveScope(lines, ScopeConfig{ExcludeSections: []string{"Spec"}}); err == nil {
+		t.Fatal("expected error when scope excludes everything")
+	}
+}
+
+func TestPlanSpecClipsChunksToScope(t *testing.T) {
+	s := spec.New("SPEC.md", "# Title\n## A\none\n## B\ntwo\n")
+	plan, err := PlanSpec(s, Config{ChunkLines: 50, ChunkOverlap: 0, ChunkMinLines: 0, ChunkTokenThreshold: 1, ChunkConcurrency: 1, Scope: []Section{{LineStart: 4, LineEnd: 5}}})
+	if err != nil {
+		t.Fatalf("PlanSpec: %v", err)
+	}
+	if len(plan.Chunks) != 1 || plan.Chunks[0].LineStart != 4 || plan.Chunks[0].LineEnd != 5 {
+		t.Fatalf("chunks = %#v, want only lines 4-5", plan.Chunks)
+	}
+}
@@ -21,6 +21,9 @@ type ExecutorConfig struct {
 	Concurrency      int
 	Verbose          bool
 	ErrWriter        io.Writer
+	// OnChunkDone, when set, is called after each chunk review completes with
+	// the number of completed chunks and the chunk total. Calls are serialized.
+	OnChunkDone func(done, total int)
 }
 
 type ChunkResult struct {
@@ -57,6 +60,7 @@ func ReviewChunks(ctx context.Context, provider llm.Provider, s *spec.Spec, plan
 	errs := make(chan error, 1)
 	var logMu sync.Mutex
 	var wg sync.WaitGroup
+	completed := 0
 	for worker := 0; worker < concurrency; worker++ {
 		wg.Add(1)
 		go func() {
@@ -75,6 +79,12 @@ func ReviewChunks(ctx context.Context, provider llm.Provider, s *spec.Spec, plan
 				}
 				logVerbose(&logMu, cfg.ErrWriter, cfg.Verbose, "Completed chunk %s", ch.ID)
 				results[idx] = result
+				if cfg.OnChunkDone != nil {
+					logMu.Lock()
+					completed++
+					cfg.OnChunkDone(completed, len(plan.Chunks))
+					logMu.Unlock()
+				}
 			}
 		}()
 	}
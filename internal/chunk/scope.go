@@ -0,0 +1,169 @@
+package chunk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ScopeConfig selects the sections of a spec that are under review. Patterns
+// match heading text case-insensitively and may use * and ? wildcards. A
+// pattern containing ">" matches the trailing segments of a heading path, so
+// "API > Auth*" selects an Auth section nested directly under API.
+type ScopeConfig struct {
+	Sections        []string
+	ExcludeSections []string
+}
+
+// Enabled reports whether the config restricts the review at all.
+func (cfg ScopeConfig) Enabled() bool {
+	return len(cfg.Sections) > 0 || len(cfg.ExcludeSections) > 0
+}
+
+// ResolveScope returns the in-scope line ranges of lines. A selected section
+// brings its subsections with it; excluded sections are removed afterwards.
+// Each range carries the heading path of the section it starts in.
+func ResolveScope(lines []string, cfg ScopeConfig) ([]Section, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	sections := BuildSections(lines, ExtractHeadings(lines))
+	include, err := compileScopePatterns(cfg.Sections)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileScopePatterns(cfg.ExcludeSections)
+	if err != nil {
+		return nil, err
+	}
+
+	inScope := make([]bool, len(lines)+1)
+	if len(include) == 0 {
+		for line := 1; line <= len(lines); line++ {
+			inScope[line] = true
+		}
+	}
+	for _, pattern := range include {
+		matched := false
+		for _, section := range sections {
+			if pattern.match(section.HeadingPath) {
+				matched = true
+				markLines(inScope, section, true)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("section %q matched no headings", pattern.raw)
+		}
+	}
+	for _, pattern := range exclude {
+		for _, section := range sections {
+			if pattern.match(section.HeadingPath) {
+				markLines(inScope, section, false)
+			}
+		}
+	}
+
+	var out []Section
+	for line := 1; line <= len(lines); line++ {
+		if !inScope[line] {
+			continue
+		}
+		end := line
+		for end < len(lines) && inScope[end+1] {
+			end++
+		}
+		out = append(out, Section{LineStart: line, LineEnd: end, HeadingPath: headingPathAt(sections, line)})
+		line = end
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("section scope excludes the entire spec")
+	}
+	return out, nil
+}
+
+// InScope reports whether the line range overlaps any scope range.
+func InScope(scope []Section, start, end int) bool {
+	if end < start {
+		end = start
+	}
+	for _, section := range scope {
+		if start <= section.LineEnd && end >= section.LineStart {
+			return true
+		}
+	}
+	return false
+}
+
+// clipToScope intersects candidate sections with the scope ranges.
+func clipToScope(sections, scope []Section) []Section {
+	if len(scope) == 0 {
+		return sections
+	}
+	var out []Section
+	for _, section := range sections {
+		for _, rng := range scope {
+			start := max(section.LineStart, rng.LineStart)
+			end := min(section.LineEnd, rng.LineEnd)
+			if start <= end {
+				out = append(out, sectionWithRange(section, start, end))
+			}
+		}
+	}
+	return out
+}
+
+type scopePattern struct {
+	raw      string
+	segments []*regexp.Regexp
+}
+
+func compileScopePatterns(patterns []string) ([]scopePattern, error) {
+	out := make([]scopePattern, 0, len(patterns))
+	for _, raw := range patterns {
+		if strings.TrimSpace(raw) == "" {
+			return nil, fmt.Errorf("section pattern must not be empty")
+		}
+		pattern := scopePattern{raw: raw}
+		for _, segment := range strings.Split(raw, ">") {
+			segment = strings.TrimSpace(segment)
+			if segment == "" {
+				return nil, fmt.Errorf("invalid section pattern %q", raw)
+			}
+			expr := regexp.QuoteMeta(strings.ToLower(segment))
+			expr = strings.ReplaceAll(expr, `\*`, ".*")
+			expr = strings.ReplaceAll(expr, `\?`, ".")
+			pattern.segments = append(pattern.segments, regexp.MustCompile("^"+expr+"$"))
+		}
+		out = append(out, pattern)
+	}
+	return out, nil
+}
+
+func (p scopePattern) match(headingPath []string) bool {
+	if len(headingPath) < len(p.segments) {
+		return false
+	}
+	offset := len(headingPath) - len(p.segments)
+	for i, segment := range p.segments {
+		if !segment.MatchString(strings.ToLower(strings.TrimSpace(headingPath[offset+i]))) {
+			return false
+		}
+	}
+	return true
+}
+
+func markLines(inScope []bool, section Section, value bool) {
+	for line := section.LineStart; line <= section.LineEnd && line < len(inScope); line++ {
+		inScope[line] = value
+	}
+}
+
+func headingPathAt(sections []Section, line int) []string {
+	var path []string
+	for _, section := range sections {
+		if section.LineStart <= line && line <= section.LineEnd && len(section.HeadingPath) >= len(path) {
+			path = section.HeadingPath
+		}
+	}
+	return append([]string(nil), path...)
+}
@@ -0,0 +1,196 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const (
+	defaultScaffoldTitle = "Untitled Specification"
+	actorsHeading        = "Actors"
+	openDecisionsHeading = "Open Decisions"
+)
+
+// scaffoldSubheadings adds subheadings that a profile requires as sections
+// but that the profile's completion template folds into a broader section.
+// They only apply to scaffolds, so completion output is unchanged.
+var scaffoldSubheadings = map[string]map[string][]string{
+	schema.CompletionTemplateRegulatedSystem: {
+		"data lifecycle and deletion": {"Data Retention"},
+	},
+}
+
+// ScaffoldInput describes a new spec skeleton generated by speccritic init.
+type ScaffoldInput struct {
+	Title         string
+	Actors        []string
+	OpenDecisions []string
+	Template      *Template
+}
+
+// ScaffoldTemplate returns the section list used to scaffold a new spec for
+// tmpl. Profile templates only describe profile-specific structure, so the
+// general purpose, non-goal, requirement, and acceptance sections are merged
+// around them to satisfy the structural preflight rules for every profile.
+func ScaffoldTemplate(tmpl *Template) Template {
+	if tmpl == nil {
+		return generalTemplate()
+	}
+	if tmpl.Name == schema.CompletionTemplateGeneral {
+		return cloneTemplate(*tmpl)
+	}
+	general := generalTemplate()
+	out := Template{Name: tmpl.Name}
+	seen := make(map[string]bool)
+	add := func(section TemplateSection) {
+		key := normalizeHeading(section.Heading)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if extra := scaffoldSubheadings[tmpl.Name][key]; len(extra) > 0 {
+			section.Subheadings = append(append([]string{}, section.Subheadings...), extra...)
+		}
+		section.Order = len(out.Sections) + 1
+		out.Sections = append(out.Sections, section)
+	}
+	var trailing []TemplateSection
+	for _, section := range general.Sections {
+		if len(section.PreflightRuleIDs) == 0 {
+			continue
+		}
+		if hasCategory(section.Categories, schema.CategoryNonTestableRequirement) {
+			trailing = append(trailing, section)
+			continue
+		}
+		add(section)
+	}
+	for _, section := range tmpl.Sections {
+		add(section)
+	}
+	for _, section := range trailing {
+		add(section)
+	}
+	for _, section := range general.Sections {
+		if normalizeHeading(section.Heading) == normalizeHeading(openDecisionsHeading) {
+			add(section)
+		}
+	}
+	return out
+}
+
+// RenderScaffold renders a Markdown spec skeleton. Placeholders keep the
+// template wording so completion and preflight recognize them on later runs.
+func RenderScaffold(input ScaffoldInput) string {
+	tmpl := ScaffoldTemplate(input.Template)
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		title = defaultScaffoldTitle
+	}
+	actors := nonEmpty(input.Actors)
+	decisions := nonEmpty(input.OpenDecisions)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	for i, section := range tmpl.Sections {
+		heading := normalizeHeading(section.Heading)
+		fmt.Fprintf(&b, "\n## %s\n\n", section.Heading)
+		if heading == normalizeHeading(openDecisionsHeading) && len(decisions) > 0 {
+			for _, decision := range decisions {
+				fmt.Fprintf(&b, "- %s\n", openDecisionText(decision))
+			}
+		} else {
+			for j, placeholder := range section.Placeholders {
+				if j > 0 {
+					b.WriteByte('\n')
+				}
+				b.WriteString(placeholder.Text)
+				b.WriteByte('\n')
+			}
+		}
+		for _, subheading := range section.Subheadings {
+			fmt.Fprintf(&b, "\n### %s\n", subheading)
+		}
+		if i == 0 && len(actors) > 0 {
+			fmt.Fprintf(&b, "\n## %s\n\n", actorsHeading)
+			for _, actor := range actors {
+				fmt.Fprintf(&b, "- %s\n", actor)
+			}
+		}
+	}
+	return b.String()
+}
+
+// ParseTemplate builds a completion template from a Markdown skeleton. Each
+// second-level heading becomes a section; non-empty body lines become
+// placeholders, and lines starting with OPEN DECISION require a decision.
+func ParseTemplate(name, raw string) (*Template, error) {
+	doc := AnalyzeSections(raw)
+	if name == "" {
+		name = "custom"
+	}
+	tmpl := &Template{Name: name}
+	for _, node := range doc.Sections {
+		if node.Level != doc.PrimaryLevel || node.Heading == "" {
+			continue
+		}
+		section := TemplateSection{
+			Heading:     node.Heading,
+			Subheadings: []string{},
+			Order:       len(tmpl.Sections) + 1,
+		}
+		for lineNo := node.BodyStart; lineNo <= node.EndLine && lineNo <= len(doc.Lines); lineNo++ {
+			line := strings.TrimSpace(doc.Lines[lineNo-1])
+			if line == "" {
+				continue
+			}
+			if atxHeadingPattern.MatchString(line) {
+				section.Subheadings = append(section.Subheadings, strings.TrimSpace(strings.TrimLeft(line, "#")))
+				continue
+			}
+			section.Placeholders = append(section.Placeholders, Placeholder{
+				Text:             line,
+				RequiresDecision: strings.HasPrefix(strings.ToUpper(line), openDecisionPrefix),
+			})
+		}
+		tmpl.Sections = append(tmpl.Sections, section)
+	}
+	if len(tmpl.Sections) == 0 {
+		return nil, fmt.Errorf("template %q has no sections", name)
+	}
+	return tmpl, nil
+}
+
+func openDecisionText(text string) string {
+	if strings.HasPrefix(strings.ToUpper(text), openDecisionPrefix) {
+		return text
+	}
+	return openDecisionPrefix + " " + text
+}
+
+func cloneTemplate(tmpl Template) Template {
+	out := Template{Name: tmpl.Name, Sections: make([]TemplateSection, len(tmpl.Sections))}
+	copy(out.Sections, tmpl.Sections)
+	return out
+}
+
+func hasCategory(categories []schema.Category, want schema.Category) bool {
+	for _, category := range categories {
+		if category == want {
+			return true
+		}
+	}
+	return false
+}
+
+func nonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
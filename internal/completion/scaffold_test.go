@@ -0,0 +1,121 @@
+package completion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/preflight"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+func TestRenderScaffoldPassesStructuralPreflight(t *testing.T) {
+	for _, name := range []string{
+		schema.CompletionTemplateGeneral,
+		schema.CompletionTemplateBackendAPI,
+		schema.CompletionTemplateRegulatedSystem,
+		schema.CompletionTemplateEventDriven,
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := GetTemplate(name, "general")
+			if err != nil {
+				t.Fatalf("GetTemplate: %v", err)
+			}
+			raw := RenderScaffold(ScaffoldInput{Template: tmpl})
+			result, err := preflight.Run(spec.New("SPEC.md", raw), preflight.Config{Enabled: true, Profile: name})
+			if err != nil {
+				t.Fatalf("preflight.Run: %v", err)
+			}
+			for _, issue := range result.Issues {
+				for _, tag := range issue.Tags {
+					if tag == "missing-section" {
+						t.Fatalf("scaffold is missing a required section: %s\n%s", issue.Title, raw)
+					}
+				}
+			}
+			scaffolded := ScaffoldTemplate(tmpl)
+			assertStrictlyIncreasingOrders(t, &scaffolded)
+			assertNoDuplicateHeadings(t, &scaffolded)
+		})
+	}
+}
+
+func TestRenderScaffoldInteractiveFields(t *testing.T) {
+	tmpl, err := GetTemplate(schema.CompletionTemplateBackendAPI, "general")
+	if err != nil {
+		t.Fatalf("GetTemplate: %v", err)
+	}
+	raw := RenderScaffold(ScaffoldInput{
+		Title:         "Upload Service",
+		Actors:        []string{"Uploader", " ", "Auditor"},
+		OpenDecisions: []string{"Choose the storage backend", "OPEN DECISION: Pick a retention period"},
+		Template:      tmpl,
+	})
+	for _, want := range []string{
+		"# Upload Service\n",
+		"## Actors\n\n- Uploader\n- Auditor\n",
+		"## Endpoints\n",
+		"- OPEN DECISION: Choose the storage backend\n",
+		"- OPEN DECISION: Pick a retention period\n",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Fatalf("scaffold missing %q:\n%s", want, raw)
+		}
+	}
+	if strings.Index(raw, "## Actors") > strings.Index(raw, "## Non-Goals") {
+		t.Fatalf("actors should follow the first section:\n%s", raw)
+	}
+}
+
+func TestScaffoldSubheadingsLeaveCompletionTemplateUnchanged(t *testing.T) {
+	tmpl, err := GetTemplate(schema.CompletionTemplateRegulatedSystem, "general")
+	if err != nil {
+		t.Fatalf("GetTemplate: %v", err)
+	}
+	for _, section := range tmpl.Sections {
+		if len(section.Subheadings) != 0 {
+			t.Fatalf("completion template section %q has subheadings %v", section.Heading, section.Subheadings)
+		}
+	}
+	raw := RenderScaffold(ScaffoldInput{Template: tmpl})
+	if !strings.Contains(raw, "## Data Lifecycle and Deletion\n") || !strings.Contains(raw, "### Data Retention\n") {
+		t.Fatalf("scaffold missing retention subheading:\n%s", raw)
+	}
+	again, err := GetTemplate(schema.CompletionTemplateRegulatedSystem, "general")
+	if err != nil {
+		t.Fatalf("GetTemplate: %v", err)
+	}
+	for _, section := range again.Sections {
+		if len(section.Subheadings) != 0 {
+			t.Fatalf("scaffolding changed the completion template: %+v", section)
+		}
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	raw := "# Custom\n\n## Overview\n\nDescribe the feature.\n\n### Context\n\n## Rollout\n\nOPEN DECISION: Choose a rollout strategy.\n"
+	tmpl, err := ParseTemplate("custom", raw)
+	if err != nil {
+		t.Fatalf("ParseTemplate: %v", err)
+	}
+	if len(tmpl.Sections) != 2 {
+		t.Fatalf("sections = %+v", tmpl.Sections)
+	}
+	if tmpl.Sections[0].Heading != "Overview" || len(tmpl.Sections[0].Subheadings) != 1 {
+		t.Fatalf("overview = %+v", tmpl.Sections[0])
+	}
+	if got := tmpl.Sections[1].Placeholders; len(got) != 1 || !got[0].RequiresDecision {
+		t.Fatalf("rollout placeholders = %+v", got)
+	}
+
+	scaffold := RenderScaffold(ScaffoldInput{Template: tmpl})
+	for _, want := range []string{"## Purpose", "## Overview", "## Rollout", "## Acceptance Criteria"} {
+		if !strings.Contains(scaffold, want) {
+			t.Fatalf("custom scaffold missing %q:\n%s", want, scaffold)
+		}
+	}
+
+	if _, err := ParseTemplate("empty", "no headings here\n"); err == nil {
+		t.Fatal("expected error for template without sections")
+	}
+}
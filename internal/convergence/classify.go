@@ -37,7 +37,8 @@ func CompareReports(previous, current *schema.Report, cfg Config, compat Compati
 		return result
 	}
 
-	prevFindings := reportFindings(previous)
+	prevFindings := withoutFingerprints(reportFindings(previous), cfg.Suppressed)
+	answered := TrackQuestions(answeredQuestions(current))
 	matches := MatchFindings(prevFindings, curFindings)
 	matchedPrev := make(map[string]Match, len(matches))
 	matchedCur := make(map[string]Match, len(matches))
@@ -67,6 +68,9 @@ func CompareReports(previous, current *schema.Report, cfg Config, compat Compati
 			continue
 		}
 		status := classifyHistorical(prev, cfg)
+		if prev.Kind == KindQuestion && len(answered) > 0 && len(MatchFindings([]TrackedFinding{prev}, answered)) > 0 {
+			status = HistoricalAnswered
+		}
 		result.Previous = append(result.Previous, HistoricalFinding{Finding: prev, Status: status})
 		addHistoricalCount(&result.Summary, prev, status)
 		if status == HistoricalUntracked && result.Status == StatusComplete {
@@ -77,9 +81,11 @@ func CompareReports(previous, current *schema.Report, cfg Config, compat Compati
 	return result
 }
 
+// reportFindings returns the report's active findings. Questions resolved by
+// an answers file are not active.
 func reportFindings(report *schema.Report) []TrackedFinding {
 	issues := TrackIssues(report.Issues)
-	questions := TrackQuestions(report.Questions)
+	questions := TrackQuestions(schema.OpenQuestions(report.Questions))
 	findings := make([]TrackedFinding, 0, len(issues)+len(questions))
 	for i := range issues {
 		issues[i].SourceIndex = i
@@ -93,10 +99,36 @@ func reportFindings(report *schema.Report) []TrackedFinding {
 	return findings
 }
 
+func withoutFingerprints(findings []TrackedFinding, fingerprints map[string]bool) []TrackedFinding {
+	if len(fingerprints) == 0 {
+		return findings
+	}
+	out := make([]TrackedFinding, 0, len(findings))
+	for _, finding := range findings {
+		if !fingerprints[Fingerprint(finding)] {
+			out = append(out, finding)
+		}
+	}
+	return out
+}
+
+func answeredQuestions(report *schema.Report) []schema.Question {
+	var out []schema.Question
+	for _, q := range report.Questions {
+		if q.Status == schema.QuestionStatusAnswered {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
 func classifyHistorical(prev TrackedFinding, cfg Config) HistoricalStatus {
 	if thresholdDrops(prev.Severity, cfg.SeverityThreshold) {
 		return HistoricalDropped
 	}
+	if len(cfg.Scope) > 0 && !evidenceInScope(prev.Evidence, cfg.Scope) {
+		return HistoricalUntracked
+	}
 	switch cfg.CurrentReviewCoverage {
 	case CoverageFull:
 		return HistoricalResolved
@@ -112,6 +144,24 @@ func classifyHistorical(prev TrackedFinding, cfg Config) HistoricalStatus {
 	}
 }
 
+func evidenceInScope(evidence []schema.Evidence, scope []schema.ScopeRange) bool {
+	for _, ev := range evidence {
+		end := ev.LineEnd
+		if end < ev.LineStart {
+			end = ev.LineStart
+		}
+		for _, rng := range scope {
+			if rng.Path != "" && ev.Path != rng.Path {
+				continue
+			}
+			if ev.LineStart <= rng.LineEnd && end >= rng.LineStart {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func thresholdDrops(severity schema.Severity, threshold string) bool {
 	if threshold == "" {
 		return false
@@ -158,6 +208,8 @@ func addHistoricalCount(summary *Summary, finding TrackedFinding, status Histori
 	switch status {
 	case HistoricalResolved:
 		summary.Previous.Resolved++
+	case HistoricalAnswered:
+		summary.Previous.Answered++
 	case HistoricalDropped:
 		summary.Previous.Dropped++
 	case HistoricalUntracked:
@@ -168,6 +220,8 @@ func addHistoricalCount(summary *Summary, finding TrackedFinding, status Histori
 	switch status {
 	case HistoricalResolved:
 		counts.Resolved++
+	case HistoricalAnswered:
+		counts.Answered++
 	case HistoricalDropped:
 		counts.Dropped++
 	case HistoricalUntracked:
@@ -179,6 +233,8 @@ func addHistoricalCount(summary *Summary, finding TrackedFinding, status Histori
 	switch status {
 	case HistoricalResolved:
 		kindCounts.Resolved++
+	case HistoricalAnswered:
+		kindCounts.Answered++
 	case HistoricalDropped:
 		kindCounts.Dropped++
 	case HistoricalUntracked:
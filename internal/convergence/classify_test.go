@@ -114,3 +114,53 @@ func question(id string, text string, quote string) schema.Question {
 		Evidence: []schema.Evidence{{Quote: quote, LineStart: 1, LineEnd: 1}},
 	}
 }
+
+func TestCompareReportsScopeKeepsOutOfScopeFindingsUntracked(t *testing.T) {
+	inScope := issue("ISSUE-0001", schema.SeverityWarn, "In scope", "old", nil)
+	inScope.Evidence = []schema.Evidence{{Path: "SPEC.md", LineStart: 2, LineEnd: 2}}
+	outOfScope := issue("ISSUE-0002", schema.SeverityWarn, "Appendix", "payload", nil)
+	outOfScope.Evidence = []schema.Evidence{{Path: "SPEC.md", LineStart: 40, LineEnd: 42}}
+	prev := reportWithIssues(inScope, outOfScope)
+	cur := reportWithIssues()
+	cfg := Config{
+		CurrentReviewCoverage: CoverageFull,
+		SeverityThreshold:     "info",
+		Scope:                 []schema.ScopeRange{{LineStart: 1, LineEnd: 10}},
+	}
+	result := CompareReports(prev, cur, cfg, Compatibility{Status: StatusComplete})
+	if result.Summary.Previous.Resolved != 1 || result.Summary.Previous.Untracked != 1 || result.Status != StatusPartial {
+		t.Fatalf("result = %#v", result)
+	}
+}
+
+func TestCompareReportsScopeMatchesFilePaths(t *testing.T) {
+	inScope := issue("ISSUE-0001", schema.SeverityWarn, "Auth", "login", nil)
+	inScope.Evidence = []schema.Evidence{{Path: "auth.md", LineStart: 2, LineEnd: 2}}
+	otherFile := issue("ISSUE-0002", schema.SeverityWarn, "Billing", "payment", nil)
+	otherFile.Evidence = []schema.Evidence{{Path: "billing.md", LineStart: 2, LineEnd: 2}}
+	prev := reportWithIssues(inScope, otherFile)
+	cfg := Config{
+		CurrentReviewCoverage: CoverageFull,
+		SeverityThreshold:     "info",
+		Scope:                 []schema.ScopeRange{{Path: "auth.md", LineStart: 1, LineEnd: 5}},
+	}
+	result := CompareReports(prev, reportWithIssues(), cfg, Compatibility{Status: StatusComplete})
+	if result.Summary.Previous.Resolved != 1 || result.Summary.Previous.Untracked != 1 {
+		t.Fatalf("summary = %#v", result.Summary)
+	}
+}
+
+func TestCompareReportsAnsweredQuestionResolvesByAnswer(t *testing.T) {
+	prev := reportWithQuestions(question("Q-0001", "What is the login timeout?", "login"))
+	answered := question("Q-0001", "What is the login timeout?", "login")
+	answered.Status = schema.QuestionStatusAnswered
+	answered.Answer = "15 minutes."
+	cur := reportWithQuestions(answered)
+	result := CompareReports(prev, cur, Config{CurrentReviewCoverage: CoverageFull, SeverityThreshold: "info"}, Compatibility{Status: StatusComplete})
+	if result.Summary.Previous.Answered != 1 || result.Summary.Previous.Resolved != 0 || result.Summary.Current.StillOpen != 0 {
+		t.Fatalf("summary = %#v", result.Summary)
+	}
+	if result.Summary.ByKind[string(KindQuestion)].Answered != 1 {
+		t.Fatalf("by kind = %#v", result.Summary.ByKind)
+	}
+}
@@ -132,5 +132,5 @@ func isVolatileTag(tag string) bool {
 	case "incremental-reused", "llm-repaired", "provider-repaired", "repair":
 		return true
 	}
-	return strings.HasPrefix(tag, "chunk:") || strings.HasPrefix(tag, "range:")
+	return strings.HasPrefix(tag, "chunk:") || strings.HasPrefix(tag, "range:") || strings.HasPrefix(tag, "triage:")
 }
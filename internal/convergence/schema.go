@@ -17,6 +17,7 @@ func ToSchemaMeta(result Result, mode Mode, previousHash, currentHash string) *s
 		},
 		Previous: schema.ConvergenceHistoricalCounts{
 			Resolved:  result.Summary.Previous.Resolved,
+			Answered:  result.Summary.Previous.Answered,
 			Dropped:   result.Summary.Previous.Dropped,
 			Untracked: result.Summary.Previous.Untracked,
 		},
@@ -33,6 +34,7 @@ func convertCounts(in map[string]CountSet) map[string]schema.ConvergenceCounts {
 			New:       value.New,
 			StillOpen: value.StillOpen,
 			Resolved:  value.Resolved,
+			Answered:  value.Answered,
 			Dropped:   value.Dropped,
 			Untracked: value.Untracked,
 		}
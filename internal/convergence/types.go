@@ -44,6 +44,7 @@ type HistoricalStatus string
 
 const (
 	HistoricalResolved  HistoricalStatus = "resolved"
+	HistoricalAnswered  HistoricalStatus = "answered"
 	HistoricalDropped   HistoricalStatus = "dropped"
 	HistoricalUntracked HistoricalStatus = "untracked"
 )
@@ -59,6 +60,13 @@ type Config struct {
 	RedactionConfigHash   string
 	CurrentSpecHash       string
 	CurrentReviewCoverage ReviewCoverage
+	// Scope lists the line ranges a section-scoped review covered. Previous
+	// findings outside these ranges were not re-reviewed and stay untracked.
+	Scope []schema.ScopeRange
+	// Suppressed holds the fingerprints of findings a triage file accepted
+	// or dismissed. Previous findings with these fingerprints are left out of
+	// the comparison, so they are not reported as resolved.
+	Suppressed map[string]bool
 }
 
 // ReviewCoverage describes how completely the current report reviewed the
@@ -141,12 +149,14 @@ type CountSet struct {
 	New       int
 	StillOpen int
 	Resolved  int
+	Answered  int
 	Dropped   int
 	Untracked int
 }
 
 type HistoricalCountSet struct {
 	Resolved  int
+	Answered  int
 	Dropped   int
 	Untracked int
 }
@@ -0,0 +1,32 @@
+// Package jsonrpc holds the JSON-RPC 2.0 message shapes and error codes
+// shared by the MCP and language servers. Framing differs between the two
+// protocols, so reading and writing stays in each server.
+package jsonrpc
+
+import "encoding/json"
+
+// Version is the jsonrpc member of every message.
+const Version = "2.0"
+
+// Error codes defined by JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+)
+
+// Message is an incoming request, notification, or response. Requests carry
+// an ID; notifications do not. Responses have no method.
+type Message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// Error is the error member of a response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,91 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+
+	"github.com/dshills/speccritic/internal/jsonrpc"
+)
+
+// maxMessageBytes bounds a single protocol message. Specs are small text
+// files, so anything larger is a framing error rather than a real document.
+const maxMessageBytes = 64 << 20
+
+// codeRequestFailed is the LSP error code for a request that was valid but
+// could not be completed.
+const codeRequestFailed = -32803
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// readMessage reads one Content-Length framed message.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	if length > maxMessageBytes {
+		return nil, fmt.Errorf("message of %d bytes exceeds the %d byte limit", length, maxMessageBytes)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writer frames outgoing messages. Reviews publish diagnostics from their
+// own goroutines, so writes are serialized.
+type writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *writer) write(v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := fmt.Fprintf(w.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.w.Write(body)
+	return err
+}
+
+func (w *writer) reply(id json.RawMessage, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return w.replyError(id, codeRequestFailed, err.Error())
+	}
+	return w.write(response{JSONRPC: jsonrpc.Version, ID: id, Result: data})
+}
+
+func (w *writer) replyError(id json.RawMessage, code int, msg string) error {
+	return w.write(response{JSONRPC: jsonrpc.Version, ID: id, Error: &jsonrpc.Error{Code: code, Message: msg}})
+}
+
+func (w *writer) notify(method string, params any) error {
+	return w.write(notification{JSONRPC: jsonrpc.Version, Method: method, Params: params})
+}
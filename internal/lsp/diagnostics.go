@@ -0,0 +1,166 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/dshills/speccritic/internal/preflight"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const diagnosticSource = "speccritic"
+
+// textIndex maps byte offsets and evidence line ranges of a document to
+// protocol positions.
+type textIndex struct {
+	text string
+	// lineStarts holds the byte offset of each line.
+	lineStarts []int
+}
+
+func newTextIndex(text string) *textIndex {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &textIndex{text: text, lineStarts: starts}
+}
+
+// position converts a byte offset to a protocol position.
+func (t *textIndex) position(offset int) Position {
+	line := 0
+	for line+1 < len(t.lineStarts) && t.lineStarts[line+1] <= offset {
+		line++
+	}
+	return Position{Line: line, Character: utf16Len(t.text[t.lineStarts[line]:offset])}
+}
+
+// lineEnd returns the offset of the end of a zero-based line, before its
+// line ending.
+func (t *textIndex) lineEnd(line int) int {
+	end := len(t.text)
+	if line+1 < len(t.lineStarts) {
+		end = t.lineStarts[line+1] - 1
+	}
+	if end > t.lineStarts[line] && t.text[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+// evidenceRange locates evidence in the document. The quote is matched in
+// the evidence lines first, then anywhere in the document when it occurs
+// exactly once there, so findings follow text that moved since the review.
+// Without a matching quote the range covers the evidence lines.
+func (t *textIndex) evidenceRange(ev schema.Evidence) Range {
+	last := len(t.lineStarts) - 1
+	start := min(max(ev.LineStart, 1), last+1) - 1
+	end := min(max(ev.LineEnd, ev.LineStart, 1), last+1) - 1
+	from, to := t.lineStarts[start], t.lineEnd(end)
+	if quote := strings.TrimSpace(ev.Quote); quote != "" {
+		if i := strings.Index(t.text[from:to], quote); i >= 0 {
+			return Range{Start: t.position(from + i), End: t.position(from + i + len(quote))}
+		}
+		if strings.Count(t.text, quote) == 1 {
+			i := strings.Index(t.text, quote)
+			return Range{Start: t.position(i), End: t.position(i + len(quote))}
+		}
+	}
+	return Range{Start: t.position(from), End: t.position(to)}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return n
+}
+
+// buildDiagnostics converts findings into diagnostics for text. Preflight
+// findings come from the latest preflight run, which follows every edit;
+// LLM findings and questions come from the last review.
+func buildDiagnostics(text string, current, review *schema.Report) []Diagnostic {
+	index := newTextIndex(text)
+	diags := []Diagnostic{}
+	if current != nil {
+		for _, issue := range current.Issues {
+			diags = append(diags, issueDiagnostics(index, issue)...)
+		}
+	}
+	if review == nil {
+		return diags
+	}
+	for _, issue := range review.Issues {
+		if current != nil && isPreflight(issue) {
+			continue
+		}
+		diags = append(diags, issueDiagnostics(index, issue)...)
+	}
+	for _, q := range review.Questions {
+		if q.Status == "answered" {
+			continue
+		}
+		message := q.Question
+		if q.WhyNeeded != "" {
+			message += "\n\n" + q.WhyNeeded
+		}
+		for _, r := range evidenceRanges(index, q.Evidence) {
+			diags = append(diags, Diagnostic{Range: r, Severity: SeverityHint, Code: q.ID, Source: diagnosticSource, Message: message})
+		}
+	}
+	return diags
+}
+
+func issueDiagnostics(index *textIndex, issue schema.Issue) []Diagnostic {
+	message := issue.Title
+	if issue.Description != "" {
+		message += "\n\n" + issue.Description
+	}
+	if issue.Recommendation != "" {
+		message += "\n\nRecommendation: " + issue.Recommendation
+	}
+	var diags []Diagnostic
+	for _, r := range evidenceRanges(index, issue.Evidence) {
+		diags = append(diags, Diagnostic{Range: r, Severity: diagnosticSeverity(issue.Severity), Code: issue.ID, Source: diagnosticSource, Message: message})
+	}
+	return diags
+}
+
+// evidenceRanges returns one range per evidence entry; findings without
+// evidence are shown on the first line.
+func evidenceRanges(index *textIndex, evidence []schema.Evidence) []Range {
+	if len(evidence) == 0 {
+		return []Range{index.evidenceRange(schema.Evidence{LineStart: 1, LineEnd: 1})}
+	}
+	ranges := make([]Range, len(evidence))
+	for i, ev := range evidence {
+		ranges[i] = index.evidenceRange(ev)
+	}
+	return ranges
+}
+
+func diagnosticSeverity(severity schema.Severity) int {
+	switch severity {
+	case schema.SeverityCritical:
+		return SeverityError
+	case schema.SeverityWarn:
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
+
+func isPreflight(issue schema.Issue) bool {
+	for _, tag := range issue.Tags {
+		if tag == preflight.TagPreflight {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestEvidenceRange(t *testing.T) {
+	index := newTextIndex("# Überblick\r\nLatenz 😀 must be fast.\r\nMoved line.\n")
+	for name, tc := range map[string]struct {
+		ev   schema.Evidence
+		want Range
+	}{
+		// The emoji is two UTF-16 code units.
+		"quote":      {schema.Evidence{LineStart: 2, LineEnd: 2, Quote: "must be fast"}, Range{Position{1, 10}, Position{1, 22}}},
+		"moved":      {schema.Evidence{LineStart: 1, LineEnd: 1, Quote: "Moved line."}, Range{Position{2, 0}, Position{2, 11}}},
+		"lines":      {schema.Evidence{LineStart: 1, LineEnd: 2, Quote: "gone"}, Range{Position{0, 0}, Position{1, 23}}},
+		"past end":   {schema.Evidence{LineStart: 40, LineEnd: 41}, Range{Position{3, 0}, Position{3, 0}}},
+		"no end set": {schema.Evidence{LineStart: 1}, Range{Position{0, 0}, Position{0, 11}}},
+	} {
+		if got := index.evidenceRange(tc.ev); got != tc.want {
+			t.Errorf("%s: range = %+v, want %+v", name, got, tc.want)
+		}
+	}
+}
+
+func TestBuildDiagnosticsPrefersCurrentPreflight(t *testing.T) {
+	text := "The system must be fast.\n"
+	stale := schema.Issue{ID: "PREFLIGHT-OLD-001", Severity: schema.SeverityWarn, Title: "stale", Tags: []string{"preflight"}}
+	current := &schema.Report{Issues: []schema.Issue{{ID: "PREFLIGHT-NEW-001", Severity: schema.SeverityInfo, Title: "new", Tags: []string{"preflight"}}}}
+	review := &schema.Report{
+		Issues:    []schema.Issue{stale, {ID: "ISSUE-0001", Severity: schema.SeverityCritical, Title: "llm"}},
+		Questions: []schema.Question{{ID: "Q-0001", Question: "open?"}, {ID: "Q-0002", Question: "done?", Status: "answered"}},
+	}
+	var codes []any
+	for _, d := range buildDiagnostics(text, current, review) {
+		codes = append(codes, d.Code)
+	}
+	want := []any{"PREFLIGHT-NEW-001", "ISSUE-0001", "Q-0001"}
+	if len(codes) != len(want) {
+		t.Fatalf("codes = %v, want %v", codes, want)
+	}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("codes = %v, want %v", codes, want)
+		}
+	}
+}
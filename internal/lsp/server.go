@@ -0,0 +1,498 @@
+// Package lsp serves SpecCritic findings to editors over the Language Server
+// Protocol. Preflight runs on every change; the LLM review runs on save and
+// reviews incrementally against the last reviewed text.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/jsonrpc"
+	"github.com/dshills/speccritic/internal/patch"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// Config controls the checks the server runs.
+type Config struct {
+	Version          string
+	Profile          string
+	Strict           bool
+	PreflightProfile string
+	ContextPaths     []string
+	LLMProvider      string
+	LLMModel         string
+	Temperature      float64
+	MaxTokens        int
+	// CompletionSuggestions offers completion patches as code actions.
+	CompletionSuggestions bool
+	// ReviewOnSave runs the LLM review when a document is saved; otherwise
+	// only preflight findings are published.
+	ReviewOnSave bool
+	// LintDelay is how long preflight waits after a change before running,
+	// so a burst of keystrokes runs it once.
+	LintDelay time.Duration
+	// ErrWriter receives check warnings and diagnostics that could not be
+	// sent to the editor.
+	ErrWriter io.Writer
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Profile:               "general",
+		Temperature:           0.2,
+		MaxTokens:             4096,
+		CompletionSuggestions: true,
+		ReviewOnSave:          true,
+		LintDelay:             300 * time.Millisecond,
+		ErrWriter:             io.Discard,
+	}
+}
+
+type checker interface {
+	Check(context.Context, app.CheckRequest) (*app.CheckResult, error)
+}
+
+type Server struct {
+	config  Config
+	checker checker
+	out     *writer
+
+	mu       sync.Mutex
+	docs     map[string]*document
+	tasks    sync.WaitGroup
+	shutdown bool
+}
+
+// document is an open spec and the findings published for it.
+type document struct {
+	uri     string
+	version int
+	text    string
+	// current is the preflight report for text.
+	current *schema.Report
+	// review is the last completed LLM review, of reviewedText.
+	review       *schema.Report
+	reviewedText string
+	reviewJSON   string
+	cancelReview context.CancelFunc
+	cancelLint   context.CancelFunc
+}
+
+func NewServer(config Config) *Server {
+	return NewServerWithChecker(config, app.NewChecker())
+}
+
+func NewServerWithChecker(config Config, c checker) *Server {
+	if config.ErrWriter == nil {
+		config.ErrWriter = io.Discard
+	}
+	return &Server{config: config, checker: c, docs: make(map[string]*document)}
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client sends exit or closes r. In-flight preflight runs and
+// reviews are cancelled before Serve returns.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.tasks.Wait()
+	}()
+	s.out = &writer{w: w}
+	in := bufio.NewReader(r)
+	for {
+		body, err := readMessage(in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("lsp: reading message: %w", err)
+		}
+		var msg jsonrpc.Message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.out.replyError(json.RawMessage("null"), jsonrpc.CodeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(ctx, msg); err != nil {
+			return fmt.Errorf("lsp: writing message: %w", err)
+		}
+	}
+}
+
+// handle dispatches one message. It returns only write errors; request
+// failures are reported to the client.
+func (s *Server) handle(ctx context.Context, msg jsonrpc.Message) error {
+	if msg.ID == nil {
+		s.handleNotification(ctx, msg)
+		return nil
+	}
+	id := *msg.ID
+	if msg.Method == "" {
+		// A response to a server request; the server sends none.
+		return nil
+	}
+	switch msg.Method {
+	case "initialize":
+		return s.out.reply(id, initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:   textDocumentSyncOptions{OpenClose: true, Change: 1, Save: saveOptions{IncludeText: true}},
+				CodeActionProvider: codeActionOptions{CodeActionKinds: []string{"quickfix"}},
+			},
+			ServerInfo: serverInfo{Name: "speccritic", Version: s.config.Version},
+		})
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		for _, doc := range s.docs {
+			doc.stop()
+		}
+		s.mu.Unlock()
+		return s.out.reply(id, nil)
+	case "textDocument/codeAction":
+		var params CodeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.out.replyError(id, jsonrpc.CodeInvalidParams, err.Error())
+		}
+		return s.out.reply(id, s.codeActions(params))
+	default:
+		return s.out.replyError(id, jsonrpc.CodeMethodNotFound, fmt.Sprintf("method %q not supported", msg.Method))
+	}
+}
+
+func (s *Server) handleNotification(ctx context.Context, msg jsonrpc.Message) {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) != nil {
+			return
+		}
+		doc := params.TextDocument
+		s.mu.Lock()
+		if old := s.docs[doc.URI]; old != nil {
+			old.stop()
+		}
+		s.docs[doc.URI] = &document{uri: doc.URI, version: doc.Version, text: doc.Text}
+		s.mu.Unlock()
+		s.lint(ctx, doc.URI, 0)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) != nil || len(params.ContentChanges) == 0 {
+			return
+		}
+		uri := params.TextDocument.URI
+		if s.update(uri, params.TextDocument.Version, params.ContentChanges[len(params.ContentChanges)-1].Text) {
+			s.lint(ctx, uri, s.config.LintDelay)
+		}
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) != nil {
+			return
+		}
+		uri := params.TextDocument.URI
+		if params.Text != nil && s.update(uri, -1, *params.Text) {
+			s.lint(ctx, uri, 0)
+		}
+		if s.config.ReviewOnSave {
+			s.review(ctx, uri)
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) != nil {
+			return
+		}
+		s.mu.Lock()
+		if doc := s.docs[params.TextDocument.URI]; doc != nil {
+			doc.stop()
+			delete(s.docs, params.TextDocument.URI)
+		}
+		s.mu.Unlock()
+		s.publish(PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	}
+}
+
+// update replaces an open document's text, keeping its version when version
+// is negative. It reports whether the text changed.
+func (s *Server) update(uri string, version int, text string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := s.docs[uri]
+	if doc == nil {
+		return false
+	}
+	if version >= 0 {
+		doc.version = version
+	}
+	changed := doc.text != text
+	doc.text = text
+	return changed
+}
+
+func (d *document) stopReview() {
+	if d.cancelReview != nil {
+		d.cancelReview()
+		d.cancelReview = nil
+	}
+}
+
+func (d *document) stopLint() {
+	if d.cancelLint != nil {
+		d.cancelLint()
+		d.cancelLint = nil
+	}
+}
+
+// stop cancels the document's preflight run and review.
+func (d *document) stop() {
+	d.stopLint()
+	d.stopReview()
+}
+
+// lint runs preflight on the document's text after delay and republishes
+// its diagnostics. It runs off the read loop, so other requests are served
+// meanwhile, and a newer change cancels a run that is waiting or in flight.
+func (s *Server) lint(ctx context.Context, uri string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := s.docs[uri]
+	if doc == nil || s.shutdown {
+		return
+	}
+	doc.stopLint()
+	lintCtx, cancel := context.WithCancel(ctx)
+	doc.cancelLint = cancel
+	s.tasks.Add(1)
+	go func() {
+		defer s.tasks.Done()
+		defer cancel()
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-lintCtx.Done():
+				timer.Stop()
+				return
+			}
+		}
+		s.mu.Lock()
+		if s.docs[uri] != doc || lintCtx.Err() != nil {
+			s.mu.Unlock()
+			return
+		}
+		req := s.request(uri, doc.text)
+		req.PreflightMode = "only"
+		version := doc.version
+		s.mu.Unlock()
+
+		result, err := s.checker.Check(lintCtx, req)
+		if lintCtx.Err() != nil {
+			// Superseded by a newer change, closed, or shutting down.
+			return
+		}
+		if err != nil {
+			s.showMessage(MessageError, fmt.Sprintf("SpecCritic preflight failed: %s", err))
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.docs[uri] != doc || lintCtx.Err() != nil || doc.version != version || doc.text != req.SpecText {
+			return
+		}
+		doc.cancelLint = nil
+		doc.current = result.Report
+		s.publishDocument(doc)
+	}()
+}
+
+// review starts an LLM review of the document's current text, replacing
+// any review still running for it. The previous review and its text seed
+// incremental mode, so only changed sections are sent to the model.
+func (s *Server) review(ctx context.Context, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := s.docs[uri]
+	if doc == nil || s.shutdown {
+		return
+	}
+	doc.stopReview()
+	req := s.request(uri, doc.text)
+	if doc.reviewJSON != "" {
+		req.IncrementalFromText = doc.reviewJSON
+		req.IncrementalBaseText = doc.reviewedText
+	}
+	reviewCtx, cancel := context.WithCancel(ctx)
+	doc.cancelReview = cancel
+	s.tasks.Add(1)
+	go func() {
+		defer s.tasks.Done()
+		defer cancel()
+		result, err := s.checker.Check(reviewCtx, req)
+		if reviewCtx.Err() != nil {
+			// Superseded by a newer save, closed, or shutting down.
+			return
+		}
+		if err != nil {
+			s.showMessage(MessageError, fmt.Sprintf("SpecCritic review failed: %s", err))
+			return
+		}
+		data, err := json.Marshal(result.Report)
+		if err != nil {
+			s.showMessage(MessageError, fmt.Sprintf("SpecCritic review failed: %s", err))
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.docs[uri] != doc || reviewCtx.Err() != nil {
+			return
+		}
+		doc.cancelReview = nil
+		doc.review = result.Report
+		doc.reviewedText = req.SpecText
+		doc.reviewJSON = string(data)
+		s.publishDocument(doc)
+	}()
+}
+
+// request builds the check request for a document from the shared defaults
+// and the server's configuration.
+func (s *Server) request(uri, text string) app.CheckRequest {
+	req := app.DefaultRequest(app.SourceCLI)
+	req.Version = s.config.Version
+	req.SpecName = documentName(uri)
+	req.SpecText = text
+	req.ContextPaths = s.config.ContextPaths
+	req.Profile = s.config.Profile
+	req.Strict = s.config.Strict
+	req.LLMProvider = s.config.LLMProvider
+	req.LLMModel = s.config.LLMModel
+	req.Temperature = s.config.Temperature
+	req.MaxTokens = s.config.MaxTokens
+	req.PreflightProfile = s.config.PreflightProfile
+	req.CompletionSuggestions = s.config.CompletionSuggestions
+	req.ErrWriter = s.config.ErrWriter
+	return req
+}
+
+// documentName returns the file name used in findings for a document URI.
+func documentName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Path == "" {
+		return "SPEC.md"
+	}
+	return filepath.Base(filepath.FromSlash(u.Path))
+}
+
+// publishDocument publishes the document's diagnostics. Callers hold s.mu.
+func (s *Server) publishDocument(doc *document) {
+	version := doc.version
+	s.publish(PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     &version,
+		Diagnostics: buildDiagnostics(doc.text, doc.current, doc.review),
+	})
+}
+
+func (s *Server) publish(params PublishDiagnosticsParams) {
+	if err := s.out.notify("textDocument/publishDiagnostics", params); err != nil {
+		fmt.Fprintf(s.config.ErrWriter, "WARN: publishing diagnostics: %s\n", err)
+	}
+}
+
+func (s *Server) showMessage(kind int, text string) {
+	if err := s.out.notify("window/showMessage", ShowMessageParams{Type: kind, Message: text}); err != nil {
+		fmt.Fprintf(s.config.ErrWriter, "WARN: %s\n", text)
+	}
+}
+
+// codeActions offers each patch that applies cleanly to the document and
+// touches the requested range as a quick fix.
+func (s *Server) codeActions(params CodeActionParams) []CodeAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := []CodeAction{}
+	doc := s.docs[params.TextDocument.URI]
+	if doc == nil {
+		return actions
+	}
+	index := newTextIndex(doc.text)
+	seen := make(map[schema.Patch]bool)
+	for _, report := range []*schema.Report{doc.review, doc.current} {
+		if report == nil {
+			continue
+		}
+		completion := completionIssues(report)
+		for _, p := range report.Patches {
+			if seen[p] || patch.Redacted(p) {
+				continue
+			}
+			seen[p] = true
+			edits, err := patch.LocateUnique(doc.text, []schema.Patch{p})
+			if err != nil {
+				continue
+			}
+			e := edits[0]
+			r := Range{Start: index.position(e.Start), End: index.position(e.End)}
+			if !overlaps(r, params.Range) {
+				continue
+			}
+			patched, err := patch.Apply(doc.text, edits)
+			if err != nil {
+				continue
+			}
+			title := "Apply SpecCritic patch for " + p.IssueID
+			if completion[p.IssueID] {
+				title = "Apply SpecCritic completion patch for " + p.IssueID
+			}
+			var diags []Diagnostic
+			for _, d := range params.Context.Diagnostics {
+				if d.Code == p.IssueID {
+					diags = append(diags, d)
+				}
+			}
+			actions = append(actions, CodeAction{
+				Title:       title,
+				Kind:        "quickfix",
+				Diagnostics: diags,
+				Edit: &WorkspaceEdit{Changes: map[string][]TextEdit{
+					doc.uri: {{Range: r, NewText: patched[e.Start : len(patched)-(len(doc.text)-e.End)]}},
+				}},
+			})
+		}
+	}
+	return actions
+}
+
+func completionIssues(report *schema.Report) map[string]bool {
+	out := make(map[string]bool)
+	for _, issue := range report.Issues {
+		for _, tag := range issue.Tags {
+			if tag == "completion-suggested" {
+				out[issue.ID] = true
+			}
+		}
+	}
+	return out
+}
+
+// overlaps reports whether two ranges share a position; touching ranges
+// overlap so a cursor at either end of a patch offers it.
+func overlaps(a, b Range) bool {
+	return !before(a.End, b.Start) && !before(b.End, a.Start)
+}
+
+func before(a, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
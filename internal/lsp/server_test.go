@@ -0,0 +1,307 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/jsonrpc"
+	"github.com/dshills/speccritic/internal/llm"
+)
+
+const testURI = "file:///work/SPEC.md"
+
+const testSpec = "# Spec\n\n## Requirements\n\nThe retry limit is TBD.\nThe API must respond quickly.\n"
+
+type fakeProvider struct {
+	mu      sync.Mutex
+	content string
+	calls   int
+}
+
+func (p *fakeProvider) Complete(_ context.Context, _ *llm.Request) (*llm.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	return &llm.Response{Content: p.content, Model: "fake:model"}, nil
+}
+
+// testClient drives a Server over in-memory pipes.
+type testClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	msgs   chan map[string]json.RawMessage
+	done   chan error
+	nextID int
+}
+
+func startServer(t *testing.T, c checker, config Config) *testClient {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	client := &testClient{t: t, in: clientW, msgs: make(chan map[string]json.RawMessage, 64), done: make(chan error, 1)}
+	go func() {
+		client.done <- NewServerWithChecker(config, c).Serve(context.Background(), serverR, serverW)
+		_ = serverW.Close()
+	}()
+	go func() {
+		r := bufio.NewReader(clientR)
+		for {
+			body, err := readMessage(r)
+			if err != nil {
+				close(client.msgs)
+				return
+			}
+			var msg map[string]json.RawMessage
+			if err := json.Unmarshal(body, &msg); err == nil {
+				client.msgs <- msg
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		client.send("exit", nil, false)
+		_ = clientW.Close()
+		select {
+		case <-client.done:
+		case <-time.After(5 * time.Second):
+			t.Error("server did not exit")
+		}
+	})
+	return client
+}
+
+func (c *testClient) send(method string, params any, request bool) int {
+	c.t.Helper()
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if request {
+		c.nextID++
+		msg["id"] = c.nextID
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatalf("marshal: %v", err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+	return c.nextID
+}
+
+// waitFor returns the first message accepted by match, skipping others.
+func (c *testClient) waitFor(what string, match func(map[string]json.RawMessage) bool) map[string]json.RawMessage {
+	c.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.msgs:
+			if !ok {
+				c.t.Fatalf("connection closed waiting for %s", what)
+			}
+			if match(msg) {
+				return msg
+			}
+		case <-timeout:
+			c.t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func (c *testClient) result(id int, v any) {
+	c.t.Helper()
+	msg := c.waitFor(fmt.Sprintf("response %d", id), func(m map[string]json.RawMessage) bool {
+		return string(m["id"]) == fmt.Sprint(id)
+	})
+	if msg["error"] != nil {
+		c.t.Fatalf("response %d error: %s", id, msg["error"])
+	}
+	if err := json.Unmarshal(msg["result"], v); err != nil {
+		c.t.Fatalf("decode result: %v", err)
+	}
+}
+
+// diagnostics waits for published diagnostics that satisfy match.
+func (c *testClient) diagnostics(what string, match func([]Diagnostic) bool) []Diagnostic {
+	c.t.Helper()
+	var diags []Diagnostic
+	c.waitFor(what, func(m map[string]json.RawMessage) bool {
+		if string(m["method"]) != `"textDocument/publishDiagnostics"` {
+			return false
+		}
+		var params PublishDiagnosticsParams
+		if json.Unmarshal(m["params"], &params) != nil {
+			return false
+		}
+		diags = params.Diagnostics
+		return match(diags)
+	})
+	return diags
+}
+
+func findDiagnostic(diags []Diagnostic, code string) *Diagnostic {
+	for i := range diags {
+		if diags[i].Code == code {
+			return &diags[i]
+		}
+	}
+	return nil
+}
+
+func openSpec(c *testClient, text string) {
+	c.send("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: testURI, LanguageID: "markdown", Version: 1, Text: text}}, false)
+}
+
+func TestServerInitializeAdvertisesCapabilities(t *testing.T) {
+	c := startServer(t, app.NewChecker(), DefaultConfig())
+	var result initializeResult
+	c.result(c.send("initialize", map[string]any{}, true), &result)
+	if result.Capabilities.TextDocumentSync.Change != 1 || !result.Capabilities.TextDocumentSync.Save.IncludeText || result.ServerInfo.Name != "speccritic" {
+		t.Fatalf("initialize result = %+v", result)
+	}
+	var unknown map[string]any
+	id := c.send("textDocument/hover", map[string]any{}, true)
+	msg := c.waitFor("hover error", func(m map[string]json.RawMessage) bool { return string(m["id"]) == fmt.Sprint(id) })
+	if msg["error"] == nil || json.Unmarshal(msg["error"], &unknown) != nil || unknown["code"] != float64(jsonrpc.CodeMethodNotFound) {
+		t.Fatalf("hover response = %v", msg)
+	}
+}
+
+func TestServerPublishesPreflightOnChange(t *testing.T) {
+	config := DefaultConfig()
+	config.ReviewOnSave = false
+	c := startServer(t, app.NewChecker(), config)
+	openSpec(c, testSpec)
+
+	diags := c.diagnostics("preflight diagnostics", func(d []Diagnostic) bool { return findDiagnostic(d, "PREFLIGHT-TODO-001") != nil })
+	todo := findDiagnostic(diags, "PREFLIGHT-TODO-001")
+	if todo.Source != "speccritic" || todo.Severity == 0 {
+		t.Fatalf("diagnostic = %+v", todo)
+	}
+	want := Range{Start: Position{Line: 4, Character: 0}, End: Position{Line: 4, Character: len("The retry limit is TBD.")}}
+	if todo.Range != want {
+		t.Fatalf("range = %+v, want %+v", todo.Range, want)
+	}
+
+	fixed := strings.Replace(testSpec, "TBD", "3 attempts", 1)
+	c.send("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: fixed}},
+	}, false)
+	c.diagnostics("diagnostics after fix", func(d []Diagnostic) bool { return findDiagnostic(d, "PREFLIGHT-TODO-001") == nil })
+}
+
+func TestServerReviewsOnSaveAndOffersPatches(t *testing.T) {
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+	provider := &fakeProvider{content: `{"issues":[{"id":"ISSUE-0001","severity":"CRITICAL","category":"NON_TESTABLE_REQUIREMENT","title":"Vague latency","description":"quickly is not testable","evidence":[{"path":"SPEC.md","line_start":6,"line_end":6,"quote":"respond quickly"}],"impact":"x","recommendation":"State a latency bound.","blocking":true,"tags":[]}],` +
+		`"questions":[{"id":"Q-0001","severity":"WARN","question":"What is the retry limit?","why_needed":"Clients need it.","blocks":["ISSUE-0001"],"evidence":[{"path":"SPEC.md","line_start":5,"line_end":5,"quote":"retry limit"}]}],` +
+		`"patches":[{"issue_id":"ISSUE-0001","before":"The API must respond quickly.","after":"The API must respond within 200 ms at p99."}]}`}
+	checker := &app.Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}
+	c := startServer(t, checker, DefaultConfig())
+	openSpec(c, testSpec)
+	// Preflight for the open runs in the background; wait for it so its
+	// completion patches are offered alongside the review's.
+	c.diagnostics("preflight diagnostics", func(d []Diagnostic) bool { return findDiagnostic(d, "PREFLIGHT-TODO-001") != nil })
+	c.send("textDocument/didSave", DidSaveTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}}, false)
+
+	diags := c.diagnostics("review diagnostics", func(d []Diagnostic) bool { return findDiagnostic(d, "ISSUE-0001") != nil })
+	issue := findDiagnostic(diags, "ISSUE-0001")
+	wantIssue := Range{Start: Position{Line: 5, Character: 13}, End: Position{Line: 5, Character: 28}}
+	if issue.Severity != SeverityError || issue.Range != wantIssue || !strings.Contains(issue.Message, "State a latency bound.") {
+		t.Fatalf("issue diagnostic = %+v", issue)
+	}
+	if q := findDiagnostic(diags, "Q-0001"); q == nil || q.Severity != SeverityHint {
+		t.Fatalf("question diagnostic = %+v", q)
+	}
+	if findDiagnostic(diags, "PREFLIGHT-TODO-001") == nil {
+		t.Fatal("preflight diagnostic dropped after review")
+	}
+
+	var actions []CodeAction
+	c.result(c.send("textDocument/codeAction", CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+		Range:        Range{Start: Position{Line: 5, Character: 15}, End: Position{Line: 5, Character: 15}},
+		Context:      CodeActionContext{Diagnostics: []Diagnostic{*issue}},
+	}, true), &actions)
+	// Completion may offer its own patch for the finding; the LLM patch must
+	// be among the actions.
+	wantEdit := TextEdit{Range: Range{Start: Position{Line: 5}, End: Position{Line: 5, Character: len("The API must respond quickly.")}}, NewText: "The API must respond within 200 ms at p99."}
+	var found bool
+	for _, a := range actions {
+		edits := a.Edit.Changes[testURI]
+		if len(edits) == 1 && edits[0] == wantEdit {
+			found = strings.HasSuffix(a.Title, "for ISSUE-0001") && len(a.Diagnostics) == 1
+		}
+	}
+	if !found {
+		t.Fatalf("no patch action for ISSUE-0001: %+v", actions)
+	}
+
+	for _, a := range actions {
+		if strings.HasPrefix(a.Title, "Apply SpecCritic completion patch for PREFLIGHT-") {
+			return
+		}
+	}
+	t.Fatalf("no completion patch action: %+v", actions)
+}
+
+// blockingChecker holds every check until its context is cancelled or
+// release is closed, and records the spec text of each check it starts.
+type blockingChecker struct {
+	mu      sync.Mutex
+	texts   []string
+	release chan struct{}
+}
+
+func (c *blockingChecker) Check(ctx context.Context, req app.CheckRequest) (*app.CheckResult, error) {
+	c.mu.Lock()
+	c.texts = append(c.texts, req.SpecText)
+	c.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.release:
+		return app.NewChecker().Check(ctx, req)
+	}
+}
+
+func (c *blockingChecker) started() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.texts...)
+}
+
+func TestServerLintsOffTheReadLoopAndDebouncesChanges(t *testing.T) {
+	config := DefaultConfig()
+	config.ReviewOnSave = false
+	config.LintDelay = 200 * time.Millisecond
+	checker := &blockingChecker{release: make(chan struct{})}
+	c := startServer(t, checker, config)
+	openSpec(c, testSpec)
+
+	// Preflight for the open is blocked, yet requests are still answered.
+	var actions []CodeAction
+	c.result(c.send("textDocument/codeAction", CodeActionParams{TextDocument: TextDocumentIdentifier{URI: testURI}}, true), &actions)
+
+	fixed := strings.Replace(testSpec, "TBD", "3 attempts", 1)
+	for version, text := range []string{testSpec + "\n", testSpec + "\n\n", fixed} {
+		c.send("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: version + 2},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+		}, false)
+	}
+	close(checker.release)
+	c.diagnostics("diagnostics for the last change", func(d []Diagnostic) bool {
+		return findDiagnostic(d, "PREFLIGHT-TODO-001") == nil
+	})
+	texts := checker.started()
+	if len(texts) != 2 || texts[0] != testSpec || texts[1] != fixed {
+		t.Fatalf("checked %d texts, want the opened text and the last change only", len(texts))
+	}
+}
@@ -0,0 +1,188 @@
+// Package mcp serves SpecCritic as Model Context Protocol tools over stdio,
+// so coding agents can review and patch specs with structured results. The
+// server keeps each spec's last review, and later checks of the same spec
+// review incrementally against it.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/jsonrpc"
+)
+
+// protocolVersion is the newest protocol revision the server implements.
+// Clients asking for an older supported revision get that revision.
+const protocolVersion = "2025-06-18"
+
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// maxMessageBytes bounds one newline-delimited message.
+const maxMessageBytes = 64 << 20
+
+// Config controls the checks the server runs. Tool arguments may override
+// the profile and strict mode per call.
+type Config struct {
+	Version          string
+	Profile          string
+	Strict           bool
+	PreflightProfile string
+	ContextPaths     []string
+	LLMProvider      string
+	LLMModel         string
+	Temperature      float64
+	MaxTokens        int
+	// CompletionSuggestions adds completion patches to reports.
+	CompletionSuggestions bool
+	// ErrWriter receives check warnings. Stdout carries the protocol, so
+	// nothing else may be written there.
+	ErrWriter io.Writer
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Profile:     "general",
+		Temperature: 0.2,
+		MaxTokens:   4096,
+		ErrWriter:   io.Discard,
+	}
+}
+
+type checker interface {
+	Check(context.Context, app.CheckRequest) (*app.CheckResult, error)
+}
+
+type Server struct {
+	config  Config
+	checker checker
+
+	mu    sync.Mutex
+	specs map[string]*specState
+}
+
+func NewServer(config Config) *Server {
+	return NewServerWithChecker(config, app.NewChecker())
+}
+
+func NewServerWithChecker(config Config, c checker) *Server {
+	if config.ErrWriter == nil {
+		config.ErrWriter = io.Discard
+	}
+	return &Server{config: config, checker: c, specs: make(map[string]*specState)}
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// rpcError is a handler failure reported as a JSON-RPC error rather than a
+// tool result.
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e *rpcError) Error() string { return e.msg }
+
+func invalidParams(format string, args ...any) error {
+	return &rpcError{code: jsonrpc.CodeInvalidParams, msg: fmt.Sprintf(format, args...)}
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes
+// responses to w until r is closed. Requests are handled in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageBytes)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var msg jsonrpc.Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			if err := enc.Encode(errorResponse(json.RawMessage("null"), jsonrpc.CodeParseError, err.Error())); err != nil {
+				return fmt.Errorf("mcp: writing message: %w", err)
+			}
+			continue
+		}
+		if msg.ID == nil || msg.Method == "" {
+			// Notifications need no reply, and the server sends no requests
+			// whose responses it would wait for.
+			continue
+		}
+		resp := s.handle(ctx, msg)
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("mcp: writing message: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("mcp: reading message: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) handle(ctx context.Context, msg jsonrpc.Message) response {
+	id := *msg.ID
+	var result any
+	var err error
+	switch msg.Method {
+	case "initialize":
+		result, err = s.initialize(msg.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = map[string]any{"tools": toolDefinitions()}
+	case "tools/call":
+		result, err = s.callTool(ctx, msg.Params)
+	default:
+		err = &rpcError{code: jsonrpc.CodeMethodNotFound, msg: fmt.Sprintf("method %q not supported", msg.Method)}
+	}
+	if err != nil {
+		code := jsonrpc.CodeInvalidRequest
+		if rpcErr, ok := err.(*rpcError); ok {
+			code = rpcErr.code
+		}
+		return errorResponse(id, code, err.Error())
+	}
+	return response{JSONRPC: jsonrpc.Version, ID: id, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) response {
+	return response{JSONRPC: jsonrpc.Version, ID: id, Error: &jsonrpc.Error{Code: code, Message: msg}}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var req struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, invalidParams("initialize: %s", err)
+		}
+	}
+	version := protocolVersion
+	if supportedVersions[req.ProtocolVersion] {
+		version = req.ProtocolVersion
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]any{"name": "speccritic", "version": s.config.Version},
+		"instructions": "Run preflight_check while editing a spec and check for a full review. " +
+			"Later checks of the same spec reuse the previous review incrementally. " +
+			"Use get_issue and list_questions to read findings, and apply_patch to apply suggested patches.",
+	}, nil
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/jsonrpc"
+	"github.com/dshills/speccritic/internal/llm"
+)
+
+const testSpec = "# Spec\n\n## Requirements\n\nThe retry limit is TBD.\nThe API must respond quickly.\n"
+
+const reviewResponse = `{"issues":[{"id":"ISSUE-0001","severity":"CRITICAL","category":"NON_TESTABLE_REQUIREMENT","title":"Vague latency","description":"quickly is not testable","evidence":[{"path":"SPEC.md","line_start":6,"line_end":6,"quote":"respond quickly"}],"impact":"x","recommendation":"State a latency bound.","blocking":true,"tags":[]}],` +
+	`"questions":[{"id":"Q-0001","severity":"WARN","question":"What is the retry limit?","why_needed":"Clients need it.","blocks":["ISSUE-0001"],"evidence":[{"path":"SPEC.md","line_start":5,"line_end":5,"quote":"retry limit"}]}],` +
+	`"patches":[{"issue_id":"ISSUE-0001","before":"The API must respond quickly.","after":"The API must respond within 200 ms at p99."}]}`
+
+type fakeProvider struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *fakeProvider) Complete(_ context.Context, _ *llm.Request) (*llm.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	return &llm.Response{Content: reviewResponse, Model: "fake:model"}, nil
+}
+
+// recordingChecker records requests before delegating to a real checker.
+type recordingChecker struct {
+	checker *app.Checker
+	reqs    []app.CheckRequest
+}
+
+func (c *recordingChecker) Check(ctx context.Context, req app.CheckRequest) (*app.CheckResult, error) {
+	c.reqs = append(c.reqs, req)
+	return c.checker.Check(ctx, req)
+}
+
+func newTestChecker(t *testing.T) *recordingChecker {
+	t.Helper()
+	t.Setenv("SPECCRITIC_LLM_PROVIDER", "fake")
+	t.Setenv("SPECCRITIC_LLM_MODEL", "model")
+	provider := &fakeProvider{}
+	return &recordingChecker{checker: &app.Checker{NewProvider: func(string) (llm.Provider, error) { return provider, nil }}}
+}
+
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+// serve runs the server over the given requests and returns the responses
+// by ID.
+func serve(t *testing.T, s *Server, requests ...map[string]any) map[int]rpcResponse {
+	t.Helper()
+	var in bytes.Buffer
+	for _, req := range requests {
+		req["jsonrpc"] = "2.0"
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		in.Write(append(data, '\n'))
+	}
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), &in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	responses := make(map[int]rpcResponse)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp rpcResponse
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		responses[resp.ID] = resp
+	}
+	return responses
+}
+
+func call(id int, name string, args map[string]any) map[string]any {
+	return map[string]any{"id": id, "method": "tools/call", "params": map[string]any{"name": name, "arguments": args}}
+}
+
+// toolOutput decodes a successful tool result's structured content.
+func toolOutput(t *testing.T, resp rpcResponse, v any) {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("response %d error: %+v", resp.ID, resp.Error)
+	}
+	var result toolResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if result.IsError || len(result.Content) != 1 {
+		t.Fatalf("response %d result = %+v", resp.ID, result)
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), v); err != nil {
+		t.Fatalf("decode content: %v", err)
+	}
+}
+
+func toolError(t *testing.T, resp rpcResponse) string {
+	t.Helper()
+	var result toolResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil || !result.IsError {
+		t.Fatalf("response %d = %s, want tool error", resp.ID, resp.Result)
+	}
+	return result.Content[0].Text
+}
+
+func TestServerInitializeAndListTools(t *testing.T) {
+	responses := serve(t, NewServerWithChecker(DefaultConfig(), app.NewChecker()),
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{"protocolVersion": "2024-11-05"}},
+		map[string]any{"method": "notifications/initialized"},
+		map[string]any{"id": 2, "method": "tools/list"},
+		map[string]any{"id": 3, "method": "resources/list"},
+	)
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(responses[1].Result, &init); err != nil || init.ProtocolVersion != "2024-11-05" {
+		t.Fatalf("initialize = %s", responses[1].Result)
+	}
+	var list struct {
+		Tools []tool `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[2].Result, &list); err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "preflight_check,check,get_issue,list_questions,apply_patch" {
+		t.Fatalf("tools = %s", got)
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != jsonrpc.CodeMethodNotFound {
+		t.Fatalf("resources/list = %+v", responses[3])
+	}
+}
+
+func TestServerCheckKeepsStateForIncrementalReview(t *testing.T) {
+	checker := newTestChecker(t)
+	s := NewServerWithChecker(DefaultConfig(), checker)
+	changed := strings.Replace(testSpec, "TBD", "3 attempts", 1)
+	responses := serve(t, s,
+		call(1, "check", map[string]any{"text": testSpec, "name": "api.md"}),
+		call(2, "get_issue", map[string]any{"name": "api.md", "id": "ISSUE-0001"}),
+		call(3, "list_questions", map[string]any{"name": "api.md"}),
+		call(4, "check", map[string]any{"text": changed, "name": "api.md"}),
+	)
+
+	var report struct {
+		Input struct {
+			SpecFile string `json:"spec_file"`
+		} `json:"input"`
+		Issues []struct {
+			ID string `json:"id"`
+		} `json:"issues"`
+	}
+	toolOutput(t, responses[1], &report)
+	if report.Input.SpecFile != "api.md" || len(report.Issues) == 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	var issue struct {
+		Issue struct {
+			Title string `json:"title"`
+		} `json:"issue"`
+		Patches []struct {
+			After string `json:"after"`
+		} `json:"patches"`
+	}
+	toolOutput(t, responses[2], &issue)
+	if issue.Issue.Title != "Vague latency" || len(issue.Patches) != 1 {
+		t.Fatalf("issue = %+v", issue)
+	}
+	var questions struct {
+		Questions []struct {
+			ID string `json:"id"`
+		} `json:"questions"`
+	}
+	toolOutput(t, responses[3], &questions)
+	if len(questions.Questions) != 1 || questions.Questions[0].ID != "Q-0001" {
+		t.Fatalf("questions = %+v", questions)
+	}
+	if len(checker.reqs) != 2 || checker.reqs[0].IncrementalFromText != "" {
+		t.Fatalf("requests = %d", len(checker.reqs))
+	}
+	if second := checker.reqs[1]; second.IncrementalFromText == "" || second.IncrementalBaseText != testSpec {
+		t.Fatal("second check did not reuse the previous review")
+	}
+}
+
+func TestServerApplyPatchToFile(t *testing.T) {
+	checker := newTestChecker(t)
+	s := NewServerWithChecker(DefaultConfig(), checker)
+	path := filepath.Join(t.TempDir(), "SPEC.md")
+	if err := os.WriteFile(path, []byte(testSpec), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	responses := serve(t, s,
+		call(1, "apply_patch", map[string]any{"path": path, "issue_ids": []string{"ISSUE-0001"}}),
+		call(2, "check", map[string]any{"path": path}),
+		call(3, "apply_patch", map[string]any{"path": path, "issue_ids": []string{"ISSUE-0009"}}),
+		call(4, "apply_patch", map[string]any{"path": path, "issue_ids": []string{"ISSUE-0001"}}),
+		call(5, "apply_patch", map[string]any{"path": path, "issue_ids": []string{"ISSUE-0001"}}),
+		call(6, "preflight_check", map[string]any{"path": path, "text": "x"}),
+	)
+	if msg := toolError(t, responses[1]); !strings.Contains(msg, "call check") {
+		t.Fatalf("apply before check = %q", msg)
+	}
+	if msg := toolError(t, responses[3]); !strings.Contains(msg, "no patch for ISSUE-0009") {
+		t.Fatalf("unknown issue = %q", msg)
+	}
+	var applied struct {
+		Applied []appliedPatch `json:"applied"`
+	}
+	toolOutput(t, responses[4], &applied)
+	if len(applied.Applied) != 1 || applied.Applied[0] != (appliedPatch{IssueID: "ISSUE-0001", Line: 6}) {
+		t.Fatalf("applied = %+v", applied)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "within 200 ms") {
+		t.Fatalf("spec = %q, %v", data, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, %v", info.Mode(), err)
+	}
+	if msg := toolError(t, responses[5]); !strings.Contains(msg, "no longer matches") {
+		t.Fatalf("stale patch = %q", msg)
+	}
+	if responses[6].Error == nil || responses[6].Error.Code != jsonrpc.CodeInvalidParams {
+		t.Fatalf("path and text = %+v", responses[6])
+	}
+}
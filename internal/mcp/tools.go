@@ -0,0 +1,459 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/patch"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// specState is what the server remembers about one spec between calls.
+type specState struct {
+	preflight *schema.Report
+	// review is the last full check, of reviewedText.
+	review       *schema.Report
+	reviewJSON   string
+	reviewedText string
+}
+
+// specArgs identifies a spec: a file on disk by path, or inline text under
+// a name. Text specs are remembered by name.
+type specArgs struct {
+	Path    string `json:"path"`
+	Text    string `json:"text"`
+	Name    string `json:"name"`
+	Profile string `json:"profile"`
+	Strict  *bool  `json:"strict"`
+}
+
+func (a specArgs) name() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return "SPEC.md"
+}
+
+// key returns the state key for the spec.
+func (a specArgs) key() string {
+	if a.Path != "" {
+		if abs, err := filepath.Abs(a.Path); err == nil {
+			return "file:" + abs
+		}
+		return "file:" + filepath.Clean(a.Path)
+	}
+	return "text:" + a.name()
+}
+
+func (a specArgs) label() string {
+	if a.Path != "" {
+		return a.Path
+	}
+	return a.name()
+}
+
+// validateSource requires exactly one of path and text.
+func (a specArgs) validateSource() error {
+	if (a.Path == "") == (a.Text == "") {
+		return invalidParams("exactly one of path and text is required")
+	}
+	return nil
+}
+
+var specProperties = map[string]any{
+	"path":    map[string]any{"type": "string", "description": "Path of the spec file"},
+	"text":    map[string]any{"type": "string", "description": "Spec text, instead of path"},
+	"name":    map[string]any{"type": "string", "description": "Name for a text spec; later calls refer to it by this name (default SPEC.md)"},
+	"profile": map[string]any{"type": "string", "description": "Specification profile: general, backend-api, regulated-system, or event-driven"},
+	"strict":  map[string]any{"type": "boolean", "description": "Treat silence as ambiguity"},
+}
+
+var refProperties = map[string]any{
+	"path": specProperties["path"],
+	"name": specProperties["name"],
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+func toolDefinitions() []tool {
+	withProps := func(base map[string]any, extra map[string]any) map[string]any {
+		props := make(map[string]any, len(base)+len(extra))
+		for k, v := range base {
+			props[k] = v
+		}
+		for k, v := range extra {
+			props[k] = v
+		}
+		return props
+	}
+	return []tool{
+		{
+			Name:        "preflight_check",
+			Description: "Run deterministic preflight rules on a spec without an LLM call. Returns a SpecCritic report.",
+			InputSchema: map[string]any{"type": "object", "properties": specProperties},
+		},
+		{
+			Name: "check",
+			Description: "Run a full SpecCritic review of a spec. Returns a SpecCritic report with issues, questions, and patches. " +
+				"Later checks of the same spec review only what changed since the previous check.",
+			InputSchema: map[string]any{"type": "object", "properties": withProps(specProperties, map[string]any{
+				"incremental": map[string]any{"type": "boolean", "description": "Reuse the previous review of this spec (default true)"},
+			})},
+		},
+		{
+			Name:        "get_issue",
+			Description: "Return one issue from the last check or preflight check of a spec, with its suggested patches.",
+			InputSchema: map[string]any{"type": "object", "properties": withProps(refProperties, map[string]any{
+				"id": map[string]any{"type": "string", "description": "Issue ID, such as ISSUE-0001 or PREFLIGHT-TODO-001"},
+			}), "required": []string{"id"}},
+		},
+		{
+			Name:        "list_questions",
+			Description: "Return the clarification questions from the last check of a spec.",
+			InputSchema: map[string]any{"type": "object", "properties": refProperties},
+		},
+		{
+			Name: "apply_patch",
+			Description: "Apply the suggested patches for the given issues. A spec file is updated in place; for a text spec, " +
+				"pass its current text and the patched text is returned. Fails without changes when a patch no longer matches exactly once.",
+			InputSchema: map[string]any{"type": "object", "properties": withProps(refProperties, map[string]any{
+				"text":      specProperties["text"],
+				"issue_ids": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Issues whose patches to apply"},
+			}), "required": []string{"issue_ids"}},
+		},
+	}
+}
+
+type toolContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content           []toolContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+// structuredResult returns v as both JSON text and structured content.
+func structuredResult(v any) (toolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return toolResult{}, err
+	}
+	return toolResult{Content: []toolContent{{Type: "text", Text: string(data)}}, StructuredContent: v}, nil
+}
+
+func errorResult(format string, args ...any) toolResult {
+	return toolResult{Content: []toolContent{{Type: "text", Text: fmt.Sprintf(format, args...)}}, IsError: true}
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil {
+		return nil, invalidParams("tools/call: %s", err)
+	}
+	if len(call.Arguments) == 0 {
+		call.Arguments = json.RawMessage("{}")
+	}
+	switch call.Name {
+	case "preflight_check":
+		var args specArgs
+		if err := decodeArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.preflightCheck(ctx, args)
+	case "check":
+		var args struct {
+			specArgs
+			Incremental *bool `json:"incremental"`
+		}
+		if err := decodeArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.check(ctx, args.specArgs, args.Incremental == nil || *args.Incremental)
+	case "get_issue":
+		var args struct {
+			specArgs
+			ID string `json:"id"`
+		}
+		if err := decodeArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if args.ID == "" {
+			return nil, invalidParams("id is required")
+		}
+		return s.getIssue(args.specArgs, args.ID)
+	case "list_questions":
+		var args specArgs
+		if err := decodeArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.listQuestions(args)
+	case "apply_patch":
+		var args struct {
+			specArgs
+			IssueIDs []string `json:"issue_ids"`
+		}
+		if err := decodeArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if len(args.IssueIDs) == 0 {
+			return nil, invalidParams("issue_ids is required")
+		}
+		return s.applyPatch(args.specArgs, args.IssueIDs)
+	default:
+		return nil, invalidParams("unknown tool %q", call.Name)
+	}
+}
+
+func decodeArgs(raw json.RawMessage, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return invalidParams("arguments: %s", err)
+	}
+	return nil
+}
+
+func (s *Server) preflightCheck(ctx context.Context, args specArgs) (any, error) {
+	if err := args.validateSource(); err != nil {
+		return nil, err
+	}
+	req := s.request(args)
+	req.PreflightMode = "only"
+	result, err := s.checker.Check(ctx, req)
+	if err != nil {
+		return errorResult("preflight check of %s failed: %s", args.label(), err), nil
+	}
+	s.mu.Lock()
+	s.state(args.key()).preflight = result.Report
+	s.mu.Unlock()
+	return structuredResult(result.Report)
+}
+
+func (s *Server) check(ctx context.Context, args specArgs, incrementalReview bool) (any, error) {
+	if err := args.validateSource(); err != nil {
+		return nil, err
+	}
+	req := s.request(args)
+	s.mu.Lock()
+	state := s.state(args.key())
+	if incrementalReview && state.reviewJSON != "" {
+		req.IncrementalFromText = state.reviewJSON
+		req.IncrementalBaseText = state.reviewedText
+	}
+	s.mu.Unlock()
+	result, err := s.checker.Check(ctx, req)
+	if err != nil {
+		return errorResult("check of %s failed: %s", args.label(), err), nil
+	}
+	data, err := json.Marshal(result.Report)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	state.review = result.Report
+	state.reviewJSON = string(data)
+	state.reviewedText = result.OriginalSpec
+	s.mu.Unlock()
+	return structuredResult(result.Report)
+}
+
+func (s *Server) getIssue(args specArgs, id string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.specs[args.key()]
+	if state == nil {
+		return errorResult("no check of %s has run; call check or preflight_check first", args.label()), nil
+	}
+	for _, report := range []*schema.Report{state.review, state.preflight} {
+		if report == nil {
+			continue
+		}
+		for _, issue := range report.Issues {
+			if issue.ID != id {
+				continue
+			}
+			patches := []schema.Patch{}
+			for _, p := range report.Patches {
+				if p.IssueID == id {
+					patches = append(patches, p)
+				}
+			}
+			return structuredResult(struct {
+				Issue   schema.Issue   `json:"issue"`
+				Patches []schema.Patch `json:"patches"`
+			}{issue, patches})
+		}
+	}
+	return errorResult("%s has no issue %s", args.label(), id), nil
+}
+
+func (s *Server) listQuestions(args specArgs) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.specs[args.key()]
+	if state == nil || state.review == nil {
+		return errorResult("no check of %s has run; call check first", args.label()), nil
+	}
+	questions := state.review.Questions
+	if questions == nil {
+		questions = []schema.Question{}
+	}
+	return structuredResult(struct {
+		Questions []schema.Question `json:"questions"`
+	}{questions})
+}
+
+type appliedPatch struct {
+	IssueID string `json:"issue_id"`
+	Line    int    `json:"line"`
+}
+
+func (s *Server) applyPatch(args specArgs, issueIDs []string) (any, error) {
+	if args.Path != "" && args.Text != "" {
+		return nil, invalidParams("path and text are mutually exclusive")
+	}
+	if args.Path == "" && args.Text == "" {
+		return nil, invalidParams("text is required for a text spec")
+	}
+	s.mu.Lock()
+	state := s.specs[args.key()]
+	var patches []schema.Patch
+	var missing []string
+	if state != nil {
+		patches, missing = selectPatches(state, issueIDs)
+	}
+	s.mu.Unlock()
+	if state == nil {
+		return errorResult("no check of %s has run; call check or preflight_check first", args.label()), nil
+	}
+	if len(missing) > 0 {
+		return errorResult("no patch for %s", strings.Join(missing, ", ")), nil
+	}
+
+	original := args.Text
+	if args.Path != "" {
+		data, err := os.ReadFile(args.Path)
+		if err != nil {
+			return errorResult("reading spec: %s", err), nil
+		}
+		original = string(data)
+	}
+	edits, err := patch.LocateUnique(original, patches)
+	if err != nil {
+		return errorResult("%s; run check again to refresh the patches", err), nil
+	}
+	patched, err := patch.Apply(original, edits)
+	if err != nil {
+		return errorResult("%s; apply one of them", err), nil
+	}
+	applied := make([]appliedPatch, len(edits))
+	for i, e := range edits {
+		applied[i] = appliedPatch{IssueID: e.Patch.IssueID, Line: strings.Count(original[:e.Start], "\n") + 1}
+	}
+	if args.Path == "" {
+		return structuredResult(struct {
+			Applied []appliedPatch `json:"applied"`
+			Text    string         `json:"text"`
+		}{applied, patched})
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(args.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(args.Path, []byte(patched), mode); err != nil {
+		return errorResult("writing spec: %s", err), nil
+	}
+	return structuredResult(struct {
+		Applied []appliedPatch `json:"applied"`
+		Path    string         `json:"path"`
+	}{applied, args.Path})
+}
+
+// selectPatches returns the patches for issueIDs from the last review and
+// preflight check, skipping redaction-affected patches, and the IDs that
+// have none.
+func selectPatches(state *specState, issueIDs []string) ([]schema.Patch, []string) {
+	want := make(map[string]bool, len(issueIDs))
+	for _, id := range issueIDs {
+		want[id] = true
+	}
+	found := make(map[string]bool)
+	seen := make(map[schema.Patch]bool)
+	var patches []schema.Patch
+	for _, report := range []*schema.Report{state.review, state.preflight} {
+		if report == nil {
+			continue
+		}
+		for _, p := range report.Patches {
+			if !want[p.IssueID] || seen[p] || patch.Redacted(p) {
+				continue
+			}
+			seen[p] = true
+			found[p.IssueID] = true
+			patches = append(patches, p)
+		}
+	}
+	var missing []string
+	for _, id := range issueIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return patches, missing
+}
+
+// state returns the remembered state for key, creating it. Callers hold
+// s.mu.
+func (s *Server) state(key string) *specState {
+	st := s.specs[key]
+	if st == nil {
+		st = &specState{}
+		s.specs[key] = st
+	}
+	return st
+}
+
+// request builds the check request for a spec from the shared defaults and
+// the server's configuration.
+func (s *Server) request(args specArgs) app.CheckRequest {
+	profile := s.config.Profile
+	if args.Profile != "" {
+		profile = args.Profile
+	}
+	strict := s.config.Strict
+	if args.Strict != nil {
+		strict = *args.Strict
+	}
+	req := app.DefaultRequest(app.SourceCLI)
+	req.Version = s.config.Version
+	req.ContextPaths = s.config.ContextPaths
+	req.Profile = profile
+	req.Strict = strict
+	req.LLMProvider = s.config.LLMProvider
+	req.LLMModel = s.config.LLMModel
+	req.Temperature = s.config.Temperature
+	req.MaxTokens = s.config.MaxTokens
+	req.PreflightProfile = s.config.PreflightProfile
+	req.CompletionSuggestions = s.config.CompletionSuggestions
+	req.ErrWriter = s.config.ErrWriter
+	if args.Path != "" {
+		req.SpecPath = args.Path
+	} else {
+		req.SpecName = args.name()
+		req.SpecText = args.Text
+	}
+	return req
+}
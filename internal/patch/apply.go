@@ -0,0 +1,204 @@
+package patch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/redact"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// redactedMarker is the placeholder redact.Redact substitutes for secrets.
+const redactedMarker = "[REDACTED]"
+
+// Edit is a patch located in the spec it applies to.
+type Edit struct {
+	// Index is the patch's position in the report's patch list.
+	Index int
+	Patch schema.Patch
+	// Start and End are the byte range of the patch's before text in the spec.
+	Start int
+	End   int
+}
+
+// Overlaps reports whether e and other replace overlapping text.
+func (e Edit) Overlaps(other Edit) bool {
+	return e.Start < other.End && other.Start < e.End
+}
+
+// ConflictError reports two selected patches that edit overlapping text.
+type ConflictError struct {
+	First  Edit
+	Second Edit
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("patches for %s and %s edit overlapping text", e.First.Patch.IssueID, e.Second.Patch.IssueID)
+}
+
+// Redacted reports whether p touches redacted or secret-looking text. Such
+// patches were written against the redacted spec and must not be applied to
+// the original.
+func Redacted(p schema.Patch) bool {
+	return strings.Contains(p.Before, redactedMarker) || strings.Contains(p.After, redactedMarker) ||
+		redact.ContainsSecret(p.Before) || redact.ContainsSecret(p.After)
+}
+
+// Locate finds each patch's before text in specRaw, using the same exact
+// then normalized matching as GenerateDiff. Patches that cannot be located
+// or that are redaction-affected are returned by index in skipped.
+func Locate(specRaw string, patches []schema.Patch) (edits []Edit, skipped []int) {
+	l := locator{raw: specRaw}
+	for i, p := range patches {
+		if Redacted(p) {
+			skipped = append(skipped, i)
+			continue
+		}
+		start, end, matches := l.find(p.Before)
+		if matches == 0 {
+			skipped = append(skipped, i)
+			continue
+		}
+		edits = append(edits, Edit{Index: i, Patch: p, Start: start, End: end})
+	}
+	return edits, skipped
+}
+
+// MatchError reports a patch whose before text does not occur exactly once
+// in the spec.
+type MatchError struct {
+	Patch   schema.Patch
+	Matches int
+}
+
+func (e *MatchError) Error() string {
+	if e.Matches == 0 {
+		return fmt.Sprintf("patch for %s no longer matches the spec", e.Patch.IssueID)
+	}
+	return fmt.Sprintf("patch for %s matches the spec %d times", e.Patch.IssueID, e.Matches)
+}
+
+// LocateUnique is like Locate but requires every patch's before text to
+// occur exactly once, returning a *MatchError for the first that does not.
+// Redaction-affected patches must be filtered out by the caller.
+func LocateUnique(specRaw string, patches []schema.Patch) ([]Edit, error) {
+	l := locator{raw: specRaw}
+	edits := make([]Edit, 0, len(patches))
+	for i, p := range patches {
+		start, end, matches := l.find(p.Before)
+		if matches != 1 {
+			return nil, &MatchError{Patch: p, Matches: matches}
+		}
+		edits = append(edits, Edit{Index: i, Patch: p, Start: start, End: end})
+	}
+	return edits, nil
+}
+
+// locator finds before texts in a spec, normalizing the spec lazily.
+type locator struct {
+	raw     string
+	norm    string
+	offsets []int
+}
+
+// find returns the byte range of the first match of before in the spec and
+// the number of matches. An exact match wins; otherwise both sides are
+// normalized and the match is mapped back to the raw spec.
+func (l *locator) find(before string) (start, end, matches int) {
+	if before == "" {
+		return 0, 0, 0
+	}
+	if start := strings.Index(l.raw, before); start >= 0 {
+		return start, start + len(before), strings.Count(l.raw, before)
+	}
+	if l.offsets == nil {
+		l.norm, l.offsets = normalizeOffsets(l.raw)
+	}
+	normBefore := normalize(before)
+	start = strings.Index(l.norm, normBefore)
+	if normBefore == "" || start < 0 {
+		return 0, 0, 0
+	}
+	end = start + len(normBefore)
+	return l.offsets[start], l.offsets[end-1] + 1, strings.Count(l.norm, normBefore)
+}
+
+// Apply replaces each edit's range in specRaw with its patch's after text,
+// converted to the line ending the spec uses at the edit. It returns a
+// *ConflictError if two edits overlap.
+func Apply(specRaw string, edits []Edit) (string, error) {
+	sorted := append([]Edit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var out strings.Builder
+	pos := 0
+	for i, e := range sorted {
+		if i > 0 && sorted[i-1].Overlaps(e) {
+			return "", &ConflictError{First: sorted[i-1], Second: e}
+		}
+		if e.Start < pos || e.End > len(specRaw) {
+			return "", fmt.Errorf("patch for %s is out of range", e.Patch.IssueID)
+		}
+		out.WriteString(specRaw[pos:e.Start])
+		out.WriteString(withLineEnding(e.Patch.After, lineEnding(specRaw, e.Start)))
+		pos = e.End
+	}
+	out.WriteString(specRaw[pos:])
+	return out.String(), nil
+}
+
+// lineEnding returns the line terminator of the line at offset, falling back
+// to the previous line's for a final line without one.
+func lineEnding(s string, offset int) string {
+	nl := strings.IndexByte(s[offset:], '\n')
+	if nl >= 0 {
+		nl += offset
+	} else {
+		nl = strings.LastIndexByte(s[:offset], '\n')
+	}
+	if nl > 0 && s[nl-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+func withLineEnding(text, eol string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if eol == "\n" {
+		return text
+	}
+	return strings.ReplaceAll(text, "\n", eol)
+}
+
+// normalizeOffsets normalizes s like normalize and maps each byte of the
+// result back to its offset in s.
+func normalizeOffsets(s string) (string, []int) {
+	var out strings.Builder
+	offsets := make([]int, 0, len(s))
+	lineStart := 0
+	for lineStart <= len(s) {
+		lineEnd := strings.IndexByte(s[lineStart:], '\n')
+		last := lineEnd < 0
+		if last {
+			lineEnd = len(s)
+		} else {
+			lineEnd += lineStart
+		}
+		content := s[lineStart:lineEnd]
+		if !last {
+			content = strings.TrimSuffix(content, "\r")
+		}
+		content = strings.TrimRight(content, " \t")
+		out.WriteString(content)
+		for i := 0; i < len(content); i++ {
+			offsets = append(offsets, lineStart+i)
+		}
+		if last {
+			break
+		}
+		out.WriteByte('\n')
+		offsets = append(offsets, lineEnd)
+		lineStart = lineEnd + 1
+	}
+	return out.String(), offsets
+}
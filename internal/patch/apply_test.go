@@ -0,0 +1,95 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestLocateAndApply(t *testing.T) {
+	spec := "# Spec\r\nLatency must be fast.  \r\nRetries are unlimited.\r\n"
+	patches := []schema.Patch{
+		{IssueID: "ISSUE-0001", Before: "Latency must be fast.\nRetries", After: "Latency p99 must be under 200 ms.\nRetries"},
+		{IssueID: "ISSUE-0002", Before: "unlimited", After: "limited to 3"},
+		{IssueID: "ISSUE-0003", Before: "not in the spec", After: "x"},
+		{IssueID: "ISSUE-0004", Before: "# Spec", After: "token [REDACTED]"},
+	}
+	edits, skipped := Locate(spec, patches)
+	if len(edits) != 2 || len(skipped) != 2 || skipped[0] != 2 || skipped[1] != 3 {
+		t.Fatalf("edits = %+v, skipped = %v", edits, skipped)
+	}
+	got, err := Apply(spec, edits)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := "# Spec\r\nLatency p99 must be under 200 ms.\r\nRetries are limited to 3.\r\n"
+	if got != want {
+		t.Fatalf("Apply = %q, want %q", got, want)
+	}
+}
+
+func TestApplyRejectsOverlappingEdits(t *testing.T) {
+	spec := "The system must be fast and reliable.\n"
+	edits, _ := Locate(spec, []schema.Patch{
+		{IssueID: "ISSUE-0001", Before: "must be fast", After: "must respond within 200 ms"},
+		{IssueID: "ISSUE-0002", Before: "fast and reliable", After: "available 99.9% of the time"},
+	})
+	if len(edits) != 2 || !edits[0].Overlaps(edits[1]) {
+		t.Fatalf("edits = %+v", edits)
+	}
+	_, err := Apply(spec, edits)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.First.Patch.IssueID != "ISSUE-0001" || conflict.Second.Patch.IssueID != "ISSUE-0002" {
+		t.Fatalf("err = %v, want ConflictError", err)
+	}
+}
+
+func TestParseDiffRoundTrip(t *testing.T) {
+	spec := "# Spec\n\nThe system must be fast.\n\nRetries are unlimited.\n"
+	patches := []schema.Patch{
+		{IssueID: "ISSUE-0001", Before: "The system must be fast.", After: "The system must respond within 250ms p95."},
+		{IssueID: "ISSUE-0002", Before: "Retries are unlimited.", After: "Retries are limited to 3 attempts."},
+	}
+	issues := []schema.Issue{{ID: "ISSUE-0002", Tags: []string{"completion-suggested"}}}
+	entries, err := ParseDiff(GenerateDiffWithIssues(spec, patches, issues, nil))
+	if err != nil {
+		t.Fatalf("ParseDiff: %v", err)
+	}
+	if len(entries) < 2 || entries[0].Patch.IssueID != "ISSUE-0001" || entries[0].Completion || !entries[len(entries)-1].Completion {
+		t.Fatalf("entries = %+v", entries)
+	}
+	parsed := make([]schema.Patch, len(entries))
+	for i, e := range entries {
+		parsed[i] = e.Patch
+	}
+	edits, err := LocateUnique(spec, parsed)
+	if err != nil {
+		t.Fatalf("LocateUnique: %v", err)
+	}
+	got, err := Apply(spec, edits)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := "# Spec\n\nThe system must respond within 250ms p95.\n\nRetries are limited to 3 attempts.\n"
+	if got != want {
+		t.Fatalf("Apply = %q, want %q", got, want)
+	}
+}
+
+func TestParseDiffRejectsHunkWithoutHeader(t *testing.T) {
+	if _, err := ParseDiff("@@ -1,4 +1,4 @@\n-fast\n+slow\n"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestLocateUniqueRejectsAmbiguousAndMissing(t *testing.T) {
+	spec := "must be fast\nmust be fast\n"
+	var match *MatchError
+	if _, err := LocateUnique(spec, []schema.Patch{{IssueID: "ISSUE-0001", Before: "must be fast", After: "x"}}); !errors.As(err, &match) || match.Matches != 2 {
+		t.Fatalf("ambiguous err = %v", err)
+	}
+	if _, err := LocateUnique(spec, []schema.Patch{{IssueID: "ISSUE-0002", Before: "gone", After: "x"}}); !errors.As(err, &match) || match.Matches != 0 {
+		t.Fatalf("missing err = %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package patch
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// DiffEntry is one hunk read back from a GenerateDiff file.
+type DiffEntry struct {
+	Patch      schema.Patch
+	Completion bool
+}
+
+// ParseDiff reads a patch file written by GenerateDiff or the check command's
+// --patch-out. Hunk positions in the file are relative to each patch's
+// before text, not the spec, so every hunk becomes its own patch whose
+// before and after texts are the hunk's context plus its removed or added
+// text.
+func ParseDiff(text string) ([]DiffEntry, error) {
+	var entries []DiffEntry
+	var path, issueID string
+	var completion bool
+	var hunk *DiffEntry
+	flush := func() {
+		if hunk != nil {
+			entries = append(entries, *hunk)
+			hunk = nil
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if header, ok := strings.CutPrefix(line, "# "); ok {
+			flush()
+			switch {
+			case strings.HasPrefix(header, "file "):
+				path, issueID = strings.TrimPrefix(header, "file "), ""
+			case strings.HasPrefix(header, "completion patch for "):
+				issueID, completion = strings.TrimPrefix(header, "completion patch for "), true
+			case strings.HasPrefix(header, "patch for "):
+				issueID, completion = strings.TrimPrefix(header, "patch for "), false
+			default:
+				return nil, fmt.Errorf("patch file: unexpected header %q", line)
+			}
+			continue
+		}
+		if hunkHeader.MatchString(line) {
+			if issueID == "" {
+				return nil, fmt.Errorf("patch file: hunk without a \"# patch for\" header")
+			}
+			flush()
+			hunk = &DiffEntry{Patch: schema.Patch{IssueID: issueID, Path: path}, Completion: completion}
+			continue
+		}
+		if hunk == nil {
+			if strings.HasPrefix(line, "--- ") {
+				return nil, fmt.Errorf("patch file: unified diffs are not supported; apply them with git apply")
+			}
+			return nil, fmt.Errorf("patch file: unexpected line %q", line)
+		}
+		// Hunk lines are URL-escaped like diffmatchpatch.PatchToText writes
+		// them; a literal plus sign stays a plus sign.
+		content, err := url.QueryUnescape(strings.ReplaceAll(line[1:], "+", "%2B"))
+		if err != nil {
+			return nil, fmt.Errorf("patch file: patch for %s: %w", issueID, err)
+		}
+		switch line[0] {
+		case ' ':
+			hunk.Patch.Before += content
+			hunk.Patch.After += content
+		case '-':
+			hunk.Patch.Before += content
+		case '+':
+			hunk.Patch.After += content
+		default:
+			return nil, fmt.Errorf("patch file: patch for %s: invalid line %q", issueID, line)
+		}
+	}
+	flush()
+	return entries, nil
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(,\d+)? \+\d+(,\d+)? @@$`)
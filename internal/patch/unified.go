@@ -0,0 +1,273 @@
+package patch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Patch file formats.
+const (
+	// FormatDMP is the diff-match-patch text written by GenerateDiff.
+	FormatDMP = "dmp"
+	// FormatUnified is a unified diff written by GenerateUnified.
+	FormatUnified = "unified"
+)
+
+// unifiedContext is the number of unchanged lines around each hunk.
+const unifiedContext = 3
+
+// GenerateUnified applies patches to specRaw and writes the result as a
+// unified diff of path that git apply and patch accept. Hunk headers count
+// lines of the original file, and lines keep their original endings.
+// Patches whose before text does not occur exactly once, that touch
+// redacted text, or that overlap another patch are skipped with a warning
+// written to w (may be nil).
+func GenerateUnified(path, specRaw string, patches []schema.Patch, w io.Writer) string {
+	if len(patches) == 0 {
+		return ""
+	}
+	l := locator{raw: specRaw}
+	var edits []Edit
+	for i, p := range patches {
+		if Redacted(p) {
+			warnf(w, "WARN: patch for %s touches redacted text; skipped\n", p.IssueID)
+			continue
+		}
+		start, end, matches := l.find(p.Before)
+		if matches != 1 {
+			warnf(w, "WARN: %s; skipped\n", &MatchError{Patch: p, Matches: matches})
+			continue
+		}
+		edits = append(edits, Edit{Index: i, Patch: p, Start: start, End: end})
+	}
+	edits = dropOverlaps(edits, w)
+	if len(edits) == 0 {
+		return ""
+	}
+	patched, err := Apply(specRaw, edits)
+	if err != nil {
+		warnf(w, "WARN: %s; skipped\n", err)
+		return ""
+	}
+	return unifiedDiff(path, specRaw, patched)
+}
+
+// dropOverlaps removes every edit that overlaps another, warning about each
+// overlapping pair, so neither patch is silently merged into the other.
+func dropOverlaps(edits []Edit, w io.Writer) []Edit {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+	conflicted := make([]bool, len(edits))
+	for i := range edits {
+		for k := i + 1; k < len(edits) && edits[k].Start < edits[i].End; k++ {
+			warnf(w, "WARN: %s; both skipped\n", &ConflictError{First: edits[i], Second: edits[k]})
+			conflicted[i], conflicted[k] = true, true
+		}
+	}
+	kept := edits[:0]
+	for i, e := range edits {
+		if !conflicted[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func warnf(w io.Writer, format string, args ...any) {
+	if w != nil {
+		_, _ = fmt.Fprintf(w, format, args...)
+	}
+}
+
+type lineOp struct {
+	op   diffmatchpatch.Operation
+	line string
+}
+
+// unifiedDiff returns a unified diff from before to after, or "" when they
+// are equal.
+func unifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diffLines(splitLinesKeepEnds(before), splitLinesKeepEnds(after))
+
+	// Each hunk covers a run of changes plus context; runs separated by at
+	// most twice the context share a hunk.
+	var out strings.Builder
+	name := unifiedPath(path)
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].op == diffmatchpatch.DiffEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+		start := i
+		for start > 0 && i-start < unifiedContext && ops[start-1].op == diffmatchpatch.DiffEqual {
+			start--
+		}
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+		end := i
+		for end < len(ops) {
+			if ops[end].op != diffmatchpatch.DiffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].op == diffmatchpatch.DiffEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*unifiedContext {
+				end += min(run-end, unifiedContext)
+				break
+			}
+			end = run
+		}
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			switch op.op {
+			case diffmatchpatch.DiffEqual:
+				body.WriteByte(' ')
+				oldCount++
+				newCount++
+			case diffmatchpatch.DiffDelete:
+				body.WriteByte('-')
+				oldCount++
+			case diffmatchpatch.DiffInsert:
+				body.WriteByte('+')
+				newCount++
+			}
+			body.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount))
+		out.WriteString(body.String())
+		for _, op := range ops[i:end] {
+			if op.op != diffmatchpatch.DiffInsert {
+				oldLine++
+			}
+			if op.op != diffmatchpatch.DiffDelete {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats one side of a hunk header. An empty range names the line
+// before it, as diff does.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d,%d", start, count)
+	}
+}
+
+// diffLines diffs two line lists by mapping each distinct line to one rune.
+func diffLines(before, after []string) []lineOp {
+	codes := make(map[string]rune)
+	encode := func(lines []string) []rune {
+		out := make([]rune, len(lines))
+		for i, line := range lines {
+			code, ok := codes[line]
+			if !ok {
+				code = rune(len(codes) + 1)
+				if code >= 0xD800 {
+					code += 0x800
+				}
+				codes[line] = code
+			}
+			out[i] = code
+		}
+		return out
+	}
+	a, b := encode(before), encode(after)
+	var ops []lineOp
+	ai, bi := 0, 0
+	for _, d := range diffmatchpatch.New().DiffMainRunes(a, b, false) {
+		for range []rune(d.Text) {
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				ops = append(ops, lineOp{op: d.Type, line: before[ai]})
+				ai++
+				bi++
+			case diffmatchpatch.DiffDelete:
+				ops = append(ops, lineOp{op: d.Type, line: before[ai]})
+				ai++
+			case diffmatchpatch.DiffInsert:
+				ops = append(ops, lineOp{op: d.Type, line: after[bi]})
+				bi++
+			}
+		}
+	}
+	return ops
+}
+
+// splitLinesKeepEnds splits s after each "\n", keeping line endings.
+func splitLinesKeepEnds(s string) []string {
+	var lines []string
+	for s != "" {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			lines = append(lines, s)
+			break
+		}
+		lines = append(lines, s[:i+1])
+		s = s[i+1:]
+	}
+	return lines
+}
+
+// unifiedPath formats path for the a/ and b/ diff headers. Relative paths
+// are kept as given. git apply rejects absolute paths, so they are made
+// relative to the working directory, falling back to the file's base name
+// when the file lies outside it.
+func unifiedPath(path string) string {
+	if path == "" {
+		return "spec.md"
+	}
+	if filepath.IsAbs(path) {
+		rel, ok := relativeToWorkingDir(path)
+		if !ok {
+			rel = filepath.Base(path)
+		}
+		path = rel
+	}
+	path = filepath.Clean(path)
+	path = filepath.ToSlash(path)
+	if path == "." || path == "/" || path == ".." {
+		return "spec.md"
+	}
+	return path
+}
+
+// relativeToWorkingDir returns path relative to the working directory when
+// the path lies inside it.
+func relativeToWorkingDir(path string) (string, bool) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
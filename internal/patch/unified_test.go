@@ -0,0 +1,75 @@
+package patch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestGenerateUnified(t *testing.T) {
+	spec := "# Spec\n\n1\n2\n3\n4\nLatency must be fast.\n5\n6\n7\n8\n9\n10\n11\nRetries are unlimited."
+	patches := []schema.Patch{
+		{IssueID: "ISSUE-0001", Before: "Latency must be fast.", After: "Latency p99 must be under 200 ms.\nBursts are excluded."},
+		{IssueID: "ISSUE-0002", Before: "unlimited", After: "limited to 3"},
+	}
+	want := "--- a/docs/SPEC.md\n+++ b/docs/SPEC.md\n" +
+		"@@ -4,7 +4,8 @@\n 2\n 3\n 4\n-Latency must be fast.\n+Latency p99 must be under 200 ms.\n+Bursts are excluded.\n 5\n 6\n 7\n" +
+		"@@ -12,4 +13,4 @@\n 9\n 10\n 11\n-Retries are unlimited.\n\\ No newline at end of file\n+Retries are limited to 3.\n\\ No newline at end of file\n"
+	if got := GenerateUnified("./docs/SPEC.md", spec, patches, nil); got != want {
+		t.Fatalf("GenerateUnified =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateUnifiedKeepsCRLF(t *testing.T) {
+	spec := "# Spec\r\nLatency must be fast.\r\n"
+	got := GenerateUnified("SPEC.md", spec, []schema.Patch{{IssueID: "ISSUE-0001", Before: "Latency must be fast.", After: "Latency p99\nmust be under 200 ms."}}, nil)
+	want := "--- a/SPEC.md\n+++ b/SPEC.md\n@@ -1,2 +1,3 @@\n # Spec\r\n-Latency must be fast.\r\n+Latency p99\r\n+must be under 200 ms.\r\n"
+	if got != want {
+		t.Fatalf("GenerateUnified = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateUnifiedReportsSkippedPatches(t *testing.T) {
+	spec := "The system must be fast and reliable.\nThe system must be fast and reliable.\nLogs are kept.\n"
+	var warn strings.Builder
+	got := GenerateUnified("SPEC.md", "# Spec\nA must be fast and reliable.\nLogs are kept.\n", []schema.Patch{
+		{IssueID: "ISSUE-0001", Before: "must be fast", After: "must respond within 200 ms"},
+		{IssueID: "ISSUE-0002", Before: "fast and reliable", After: "available"},
+		{IssueID: "ISSUE-0003", Before: "Logs are kept.", After: "Logs are kept for 30 days."},
+	}, &warn)
+	if !strings.Contains(got, "+Logs are kept for 30 days.\n") || strings.Contains(got, "200 ms") || strings.Contains(got, "available") {
+		t.Fatalf("diff = %s", got)
+	}
+	if !strings.Contains(warn.String(), "patches for ISSUE-0001 and ISSUE-0002 edit overlapping text; both skipped") {
+		t.Fatalf("warnings = %q", warn.String())
+	}
+
+	warn.Reset()
+	if got := GenerateUnified("SPEC.md", spec, []schema.Patch{{IssueID: "ISSUE-0004", Before: "must be fast", After: "x"}}, &warn); got != "" {
+		t.Fatalf("ambiguous patch produced diff: %s", got)
+	}
+	if !strings.Contains(warn.String(), "patch for ISSUE-0004 matches the spec 2 times; skipped") {
+		t.Fatalf("warnings = %q", warn.String())
+	}
+}
+
+func TestUnifiedPathIsRelative(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		"./docs/SPEC.md":                         "docs/SPEC.md",
+		filepath.Join(wd, "docs", "SPEC.md"):     "docs/SPEC.md",
+		filepath.Join(os.TempDir(), "x/SPEC.md"): "SPEC.md",
+		"../other/SPEC.md":                       "../other/SPEC.md",
+		"":                                       "spec.md",
+	} {
+		if got := unifiedPath(path); got != want {
+			t.Fatalf("unifiedPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package preflight
+
+import "github.com/dshills/speccritic/internal/chunk"
+
+// analysisKey names one per-document analysis.
+type analysisKey int
+
+const (
+	analysisFenced analysisKey = iota
+	analysisHeadings
+	analysisAllSentences
+	analysisNormative
+	analysisAllNormative
+	analysisRequirements
+	analysisProse
+	analysisLinks
+	analysisSentences
+	analysisQuantities
+	analysisTables
+	analysisSections
+	analysisDuplicates
+)
+
+// analyses holds the per-document analyses of one run. Many rules read the
+// same sentences, keywords, requirement IDs or quantities, so each analysis
+// is computed on first use and shared by the rules after it.
+type analyses map[analysisKey]any
+
+// shared returns the analysis stored under key, computing it on first use.
+// A Document without analyses computes it on every call.
+func shared[T any](doc Document, key analysisKey, compute func() T) T {
+	if doc.analyses == nil {
+		return compute()
+	}
+	if v, ok := doc.analyses[key]; ok {
+		return v.(T)
+	}
+	v := compute()
+	doc.analyses[key] = v
+	return v
+}
+
+func (d Document) fenced() []bool {
+	return shared(d, analysisFenced, func() []bool { return fencedLines(d.Lines) })
+}
+
+func (d Document) headings() []chunk.Heading {
+	return shared(d, analysisHeadings, func() []chunk.Heading { return headings(d.Lines, d.fenced()) })
+}
+
+func (d Document) allSentences() []sentence {
+	return shared(d, analysisAllSentences, func() []sentence { return proseSentences(d.Lines, false) })
+}
+
+// normative analyzes the sentences outside example sections; allNormative
+// includes them.
+func (d Document) normative() normativeAnalysis {
+	return shared(d, analysisNormative, func() normativeAnalysis {
+		all := d.allNormative()
+		out := normativeAnalysis{Declared: all.Declared, Declarations: all.Declarations}
+		for _, s := range all.Sentences {
+			if !s.Example {
+				out.Sentences = append(out.Sentences, s)
+			}
+		}
+		return out
+	})
+}
+
+func (d Document) allNormative() normativeAnalysis {
+	return shared(d, analysisAllNormative, func() normativeAnalysis { return analyzeNormative(d.allSentences()) })
+}
+
+// requirements analyzes requirement IDs. Every rule in a run gets the same
+// Config, so the analysis is shared.
+func (d Document) requirements(cfg Config) requirementAnalysis {
+	return shared(d, analysisRequirements, func() requirementAnalysis { return analyzeRequirements(d.Lines, cfg) })
+}
+
+func (d Document) prose() []string {
+	return shared(d, analysisProse, func() []string { return proseLines(d.Lines) })
+}
+
+func (d Document) links() []docLink {
+	return shared(d, analysisLinks, func() []docLink { return documentLinks(d.prose()) })
+}
+
+// sentences returns the prose sentences outside example sections;
+// allSentences includes them.
+func (d Document) sentences() []sentence {
+	return shared(d, analysisSentences, func() []sentence {
+		var out []sentence
+		for _, s := range d.allSentences() {
+			if !s.Example {
+				out = append(out, s)
+			}
+		}
+		return out
+	})
+}
+
+func (d Document) quantityGroups() [][]quantity {
+	return shared(d, analysisQuantities, func() [][]quantity { return quantityGroups(d.sentences()) })
+}
+
+func (d Document) tables() []markdownTable {
+	return shared(d, analysisTables, func() []markdownTable { return markdownTables(d.Lines, true) })
+}
+
+func (d Document) sections() []int {
+	return shared(d, analysisSections, func() []int { return lineSections(d.Lines) })
+}
+
+func (d Document) duplicates() []duplicatePair {
+	return shared(d, analysisDuplicates, func() []duplicatePair { return nearDuplicates(d.sentences(), d.sections()) })
+}
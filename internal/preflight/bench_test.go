@@ -49,3 +49,18 @@ func syntheticLargeSpec(lines int) string {
 	b.WriteString("- A benchmark request receives status 200 within 100 ms.\n")
 	return b.String()
 }
+
+func BenchmarkNearDuplicatesFiveThousandLineSpec(b *testing.B) {
+	var lines []string
+	for section := 0; len(lines) < 5000; section++ {
+		lines = append(lines, fmt.Sprintf("## Area %d", section))
+		for i := 0; i < 9; i++ {
+			lines = append(lines, fmt.Sprintf("The gateway MUST forward each signed request to the billing backend within %d ms.", section*10+i))
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nearDuplicates(proseSentences(lines, true), lineSections(lines))
+	}
+}
@@ -0,0 +1,488 @@
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	ctxpkg "github.com/dshills/speccritic/internal/context"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+// maxSchemaDepth bounds $ref recursion while validating.
+const maxSchemaDepth = 32
+
+// The validator covers the JSON Schema keywords API specs rely on: $ref
+// within the same document, type, nullable, enum, const, required,
+// properties, additionalProperties, items, allOf, anyOf, oneOf and the
+// length, range and pattern limits. Unknown keywords and external
+// references are ignored.
+
+// schemaRef is a schema with the document its references resolve in.
+type schemaRef struct {
+	Name   string
+	Schema any
+	Root   any
+}
+
+// apiOperation is one OpenAPI operation with its request and response body
+// schemas.
+type apiOperation struct {
+	Method    string
+	Path      *regexp.Regexp
+	Request   *schemaRef
+	Responses map[string]*schemaRef
+}
+
+// schemaCatalog holds the schemas and operations found in context files.
+type schemaCatalog struct {
+	Named      map[string]schemaRef
+	Operations []apiOperation
+}
+
+// loadSchemaCatalog reads JSON Schema and OpenAPI documents from the
+// context files. Files that are not JSON or YAML, or do not parse, are
+// skipped.
+func loadSchemaCatalog(files []ctxpkg.ContextFile) schemaCatalog {
+	catalog := schemaCatalog{Named: make(map[string]schemaRef)}
+	for _, file := range files {
+		var doc any
+		switch strings.ToLower(path.Ext(file.Path)) {
+		case ".json":
+			if err := json.Unmarshal([]byte(file.Content), &doc); err != nil {
+				continue
+			}
+		case ".yaml", ".yml":
+			value, _, err := parseYAML(spec.Lines(file.Content))
+			if err != nil {
+				continue
+			}
+			doc = value
+		default:
+			continue
+		}
+		root, ok := doc.(map[string]any)
+		if !ok {
+			continue
+		}
+		if root["openapi"] != nil || root["swagger"] != nil {
+			catalog.addOpenAPI(root)
+			continue
+		}
+		if !isJSONSchema(root) {
+			continue
+		}
+		name := strings.TrimSuffix(path.Base(file.Path), path.Ext(file.Path))
+		catalog.add(strings.TrimSuffix(name, ".schema"), root, root)
+		if title, ok := root["title"].(string); ok {
+			catalog.add(title, root, root)
+		}
+		for _, key := range []string{"$defs", "definitions"} {
+			defs, _ := root[key].(map[string]any)
+			for defName, def := range defs {
+				catalog.add(defName, def, root)
+			}
+		}
+	}
+	return catalog
+}
+
+func isJSONSchema(root map[string]any) bool {
+	for _, key := range []string{"$schema", "type", "properties", "$defs", "definitions"} {
+		if root[key] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *schemaCatalog) add(name string, schema, root any) {
+	if name != "" {
+		c.Named[name] = schemaRef{Name: name, Schema: schema, Root: root}
+	}
+}
+
+func (c *schemaCatalog) addOpenAPI(root map[string]any) {
+	components, _ := root["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	if definitions, ok := root["definitions"].(map[string]any); ok {
+		schemas = definitions
+	}
+	for name, schema := range schemas {
+		c.add(name, schema, root)
+	}
+	paths, _ := root["paths"].(map[string]any)
+	templates := make([]string, 0, len(paths))
+	for template := range paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	for _, template := range templates {
+		item, _ := paths[template].(map[string]any)
+		for method, raw := range item {
+			op, ok := resolveRef(raw, root).(map[string]any)
+			if !ok || !httpMethods[strings.ToUpper(method)] {
+				continue
+			}
+			operation := apiOperation{Method: strings.ToUpper(method), Path: pathTemplateRe(template), Responses: make(map[string]*schemaRef)}
+			name := strings.ToUpper(method) + " " + template
+			if body, ok := resolveRef(op["requestBody"], root).(map[string]any); ok {
+				operation.Request = jsonContentSchema(body, root, name+" request")
+			}
+			params, _ := op["parameters"].([]any)
+			for _, param := range params {
+				if p, ok := resolveRef(param, root).(map[string]any); ok && p["in"] == "body" && p["schema"] != nil {
+					operation.Request = &schemaRef{Name: name + " request", Schema: p["schema"], Root: root}
+				}
+			}
+			responses, _ := op["responses"].(map[string]any)
+			for code, raw := range responses {
+				response, ok := resolveRef(raw, root).(map[string]any)
+				if !ok {
+					continue
+				}
+				if ref := jsonContentSchema(response, root, name+" "+code+" response"); ref != nil {
+					operation.Responses[code] = ref
+				} else if response["schema"] != nil {
+					operation.Responses[code] = &schemaRef{Name: name + " " + code + " response", Schema: response["schema"], Root: root}
+				}
+			}
+			c.Operations = append(c.Operations, operation)
+		}
+	}
+}
+
+// jsonContentSchema returns the schema of an OpenAPI body's JSON media type.
+func jsonContentSchema(body map[string]any, root any, name string) *schemaRef {
+	content, _ := body["content"].(map[string]any)
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	for _, mediaType := range types {
+		media, _ := content[mediaType].(map[string]any)
+		if strings.Contains(mediaType, "json") && media["schema"] != nil {
+			return &schemaRef{Name: name, Schema: media["schema"], Root: root}
+		}
+	}
+	return nil
+}
+
+var pathParamRe = regexp.MustCompile(`\\\{[^/]+?\\\}`)
+
+// pathTemplateRe matches request paths against an OpenAPI path template,
+// allowing a base path such as /v1 before it.
+func pathTemplateRe(template string) *regexp.Regexp {
+	pattern := pathParamRe.ReplaceAllString(regexp.QuoteMeta(strings.TrimSuffix(template, "/")), `[^/]+`)
+	return regexp.MustCompile(`^(?:/[^/]+)*?` + pattern + `/?$`)
+}
+
+// operation returns the catalog operation for a method and request path.
+func (c schemaCatalog) operation(method, target string) *apiOperation {
+	for i, op := range c.Operations {
+		if op.Method == method && op.Path.MatchString(target) {
+			return &c.Operations[i]
+		}
+	}
+	return nil
+}
+
+// response returns the schema for a status code, falling back to 2XX style
+// ranges and the default response.
+func (op *apiOperation) response(code string) *schemaRef {
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if ref := op.Responses[key]; ref != nil {
+			return ref
+		}
+	}
+	return nil
+}
+
+// resolveRef follows a local $ref to the schema it points at.
+func resolveRef(schema, root any) any {
+	for i := 0; i < maxSchemaDepth; i++ {
+		m, ok := schema.(map[string]any)
+		if !ok {
+			return schema
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return schema
+		}
+		schema = jsonPointer(root, strings.TrimPrefix(ref, "#"))
+	}
+	return nil
+}
+
+func jsonPointer(root any, pointer string) any {
+	node := root
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	return node
+}
+
+// schemaViolation is one place where a value breaks its schema. Path is a
+// JSON pointer into the value.
+type schemaViolation struct {
+	Path    string
+	Message string
+}
+
+func validateSchema(value any, ref schemaRef) []schemaViolation {
+	return validateNode(value, ref.Schema, ref.Root, "", 0)
+}
+
+func validateNode(value, schema, root any, at string, depth int) []schemaViolation {
+	if depth > maxSchemaDepth {
+		return nil
+	}
+	schema = resolveRef(schema, root)
+	if allowed, ok := schema.(bool); ok {
+		if !allowed {
+			return []schemaViolation{{at, "is not allowed"}}
+		}
+		return nil
+	}
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if value == nil && s["nullable"] == true {
+		return nil
+	}
+	var out []schemaViolation
+	fail := func(format string, args ...any) {
+		out = append(out, schemaViolation{at, fmt.Sprintf(format, args...)})
+	}
+	if types := schemaTypes(s["type"]); len(types) > 0 && !matchesType(value, types) {
+		fail("is %s, want %s", jsonTypeName(value), strings.Join(types, " or "))
+		return out
+	}
+	if enum, ok := s["enum"].([]any); ok && !containsValue(enum, value) {
+		fail("is %s, want one of %s", formatValue(value), formatValues(enum))
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		fail("is %s, want %s", formatValue(value), formatValue(c))
+	}
+	for _, sub := range asSlice(s["allOf"]) {
+		out = append(out, validateNode(value, sub, root, at, depth+1)...)
+	}
+	if anyOf := asSlice(s["anyOf"]); len(anyOf) > 0 && countMatches(value, anyOf, root, at, depth) == 0 {
+		fail("matches none of the anyOf schemas")
+	}
+	if oneOf := asSlice(s["oneOf"]); len(oneOf) > 0 {
+		if n := countMatches(value, oneOf, root, at, depth); n != 1 {
+			fail("matches %d of the oneOf schemas, want exactly 1", n)
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		n := float64(utf8.RuneCountInString(v))
+		if limit, ok := s["minLength"].(float64); ok && n < limit {
+			fail("is shorter than %g characters", limit)
+		}
+		if limit, ok := s["maxLength"].(float64); ok && n > limit {
+			fail("is longer than %g characters", limit)
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("does not match pattern %s", pattern)
+			}
+		}
+	case float64:
+		out = append(out, validateRange(v, s, at)...)
+	case []any:
+		if limit, ok := s["minItems"].(float64); ok && float64(len(v)) < limit {
+			fail("has %d items, want at least %g", len(v), limit)
+		}
+		if limit, ok := s["maxItems"].(float64); ok && float64(len(v)) > limit {
+			fail("has %d items, want at most %g", len(v), limit)
+		}
+		for i, item := range v {
+			itemSchema := s["items"]
+			if tuple, ok := itemSchema.([]any); ok {
+				if i >= len(tuple) {
+					break
+				}
+				itemSchema = tuple[i]
+			}
+			if itemSchema != nil {
+				out = append(out, validateNode(item, itemSchema, root, fmt.Sprintf("%s/%d", at, i), depth+1)...)
+			}
+		}
+	case map[string]any:
+		for _, name := range asSlice(s["required"]) {
+			if key, ok := name.(string); ok {
+				if _, present := v[key]; !present {
+					fail("is missing required property %q", key)
+				}
+			}
+		}
+		props, _ := s["properties"].(map[string]any)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := at + "/" + escapePointer(key)
+			if prop, ok := props[key]; ok {
+				out = append(out, validateNode(v[key], prop, root, child, depth+1)...)
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					out = append(out, schemaViolation{child, "is not a defined property"})
+				}
+			case map[string]any:
+				out = append(out, validateNode(v[key], extra, root, child, depth+1)...)
+			}
+		}
+	}
+	return out
+}
+
+func validateRange(v float64, s map[string]any, at string) []schemaViolation {
+	var out []schemaViolation
+	exclusiveMin, _ := s["exclusiveMinimum"].(bool)
+	exclusiveMax, _ := s["exclusiveMaximum"].(bool)
+	if limit, ok := s["minimum"].(float64); ok && (v < limit || exclusiveMin && v == limit) {
+		out = append(out, schemaViolation{at, fmt.Sprintf("is %g, below the minimum %g", v, limit)})
+	}
+	if limit, ok := s["maximum"].(float64); ok && (v > limit || exclusiveMax && v == limit) {
+		out = append(out, schemaViolation{at, fmt.Sprintf("is %g, above the maximum %g", v, limit)})
+	}
+	if limit, ok := s["exclusiveMinimum"].(float64); ok && v <= limit {
+		out = append(out, schemaViolation{at, fmt.Sprintf("is %g, want more than %g", v, limit)})
+	}
+	if limit, ok := s["exclusiveMaximum"].(float64); ok && v >= limit {
+		out = append(out, schemaViolation{at, fmt.Sprintf("is %g, want less than %g", v, limit)})
+	}
+	return out
+}
+
+func countMatches(value any, schemas []any, root any, at string, depth int) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(validateNode(value, sub, root, at, depth+1)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func schemaTypes(raw any) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var out []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func matchesType(value any, types []string) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case float64:
+			if t == "number" || t == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case string:
+		return "a string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "an integer"
+		}
+		return "a number"
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func asSlice(raw any) []any {
+	s, _ := raw.([]any)
+	return s
+}
+
+func formatValue(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatValue(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,321 @@
+package preflight
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/chunk"
+)
+
+// fencedLines reports which lines belong to fenced code blocks, including
+// the fence lines themselves. An unclosed fence runs to the end of the
+// document, as in CommonMark.
+func fencedLines(lines []string) []bool {
+	fenced := make([]bool, len(lines))
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if marker := fenceMarker(trimmed); marker != "" && leadingIndent(line) < 4 {
+				fence = marker
+				fenced[i] = true
+			}
+			continue
+		}
+		fenced[i] = true
+		if marker := fenceMarker(trimmed); marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) && strings.TrimLeft(trimmed, marker[:1]) == "" {
+			fence = ""
+		}
+	}
+	return fenced
+}
+
+// fencedBlock is one fenced code block. Start is the 1-based line of the
+// opening fence, so content line i is spec line Start+1+i.
+type fencedBlock struct {
+	Start int
+	Info  string
+	Lang  string
+	Lines []string
+}
+
+// fencedBlocks returns the document's fenced code blocks with their info
+// strings. An unclosed fence runs to the end of the document.
+func fencedBlocks(lines []string) []fencedBlock {
+	fenced := fencedLines(lines)
+	var blocks []fencedBlock
+	for i := 0; i < len(lines); i++ {
+		if !fenced[i] {
+			continue
+		}
+		trimmed := strings.TrimSpace(lines[i])
+		marker := fenceMarker(trimmed)
+		info := strings.TrimSpace(strings.TrimLeft(trimmed, marker[:1]))
+		block := fencedBlock{Start: i + 1, Info: info}
+		if fields := strings.Fields(info); len(fields) > 0 {
+			block.Lang = strings.ToLower(strings.Trim(fields[0], "{}."))
+		}
+		for i++; i < len(lines) && fenced[i]; i++ {
+			if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, marker) && strings.TrimLeft(t, marker[:1]) == "" {
+				break
+			}
+			block.Lines = append(block.Lines, lines[i])
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// fenceMarker returns the run of backticks or tildes opening a fence, or "".
+func fenceMarker(trimmed string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(trimmed) && trimmed[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return trimmed[:n]
+		}
+	}
+	return ""
+}
+
+func leadingIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// headings returns the document's Markdown headings outside fenced code.
+func headings(lines []string, fenced []bool) []chunk.Heading {
+	var out []chunk.Heading
+	for _, h := range chunk.ExtractHeadings(lines) {
+		if !fenced[h.Line-1] {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// proseLines returns the document with fenced code, example sections and
+// inline code blanked, keeping line numbers and byte offsets.
+func proseLines(lines []string) []string {
+	fenced := fencedLines(lines)
+	out := make([]string, len(lines))
+	inExample := false
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if isMarkdownHeading(line) {
+			inExample = isExampleHeading(line)
+		}
+		if inExample {
+			continue
+		}
+		out[i] = blankInlineCode(line)
+	}
+	return out
+}
+
+var inlineCodeRe = regexp.MustCompile("`+[^`]*`+")
+
+// blankInlineCode replaces inline code spans with spaces so byte offsets
+// into the line are preserved.
+func blankInlineCode(line string) string {
+	return inlineCodeRe.ReplaceAllStringFunc(line, func(code string) string {
+		return strings.Repeat(" ", len(code))
+	})
+}
+
+// sentence is one sentence of prose, from Line to LineEnd. Start is its
+// byte offset in the first line. Text is the source text, with newlines
+// where the sentence wraps; Prose is the same text with inline code
+// blanked, so offsets into either agree. Example marks sentences in example
+// sections.
+type sentence struct {
+	Line    int
+	LineEnd int
+	Start   int
+	Text    string
+	Prose   string
+	Example bool
+}
+
+var (
+	sentenceEndRe = regexp.MustCompile(`[.!?]+\s+`)
+	// blockStartRe matches lines that start a list item, table row or block
+	// quote, and so end the paragraph before them.
+	blockStartRe = regexp.MustCompile(`^\s*(?:[-*+]\s|\d+[.)]\s|\||>)`)
+)
+
+// proseSentences splits the prose of a document into sentences. Fenced
+// code, headings and, when suppressExamples is set, example sections are
+// skipped; inline code is blanked. A sentence runs across the lines of a
+// hard-wrapped paragraph or list item, so paragraphs end only at blank
+// lines and at the start of a list item, table row or block quote.
+func proseSentences(lines []string, suppressExamples bool) []sentence {
+	fenced := fencedLines(lines)
+	var out []sentence
+	first := 0
+	inExample := false
+	for i, line := range lines {
+		heading := !fenced[i] && isMarkdownHeading(line)
+		skip := fenced[i] || heading || inExample && suppressExamples || strings.TrimSpace(line) == ""
+		if skip || blockStartRe.MatchString(line) {
+			out = appendParagraph(out, lines, first, i, inExample)
+			first = i
+		}
+		if heading {
+			inExample = isExampleHeading(line)
+		}
+		if skip {
+			first = i + 1
+		}
+	}
+	return appendParagraph(out, lines, first, len(lines), inExample)
+}
+
+// appendParagraph splits the paragraph lines[first:end] into sentences.
+func appendParagraph(out []sentence, lines []string, first, end int, example bool) []sentence {
+	if first >= end {
+		return out
+	}
+	text := strings.Join(lines[first:end], "\n")
+	prose := make([]string, 0, end-first)
+	for _, line := range lines[first:end] {
+		prose = append(prose, blankInlineCode(line))
+	}
+	p := paragraph{text: text, prose: strings.Join(prose, "\n"), line: first + 1, example: example}
+	start := 0
+	for _, loc := range sentenceEndRe.FindAllStringIndex(p.prose, -1) {
+		out = p.appendSentence(out, start, loc[1])
+		start = loc[1]
+	}
+	return p.appendSentence(out, start, len(p.prose))
+}
+
+// paragraph tracks the line of its last sentence so that locating each
+// sentence scans the paragraph once.
+type paragraph struct {
+	text, prose string
+	line        int
+	offset      int
+	example     bool
+}
+
+func (p *paragraph) appendSentence(out []sentence, start, end int) []sentence {
+	trimmed := strings.TrimSpace(p.prose[start:end])
+	if trimmed == "" {
+		return out
+	}
+	start += strings.Index(p.prose[start:end], trimmed)
+	end = start + len(trimmed)
+	p.line += strings.Count(p.prose[p.offset:start], "\n")
+	p.offset = start
+	column := start - (strings.LastIndexByte(p.prose[:start], '\n') + 1)
+	return append(out, sentence{
+		Line:    p.line,
+		LineEnd: p.line + strings.Count(trimmed, "\n"),
+		Start:   column,
+		Text:    p.text[start:end],
+		Prose:   p.prose[start:end],
+		Example: p.example,
+	})
+}
+
+// markdownTable is one GFM pipe table. Line is the 1-based line of the
+// header row and Heading the text of the section it sits in.
+type markdownTable struct {
+	Line      int
+	Heading   string
+	Header    []string
+	Delimiter int
+	Rows      []tableRow
+}
+
+// tableRow is one body row of a table.
+type tableRow struct {
+	Line  int
+	Cells []string
+}
+
+var tableDelimiterRe = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+
+// markdownTables returns the pipe tables outside fenced code and, when
+// suppressExamples is set, outside example sections. A table is a header
+// row, a delimiter row and the non-blank rows after it.
+func markdownTables(lines []string, suppressExamples bool) []markdownTable {
+	fenced := fencedLines(lines)
+	var tables []markdownTable
+	heading := ""
+	inExample := false
+	for i := 0; i < len(lines); i++ {
+		if fenced[i] {
+			continue
+		}
+		if isMarkdownHeading(lines[i]) {
+			heading = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(lines[i]), "#"))
+			inExample = suppressExamples && isExampleHeading(lines[i])
+			continue
+		}
+		if inExample || i+1 >= len(lines) || fenced[i+1] || !strings.Contains(lines[i], "|") {
+			continue
+		}
+		delimiter := strings.TrimSpace(lines[i+1])
+		if !strings.Contains(delimiter, "|") || !tableDelimiterRe.MatchString(delimiter) {
+			continue
+		}
+		table := markdownTable{
+			Line:      i + 1,
+			Heading:   heading,
+			Header:    tableCells(lines[i]),
+			Delimiter: len(tableCells(delimiter)),
+		}
+		for i += 2; i < len(lines) && !fenced[i] && strings.TrimSpace(lines[i]) != "" && !isMarkdownHeading(lines[i]); i++ {
+			table.Rows = append(table.Rows, tableRow{Line: i + 1, Cells: tableCells(lines[i])})
+		}
+		i--
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// tableCells splits a table row into trimmed cells. Escaped pipes and pipes
+// inside code spans do not split cells; an unclosed backtick is literal.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	if cells, ok := splitCells(line, true); ok {
+		return cells
+	}
+	cells, _ := splitCells(line, false)
+	return cells
+}
+
+func splitCells(line string, codeSpans bool) ([]string, bool) {
+	var cells []string
+	start, ticks := 0, 0
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '`' && codeSpans:
+			n := 1
+			for i+n < len(line) && line[i+n] == '`' {
+				n++
+			}
+			if ticks == 0 {
+				ticks = n
+			} else if ticks == n {
+				ticks = 0
+			}
+			i += n - 1
+		case line[i] == '|' && ticks == 0:
+			cells = append(cells, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[start:])), ticks == 0
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	ctxpkg "github.com/dshills/speccritic/internal/context"
 	"github.com/dshills/speccritic/internal/schema"
 	"github.com/dshills/speccritic/internal/spec"
 )
@@ -25,10 +26,33 @@ type Config struct {
 	Profile   string
 	Strict    bool
 	IgnoreIDs []string
+	// RequirementIDPatterns are regular expressions matching requirement
+	// IDs. The default matches IDs such as REQ-001, FR-12 and API-AUTH-3.
+	RequirementIDPatterns []string
+	// Synonyms are extra groups of terms that name the same concept, checked
+	// alongside the built-in groups.
+	Synonyms [][]string
+	// SystemActors are extra component names that count as the agent of a
+	// passive requirement, as in "validated by the ledger".
+	SystemActors []string
+	// Fix names the rules whose autofixes are returned as patches. Each must
+	// be a fixable rule.
+	Fix []string
+	// Context holds the context files supplied with the spec. Links to other
+	// local files are reported as missing context, and JSON Schema or OpenAPI
+	// files validate the spec's example payloads.
+	Context []ctxpkg.ContextFile
 }
 
 type Result struct {
 	Issues []schema.Issue
+	// Normative counts RFC 2119 keywords per section; nil when the spec
+	// uses none.
+	Normative *schema.NormativeMeta
+	// Requirements is the catalog of requirement IDs the spec defines.
+	Requirements []schema.Requirement
+	// Patches are the autofixes of the rules named in Config.Fix.
+	Patches []schema.Patch
 }
 
 type Rule struct {
@@ -43,7 +67,9 @@ type Rule struct {
 	Recommendation string
 	Blocking       bool
 	Tags           []string
-	Matcher        Matcher
+	// Fixable rules attach a Fix to their findings.
+	Fixable bool
+	Matcher Matcher
 }
 
 type Finding struct {
@@ -58,6 +84,29 @@ type Finding struct {
 	Recommendation string
 	Blocking       bool
 	Tags           []string
+	// Related adds evidence ranges after the primary one, for findings that
+	// span several places in the spec.
+	Related []Location
+	// Fixes optionally resolve the finding. They are reported only when
+	// the rule is named in Config.Fix and never for fenced code.
+	Fixes []Fix
+}
+
+// Fix is one edit: it replaces the bytes from Start to End with After.
+// Offsets count from the start of the 1-based Line and may run into the
+// lines after it, which are joined with "\n".
+type Fix struct {
+	Line       int
+	Start, End int
+	After      string
+}
+
+// Location is one evidence range in the spec. LineEnd defaults to LineStart
+// and Quote to the quoted lines.
+type Location struct {
+	LineStart int
+	LineEnd   int
+	Quote     string
 }
 
 type Matcher interface {
@@ -75,6 +124,10 @@ type Document struct {
 	Raw       string
 	Lines     []string
 	LineCount int
+	// Files are the paths of the files in a composite spec.
+	Files []string
+
+	analyses analyses
 }
 
 func Run(s *spec.Spec, cfg Config) (Result, error) {
@@ -99,9 +152,18 @@ func RunRules(s *spec.Spec, cfg Config, rules []Rule) (Result, error) {
 		Raw:       s.Raw,
 		Lines:     spec.Lines(s.Raw),
 		LineCount: s.LineCount,
+		analyses:  make(analyses),
+	}
+	for _, file := range s.Files {
+		doc.Files = append(doc.Files, file.Path)
 	}
 	ignored := ignoreSet(cfg.IgnoreIDs)
+	fixes, err := fixSet(cfg.Fix, rules)
+	if err != nil {
+		return Result{}, err
+	}
 	var issues []schema.Issue
+	var edits []ruleFix
 	for _, rule := range rules {
 		if err := validateRule(rule); err != nil {
 			return Result{}, err
@@ -115,20 +177,173 @@ func RunRules(s *spec.Spec, cfg Config, rules []Rule) (Result, error) {
 				return Result{}, err
 			}
 			issues = append(issues, issue)
+			if fixes[rule.ID] {
+				for _, fix := range finding.Fixes {
+					edits = append(edits, ruleFix{RuleID: rule.ID, Fix: fix})
+				}
+			}
 		}
 	}
 	issues = dedupeIssues(issues)
 	sortIssues(issues)
-	return Result{Issues: issues}, nil
+	return Result{
+		Issues:       issues,
+		Normative:    normativeCounts(doc),
+		Requirements: requirementCatalog(doc, cfg),
+		Patches:      fixPatches(doc, edits),
+	}, nil
+}
+
+// fixSet returns the rule IDs whose fixes are reported, rejecting IDs that
+// name no fixable rule.
+func fixSet(ids []string, rules []Rule) (map[string]bool, error) {
+	fixable := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		fixable[rule.ID] = rule.Fixable
+	}
+	out := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !fixable[id] {
+			return nil, fmt.Errorf("preflight rule %q has no autofix", id)
+		}
+		out[id] = true
+	}
+	return out, nil
+}
+
+// ruleFix is a requested fix and the rule that made it.
+type ruleFix struct {
+	RuleID string
+	Fix
+}
+
+// fixPatches turns the requested fixes into patches. Each patch replaces
+// the fewest whole words around its fix that occur only once in the spec,
+// so fixes from different rules on one line do not collide. Fixes whose
+// words overlap share one patch, credited to the earlier rule. Fixes that
+// touch fenced code or have no unique context on their line are dropped.
+func fixPatches(doc Document, fixes []ruleFix) []schema.Patch {
+	if len(fixes) == 0 {
+		return nil
+	}
+	text := strings.Join(doc.Lines, "\n")
+	starts := make([]int, len(doc.Lines))
+	for i := 1; i < len(doc.Lines); i++ {
+		starts[i] = starts[i-1] + len(doc.Lines[i-1]) + 1
+	}
+	fenced := doc.fenced()
+	type span struct {
+		start, end int
+		first      int
+		fixes      []ruleFix
+	}
+	var spans []span
+	for i, fix := range fixes {
+		if fix.Line < 1 || fix.Line > len(doc.Lines) || fix.Start < 0 || fix.End < fix.Start {
+			continue
+		}
+		start, end := starts[fix.Line-1]+fix.Start, starts[fix.Line-1]+fix.End
+		if end > len(text) || text[start:end] == fix.After {
+			continue
+		}
+		last := fix.Line - 1 + strings.Count(text[start:end], "\n")
+		touchesFence := false
+		for line := fix.Line - 1; line <= last; line++ {
+			touchesFence = touchesFence || fenced[line]
+		}
+		if touchesFence {
+			continue
+		}
+		fix.Start, fix.End = start, end
+		if start, end, ok := uniqueWords(text, start, end); ok {
+			spans = append(spans, span{start, end, i, []ruleFix{fix}})
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start < merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			merged[n-1].first = min(merged[n-1].first, s.first)
+			merged[n-1].fixes = append(merged[n-1].fixes, s.fixes...)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	patches := make([]schema.Patch, 0, len(merged))
+	for _, s := range merged {
+		patch := spanPatch(text, s.start, s.end, s.fixes)
+		patch.IssueID = fixes[s.first].RuleID
+		patches = append(patches, patch)
+	}
+	return patches
+}
+
+// spanPatch applies the fixes inside text[start:end]. A fix that overlaps
+// one already applied, such as the same fix from a second finding, is
+// skipped.
+func spanPatch(text string, start, end int, fixes []ruleFix) schema.Patch {
+	sort.SliceStable(fixes, func(i, j int) bool { return fixes[i].Start < fixes[j].Start })
+	var b strings.Builder
+	last := start
+	for _, fix := range fixes {
+		if fix.Start < last {
+			continue
+		}
+		b.WriteString(text[last:fix.Start])
+		b.WriteString(fix.After)
+		last = fix.End
+	}
+	b.WriteString(text[last:end])
+	return schema.Patch{Before: text[start:end], After: b.String()}
+}
+
+// uniqueWords widens text[start:end] to whole words, then by one word at a
+// time on alternate sides within its lines, until it occurs only once in
+// text.
+func uniqueWords(text string, start, end int) (int, int, bool) {
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' }
+	for start > 0 && !isSpace(text[start-1]) && text[start-1] != '\n' {
+		start--
+	}
+	for end < len(text) && !isSpace(text[end]) && text[end] != '\n' {
+		end++
+	}
+	for left := true; ; left = !left {
+		if before := text[start:end]; strings.TrimSpace(before) != "" && strings.Index(text, before) == start && !strings.Contains(text[start+1:], before) {
+			return start, end, true
+		}
+		canLeft := start > 0 && text[start-1] != '\n'
+		canRight := end < len(text) && text[end] != '\n'
+		switch {
+		case !canLeft && !canRight:
+			return 0, 0, false
+		case left && canLeft || !canRight:
+			for start > 0 && isSpace(text[start-1]) {
+				start--
+			}
+			for start > 0 && !isSpace(text[start-1]) && text[start-1] != '\n' {
+				start--
+			}
+		default:
+			for end < len(text) && isSpace(text[end]) {
+				end++
+			}
+			for end < len(text) && !isSpace(text[end]) && text[end] != '\n' {
+				end++
+			}
+		}
+	}
 }
 
 func validateConfig(cfg Config) error {
 	switch cfg.Mode {
 	case "", ModeWarn, ModeGate, ModeOnly:
-		return nil
 	default:
 		return fmt.Errorf("invalid preflight mode %q", cfg.Mode)
 	}
+	_, err := compileRequirementIDPatterns(cfg.RequirementIDPatterns)
+	return err
 }
 
 func validateRule(rule Rule) error {
@@ -165,20 +380,17 @@ func ruleApplies(rule Rule, profile string) bool {
 }
 
 func issueFromFinding(doc Document, rule Rule, finding Finding) (schema.Issue, error) {
-	lineStart := finding.LineStart
-	if lineStart == 0 {
-		lineStart = 1
-	}
-	lineEnd := finding.LineEnd
-	if lineEnd == 0 {
-		lineEnd = lineStart
-	}
-	if lineStart < 1 || lineEnd < lineStart || lineEnd > len(doc.Lines) {
-		return schema.Issue{}, fmt.Errorf("preflight rule %s produced invalid evidence range %d-%d for %d line spec", rule.ID, lineStart, lineEnd, len(doc.Lines))
-	}
-	quote := finding.Quote
-	if quote == "" {
-		quote = strings.Join(doc.Lines[lineStart-1:lineEnd], "\n")
+	primary, err := evidenceFor(doc, rule, Location{LineStart: finding.LineStart, LineEnd: finding.LineEnd, Quote: finding.Quote})
+	if err != nil {
+		return schema.Issue{}, err
+	}
+	evidence := []schema.Evidence{primary}
+	for _, loc := range finding.Related {
+		ev, err := evidenceFor(doc, rule, loc)
+		if err != nil {
+			return schema.Issue{}, err
+		}
+		evidence = append(evidence, ev)
 	}
 	severity := finding.Severity
 	if severity == "" {
@@ -214,17 +426,12 @@ func issueFromFinding(doc Document, rule Rule, finding Finding) (schema.Issue, e
 	tags = append(tags, finding.Tags...)
 	blocking := finding.Blocking || rule.Blocking || rule.Severity == schema.SeverityCritical || severity == schema.SeverityCritical
 	return schema.Issue{
-		ID:          rule.ID,
-		Severity:    severity,
-		Category:    category,
-		Title:       title,
-		Description: description,
-		Evidence: []schema.Evidence{{
-			Path:      doc.Path,
-			LineStart: lineStart,
-			LineEnd:   lineEnd,
-			Quote:     quote,
-		}},
+		ID:             rule.ID,
+		Severity:       severity,
+		Category:       category,
+		Title:          title,
+		Description:    description,
+		Evidence:       evidence,
 		Impact:         impact,
 		Recommendation: recommendation,
 		Blocking:       blocking,
@@ -232,6 +439,30 @@ func issueFromFinding(doc Document, rule Rule, finding Finding) (schema.Issue, e
 	}, nil
 }
 
+func evidenceFor(doc Document, rule Rule, loc Location) (schema.Evidence, error) {
+	lineStart := loc.LineStart
+	if lineStart == 0 {
+		lineStart = 1
+	}
+	lineEnd := loc.LineEnd
+	if lineEnd == 0 {
+		lineEnd = lineStart
+	}
+	if lineStart < 1 || lineEnd < lineStart || lineEnd > len(doc.Lines) {
+		return schema.Evidence{}, fmt.Errorf("preflight rule %s produced invalid evidence range %d-%d for %d line spec", rule.ID, lineStart, lineEnd, len(doc.Lines))
+	}
+	quote := loc.Quote
+	if quote == "" {
+		quote = strings.Join(doc.Lines[lineStart-1:lineEnd], "\n")
+	}
+	return schema.Evidence{
+		Path:      doc.Path,
+		LineStart: lineStart,
+		LineEnd:   lineEnd,
+		Quote:     quote,
+	}, nil
+}
+
 func dedupeIssues(issues []schema.Issue) []schema.Issue {
 	seen := make(map[issueKey]bool, len(issues))
 	out := make([]schema.Issue, 0, len(issues))
@@ -1,6 +1,7 @@
 package preflight
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/dshills/speccritic/internal/schema"
@@ -133,3 +134,88 @@ func hasTag(tags []string, want string) bool {
 	}
 	return false
 }
+
+func TestRunReportsFixesOnlyForRequestedRules(t *testing.T) {
+	text := strings.Join([]string{
+		"# Upload",
+		"The key words MUST and SHOULD are interpreted as described in RFC 2119.",
+		"The service must store each file and `must` is quoted. The client should retry.",
+		"Files are mirrored to the archive tier via XFS.",
+		"| Limit | Value |",
+		"|-------|-------|",
+		"| Size | TBD |",
+		"```text",
+		"Retention: TBD",
+		"```",
+	}, "\n")
+	s := spec.New("SPEC.md", text)
+
+	result, err := Run(s, Config{Enabled: true, Profile: "general"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Patches) != 0 {
+		t.Fatalf("patches = %#v, want none without Fix", result.Patches)
+	}
+
+	result, err = Run(s, Config{Enabled: true, Profile: "general", Fix: []string{"PREFLIGHT-RFC2119-001", "PREFLIGHT-TODO-001", "PREFLIGHT-ACRONYM-001"}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got := map[string]string{}
+	for _, p := range result.Patches {
+		got[p.Before] = p.IssueID + ": " + p.After
+	}
+	want := map[string]string{
+		"service must": "PREFLIGHT-RFC2119-001: service MUST",
+		"| TBD":        "PREFLIGHT-TODO-001: | OPEN DECISION",
+		"XFS.":         "PREFLIGHT-ACRONYM-001: XFS (OPEN DECISION: define XFS).",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("patches = %#v", result.Patches)
+	}
+	for before, after := range want {
+		if got[before] != after {
+			t.Fatalf("patch for %q = %q, want %q", before, got[before], after)
+		}
+	}
+
+	if _, err := Run(s, Config{Enabled: true, Fix: []string{"PREFLIGHT-VAGUE-001"}}); err == nil || !strings.Contains(err.Error(), "has no autofix") {
+		t.Fatalf("err = %v, want no autofix error", err)
+	}
+}
+
+func TestRunKeepsFixesFromRulesOnTheSameLine(t *testing.T) {
+	text := strings.Join([]string{
+		"The key words MUST and MAY are to be interpreted as described in BCP 14.",
+		"The XYZ retry limit is TBD and clients must retry. Users may notice a delay.",
+		"The XYZ backoff doubles.",
+	}, "\n")
+	s := spec.New("SPEC.md", text)
+	cfg := Config{Enabled: true, Profile: "general", Fix: []string{"PREFLIGHT-RFC2119-001", "PREFLIGHT-TODO-001", "PREFLIGHT-ACRONYM-001"}}
+	result, err := Run(s, cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	fixed := text
+	for _, p := range result.Patches {
+		if strings.Count(fixed, p.Before) != 1 {
+			t.Fatalf("patch %#v is not unique in %q", p, fixed)
+		}
+		fixed = strings.Replace(fixed, p.Before, p.After, 1)
+	}
+	wantLine := "The XYZ (OPEN DECISION: define XYZ) retry limit is OPEN DECISION and clients MUST retry. Users may notice a delay."
+	if got := strings.Split(fixed, "\n")[1]; got != wantLine {
+		t.Fatalf("fixed line = %q, want %q", got, wantLine)
+	}
+
+	after, err := Run(spec.New("SPEC.md", fixed), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, issue := range after.Issues {
+		if issue.ID == "PREFLIGHT-ACRONYM-001" && !hasTag(issue.Tags, "term:XYZ") && !hasTag(issue.Tags, "term:BCP") {
+			t.Fatalf("fix introduced %#v", issue)
+		}
+	}
+}
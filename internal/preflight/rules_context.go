@@ -44,12 +44,21 @@ func undefinedAcronymRule() Rule {
 		Impact:         "Readers may interpret the acronym differently.",
 		Recommendation: "Define the acronym on first use or add it to a glossary.",
 		Tags:           []string{"acronym"},
-		Matcher: MatcherFunc(func(doc Document, _ Rule, _ Config) []Finding {
+		Fixable:        true,
+		Matcher: MatcherFunc(func(doc Document, _ Rule, cfg Config) []Finding {
 			defined := collectDefinedAcronyms(doc.Lines)
+			ids, _ := compileRequirementIDPatterns(cfg.RequirementIDPatterns)
+			declarations := doc.allNormative().Declarations
 			seen := make(map[string]bool)
 			var findings []Finding
 			for i, line := range doc.Lines {
-				for _, token := range acronymRe.FindAllString(line, -1) {
+				// Declaration sentences name RFC 2119 and BCP 14 rather than
+				// use acronyms the spec has to define.
+				if declarations[i+1] {
+					continue
+				}
+				text := acronymText(line, ids)
+				for _, token := range acronymRe.FindAllString(text, -1) {
 					if allowedAcronyms[token] || defined[token] || seen[token] {
 						continue
 					}
@@ -58,6 +67,7 @@ func undefinedAcronymRule() Rule {
 						LineStart: i + 1,
 						Quote:     strings.TrimSpace(line),
 						Tags:      []string{"acronym", "term:" + token},
+						Fixes:     acronymFixes(i+1, line, text, token),
 					})
 				}
 			}
@@ -66,6 +76,33 @@ func undefinedAcronymRule() Rule {
 	}
 }
 
+// acronymText blanks the parts of line that look like acronyms but are not:
+// the OPEN DECISION markers that fixes insert, requirement IDs such as
+// REQ-001, and RFC 2119 keywords such as the NOT of MUST NOT.
+func acronymText(line string, ids []*regexp.Regexp) string {
+	blank := func(s string) string { return strings.Repeat(" ", len(s)) }
+	text := strings.ReplaceAll(line, openDecisionMarker, blank(openDecisionMarker))
+	for _, re := range ids {
+		text = re.ReplaceAllStringFunc(text, blank)
+	}
+	return normativeKeywordRe.ReplaceAllStringFunc(text, blank)
+}
+
+// acronymFixes mark the first use of token in text, the line as blanked by
+// acronymText, outside inline code with an open decision to define it.
+func acronymFixes(n int, line, text, token string) []Fix {
+	marker := "(" + openDecisionMarker + ": define " + token + ")"
+	if strings.Contains(line, marker) {
+		return nil
+	}
+	for _, loc := range acronymRe.FindAllStringIndex(blankInlineCode(text), -1) {
+		if text[loc[0]:loc[1]] == token {
+			return []Fix{{Line: n, Start: loc[0], End: loc[1], After: token + " " + marker}}
+		}
+	}
+	return nil
+}
+
 func measurableCriteriaRule() Rule {
 	return Rule{
 		ID:             "PREFLIGHT-MEASURABLE-001",
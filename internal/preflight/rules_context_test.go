@@ -1,6 +1,7 @@
 package preflight
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/dshills/speccritic/internal/schema"
@@ -33,6 +34,27 @@ func TestAcronymRuleHonorsGlossaryDefinition(t *testing.T) {
 	}
 }
 
+func TestAcronymRuleSkipsRequirementIDsKeywordsAndDeclarations(t *testing.T) {
+	text := strings.Join([]string{
+		"The key words MUST NOT and MAY are interpreted as described in RFC 2119.",
+		"REQ-001: The service MUST NOT drop uploads.",
+		"- TKT-7: The XFS mirror MAY lag.",
+	}, "\n")
+	cfg := Config{Enabled: true, Profile: "general", RequirementIDPatterns: []string{`\b(?:REQ|TKT)-\d+\b`}, Fix: []string{"PREFLIGHT-ACRONYM-001"}}
+	result, err := Run(spec.New("SPEC.md", text), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, issue := range result.Issues {
+		if issue.ID == "PREFLIGHT-ACRONYM-001" && !hasTag(issue.Tags, "term:XFS") {
+			t.Fatalf("unexpected acronym issue %#v", issue)
+		}
+	}
+	if len(result.Patches) != 1 || result.Patches[0].Before != "XFS" || result.Patches[0].After != "XFS (OPEN DECISION: define XFS)" {
+		t.Fatalf("patches = %#v", result.Patches)
+	}
+}
+
 func TestMeasurableRuleDetectsMissingValue(t *testing.T) {
 	result := runBuiltin(t, "SPEC.md", "The API timeout must be configurable.")
 	requireIssue(t, result.Issues, "PREFLIGHT-MEASURABLE-001", schema.SeverityWarn, 1)
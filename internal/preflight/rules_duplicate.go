@@ -0,0 +1,258 @@
+package preflight
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const (
+	// duplicateThreshold is the token Jaccard similarity at which two
+	// requirements count as near-duplicates.
+	duplicateThreshold = 0.8
+	// duplicateMinTokens skips short sentences, which share most of their
+	// words by chance.
+	duplicateMinTokens = 6
+	// duplicateMaxPostings bounds the candidates read per token, so specs
+	// of near-identical lines stay linear.
+	duplicateMaxPostings = 64
+)
+
+// duplicateStopwords carry no meaning for similarity. Negations, modals and
+// numbers are kept: they are what copied requirements drift in.
+var duplicateStopwords = map[string]bool{
+	"the": true, "an": true, "and": true, "of": true, "to": true, "in": true, "for": true,
+	"on": true, "at": true, "by": true, "with": true, "or": true, "is": true, "are": true,
+	"be": true, "as": true, "it": true, "its": true, "this": true, "that": true, "from": true,
+}
+
+// driftTokens are words whose difference turns a duplicate into a
+// contradiction.
+var driftTokens = map[string]bool{
+	"not": true, "no": true, "never": true, "must": true, "shall": true, "should": true,
+	"may": true, "required": true, "optional": true, "before": true, "after": true,
+}
+
+func duplicateRules() []Rule {
+	return []Rule{repeatedRequirementRule(), divergentRepeatRule()}
+}
+
+func repeatedRequirementRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-DUP-001",
+		Group:          "duplicate",
+		Title:          "Requirement is repeated in another section",
+		Description:    "Two sections state nearly the same requirement.",
+		Severity:       schema.SeverityInfo,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Copies are edited separately and drift apart, and readers cannot tell which one is authoritative.",
+		Recommendation: "Keep the requirement in one section and reference it from the other.",
+		Tags:           []string{"duplicate"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			return duplicateFindings(doc, rule, false)
+		}),
+	}
+}
+
+func divergentRepeatRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-DUP-002",
+		Group:          "duplicate",
+		Title:          "Repeated requirement differs between sections",
+		Description:    "Two sections state nearly the same requirement with different values or obligations.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryContradiction,
+		Impact:         "The copies contradict each other, so implementers must guess which one applies.",
+		Recommendation: "Decide which version is correct, keep it in one section, and reference it from the other.",
+		Tags:           []string{"duplicate"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			return duplicateFindings(doc, rule, true)
+		}),
+	}
+}
+
+// duplicateUnit is one requirement sentence with its distinct tokens, in
+// sentence order and in global rarest-first order.
+type duplicateUnit struct {
+	sentence
+	Section int
+	Tokens  []string
+	Sorted  []string
+	Set     map[string]bool
+}
+
+// duplicatePair is a later requirement and the earlier one it nearly repeats.
+type duplicatePair struct {
+	Later, Earlier *duplicateUnit
+	Similarity     float64
+}
+
+func duplicateFindings(doc Document, rule Rule, divergent bool) []Finding {
+	sectionNames := map[int]string{}
+	for _, h := range doc.headings() {
+		sectionNames[h.Line] = h.Text
+	}
+	var findings []Finding
+	for _, pair := range doc.duplicates() {
+		later, earlier := pair.Later.Tokens, pair.Earlier.Tokens
+		added, removed := tokenDifference(later, pair.Earlier.Set), tokenDifference(earlier, pair.Later.Set)
+		if isDivergent(added, removed) != divergent {
+			continue
+		}
+		where := fmt.Sprintf("line %d", pair.Earlier.Line)
+		if name := sectionNames[pair.Earlier.Section]; name != "" {
+			where += fmt.Sprintf(" in %q", name)
+		}
+		description := fmt.Sprintf("This requirement nearly repeats %s (%.0f%% of words shared).", where, pair.Similarity*100)
+		if divergent {
+			description = fmt.Sprintf("This requirement nearly repeats %s but differs: %s here versus %s there.", where, quotedTokens(added), quotedTokens(removed))
+		}
+		findings = append(findings, Finding{
+			LineStart:   pair.Later.Line,
+			LineEnd:     pair.Later.LineEnd,
+			Quote:       pair.Later.Text,
+			Description: description,
+			Tags:        []string{rule.Group},
+			Related:     []Location{{LineStart: pair.Earlier.Line, LineEnd: pair.Earlier.LineEnd, Quote: pair.Earlier.Text}},
+		})
+	}
+	return findings
+}
+
+// nearDuplicates pairs each requirement sentence with the most similar
+// earlier one in another section. Candidates come from a prefix-filtered
+// inverted index: sets with Jaccard similarity t share one of their first
+// n-ceil(t*n)+1 rarest tokens, so most pairs are never compared.
+func nearDuplicates(sentences []sentence, sections []int) []duplicatePair {
+	var units []*duplicateUnit
+	frequency := map[string]int{}
+	for _, s := range sentences {
+		if !normativeKeywordRe.MatchString(s.Prose) {
+			continue
+		}
+		tokens := duplicateTokens(s.Prose)
+		if len(tokens) < duplicateMinTokens {
+			continue
+		}
+		unit := &duplicateUnit{sentence: s, Section: sections[s.Line-1], Tokens: tokens, Set: map[string]bool{}}
+		for _, token := range tokens {
+			unit.Set[token] = true
+			frequency[token]++
+		}
+		units = append(units, unit)
+	}
+	for _, unit := range units {
+		unit.Sorted = append([]string(nil), unit.Tokens...)
+		sort.Slice(unit.Sorted, func(i, j int) bool {
+			a, b := unit.Sorted[i], unit.Sorted[j]
+			if frequency[a] != frequency[b] {
+				return frequency[a] < frequency[b]
+			}
+			return a < b
+		})
+	}
+
+	postings := map[string][]int{}
+	var pairs []duplicatePair
+	for i, unit := range units {
+		prefix := prefixLength(len(unit.Sorted))
+		best, bestScore := -1, 0.0
+		seen := map[int]bool{}
+		for _, token := range unit.Sorted[:prefix] {
+			candidates := postings[token]
+			if len(candidates) > duplicateMaxPostings {
+				candidates = candidates[len(candidates)-duplicateMaxPostings:]
+			}
+			for _, j := range candidates {
+				other := units[j]
+				if seen[j] || other.Section == unit.Section {
+					continue
+				}
+				seen[j] = true
+				if score := setJaccard(unit.Set, other.Set); score >= duplicateThreshold && score > bestScore {
+					best, bestScore = j, score
+				}
+			}
+		}
+		if best >= 0 {
+			pairs = append(pairs, duplicatePair{Later: unit, Earlier: units[best], Similarity: bestScore})
+		}
+		for _, token := range unit.Sorted[:prefix] {
+			postings[token] = append(postings[token], i)
+		}
+	}
+	return pairs
+}
+
+func prefixLength(n int) int {
+	return n - int(math.Ceil(duplicateThreshold*float64(n))) + 1
+}
+
+// duplicateTokens returns the distinct lowercase words of text in order,
+// without stopwords and single letters, as incremental review does.
+func duplicateTokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := map[string]bool{}
+	var tokens []string
+	for _, field := range fields {
+		if (len(field) < 2 && !unicode.IsDigit(rune(field[0]))) || duplicateStopwords[field] || seen[field] {
+			continue
+		}
+		seen[field] = true
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+func setJaccard(a, b map[string]bool) float64 {
+	intersection := 0
+	for token := range a {
+		if b[token] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+func tokenDifference(tokens []string, other map[string]bool) []string {
+	var out []string
+	for _, token := range tokens {
+		if !other[token] {
+			out = append(out, token)
+		}
+	}
+	return out
+}
+
+// isDivergent reports whether the differing words change a value, a
+// negation, an obligation or an ordering.
+func isDivergent(added, removed []string) bool {
+	for _, tokens := range [][]string{added, removed} {
+		for _, token := range tokens {
+			if driftTokens[token] || unicode.IsDigit(rune(token[0])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func quotedTokens(tokens []string) string {
+	if len(tokens) == 0 {
+		return "nothing"
+	}
+	if len(tokens) > 3 {
+		tokens = tokens[:3]
+	}
+	quotedList := make([]string, len(tokens))
+	for i, token := range tokens {
+		quotedList[i] = quoted(token)
+	}
+	return joinList(quotedList)
+}
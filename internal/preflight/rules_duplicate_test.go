@@ -0,0 +1,83 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestDuplicateRulesReportRepeatedAndDivergentRequirements(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"## Sessions",
+		"The service MUST expire idle sessions after 30 minutes of inactivity.",
+		"Every upload MUST be scanned for malware before it is stored in the bucket.",
+		"The service MUST log each failed login attempt with the client address.",
+		"## Security",
+		"The service MUST expire idle sessions after 60 minutes of inactivity.",
+		"Every upload MUST be scanned for malware before it is stored in the archive bucket.",
+		"## Auditing",
+		"The service SHOULD log each failed login attempt with the client address.",
+	}, "\n"))
+
+	divergent := requireIssueAt(t, result.Issues, "PREFLIGHT-DUP-002", 6)
+	if divergent.Category != schema.CategoryContradiction || len(divergent.Evidence) != 2 || divergent.Evidence[1].LineStart != 2 {
+		t.Fatalf("issue = %#v", divergent)
+	}
+	if divergent.Description != `This requirement nearly repeats line 2 in "Sessions" but differs: "60" here versus "30" there.` {
+		t.Fatalf("description = %q", divergent.Description)
+	}
+	if !strings.HasPrefix(divergent.Evidence[0].Quote, "The service MUST expire") {
+		t.Fatalf("evidence = %#v", divergent.Evidence)
+	}
+
+	repeated := requireIssueAt(t, result.Issues, "PREFLIGHT-DUP-001", 7)
+	if repeated.Severity != schema.SeverityInfo || repeated.Evidence[1].LineStart != 3 {
+		t.Fatalf("issue = %#v", repeated)
+	}
+	if repeated.Description != `This requirement nearly repeats line 3 in "Sessions" (89% of words shared).` {
+		t.Fatalf("description = %q", repeated.Description)
+	}
+
+	modal := requireIssueAt(t, result.Issues, "PREFLIGHT-DUP-002", 9)
+	if !strings.Contains(modal.Description, `"should" here versus "must" there`) {
+		t.Fatalf("description = %q", modal.Description)
+	}
+	if n := countIssues(result.Issues, "PREFLIGHT-DUP-001") + countIssues(result.Issues, "PREFLIGHT-DUP-002"); n != 3 {
+		t.Fatalf("duplicate issues = %d, want 3", n)
+	}
+}
+
+func TestDuplicateRulesIgnoreSameSectionAndNonRequirements(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"## Limits",
+		"The service MUST reject uploads larger than 10 MB with status 413.",
+		"The service MUST reject uploads larger than 20 MB with status 413.",
+		"## Background",
+		"Uploads larger than 10 MB were rejected with status 413 by the old service.",
+		"## Examples",
+		"The service MUST reject uploads larger than 10 MB with status 413.",
+	}, "\n"))
+	for _, id := range []string{"PREFLIGHT-DUP-001", "PREFLIGHT-DUP-002"} {
+		if issue := findIssue(result.Issues, id); issue != nil {
+			t.Fatalf("unexpected issue %#v", issue)
+		}
+	}
+}
+
+func TestNearDuplicatesScalesToLargeSpecs(t *testing.T) {
+	// Every sentence is a near-duplicate of every other one, in 500
+	// sections; the posting cap keeps this linear.
+	var lines []string
+	for section := 0; section < 500; section++ {
+		lines = append(lines, fmt.Sprintf("## Area %d", section))
+		for i := 0; i < 9; i++ {
+			lines = append(lines, fmt.Sprintf("The gateway MUST forward each signed request to the billing backend within %d ms.", section*10+i))
+		}
+	}
+	pairs := nearDuplicates(proseSentences(lines, true), lineSections(lines))
+	if len(pairs) != 500*9-9 {
+		t.Fatalf("pairs = %d, want every sentence after the first section paired", len(pairs))
+	}
+}
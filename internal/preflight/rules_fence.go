@@ -0,0 +1,500 @@
+package preflight
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/chunk"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// redactedMarker is the placeholder redact.Redact substitutes for secrets.
+const redactedMarker = "[REDACTED]"
+
+// maxSchemaFindings caps schema findings per fenced block so one wrong
+// example does not drown the report.
+const maxSchemaFindings = 5
+
+var (
+	httpMethods = map[string]bool{
+		"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+	}
+	httpRequestLineRe = regexp.MustCompile(`^([A-Z]+)\s+(\S+)(?:\s+HTTP/\d(?:\.\d)?)?$`)
+	httpStatusLineRe  = regexp.MustCompile(`^HTTP/\d(?:\.\d)?\s+(\d{3})(?:\s.*)?$`)
+	httpHeaderRe      = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+:")
+	jsonMemberRe      = regexp.MustCompile(`^\s*"[^"]*"\s*:`)
+	ellipsisRe        = regexp.MustCompile(`(?:^|[\s\[{,:])(?:\.\.\.|…)(?:[\s\]},]|$)`)
+	schemaAttrRe      = regexp.MustCompile(`\bschema=["']?([^"'\s}]+)`)
+	// invalidExampleRe matches explicit markers of a deliberately broken
+	// example. A bare "bad" or "invalid" is not enough: "400 Bad Request"
+	// introduces a response that should still parse.
+	invalidExampleRe = regexp.MustCompile(`(?i)\b(?:invalid|malformed|incorrect|wrong|bad)\s+(?:examples?|payloads?|samples?|snippets?)\b|\banti-?patterns?\b`)
+	invalidFenceRe   = regexp.MustCompile(`(?i)^\{?\.?(?:invalid|malformed|anti-?pattern)\}?$`)
+)
+
+func fenceRules() []Rule {
+	return []Rule{fenceSyntaxRule(), fenceSchemaRule()}
+}
+
+func fenceSyntaxRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-FENCE-001",
+		Group:          "fenced-code",
+		Title:          "Fenced example has invalid syntax",
+		Description:    "A fenced json, yaml, xml or http block does not parse.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryUndefinedInterface,
+		Impact:         "Implementers copying the example get a payload that cannot be parsed, and the intended format is unclear.",
+		Recommendation: "Fix the example so it parses, or mark it as deliberately abbreviated with an ellipsis.",
+		Tags:           []string{"fenced-code"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, problem := range analyzeFences(doc, cfg) {
+				if !problem.Schema {
+					findings = append(findings, problem.finding(rule))
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func fenceSchemaRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-FENCE-002",
+		Group:          "fenced-code",
+		Title:          "Example payload does not match its schema",
+		Description:    "A fenced example contradicts the JSON Schema or OpenAPI definition supplied as context.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryContradiction,
+		Impact:         "The example and the schema disagree, so implementers and tests will follow different contracts.",
+		Recommendation: "Correct the example or the schema so they agree.",
+		Tags:           []string{"fenced-code", "schema"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, problem := range analyzeFences(doc, cfg) {
+				if problem.Schema {
+					findings = append(findings, problem.finding(rule))
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+type fenceProblem struct {
+	Line        int
+	Description string
+	Lang        string
+	Schema      bool
+}
+
+func (p fenceProblem) finding(rule Rule) Finding {
+	return Finding{
+		LineStart:   p.Line,
+		Description: p.Description,
+		Tags:        []string{rule.Group, "lang:" + p.Lang},
+	}
+}
+
+// fenceContext carries what the checks need beyond the block itself.
+type fenceContext struct {
+	catalog schemaCatalog
+	heading string
+	leadIn  string
+	// lastOperation is the operation of the most recent HTTP request, which
+	// response examples that follow it are checked against.
+	lastOperation *apiOperation
+}
+
+// analyzeFences checks every json, yaml, xml and http block. Blocks under
+// headings or lead-in sentences that call them invalid examples or
+// anti-patterns, or annotated as invalid after the fence language, are
+// skipped, as are json and yaml blocks abbreviated with an ellipsis. Preflight runs on the
+// redacted spec, so blocks with a redacted secret are skipped too: the
+// placeholder breaks examples that were valid.
+func analyzeFences(doc Document, cfg Config) []fenceProblem {
+	ctx := &fenceContext{catalog: loadSchemaCatalog(cfg.Context)}
+	hs := doc.headings()
+	var problems []fenceProblem
+	for _, block := range fencedBlocks(doc.Lines) {
+		ctx.heading = enclosingHeading(hs, block.Start)
+		ctx.leadIn = leadInLine(doc.Lines, block.Start)
+		if invalidExampleRe.MatchString(ctx.heading) || invalidExampleRe.MatchString(ctx.leadIn) || annotatedInvalid(block) || containsRedaction(block.Lines) {
+			continue
+		}
+		var found []fenceProblem
+		switch block.Lang {
+		case "json":
+			found = checkJSONBlock(block.Lines, block.Start+1, ctx.schemaFor(block, nil))
+		case "yaml", "yml":
+			found = checkYAMLBlock(block, ctx.schemaFor(block, nil))
+		case "xml":
+			found = checkXMLBlock(block)
+		case "http":
+			found = checkHTTPBlock(block, ctx)
+		}
+		problems = append(problems, found...)
+	}
+	return problems
+}
+
+// annotatedInvalid reports whether the fence info string marks the block as
+// invalid, as in "```json invalid" or "```json {.invalid}".
+func annotatedInvalid(block fencedBlock) bool {
+	fields := strings.Fields(block.Info)
+	for i := 1; i < len(fields); i++ {
+		if invalidFenceRe.MatchString(fields[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRedaction(lines []string) bool {
+	for _, line := range lines {
+		if strings.Contains(line, redactedMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+func enclosingHeading(hs []chunk.Heading, line int) string {
+	text := ""
+	for _, h := range hs {
+		if h.Line > line {
+			break
+		}
+		text = h.Text
+	}
+	return text
+}
+
+// leadInLine returns the last non-blank line before a fence.
+func leadInLine(lines []string, fence int) string {
+	for i := fence - 2; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return lines[i]
+		}
+	}
+	return ""
+}
+
+// schemaFor picks the schema an example is checked against: a schema=Name
+// attribute on the fence, the operation of an HTTP message, or a schema
+// named in the lead-in sentence or section heading.
+func (c *fenceContext) schemaFor(block fencedBlock, fromOperation *schemaRef) *schemaRef {
+	if match := schemaAttrRe.FindStringSubmatch(block.Info); match != nil {
+		if ref, ok := c.catalog.Named[match[1]]; ok {
+			return &ref
+		}
+		return nil
+	}
+	if fromOperation != nil {
+		return fromOperation
+	}
+	var best *schemaRef
+	for _, text := range []string{c.leadIn, c.heading} {
+		for name, ref := range c.catalog.Named {
+			if len(name) < 3 || !containsWord(text, name) {
+				continue
+			}
+			if best == nil || len(name) > len(best.Name) || len(name) == len(best.Name) && name < best.Name {
+				ref := ref
+				best = &ref
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// containsWord reports whether name appears in text as a whole word, with
+// its exact case.
+func containsWord(text, name string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], name)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(name)
+		if (i == 0 || !isWordByte(text[i-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// checkJSONBlock parses JSON whose first line is spec line first and
+// validates it against ref when one applies.
+func checkJSONBlock(lines []string, first int, ref *schemaRef) []fenceProblem {
+	text := strings.Join(lines, "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		// `"meta": {...}` fragments show members to add to a larger object.
+		if ellipsisRe.MatchString(text) || jsonMemberRe.MatchString(text) && json.Valid([]byte("{"+text+"}")) {
+			return nil
+		}
+		line := first
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line = first + offsetLine(text, int(syntax.Offset))
+		}
+		return []fenceProblem{{Line: line, Lang: "json", Description: fmt.Sprintf("Invalid JSON: %s.", strings.TrimPrefix(err.Error(), "json: "))}}
+	}
+	if ref == nil {
+		return nil
+	}
+	return schemaProblems(value, jsonPaths(text), first, ref, "json")
+}
+
+func checkYAMLBlock(block fencedBlock, ref *schemaRef) []fenceProblem {
+	if ellipsisRe.MatchString(strings.Join(block.Lines, "\n")) {
+		return nil
+	}
+	value, paths, err := parseYAML(block.Lines)
+	if err != nil {
+		var yerr *yamlError
+		if errors.As(err, &yerr) {
+			return []fenceProblem{{Line: block.Start + yerr.Line, Lang: "yaml", Description: fmt.Sprintf("Invalid YAML: %s.", yerr.Msg)}}
+		}
+		return nil
+	}
+	if ref == nil {
+		return nil
+	}
+	return schemaProblems(value, paths, block.Start+1, ref, "yaml")
+}
+
+func checkXMLBlock(block fencedBlock) []fenceProblem {
+	text := strings.Join(block.Lines, "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	dec := xml.NewDecoder(strings.NewReader(text))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			line := block.Start + 1
+			var syntax *xml.SyntaxError
+			if errors.As(err, &syntax) {
+				line = block.Start + syntax.Line
+			}
+			msg := err.Error()
+			if _, after, ok := strings.Cut(msg, ": "); ok && syntax != nil {
+				msg = after
+			}
+			return []fenceProblem{{Line: line, Lang: "xml", Description: fmt.Sprintf("Invalid XML: %s.", msg)}}
+		}
+	}
+}
+
+// checkHTTPBlock checks each request or response message in the block: the
+// start line, header lines and a JSON body.
+func checkHTTPBlock(block fencedBlock, ctx *fenceContext) []fenceProblem {
+	var problems []fenceProblem
+	lines := block.Lines
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	for i < len(lines) {
+		start := strings.TrimSpace(lines[i])
+		lineNo := block.Start + 1 + i
+		var bodySchema *schemaRef
+		switch {
+		case httpStatusLineRe.MatchString(start):
+			code := httpStatusLineRe.FindStringSubmatch(start)[1]
+			if ctx.lastOperation != nil {
+				bodySchema = ctx.lastOperation.response(code)
+			}
+		case httpRequestLineRe.MatchString(start) && httpMethods[httpRequestLineRe.FindStringSubmatch(start)[1]]:
+			match := httpRequestLineRe.FindStringSubmatch(start)
+			ctx.lastOperation = ctx.catalog.operation(match[1], requestPath(match[2]))
+			if ctx.lastOperation != nil {
+				bodySchema = ctx.lastOperation.Request
+			}
+		default:
+			return append(problems, fenceProblem{Line: lineNo, Lang: "http", Description: fmt.Sprintf("Invalid HTTP message: %q is not a request or status line.", start)})
+		}
+		i++
+		contentType := ""
+		for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			header := strings.TrimSpace(lines[i])
+			if ellipsisRe.MatchString(header) {
+				continue
+			}
+			if !httpHeaderRe.MatchString(header) {
+				problems = append(problems, fenceProblem{Line: block.Start + 1 + i, Lang: "http", Description: fmt.Sprintf("Invalid HTTP header line %q.", header)})
+				continue
+			}
+			if name, value, _ := strings.Cut(header, ":"); strings.EqualFold(name, "Content-Type") {
+				contentType = strings.ToLower(strings.TrimSpace(value))
+			}
+		}
+		bodyStart := i + 1
+		end := bodyStart
+		for end < len(lines) && !isHTTPStartLine(lines[end]) {
+			end++
+		}
+		if bodyStart < end {
+			body := lines[bodyStart:end]
+			trimmed := strings.TrimSpace(strings.Join(body, "\n"))
+			if strings.Contains(contentType, "json") || contentType == "" && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+				ref := ctx.schemaFor(block, bodySchema)
+				for _, problem := range checkJSONBlock(body, block.Start+1+bodyStart, ref) {
+					problem.Lang = "http"
+					problems = append(problems, problem)
+				}
+			}
+		}
+		i = end
+	}
+	return problems
+}
+
+func isHTTPStartLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if httpStatusLineRe.MatchString(trimmed) {
+		return true
+	}
+	match := httpRequestLineRe.FindStringSubmatch(trimmed)
+	return match != nil && httpMethods[match[1]]
+}
+
+// requestPath returns the path of a request target, which may be a full URL.
+func requestPath(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Path != "" {
+		return u.Path
+	}
+	path, _, _ := strings.Cut(target, "?")
+	return path
+}
+
+// schemaProblems validates value and reports each violation at the line of
+// its nearest located path.
+func schemaProblems(value any, paths map[string]int, first int, ref *schemaRef, lang string) []fenceProblem {
+	violations := validateSchema(value, *ref)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return pathLine(paths, violations[i].Path) < pathLine(paths, violations[j].Path)
+	})
+	var problems []fenceProblem
+	for _, v := range violations {
+		if len(problems) == maxSchemaFindings {
+			break
+		}
+		at := v.Path
+		if at == "" {
+			at = "the payload"
+		}
+		problems = append(problems, fenceProblem{
+			Line:        first + pathLine(paths, v.Path) - 1,
+			Lang:        lang,
+			Schema:      true,
+			Description: fmt.Sprintf("Example does not match %s: %s %s.", ref.Name, at, v.Message),
+		})
+	}
+	return problems
+}
+
+// pathLine returns the 1-based line of a JSON pointer, falling back to its
+// closest located parent.
+func pathLine(paths map[string]int, pointer string) int {
+	for {
+		if line, ok := paths[pointer]; ok {
+			return line
+		}
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			return 1
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// jsonPaths maps the JSON pointer of each object member and array element
+// in valid JSON to its 1-based line.
+func jsonPaths(text string) map[string]int {
+	type frame struct {
+		array bool
+		index int
+		key   string
+		path  string
+	}
+	paths := map[string]int{"": 1 + offsetLine(text, len(text)-len(strings.TrimLeft(text, " \t\r\n")))}
+	dec := json.NewDecoder(strings.NewReader(text))
+	var stack []*frame
+	expectKey := false
+	for {
+		before := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return paths
+		}
+		line := 1 + offsetLine(text, int(before)+len(text[before:])-len(strings.TrimLeft(text[before:], " \t\r\n,:")))
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if !top.array && expectKey {
+				if key, ok := tok.(string); ok {
+					top.key = key
+					paths[top.path+"/"+escapePointer(key)] = line
+					expectKey = false
+					continue
+				}
+			}
+		}
+		path := ""
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.array {
+				if d, ok := tok.(json.Delim); !ok || d != ']' {
+					path = fmt.Sprintf("%s/%d", top.path, top.index)
+					paths[path] = line
+					top.index++
+				}
+			} else {
+				path = top.path + "/" + escapePointer(top.key)
+			}
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{path: path})
+			expectKey = true
+			continue
+		case json.Delim('['):
+			stack = append(stack, &frame{array: true, path: path})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+		expectKey = len(stack) > 0 && !stack[len(stack)-1].array
+	}
+}
+
+// offsetLine returns the number of newlines before a byte offset.
+func offsetLine(text string, offset int) int {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	return strings.Count(text[:offset], "\n")
+}
@@ -0,0 +1,252 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	ctxpkg "github.com/dshills/speccritic/internal/context"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+func fence(lang string, lines ...string) string {
+	return "```" + lang + "\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+func TestFenceSyntaxRuleReportsExactLines(t *testing.T) {
+	text := strings.Join([]string{
+		"# Orders",
+		"A created order:",
+		fence("json", "{", `  "id": "ord_1",`, `  "total": 12.5`, `  "currency": "USD"`, "}"),
+		"Configuration:",
+		fence("yaml", "retries: 3", "backoff:", "  initial: 1s", "   max: 30s"),
+		"The feed entry:",
+		fence("xml", "<order>", "  <id>1</id>", "</orders>"),
+		"The request:",
+		fence("http", "POST /orders HTTP/1.1", "Content-Type: application/json", "Bad header", "", `{"id": 1,}`),
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	var lines []int
+	for _, issue := range result.Issues {
+		if issue.ID != "PREFLIGHT-FENCE-001" {
+			continue
+		}
+		if issue.Severity != schema.SeverityWarn || !hasTag(issue.Tags, TagPreflight) {
+			t.Fatalf("issue = %#v", issue)
+		}
+		lines = append(lines, issue.Evidence[0].LineStart)
+	}
+	// JSON missing comma, YAML indentation, XML mismatched tag, HTTP header
+	// and HTTP body.
+	want := []int{7, 15, 21, 27, 29}
+	if len(lines) != len(want) {
+		t.Fatalf("fence findings at lines %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("fence findings at lines %v, want %v", lines, want)
+		}
+	}
+}
+
+func TestFenceSyntaxRuleSkipsAbbreviatedAndInvalidExamples(t *testing.T) {
+	text := strings.Join([]string{
+		"Abbreviated:",
+		fence("json", `{"id": 1, ...}`),
+		"Add to `meta`:",
+		fence("json", `"completion": {`, `  "enabled": true`, `}`),
+		"The parser rejects this body:",
+		fence("json invalid", `{"id": }`),
+		"## Anti-patterns",
+		fence("json", `{"id": }`),
+		"## Invalid Payloads",
+		fence("json", `{"id": }`),
+		"Other languages are not checked:",
+		fence("go", "func {"),
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	if issue := findIssue(result.Issues, "PREFLIGHT-FENCE-001"); issue != nil {
+		t.Fatalf("unexpected issue %#v", issue)
+	}
+}
+
+func TestFenceSyntaxRuleChecksBadRequestResponses(t *testing.T) {
+	text := strings.Join([]string{
+		"## Errors",
+		"| Status | Meaning |",
+		"|--------|---------|",
+		"| 400 | Bad input |",
+		"",
+		"The service returns 400 Bad Request with this body:",
+		fence("json", `{"error": "bad_request",}`),
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	requireIssueAt(t, result.Issues, "PREFLIGHT-FENCE-001", 8)
+}
+
+func TestFenceSyntaxRuleSkipsRedactedAndUnsupportedBlocks(t *testing.T) {
+	text := strings.Join([]string{
+		"Secrets are redacted before preflight runs:",
+		fence("json", `{"[REDACTED]}`),
+		fence("yaml", "env:", "  ANTHROPIC_[REDACTED]"),
+		"Anchors and complex keys are valid YAML:",
+		fence("yaml", "base: &b", "  x: 1", "derived:", "  <<: *b", "  y: 2"),
+		fence("yaml", "? [a, b]", ": pair"),
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	if issue := findIssue(result.Issues, "PREFLIGHT-FENCE-001"); issue != nil {
+		t.Fatalf("unexpected issue %#v", issue)
+	}
+}
+
+const orderOpenAPI = `openapi: 3.0.3
+info:
+  title: Orders
+  version: "1"
+paths:
+  /orders/{id}:
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+        default:
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Error'}
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id, status]
+      additionalProperties: false
+      properties:
+        id: {type: string, pattern: '^ord_'}
+        status:
+          type: string
+          enum: [pending, paid]
+        total:
+          type: number
+          minimum: 0
+    Error:
+      type: object
+      required: [code]
+      properties:
+        code: {type: integer}
+`
+
+func TestFenceSchemaRuleValidatesAgainstOpenAPI(t *testing.T) {
+	text := strings.Join([]string{
+		"# Orders",
+		"An `Order` looks like:",
+		fence("json", "{", `  "id": "ord_1",`, `  "status": "shipped",`, `  "total": -1,`, `  "note": "x"`, "}"),
+		"Updating an order:",
+		fence("http", "PUT https://api.example.com/v1/orders/ord_1", "Content-Type: application/json", "", `{"id": "ord_1"}`),
+		"It fails with:",
+		fence("http", "HTTP/1.1 409 Conflict", "", `{"code": "conflict"}`),
+		"Unrelated data:",
+		fence("json", `{"status": "shipped"}`),
+	}, "\n")
+	s := spec.New("SPEC.md", text)
+	result, err := Run(s, Config{Enabled: true, Profile: "general", Context: []ctxpkg.ContextFile{{Path: "api/openapi.yaml", Content: orderOpenAPI}}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got []string
+	for _, issue := range result.Issues {
+		if issue.ID == "PREFLIGHT-FENCE-002" {
+			if issue.Category != schema.CategoryContradiction {
+				t.Fatalf("issue = %#v", issue)
+			}
+			got = append(got, issue.Description+" @"+strings.TrimSpace(issue.Evidence[0].Quote))
+		}
+	}
+	want := []string{
+		`Example does not match Order: /status is "shipped", want one of "pending", "paid". @"status": "shipped",`,
+		`Example does not match Order: /total is -1, below the minimum 0. @"total": -1,`,
+		`Example does not match Order: /note is not a defined property. @"note": "x"`,
+		`Example does not match PUT /orders/{id} request: the payload is missing required property "status". @{"id": "ord_1"}`,
+		`Example does not match PUT /orders/{id} default response: /code is a string, want integer. @{"code": "conflict"}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("schema findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFenceSchemaRuleUsesSchemaAttributeAndJSONSchemaFiles(t *testing.T) {
+	schemaFile := `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Invoice",
+		"type": "object", "properties": {"lines": {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/Line"}}},
+		"$defs": {"Line": {"type": "object", "required": ["sku"]}}}`
+	text := fence("yaml schema=Invoice", "lines:", "  - qty: 2")
+	result, err := Run(spec.New("SPEC.md", text), Config{Enabled: true, Profile: "general", Context: []ctxpkg.ContextFile{{Path: "invoice.schema.json", Content: schemaFile}}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-FENCE-002", schema.SeverityWarn, 3)
+	if issue.Description != `Example does not match Invoice: /lines/0 is missing required property "sku".` {
+		t.Fatalf("description = %q", issue.Description)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	value, paths, err := parseYAML(strings.Split(orderOpenAPI, "\n"))
+	if err != nil {
+		t.Fatalf("parseYAML: %v", err)
+	}
+	order := jsonPointer(value, "/components/schemas/Order")
+	if required := jsonPointer(order, "/required"); len(asSlice(required)) != 2 {
+		t.Fatalf("required = %#v", required)
+	}
+	if paths["/components/schemas/Order/properties/status/enum"] != 33 {
+		t.Fatalf("enum line = %d", paths["/components/schemas/Order/properties/status/enum"])
+	}
+
+	for _, tc := range []struct {
+		text string
+		line int
+		msg  string
+	}{
+		{"a: 1\na: 2", 2, "duplicate key"},
+		{"a:\n  b: 1\n c: 2", 3, "inconsistent indentation"},
+		{"a: 1\n  b: 2", 2, "unexpected indentation"},
+		{"a: b: c", 1, "mapping values are not allowed"},
+		{"items:\n  - one\n  two", 3, "inconsistent indentation"},
+		{"a: [1, 2", 1, "unclosed flow collection"},
+		{"a: \"open", 1, "unterminated quoted string"},
+		{"a:\n\tb: 1", 2, "tab character"},
+		{"just text\nkey: value", 2, "inconsistent indentation"},
+		{"base: &b\n  x: 1\n y: 2", 3, "inconsistent indentation"},
+	} {
+		_, _, err := parseYAML(strings.Split(tc.text, "\n"))
+		yerr, ok := err.(*yamlError)
+		if !ok || yerr.Line != tc.line || !strings.Contains(yerr.Msg, tc.msg) {
+			t.Fatalf("parseYAML(%q) error = %v, want line %d %q", tc.text, err, tc.line, tc.msg)
+		}
+	}
+
+	value, _, err = parseYAML(strings.Split("# comment\nlist:\n- a\n- b: 1\n  c: |\n    text\n    more\nflag: true # note", "\n"))
+	if err != nil {
+		t.Fatalf("parseYAML: %v", err)
+	}
+	if got := formatValue(value); got != `{"flag":true,"list":["a",{"b":1,"c":"text\nmore\n"}]}` {
+		t.Fatalf("value = %s", got)
+	}
+
+	value, _, err = parseYAML(strings.Split("base: &b\n  x: 1\nlist:\n- !item\n  y: 2", "\n"))
+	if err != nil {
+		t.Fatalf("parseYAML: %v", err)
+	}
+	if got := formatValue(value); got != `{"base":{"x":1},"list":[{"y":2}]}` {
+		t.Fatalf("value = %s", got)
+	}
+	if _, _, err := parseYAML([]string{"? key", ": value"}); err != errYAMLUnsupported {
+		t.Fatalf("parseYAML complex key error = %v, want errYAMLUnsupported", err)
+	}
+}
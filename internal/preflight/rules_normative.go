@@ -0,0 +1,300 @@
+package preflight
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+var (
+	rfc2119DeclarationRe = regexp.MustCompile(`(?i)\b(RFC\s*2119|RFC\s*8174|BCP\s*14)\b`)
+	normativeKeywordRe   = regexp.MustCompile(`(?i)\b(MUST|SHALL|SHOULD)(\s+NOT)?\b|\bMAY\b`)
+	securityTermRe       = regexp.MustCompile(`(?i)\b(authenticat\w*|authoriz\w*|auth|encrypt\w*|decrypt\w*|passwords?|credentials?|secrets?|tokens?|tls|ssl|certificates?|permissions?|access control|signatures?|verif\w*|sanitiz\w*|audit\w*|sessions?|csrf|privileges?|(api|private|signing) keys?)\b`)
+	exceptionPatterns    = compileWordishPatterns([]string{"unless", "except", "otherwise", "if", "when", "whenever", "where", "provided that", "in which case", "exception"})
+)
+
+// keywordLevels maps each keyword to its requirement level. MUST NOT and
+// SHALL NOT are as binding as MUST, so combining them is not mixing levels.
+var keywordLevels = map[string]string{
+	"MUST": "required", "MUST NOT": "required", "SHALL": "required", "SHALL NOT": "required",
+	"SHOULD": "recommended", "SHOULD NOT": "recommended",
+	"MAY": "optional",
+}
+
+type keywordUse struct {
+	Keyword string
+	Text    string
+}
+
+type normativeSentence struct {
+	sentence
+	Keywords  []keywordUse
+	Normative []keywordUse
+}
+
+type normativeAnalysis struct {
+	Declared bool
+	// Declarations holds the lines of the sentences declaring RFC 2119
+	// conventions.
+	Declarations map[int]bool
+	Sentences    []normativeSentence
+}
+
+// analyzeNormative finds the RFC 2119 keywords in each sentence, keeping
+// sentences without keywords so rules can read context. Only uppercase
+// keywords are normative: "may" and "must" in lowercase are ordinary prose
+// whether or not the spec declares RFC 2119 conventions. Declaration lines
+// themselves are skipped because they list every keyword.
+func analyzeNormative(sentences []sentence) normativeAnalysis {
+	analysis := normativeAnalysis{Declarations: make(map[int]bool)}
+	for _, s := range sentences {
+		if mayDeclare(s.Prose) && rfc2119DeclarationRe.MatchString(s.Prose) {
+			analysis.Declared = true
+			for line := s.Line; line <= s.LineEnd; line++ {
+				analysis.Declarations[line] = true
+			}
+		}
+	}
+	for _, s := range sentences {
+		if analysis.Declarations[s.Line] {
+			continue
+		}
+		ns := normativeSentence{sentence: s}
+		if !mayHaveKeyword(s.Prose) {
+			analysis.Sentences = append(analysis.Sentences, ns)
+			continue
+		}
+		for _, text := range normativeKeywordRe.FindAllString(s.Prose, -1) {
+			use := keywordUse{Keyword: strings.Join(strings.Fields(strings.ToUpper(text)), " "), Text: text}
+			ns.Keywords = append(ns.Keywords, use)
+			if text == strings.ToUpper(text) {
+				ns.Normative = append(ns.Normative, use)
+			}
+		}
+		analysis.Sentences = append(analysis.Sentences, ns)
+	}
+	return analysis
+}
+
+// mayHaveKeyword and mayDeclare are cheap checks that let most sentences
+// skip the case-insensitive patterns.
+func mayHaveKeyword(prose string) bool {
+	lower := strings.ToLower(prose)
+	return strings.Contains(lower, "must") || strings.Contains(lower, "shall") || strings.Contains(lower, "should") || strings.Contains(lower, "may")
+}
+
+func mayDeclare(prose string) bool {
+	lower := strings.ToLower(prose)
+	return strings.Contains(lower, "rfc") || strings.Contains(lower, "bcp")
+}
+
+func normativeRules() []Rule {
+	return []Rule{keywordCaseRule(), shouldExceptionRule(), securityMayRule(), mixedLevelRule()}
+}
+
+func keywordCaseRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-RFC2119-001",
+		Group:          "normative",
+		Title:          "Normative keyword is not uppercase",
+		Description:    "The spec declares RFC 2119 conventions but uses a keyword in lowercase or mixed case.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Readers cannot tell whether the sentence states a requirement or ordinary prose.",
+		Recommendation: "Write the keyword in uppercase if it is normative, or rephrase the sentence without it.",
+		Tags:           []string{"normative"},
+		Fixable:        true,
+		Matcher: MatcherFunc(func(doc Document, rule Rule, _ Config) []Finding {
+			analysis := doc.normative()
+			if !analysis.Declared {
+				return nil
+			}
+			var findings []Finding
+			for _, s := range analysis.Sentences {
+				for _, use := range s.Keywords {
+					if use.Text == strings.ToUpper(use.Text) {
+						continue
+					}
+					finding := normativeFinding(rule, s, use.Keyword)
+					finding.Fixes = uppercaseKeywords(s.sentence)
+					findings = append(findings, finding)
+					break
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+// uppercaseKeywords uppercases the sentence's lowercase "must" and "shall"
+// keywords. "should" and "may" are left alone: in lowercase they are
+// usually ordinary prose, as in "Users may notice a delay". Keywords in
+// inline code are left alone too.
+func uppercaseKeywords(s sentence) []Fix {
+	var fixes []Fix
+	for _, loc := range normativeKeywordRe.FindAllStringIndex(s.Prose, -1) {
+		text := s.Text[loc[0]:loc[1]]
+		if keywordLevels[strings.Join(strings.Fields(strings.ToUpper(text)), " ")] != "required" || text == strings.ToUpper(text) {
+			continue
+		}
+		fixes = append(fixes, Fix{Line: s.Line, Start: s.Start + loc[0], End: s.Start + loc[1], After: strings.ToUpper(text)})
+	}
+	return fixes
+}
+
+func shouldExceptionRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-RFC2119-002",
+		Group:          "normative",
+		Title:          "SHOULD has no stated exception",
+		Description:    "The requirement uses SHOULD without saying when it may be ignored.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Implementers cannot tell which deviations are acceptable, so SHOULD behaves as either MUST or MAY.",
+		Recommendation: "State the circumstances in which the behavior may be skipped, or use MUST or MAY instead.",
+		Tags:           []string{"normative"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, _ Config) []Finding {
+			analysis := doc.normative()
+			var findings []Finding
+			for i, s := range analysis.Sentences {
+				if !hasKeyword(s.Normative, "SHOULD", "SHOULD NOT") || hasException(s.Prose) {
+					continue
+				}
+				// The exception may follow in the next sentence, unless that
+				// sentence is another SHOULD requirement.
+				if i+1 < len(analysis.Sentences) {
+					next := analysis.Sentences[i+1]
+					if next.Line <= s.LineEnd+1 && !hasKeyword(next.Normative, "SHOULD", "SHOULD NOT") && hasException(next.Prose) {
+						continue
+					}
+				}
+				findings = append(findings, normativeFinding(rule, s, "SHOULD"))
+			}
+			return findings
+		}),
+	}
+}
+
+func securityMayRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-RFC2119-003",
+		Group:          "normative",
+		Title:          "Security behavior is optional",
+		Description:    "The requirement uses MAY for security-relevant behavior.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "An implementation can omit the security control and still comply with the spec.",
+		Recommendation: "Use MUST for security controls, or state the conditions under which the control may be omitted.",
+		Tags:           []string{"normative", "security"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, s := range doc.normative().Sentences {
+				if !hasKeyword(s.Normative, "MAY") || !securityTermRe.MatchString(s.Prose) {
+					continue
+				}
+				finding := normativeFinding(rule, s, "MAY")
+				if cfg.Strict {
+					finding.Severity = schema.SeverityCritical
+					finding.Blocking = true
+				}
+				findings = append(findings, finding)
+			}
+			return findings
+		}),
+	}
+}
+
+func mixedLevelRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-RFC2119-004",
+		Group:          "normative",
+		Title:          "Sentence mixes requirement levels",
+		Description:    "The sentence combines normative keywords of different strength.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "It is unclear which parts of the sentence are required and which are optional.",
+		Recommendation: "Split the sentence so each requirement carries a single keyword.",
+		Tags:           []string{"normative"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, _ Config) []Finding {
+			var findings []Finding
+			for _, s := range doc.normative().Sentences {
+				levels := make(map[string]bool)
+				var keywords []string
+				for _, use := range s.Normative {
+					levels[keywordLevels[use.Keyword]] = true
+					keywords = append(keywords, use.Keyword)
+				}
+				if len(levels) < 2 {
+					continue
+				}
+				findings = append(findings, normativeFinding(rule, s, keywords...))
+			}
+			return findings
+		}),
+	}
+}
+
+func normativeFinding(rule Rule, s normativeSentence, keywords ...string) Finding {
+	tags := []string{rule.Group}
+	for _, keyword := range keywords {
+		tags = append(tags, "keyword:"+keyword)
+	}
+	return Finding{LineStart: s.Line, LineEnd: s.LineEnd, Quote: s.Text, Tags: uniqueStrings(tags)}
+}
+
+func hasKeyword(uses []keywordUse, keywords ...string) bool {
+	for _, use := range uses {
+		for _, keyword := range keywords {
+			if use.Keyword == keyword {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasException(prose string) bool {
+	lower := strings.ToLower(prose)
+	for _, pattern := range exceptionPatterns {
+		if pattern.match(prose, lower) {
+			return true
+		}
+	}
+	return false
+}
+
+// normativeCounts counts normative keywords per section. Sections without
+// keywords are omitted, and nil is returned when the spec has none.
+func normativeCounts(doc Document) *schema.NormativeMeta {
+	analysis := doc.allNormative()
+	meta := &schema.NormativeMeta{RFC2119: analysis.Declared, Counts: make(map[string]int)}
+	sections := doc.headings()
+	next := 0
+	var current *schema.NormativeSection
+	for _, s := range analysis.Sentences {
+		if len(s.Normative) == 0 {
+			continue
+		}
+		if current == nil || next < len(sections) && sections[next].Line <= s.Line {
+			heading, start, end := "", 1, len(doc.Lines)
+			for next < len(sections) && sections[next].Line <= s.Line {
+				heading, start = sections[next].Text, sections[next].Line
+				next++
+			}
+			if next < len(sections) {
+				end = sections[next].Line - 1
+			}
+			meta.Sections = append(meta.Sections, schema.NormativeSection{Heading: heading, LineStart: start, LineEnd: end, Counts: make(map[string]int)})
+			current = &meta.Sections[len(meta.Sections)-1]
+		}
+		for _, use := range s.Normative {
+			meta.Counts[use.Keyword]++
+			current.Counts[use.Keyword]++
+		}
+	}
+	if len(meta.Counts) == 0 {
+		return nil
+	}
+	return meta
+}
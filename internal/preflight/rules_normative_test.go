@@ -0,0 +1,174 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+const rfc2119Declaration = "The key words MUST, MUST NOT, SHALL, SHOULD and MAY are to be interpreted as described in RFC 2119."
+
+func TestNormativeRuleFlagsLowercaseKeywordWhenDeclared(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		rfc2119Declaration,
+		"The service MUST log every upload.",
+		"The service Must reject empty files.",
+	}, "\n"))
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-RFC2119-001", schema.SeverityWarn, 3)
+	if !hasTag(issue.Tags, "keyword:MUST") || issue.Evidence[0].Quote != "The service Must reject empty files." {
+		t.Fatalf("issue = %#v", issue)
+	}
+	if countIssues(result.Issues, "PREFLIGHT-RFC2119-001") != 1 {
+		t.Fatalf("issues = %#v, want one case finding", result.Issues)
+	}
+}
+
+func TestNormativeRuleIgnoresLowercaseWithoutDeclaration(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", "The service must reject empty files.")
+	if findIssue(result.Issues, "PREFLIGHT-RFC2119-001") != nil {
+		t.Fatal("case rule fired without an RFC 2119 declaration")
+	}
+}
+
+func TestNormativeRuleFlagsShouldWithoutException(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The client SHOULD cache responses.",
+		"The client SHOULD retry unless the server returns 4xx.",
+		"The client SHOULD compress uploads. This may be skipped when the payload is under 1 KB.",
+		"The client SHOULD sign requests.",
+		"Otherwise the gateway rejects them.",
+	}, "\n"))
+	requireIssue(t, result.Issues, "PREFLIGHT-RFC2119-002", schema.SeverityWarn, 1)
+	if countIssues(result.Issues, "PREFLIGHT-RFC2119-002") != 1 {
+		t.Fatalf("issues = %#v, want only line 1", result.Issues)
+	}
+}
+
+func TestNormativeRuleFlagsOptionalSecurityBehavior(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", "The gateway MAY verify request signatures.\nThe gateway MAY cache thumbnails.")
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-RFC2119-003", schema.SeverityWarn, 1)
+	if !hasTag(issue.Tags, "security") || countIssues(result.Issues, "PREFLIGHT-RFC2119-003") != 1 {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+
+	strict, err := Run(spec.New("SPEC.md", "Clients MAY send an API key."), Config{Enabled: true, Profile: "general", Strict: true})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if issue := requireIssue(t, strict.Issues, "PREFLIGHT-RFC2119-003", schema.SeverityCritical, 1); !issue.Blocking {
+		t.Fatal("strict optional security behavior should be blocking")
+	}
+}
+
+func TestNormativeRuleFlagsMixedRequirementLevels(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The service MUST store the file and MAY index it.",
+		"The service MUST accept JSON and MUST NOT accept XML.",
+	}, "\n"))
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-RFC2119-004", schema.SeverityWarn, 1)
+	if !hasTag(issue.Tags, "keyword:MUST") || !hasTag(issue.Tags, "keyword:MAY") {
+		t.Fatalf("tags = %v", issue.Tags)
+	}
+	if countIssues(result.Issues, "PREFLIGHT-RFC2119-004") != 1 {
+		t.Fatalf("issues = %#v, want only line 1", result.Issues)
+	}
+}
+
+func TestNormativeRulesSkipCodeAndExamples(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"## Requirements",
+		"Set `SHOULD_RETRY` to enable retries.",
+		"```",
+		"The client SHOULD cache responses.",
+		"```",
+		"## Examples",
+		"The client MAY skip authentication.",
+	}, "\n"))
+	for _, issue := range result.Issues {
+		if strings.HasPrefix(issue.ID, "PREFLIGHT-RFC2119-") {
+			t.Fatalf("normative rule fired on code or example: %#v", issue)
+		}
+	}
+}
+
+func TestNormativeCountsPerSection(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"# Upload",
+		rfc2119Declaration,
+		"## Limits",
+		"Files MUST NOT exceed 10 MB. Uploads MUST be scanned.",
+		"Clients may retry.",
+		"## Notes",
+		"None.",
+		"## Retention",
+		"Files SHOULD be kept 30 days unless deleted.",
+	}, "\n"))
+	got := result.Normative
+	if got == nil || !got.RFC2119 {
+		t.Fatalf("normative = %#v", got)
+	}
+	if got.Counts["MUST"] != 1 || got.Counts["MUST NOT"] != 1 || got.Counts["SHOULD"] != 1 || got.Counts["MAY"] != 0 {
+		t.Fatalf("counts = %v", got.Counts)
+	}
+	if len(got.Sections) != 2 {
+		t.Fatalf("sections = %#v", got.Sections)
+	}
+	limits, retention := got.Sections[0], got.Sections[1]
+	if limits.Heading != "Limits" || limits.LineStart != 3 || limits.LineEnd != 5 || limits.Counts["MUST"] != 1 {
+		t.Fatalf("limits = %#v", limits)
+	}
+	if retention.Heading != "Retention" || retention.LineStart != 8 || retention.LineEnd != 9 || retention.Counts["SHOULD"] != 1 {
+		t.Fatalf("retention = %#v", retention)
+	}
+
+	if empty := runBuiltin(t, "SPEC.md", "# Upload\nNothing normative here."); empty.Normative != nil {
+		t.Fatalf("normative = %#v, want nil", empty.Normative)
+	}
+}
+
+func countIssues(issues []schema.Issue, id string) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.ID == id {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNormativeRulesIgnoreLowercaseKeywordsWithoutDeclaration(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The session may expire at any time.",
+		"This may be slow, and the API must respond within 2 s.",
+	}, "\n"))
+	for _, issue := range result.Issues {
+		if strings.HasPrefix(issue.ID, "PREFLIGHT-RFC2119-") {
+			t.Fatalf("normative rule fired on lowercase prose: %#v", issue)
+		}
+	}
+	if result.Normative != nil {
+		t.Fatalf("normative = %#v, want nil", result.Normative)
+	}
+}
+
+func TestNormativeRulesReadSentencesAcrossWrappedLines(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The service MUST store every uploaded file and",
+		"MAY index it for search.",
+		"",
+		"- The client SHOULD retry failed uploads",
+		"  three times.",
+		"- The client SHOULD compress uploads",
+		"  unless the payload is under 1 KB.",
+	}, "\n"))
+	mixed := requireIssue(t, result.Issues, "PREFLIGHT-RFC2119-004", schema.SeverityWarn, 1)
+	if mixed.Evidence[0].LineEnd != 2 || mixed.Evidence[0].Quote != "The service MUST store every uploaded file and\nMAY index it for search." {
+		t.Fatalf("evidence = %#v", mixed.Evidence[0])
+	}
+	should := requireIssue(t, result.Issues, "PREFLIGHT-RFC2119-002", schema.SeverityWarn, 4)
+	if should.Evidence[0].LineEnd != 5 || countIssues(result.Issues, "PREFLIGHT-RFC2119-002") != 1 {
+		t.Fatalf("issues = %#v, want only the retry requirement", result.Issues)
+	}
+}
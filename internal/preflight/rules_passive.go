@@ -0,0 +1,84 @@
+package preflight
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// passiveRe matches a normative passive: a modal, "be" and a past
+// participle, as in "must be validated" or "will be sent". Group 1 is the
+// participle. Prohibitions such as "must not be logged" bind every
+// component, so they are not matched.
+var passiveRe = regexp.MustCompile(`(?i)\b(?:must|shall|should|will)(?:\s+\w+ly)?\s+be\s+(\w+ed|sent|kept|held|built|set|put|shown|known|made|done|given|taken|written|hidden|run|sold|found|paid|split|spent|told|thrown|chosen|overridden|rewritten|withheld)\b`)
+
+// passiveAgentRe matches the "by ..." phrase that names who performs a
+// passive action, up to four words.
+var passiveAgentRe = regexp.MustCompile(`(?i)^[^.;!?]*?\bby\s+((?:[\w-]+\s+){0,3}[\w-]+)`)
+
+// statives are participles that describe a state rather than an action, so
+// "must be based on" or "will be limited to" have no missing actor.
+var statives = map[string]bool{
+	"based": true, "limited": true, "bounded": true, "capped": true, "formatted": true,
+	"encoded": true, "expressed": true, "named": true, "considered": true, "interpreted": true,
+	"treated": true, "located": true, "contained": true, "defined": true, "prefixed": true,
+	"suffixed": true, "sorted": true, "ordered": true, "scoped": true, "sized": true,
+	"used": true, "required": true, "allowed": true, "permitted": true, "supported": true,
+	"expected": true, "satisfied": true, "exceeded": true, "reached": true, "exposed": true,
+	"unchanged": true, "included": true, "excluded": true, "omitted": true, "set": true,
+}
+
+// builtinActors count as a valid agent in "by the ..." phrases. Configured
+// actors extend the list.
+var builtinActors = []string{
+	"system", "service", "server", "client", "user", "caller", "application", "app", "api",
+	"gateway", "backend", "frontend", "browser", "worker", "scheduler", "administrator",
+	"admin", "operator", "cli", "database", "sdk", "implementation",
+}
+
+func passiveVoiceRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-PASSIVE-001",
+		Group:          "passive-voice",
+		Title:          "Requirement does not say who acts",
+		Description:    "The requirement is in the passive voice and does not name the component that performs the action.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Implementers may each assume another component is responsible, so the behavior is built twice or not at all.",
+		Recommendation: "Name the actor, for example \"the API MUST validate the request\", or add \"by the <component>\".",
+		Tags:           []string{"passive-voice"},
+		Matcher: linePatternMatcher([]textPattern{{term: "passive", pattern: passiveRe}}, true, func(f Finding, cfg Config) (Finding, bool) {
+			return f, hasActorlessPassive(f.Quote, cfg.SystemActors)
+		}),
+	}
+}
+
+// hasActorlessPassive reports whether line holds a normative passive whose
+// participle is an action and whose sentence names no system actor with
+// "by".
+func hasActorlessPassive(line string, actors []string) bool {
+	prose := blankInlineCode(line)
+	for _, m := range passiveRe.FindAllStringSubmatchIndex(prose, -1) {
+		if statives[strings.ToLower(prose[m[2]:m[3]])] {
+			continue
+		}
+		agent := passiveAgentRe.FindStringSubmatch(prose[m[1]:])
+		if agent == nil || !namesActor(strings.ToLower(agent[1]), actors) {
+			return true
+		}
+	}
+	return false
+}
+
+func namesActor(phrase string, actors []string) bool {
+	for _, list := range [][]string{builtinActors, actors} {
+		for _, actor := range list {
+			actor = strings.ToLower(strings.TrimSpace(actor))
+			if actor != "" && (containsWordish(phrase, actor) || containsWordish(phrase, actor+"s")) {
+				return true
+			}
+		}
+	}
+	return false
+}
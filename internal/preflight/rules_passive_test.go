@@ -0,0 +1,70 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+func TestPassiveVoiceRuleFlagsActorlessRequirements(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"# Requests",
+		"The request shall be validated.",
+		"Data will be encrypted at rest.",
+		"The token MUST be rotated by the auth service.",
+		"The payload must be signed by a key from the vault.",
+		"The response must be sent within 2s.",
+	}, "\n"))
+	var lines []int
+	for _, issue := range result.Issues {
+		if issue.ID != "PREFLIGHT-PASSIVE-001" {
+			continue
+		}
+		if issue.Severity != schema.SeverityWarn || issue.Category != schema.CategoryAmbiguousBehavior || !hasTag(issue.Tags, "passive-voice") {
+			t.Fatalf("issue = %#v", issue)
+		}
+		lines = append(lines, issue.Evidence[0].LineStart)
+	}
+	// "by a key" names an instrument, not an actor.
+	want := []int{2, 3, 5, 6}
+	if len(lines) != len(want) {
+		t.Fatalf("passive findings at lines %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("passive findings at lines %v, want %v", lines, want)
+		}
+	}
+}
+
+func TestPassiveVoiceRuleIgnoresStativesProhibitionsAndExamples(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"# Limits",
+		"Names must be limited to 64 characters.",
+		"Passwords MUST NOT be logged.",
+		"Retries may be disabled.",
+		"The `id` field must be set to a UUID.",
+		"Requests will be rejected by the gateway with 429.",
+		"## Bad Wording Examples",
+		"The record shall be deleted.",
+	}, "\n"))
+	if issue := findIssue(result.Issues, "PREFLIGHT-PASSIVE-001"); issue != nil {
+		t.Fatalf("unexpected issue %#v", issue)
+	}
+}
+
+func TestPassiveVoiceRuleUsesConfiguredActors(t *testing.T) {
+	text := "Balances must be recalculated by the ledger nightly."
+	result := runBuiltin(t, "SPEC.md", text)
+	requireIssue(t, result.Issues, "PREFLIGHT-PASSIVE-001", schema.SeverityWarn, 1)
+
+	result, err := Run(spec.New("SPEC.md", text), Config{Enabled: true, Profile: "general", SystemActors: []string{"Ledger"}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if issue := findIssue(result.Issues, "PREFLIGHT-PASSIVE-001"); issue != nil {
+		t.Fatalf("unexpected issue %#v", issue)
+	}
+}
@@ -0,0 +1,390 @@
+package preflight
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// unit is one unit a quantity can be stated in, with its factor to the
+// dimension's base unit: milliseconds, bytes, percent, requests per second
+// or a plain count.
+type unit struct {
+	Dimension string
+	Name      string
+	Factor    float64
+}
+
+var quantityUnits = map[string]unit{
+	"ns": {"time", "ns", 1e-6}, "µs": {"time", "µs", 1e-3}, "us": {"time", "µs", 1e-3},
+	"ms": {"time", "ms", 1}, "msec": {"time", "ms", 1}, "msecs": {"time", "ms", 1}, "millisecond": {"time", "ms", 1}, "milliseconds": {"time", "ms", 1},
+	"s": {"time", "s", 1e3}, "sec": {"time", "s", 1e3}, "secs": {"time", "s", 1e3}, "second": {"time", "s", 1e3}, "seconds": {"time", "s", 1e3},
+	"min": {"time", "min", 6e4}, "mins": {"time", "min", 6e4}, "minute": {"time", "min", 6e4}, "minutes": {"time", "min", 6e4},
+	"h": {"time", "h", 36e5}, "hr": {"time", "h", 36e5}, "hrs": {"time", "h", 36e5}, "hour": {"time", "h", 36e5}, "hours": {"time", "h", 36e5},
+	"day": {"time", "d", 864e5}, "days": {"time", "d", 864e5}, "week": {"time", "w", 6048e5}, "weeks": {"time", "w", 6048e5},
+	"b": {"size", "B", 1}, "byte": {"size", "B", 1}, "bytes": {"size", "B", 1},
+	"kb": {"size", "KB", 1e3}, "mb": {"size", "MB", 1e6}, "gb": {"size", "GB", 1e9}, "tb": {"size", "TB", 1e12},
+	"kib": {"size", "KiB", 1 << 10}, "mib": {"size", "MiB", 1 << 20}, "gib": {"size", "GiB", 1 << 30}, "tib": {"size", "TiB", 1 << 40},
+	"%": {"percent", "%", 1}, "percent": {"percent", "%", 1},
+	"rps": {"rate", "/s", 1}, "qps": {"rate", "/s", 1},
+	"retries": {"count", "count", 1}, "attempts": {"count", "count", 1}, "times": {"count", "count", 1},
+}
+
+var (
+	quantityRe = regexp.MustCompile(`(?i)(\d{1,3}(?:,\d{3})+|\d+(?:\.\d+)?)(\s?)(` +
+		`(?:requests?|req|queries|calls)\s*(?:/\s*|per\s+)(?:second|sec|s|minute|min|hour)|` +
+		`milliseconds?|msecs?|seconds?|secs?|minutes?|mins?|hours?|hrs?|days?|weeks?|` +
+		`bytes?|[kmgt]i?b|percent|rps|qps|retries|attempts|times|ns|µs|us|ms|min|%|s|h|b)`)
+	ratePeriodRe     = regexp.MustCompile(`(?i)(second|sec|s|minute|min|hour)$`)
+	qualifierRe      = regexp.MustCompile(`([A-Za-z][\w-]*)\s+$`)
+	subjectRe        = regexp.MustCompile(`(?i)^\s+(?:for|of|on|in)\s+(?:the\s+|an?\s+)?([^\s,;:.]+)`)
+	trailingRe       = regexp.MustCompile(`(?i)^\s+(?:for|of)\s+(?:the\s+|an?\s+)?([^\s,;:.]+)`)
+	minimumBoundRe   = regexp.MustCompile(`(?i)\b(?:at least|minimum(?: of)?|no less than|no fewer than|not less than)\s*$|>=?\s*$`)
+	quantityStopword = map[string]bool{
+		"a": true, "an": true, "the": true, "of": true, "per": true, "its": true, "their": true, "this": true, "that": true,
+		"each": true, "every": true, "any": true, "default": true, "max": true, "maximum": true, "min": true, "minimum": true,
+		"total": true, "overall": true, "same": true, "new": true, "is": true, "are": true, "and": true, "or": true,
+	}
+)
+
+// quantityTerms are the measurement nouns a quantity can be keyed by.
+var quantityTerms = map[string]bool{
+	"timeout": true, "timeouts": true, "latency": true, "latencies": true, "ttl": true, "ttls": true,
+	"expiry": true, "expiration": true, "lifetime": true, "retention": true, "interval": true, "intervals": true,
+	"delay": true, "delays": true, "backoff": true, "deadline": true, "deadlines": true, "duration": true, "durations": true,
+	"window": true, "windows": true, "size": true, "sizes": true, "limit": true, "limits": true, "quota": true, "quotas": true,
+	"rate": true, "rates": true, "throughput": true, "availability": true, "uptime": true, "retries": true, "retry": true,
+	"attempt": true, "attempts": true, "capacity": true, "bandwidth": true,
+}
+
+// maxTermDistance is how far, in bytes, a quantity may sit from the noun it
+// is keyed by.
+const maxTermDistance = 48
+
+// quantity is one stated value, keyed by the noun it modifies.
+type quantity struct {
+	Key   string
+	Unit  unit
+	Value float64
+	Text  string
+	Line  int
+}
+
+func quantityRules() []Rule {
+	return []Rule{conflictingQuantityRule(), mixedUnitRule()}
+}
+
+func conflictingQuantityRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-QUANTITY-001",
+		Group:          "quantity",
+		Title:          "Quantity is stated with conflicting values",
+		Description:    "The same quantity is given different values in different places.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryContradiction,
+		Impact:         "Implementers will pick one value, and tests written from another part of the spec will fail.",
+		Recommendation: "State the value once and refer to it, or qualify each value so they describe different quantities.",
+		Tags:           []string{"quantity"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, group := range doc.quantityGroups() {
+				if distinctValues(group) > 1 {
+					findings = append(findings, quantityFinding(rule, group))
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func mixedUnitRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-QUANTITY-002",
+		Group:          "quantity",
+		Title:          "Quantity is stated in mixed units",
+		Description:    "The same quantity is given in different units.",
+		Severity:       schema.SeverityInfo,
+		Category:       schema.CategoryUnspecifiedConstraint,
+		Impact:         "Readers must convert between units to confirm the values agree, and later edits may update only one.",
+		Recommendation: "Use one unit for the quantity throughout the spec.",
+		Tags:           []string{"quantity"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, group := range doc.quantityGroups() {
+				if distinctValues(group) == 1 && distinctUnits(group) > 1 {
+					findings = append(findings, quantityFinding(rule, group))
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+// quantityGroups returns the quantities stated more than once under the
+// same key, dimension and bound, in line order. A sentence that states one
+// key several times gives a range or tiers, so it is left out of that key.
+func quantityGroups(sentences []sentence) [][]quantity {
+	groups := make(map[string][]quantity)
+	for _, s := range sentences {
+		found := sentenceQuantities(s)
+		perKey := make(map[string]int)
+		for _, q := range found {
+			perKey[q.Key]++
+		}
+		for _, q := range found {
+			if perKey[q.Key] == 1 {
+				groups[q.Key] = append(groups[q.Key], q)
+			}
+		}
+	}
+	keys := make([]string, 0, len(groups))
+	for key, group := range groups {
+		if len(group) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := groups[keys[i]], groups[keys[j]]
+		if a[0].Line != b[0].Line {
+			return a[0].Line < b[0].Line
+		}
+		return keys[i] < keys[j]
+	})
+	out := make([][]quantity, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, groups[key])
+	}
+	return out
+}
+
+// sentenceQuantities extracts the quantities in a sentence that sit near a
+// measurement noun. The key is the noun with the word before it, such as
+// "session timeout", plus the dimension and whether the value is a minimum.
+func sentenceQuantities(s sentence) []quantity {
+	prose := s.Prose
+	terms := measurementTerms(prose)
+	if len(terms) == 0 {
+		return nil
+	}
+	var out []quantity
+	for _, loc := range quantityRe.FindAllStringSubmatchIndex(prose, -1) {
+		if !quantityBoundary(prose, loc[0], loc[1]) {
+			continue
+		}
+		number := strings.ReplaceAll(prose[loc[2]:loc[3]], ",", "")
+		unitText := strings.ToLower(prose[loc[6]:loc[7]])
+		if utf8.RuneCountInString(unitText) == 1 && unitText != "%" && loc[5] > loc[4] {
+			// "30 s" is rare; "5 h" and "2 b" are usually prose.
+			continue
+		}
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			continue
+		}
+		u, ok := quantityUnit(unitText)
+		if !ok {
+			continue
+		}
+		term, ok := nearestTerm(prose, terms, loc[0], loc[1])
+		if !ok {
+			continue
+		}
+		key := u.Dimension + ":" + term
+		if minimumBoundRe.MatchString(prose[:loc[0]]) {
+			key += ":min"
+		}
+		out = append(out, quantity{Key: key, Unit: u, Value: value * u.Factor, Text: prose[loc[0]:loc[1]], Line: s.Line})
+	}
+	return out
+}
+
+// measurementTerms returns the byte ranges of the measurement nouns in
+// prose, matching whole words in any case. The verb "time out" counts as
+// the noun "timeout", so "Requests time out after 30 s" is keyed.
+func measurementTerms(prose string) [][]int {
+	var terms [][]int
+	for i := 0; i < len(prose); {
+		if !isWordByte(prose[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(prose) && isWordByte(prose[i]) {
+			i++
+		}
+		word := strings.ToLower(prose[start:i])
+		if i-start > len("availability") {
+			continue
+		}
+		if quantityTerms[word] {
+			terms = append(terms, []int{start, i})
+			continue
+		}
+		if word == "time" || word == "times" || word == "timed" {
+			if end := i + timeOutLen(prose[i:]); end > i {
+				terms = append(terms, []int{start, end})
+				i = end
+			}
+		}
+	}
+	return terms
+}
+
+// timeOutLen returns the length of the " out" that follows a form of
+// "time", or 0.
+func timeOutLen(rest string) int {
+	n := len(rest) - len(strings.TrimLeft(rest, " \t\n"))
+	if n == 0 || len(rest) < n+3 || !strings.EqualFold(rest[n:n+3], "out") || len(rest) > n+3 && isWordByte(rest[n+3]) {
+		return 0
+	}
+	return n + 3
+}
+
+// quantityBoundary rejects numbers inside identifiers and versions, such as
+// REQ-001 or v1.5s, and units that run into a longer word.
+func quantityBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func quantityUnit(text string) (unit, bool) {
+	if u, ok := quantityUnits[text]; ok {
+		return u, true
+	}
+	period := ratePeriodRe.FindString(text)
+	if period == "" {
+		return unit{}, false
+	}
+	switch period {
+	case "minute", "min":
+		return unit{"rate", "/min", 1.0 / 60}, true
+	case "hour":
+		return unit{"rate", "/h", 1.0 / 3600}, true
+	}
+	return unit{"rate", "/s", 1}, true
+}
+
+// nearestTerm returns the key of the measurement noun closest to the
+// quantity at prose[start:end]. The key keeps the word before the noun and
+// the subject after it, so "P99 latency for /login" and "P99 latency for
+// /validate" are different quantities. A "for" or "of" subject after the
+// quantity is kept too, so "15 minutes for admins" and "60 minutes for
+// guests" are.
+func nearestTerm(prose string, terms [][]int, start, end int) (string, bool) {
+	best, bestDistance := -1, maxTermDistance+1
+	for i, term := range terms {
+		distance := 0
+		switch {
+		case term[1] <= start:
+			distance = start - term[1]
+		case term[0] >= end:
+			distance = term[0] - end
+		}
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best < 0 {
+		return "", false
+	}
+	term := terms[best]
+	key := "timeout"
+	if word := strings.ToLower(prose[term[0]:term[1]]); quantityTerms[word] {
+		key = singular(word)
+	}
+	if match := qualifierRe.FindStringSubmatch(prose[:term[0]]); match != nil && !quantityStopword[strings.ToLower(match[1])] {
+		key = singular(strings.ToLower(match[1])) + " " + key
+	}
+	if match := subjectRe.FindStringSubmatch(prose[term[1]:]); match != nil && !unicode.IsDigit(rune(match[1][0])) {
+		key += " " + strings.ToLower(strings.TrimSpace(match[0]))
+	}
+	if term[1] <= start {
+		if match := trailingRe.FindStringSubmatch(prose[end:]); match != nil && !unicode.IsDigit(rune(match[1][0])) {
+			key += " " + strings.ToLower(strings.TrimSpace(match[0]))
+		}
+	}
+	return key, true
+}
+
+func distinctValues(group []quantity) int {
+	var values []float64
+	for _, q := range group {
+		seen := false
+		for _, v := range values {
+			if math.Abs(v-q.Value) <= 1e-9*math.Max(math.Abs(v), math.Abs(q.Value)) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			values = append(values, q.Value)
+		}
+	}
+	return len(values)
+}
+
+func distinctUnits(group []quantity) int {
+	units := make(map[string]bool)
+	for _, q := range group {
+		units[q.Unit.Name] = true
+	}
+	return len(units)
+}
+
+// quantityFinding cites the first statement of each distinct value and
+// unit.
+func quantityFinding(rule Rule, group []quantity) Finding {
+	var cited []quantity
+	seen := make(map[string]bool)
+	for _, q := range group {
+		id := strconv.FormatFloat(q.Value, 'g', -1, 64) + q.Unit.Name
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		cited = append(cited, q)
+	}
+	parts := make([]string, len(cited))
+	for i, q := range cited {
+		parts[i] = fmt.Sprintf("%q (line %d)", q.Text, q.Line)
+	}
+	_, term, _ := strings.Cut(group[0].Key, ":")
+	term, minimum := strings.CutSuffix(term, ":min")
+	if minimum {
+		term = "minimum " + term
+	}
+	finding := Finding{
+		LineStart:   cited[0].Line,
+		Description: fmt.Sprintf("The %s is stated as %s.", term, joinList(parts)),
+		Tags:        []string{rule.Group, "quantity:" + term},
+	}
+	for _, q := range cited[1:] {
+		finding.Related = append(finding.Related, Location{LineStart: q.Line})
+	}
+	return finding
+}
+
+func joinList(items []string) string {
+	if len(items) < 3 {
+		return strings.Join(items, " and ")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
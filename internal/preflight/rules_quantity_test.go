@@ -0,0 +1,99 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestQuantityRulesFlagConflictingValues(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"## Upload",
+		"The request timeout is 30s.",
+		"Clients retry at most 3 times.",
+		"## Operations",
+		"A request timeout of 30000 ms applies to every call.",
+		"Upstream calls use a request timeout of 1 minute.",
+		"Clients retry at most 5 times.",
+	}, "\n"))
+	issue := findIssue(result.Issues, "PREFLIGHT-QUANTITY-001")
+	if issue == nil || countIssues(result.Issues, "PREFLIGHT-QUANTITY-001") != 2 {
+		t.Fatalf("issues = %#v, want timeout and retry conflicts", result.Issues)
+	}
+	if issue.Severity != schema.SeverityWarn || issue.Category != schema.CategoryContradiction {
+		t.Fatalf("issue = %#v", issue)
+	}
+	want := `The request timeout is stated as "30s" (line 2), "30000 ms" (line 5) and "1 minute" (line 6).`
+	if issue.Description != want {
+		t.Fatalf("description = %q, want %q", issue.Description, want)
+	}
+	if len(issue.Evidence) != 3 || issue.Evidence[2].LineStart != 6 {
+		t.Fatalf("evidence = %#v", issue.Evidence)
+	}
+	if findIssue(result.Issues, "PREFLIGHT-QUANTITY-002") != nil {
+		t.Fatal("mixed units reported alongside a conflict")
+	}
+}
+
+func TestQuantityRulesFlagMixedUnits(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", "Uploads have a size limit of 10 MB.\nThe API rejects bodies over the size limit of 10,000,000 bytes.\n")
+	issue := requireIssueAt(t, result.Issues, "PREFLIGHT-QUANTITY-002", 1)
+	if issue.Severity != schema.SeverityInfo || issue.Category != schema.CategoryUnspecifiedConstraint || !hasTag(issue.Tags, "quantity:size limit") {
+		t.Fatalf("issue = %#v", issue)
+	}
+	if findIssue(result.Issues, "PREFLIGHT-QUANTITY-001") != nil {
+		t.Fatal("equal values reported as a conflict")
+	}
+}
+
+func TestQuantityRulesKeepDistinctQuantitiesApart(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"P99 latency for /login is 200 ms.",
+		"P99 latency for /validate is 50 ms.",
+		"The session timeout is 15 minutes and the request timeout is 30s.",
+		"The idle timeout is 5 minutes.",
+		"Backoff is at least 2s.",
+		"Backoff is 10s.",
+		"Retention is between 7 days and 30 days.",
+		"Retention is 30 days.",
+		"## Examples",
+		"The request timeout is 45s.",
+		"```",
+		"timeout: 5s",
+		"```",
+	}, "\n"))
+	for _, id := range []string{"PREFLIGHT-QUANTITY-001", "PREFLIGHT-QUANTITY-002"} {
+		if issue := findIssue(result.Issues, id); issue != nil {
+			t.Fatalf("unexpected %s: %#v", id, issue)
+		}
+	}
+}
+
+func TestQuantityRulesKeyTrailingSubjects(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The session timeout is 15 minutes for admins.",
+		"The session timeout is 60 minutes for guests.",
+		"The rate limit is 100 rps for the free tier.",
+		"The rate limit is 1000 rps for the paid tier.",
+	}, "\n"))
+	if issue := findIssue(result.Issues, "PREFLIGHT-QUANTITY-001"); issue != nil {
+		t.Fatalf("unexpected conflict %#v", issue)
+	}
+}
+
+func TestQuantityRulesReadTimeOutAsTimeout(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", "Requests time out after 30000 ms.\nThe request timeout is 10s.\n")
+	issue := requireIssueAt(t, result.Issues, "PREFLIGHT-QUANTITY-001", 1)
+	if !hasTag(issue.Tags, "quantity:request timeout") || len(issue.Evidence) != 2 {
+		t.Fatalf("issue = %#v", issue)
+	}
+}
+
+func TestQuantityRulesNormalizeRates(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", "The rate limit is 100 requests per second.\nThe gateway enforces a rate limit of 6000 requests per minute.\nThe rate limit is 120 rps.\n")
+	issue := findIssue(result.Issues, "PREFLIGHT-QUANTITY-001")
+	if issue == nil || len(issue.Evidence) != 3 {
+		t.Fatalf("issue = %#v, want 100/s, 6000/min and 120/s cited", issue)
+	}
+}
@@ -0,0 +1,443 @@
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/chunk"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// defaultRequirementIDPattern matches IDs such as REQ-001, FR-12 and
+// API-AUTH-3.
+const defaultRequirementIDPattern = `\b[A-Z][A-Z0-9]+(?:-[A-Z][A-Z0-9]*)*-\d+\b`
+
+// ignoredRequirementFamilies are ID-shaped tokens the default pattern must
+// not treat as requirements: SpecCritic's own IDs and common standards.
+var ignoredRequirementFamilies = map[string]bool{
+	"ISSUE": true, "PREFLIGHT": true, "CHUNK": true,
+	"RFC": true, "BCP": true, "ISO": true, "IEC": true, "IEEE": true, "ECMA": true,
+	"UTF": true, "SHA": true, "MD": true, "AES": true, "RSA": true, "HTTP": true, "TLS": true, "SSL": true,
+	"CVE": true, "CWE": true, "UTC": true, "GMT": true,
+}
+
+var (
+	requirementNumberRe = regexp.MustCompile(`^(.*?)(\d+)$`)
+	listMarkerRe        = regexp.MustCompile(`^\s*(?:>\s*)*(?:[-*+]\s+|\d+[.)]\s+)?(?:\[[ xX]\]\s+)?`)
+	acceptanceTerms     = normalizeTerms([]string{"acceptance criteria", "acceptance", "testability", "tests", "testing", "verification"})
+)
+
+// compileRequirementIDPatterns compiles the configured requirement ID
+// patterns, or the default pattern when none are configured.
+func compileRequirementIDPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return []*regexp.Regexp{regexp.MustCompile(defaultRequirementIDPattern)}, nil
+	}
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requirement ID pattern %q: %w", pattern, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+type requirementUse struct {
+	ID         string
+	Line       int
+	Definition bool
+	Acceptance bool
+}
+
+type requirementAnalysis struct {
+	Uses          []requirementUse
+	Definitions   map[string][]requirementUse
+	Families      map[string]bool
+	HasAcceptance bool
+	Sections      []string
+}
+
+// analyzeRequirements finds requirement IDs outside fenced code. An ID
+// defines a requirement when it starts a heading, list item, paragraph or
+// table row; other occurrences reference it. In acceptance criteria
+// sections, leading IDs of requirements defined elsewhere are references,
+// so "- REQ-001: uploads are rejected" tests REQ-001 rather than redefining
+// it.
+func analyzeRequirements(lines []string, cfg Config) requirementAnalysis {
+	patterns, err := compileRequirementIDPatterns(cfg.RequirementIDPatterns)
+	if err != nil {
+		return requirementAnalysis{}
+	}
+	custom := len(cfg.RequirementIDPatterns) > 0
+	fenced := fencedLines(lines)
+	acceptance, sections := acceptanceLines(lines, fenced)
+	var uses []requirementUse
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		text := blankInlineCode(line)
+		for _, pattern := range patterns {
+			for _, loc := range pattern.FindAllStringIndex(text, -1) {
+				id := text[loc[0]:loc[1]]
+				if !custom && ignoredRequirementFamilies[strings.SplitN(id, "-", 2)[0]] {
+					continue
+				}
+				uses = append(uses, requirementUse{
+					ID:         id,
+					Line:       i + 1,
+					Definition: isRequirementDefinition(text, loc[0], loc[1]),
+					Acceptance: acceptance[i],
+				})
+			}
+		}
+	}
+	sort.SliceStable(uses, func(i, j int) bool { return uses[i].Line < uses[j].Line })
+
+	analysis := requirementAnalysis{Definitions: make(map[string][]requirementUse), Families: make(map[string]bool), Sections: sections}
+	for _, a := range acceptance {
+		if a {
+			analysis.HasAcceptance = true
+			break
+		}
+	}
+	outside := make(map[string]bool)
+	for _, use := range uses {
+		if use.Definition && !use.Acceptance {
+			outside[requirementFamily(use.ID)] = true
+		}
+	}
+	for i := range uses {
+		use := &uses[i]
+		if use.Definition && use.Acceptance && outside[requirementFamily(use.ID)] {
+			use.Definition = false
+		}
+		if use.Definition {
+			analysis.Definitions[use.ID] = append(analysis.Definitions[use.ID], *use)
+			analysis.Families[requirementFamily(use.ID)] = true
+		}
+	}
+	analysis.Uses = uses
+	return analysis
+}
+
+// isRequirementDefinition reports whether the ID at text[start:end] leads
+// its heading, list item, paragraph or first table cell.
+func isRequirementDefinition(text string, start, end int) bool {
+	prefix := text[:start]
+	trimmed := strings.TrimSpace(prefix)
+	switch {
+	case strings.Trim(trimmed, "#") == "" && strings.HasPrefix(trimmed, "#"):
+		return true
+	case strings.Trim(trimmed, "|*_` ") == "" && strings.HasPrefix(trimmed, "|"):
+		return strings.Count(trimmed, "|") == 1
+	}
+	lead := listMarkerRe.FindString(prefix)
+	if strings.Trim(prefix[len(lead):], "*_[") != "" {
+		return false
+	}
+	rest := strings.TrimLeft(text[end:], "*_]")
+	rest = strings.TrimLeft(rest, " \t")
+	if rest == "" {
+		return lead != ""
+	}
+	for _, delim := range []string{":", ".", ")", "-", "–", "—", "|"} {
+		if strings.HasPrefix(rest, delim) {
+			return true
+		}
+	}
+	// A list item that opens with an ID, e.g. "- REQ-001 The service ...".
+	return strings.TrimSpace(lead) != ""
+}
+
+// acceptanceLines marks the lines inside acceptance criteria sections and
+// returns each line's enclosing heading text.
+func acceptanceLines(lines []string, fenced []bool) ([]bool, []string) {
+	acceptance := make([]bool, len(lines))
+	sections := make([]string, len(lines))
+	hs := headings(lines, fenced)
+	for i, h := range hs {
+		end := len(lines)
+		if i+1 < len(hs) {
+			end = hs[i+1].Line - 1
+		}
+		for line := h.Line; line <= end; line++ {
+			sections[line-1] = h.Text
+		}
+		if !containsAnyPhrase(strings.Fields(normalizeText(h.Text)), acceptanceTerms) {
+			continue
+		}
+		// Subsections of an acceptance section are acceptance criteria too.
+		for line := h.Line; line <= sectionEnd(hs, i, len(lines)); line++ {
+			acceptance[line-1] = true
+		}
+	}
+	return acceptance, sections
+}
+
+// sectionEnd returns the last line of heading i's section, including its
+// subsections.
+func sectionEnd(hs []chunk.Heading, i, lineCount int) int {
+	for _, next := range hs[i+1:] {
+		if next.Level <= hs[i].Level {
+			return next.Line - 1
+		}
+	}
+	return lineCount
+}
+
+func containsAnyPhrase(words []string, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		if containsPhrase(words, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func requirementFamily(id string) string {
+	if m := requirementNumberRe.FindStringSubmatch(id); m != nil {
+		return m[1]
+	}
+	return id
+}
+
+func requirementRules() []Rule {
+	return []Rule{duplicateRequirementRule(), requirementGapRule(), danglingRequirementRule(), untestedRequirementRule()}
+}
+
+func duplicateRequirementRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-REQID-001",
+		Group:          "requirement-id",
+		Title:          "Requirement ID is defined more than once",
+		Description:    "Two requirements share the same ID.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "References, tests and questions that cite the ID cannot tell which requirement they mean.",
+		Recommendation: "Give each requirement a unique ID.",
+		Tags:           []string{"requirement-id"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			analysis := doc.requirements(cfg)
+			var findings []Finding
+			for _, id := range sortedRequirementIDs(analysis.Definitions) {
+				defs := analysis.Definitions[id]
+				for _, dup := range defs[1:] {
+					findings = append(findings, Finding{
+						LineStart:   dup.Line,
+						Description: fmt.Sprintf("%s is already defined on line %d.", id, defs[0].Line),
+						Tags:        []string{rule.Group, "requirement:" + id},
+					})
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func requirementGapRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-REQID-002",
+		Group:          "requirement-id",
+		Title:          "Requirement numbering has a gap",
+		Description:    "Requirement IDs skip one or more numbers.",
+		Severity:       schema.SeverityInfo,
+		Category:       schema.CategoryUnspecifiedConstraint,
+		Impact:         "A removed or forgotten requirement may still be referenced or expected by readers.",
+		Recommendation: "Restore the missing requirements, or note that the IDs were retired.",
+		Tags:           []string{"requirement-id"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			analysis := doc.requirements(cfg)
+			type numbered struct {
+				n    int
+				id   string
+				line int
+			}
+			families := make(map[string][]numbered)
+			for id, defs := range analysis.Definitions {
+				m := requirementNumberRe.FindStringSubmatch(id)
+				if m == nil {
+					continue
+				}
+				n, err := strconv.Atoi(m[2])
+				if err != nil {
+					continue
+				}
+				families[m[1]] = append(families[m[1]], numbered{n: n, id: id, line: defs[0].Line})
+			}
+			var findings []Finding
+			for family, ids := range families {
+				sort.Slice(ids, func(i, j int) bool { return ids[i].n < ids[j].n })
+				for i := 1; i < len(ids); i++ {
+					prev, next := ids[i-1], ids[i]
+					// Numbering that restarts at a new hundred, e.g. FR-101
+					// then FR-201, groups requirements by section.
+					if next.n-prev.n < 2 || next.n%100 <= 1 && next.n/100 > prev.n/100 {
+						continue
+					}
+					width := len(next.id) - len(family)
+					missing := formatRequirementID(family, prev.n+1, width)
+					if next.n-prev.n > 2 {
+						missing += " to " + formatRequirementID(family, next.n-1, width) + " are"
+					} else {
+						missing += " is"
+					}
+					findings = append(findings, Finding{
+						LineStart:   next.line,
+						Description: fmt.Sprintf("%s missing between %s and %s.", missing, prev.id, next.id),
+						Tags:        []string{rule.Group, "requirement:" + next.id},
+					})
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func danglingRequirementRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-REQID-003",
+		Group:          "requirement-id",
+		Title:          "Reference to undefined requirement",
+		Description:    "The spec references a requirement ID that is never defined.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryUnspecifiedConstraint,
+		Impact:         "The referenced behavior cannot be implemented or verified.",
+		Recommendation: "Define the requirement or correct the reference.",
+		Tags:           []string{"requirement-id"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			analysis := doc.requirements(cfg)
+			first := make(map[string]int)
+			count := make(map[string]int)
+			var order []string
+			for _, use := range analysis.Uses {
+				// Only IDs from a family the spec defines count; this keeps
+				// tokens such as COVID-19 out of the results.
+				if use.Definition || len(analysis.Definitions[use.ID]) > 0 || !analysis.Families[requirementFamily(use.ID)] {
+					continue
+				}
+				if count[use.ID] == 0 {
+					first[use.ID] = use.Line
+					order = append(order, use.ID)
+				}
+				count[use.ID]++
+			}
+			findings := make([]Finding, 0, len(order))
+			for _, id := range order {
+				findings = append(findings, Finding{
+					LineStart:   first[id],
+					Description: fmt.Sprintf("%s is referenced %d time(s) but never defined.", id, count[id]),
+					Tags:        []string{rule.Group, "requirement:" + id},
+				})
+			}
+			return findings
+		}),
+	}
+}
+
+func untestedRequirementRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-REQID-004",
+		Group:          "requirement-id",
+		Title:          "Requirement is not covered by acceptance criteria",
+		Description:    "No acceptance criterion references the requirement.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryNonTestableRequirement,
+		Impact:         "Nothing states how the requirement is verified.",
+		Recommendation: "Add an acceptance criterion that references the requirement ID.",
+		Tags:           []string{"requirement-id"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			analysis := doc.requirements(cfg)
+			if !analysis.HasAcceptance {
+				return nil
+			}
+			tested := acceptanceReferences(analysis)
+			var findings []Finding
+			for _, id := range sortedRequirementIDs(analysis.Definitions) {
+				def := analysis.Definitions[id][0]
+				if def.Acceptance || tested[id] {
+					continue
+				}
+				findings = append(findings, Finding{
+					LineStart:   def.Line,
+					Description: fmt.Sprintf("No acceptance criterion references %s.", id),
+					Tags:        []string{rule.Group, "requirement:" + id},
+				})
+			}
+			return findings
+		}),
+	}
+}
+
+func acceptanceReferences(analysis requirementAnalysis) map[string]bool {
+	tested := make(map[string]bool)
+	for _, use := range analysis.Uses {
+		if use.Acceptance && !use.Definition {
+			tested[use.ID] = true
+		}
+	}
+	return tested
+}
+
+// requirementCatalog lists the requirements the spec defines, in document
+// order, with every line that references them.
+func requirementCatalog(doc Document, cfg Config) []schema.Requirement {
+	analysis := doc.requirements(cfg)
+	if len(analysis.Definitions) == 0 {
+		return nil
+	}
+	tested := acceptanceReferences(analysis)
+	index := make(map[string]int)
+	var catalog []schema.Requirement
+	for _, use := range analysis.Uses {
+		if _, ok := index[use.ID]; !ok && use.Definition {
+			index[use.ID] = len(catalog)
+			catalog = append(catalog, schema.Requirement{
+				ID:         use.ID,
+				Section:    analysis.Sections[use.Line-1],
+				Definition: lineEvidence(doc, use.Line),
+				Tested:     tested[use.ID],
+			})
+		}
+	}
+	for _, use := range analysis.Uses {
+		i, ok := index[use.ID]
+		if !ok || use.Line == catalog[i].Definition.LineStart {
+			continue
+		}
+		refs := catalog[i].References
+		if len(refs) > 0 && refs[len(refs)-1].LineStart == use.Line {
+			continue
+		}
+		catalog[i].References = append(refs, lineEvidence(doc, use.Line))
+	}
+	return catalog
+}
+
+func lineEvidence(doc Document, line int) schema.Evidence {
+	return schema.Evidence{Path: doc.Path, LineStart: line, LineEnd: line, Quote: strings.TrimSpace(doc.Lines[line-1])}
+}
+
+func sortedRequirementIDs(defs map[string][]requirementUse) []string {
+	ids := make([]string, 0, len(defs))
+	for id := range defs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		left, right := defs[ids[i]][0].Line, defs[ids[j]][0].Line
+		if left != right {
+			return left < right
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func formatRequirementID(family string, n, width int) string {
+	return fmt.Sprintf("%s%0*d", family, width, n)
+}
@@ -0,0 +1,118 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+const requirementSpec = `# Upload Service
+
+## Requirements
+
+- REQ-001: The service accepts PDF uploads.
+- **REQ-002**: The service rejects empty files.
+- REQ-004: The service stores files for 30 days, as REQ-007 requires.
+- REQ-002: The service scans uploads for malware.
+
+| ID | Requirement |
+|----|-------------|
+| FR-12 | Uploads are listed by date. |
+
+## Acceptance Criteria
+
+- REQ-001: Uploading a 1 MB PDF returns HTTP 201.
+- A test for REQ-004 checks retention and FR-12 ordering.
+- The checksum uses SHA-256.
+`
+
+func TestRequirementRulesFlagDuplicates(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", requirementSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-REQID-001", schema.SeverityWarn, 8)
+	if !strings.Contains(issue.Description, "line 6") || !hasTag(issue.Tags, "requirement:REQ-002") {
+		t.Fatalf("issue = %#v", issue)
+	}
+}
+
+func TestRequirementRulesFlagGaps(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", requirementSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-REQID-002", schema.SeverityInfo, 7)
+	if issue.Description != "REQ-003 is missing between REQ-002 and REQ-004." {
+		t.Fatalf("description = %q", issue.Description)
+	}
+
+	grouped := runBuiltin(t, "SPEC.md", "- FR-101: a\n- FR-102: b\n- FR-201: c\n- FR-205: d\n")
+	if n := countIssues(grouped.Issues, "PREFLIGHT-REQID-002"); n != 1 {
+		t.Fatalf("gap findings = %d, want only FR-202 to FR-204", n)
+	}
+	if got := findIssue(grouped.Issues, "PREFLIGHT-REQID-002").Description; got != "FR-202 to FR-204 are missing between FR-201 and FR-205." {
+		t.Fatalf("description = %q", got)
+	}
+}
+
+func TestRequirementRulesFlagDanglingReferences(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", requirementSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-REQID-003", schema.SeverityWarn, 7)
+	if !hasTag(issue.Tags, "requirement:REQ-007") || countIssues(result.Issues, "PREFLIGHT-REQID-003") != 1 {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+}
+
+func TestRequirementRulesFlagUntestedRequirements(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", requirementSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-REQID-004", schema.SeverityWarn, 6)
+	if !hasTag(issue.Tags, "requirement:REQ-002") || countIssues(result.Issues, "PREFLIGHT-REQID-004") != 1 {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+
+	noAcceptance := runBuiltin(t, "SPEC.md", "## Requirements\n- REQ-001: a\n")
+	if findIssue(noAcceptance.Issues, "PREFLIGHT-REQID-004") != nil {
+		t.Fatal("untested rule fired without an acceptance criteria section")
+	}
+}
+
+func TestRequirementCatalog(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", requirementSpec)
+	var ids []string
+	for _, requirement := range result.Requirements {
+		ids = append(ids, requirement.ID)
+	}
+	if got := strings.Join(ids, ","); got != "REQ-001,REQ-002,REQ-004,FR-12" {
+		t.Fatalf("catalog = %s", got)
+	}
+	first := result.Requirements[0]
+	if first.Section != "Requirements" || first.Definition.LineStart != 5 || !first.Tested {
+		t.Fatalf("REQ-001 = %#v", first)
+	}
+	if len(first.References) != 1 || first.References[0].LineStart != 16 {
+		t.Fatalf("REQ-001 references = %#v", first.References)
+	}
+	if result.Requirements[1].Tested {
+		t.Fatal("REQ-002 should not be tested")
+	}
+}
+
+func TestRequirementRulesCustomPatterns(t *testing.T) {
+	text := "## Requirements\n- SR.1: a\n- SR.3: b\n"
+	result, err := Run(spec.New("SPEC.md", text), Config{Enabled: true, Profile: "general", RequirementIDPatterns: []string{`\bSR\.\d+\b`}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	requireIssue(t, result.Issues, "PREFLIGHT-REQID-002", schema.SeverityInfo, 3)
+	if len(result.Requirements) != 2 {
+		t.Fatalf("catalog = %#v", result.Requirements)
+	}
+
+	if _, err := Run(spec.New("SPEC.md", text), Config{Enabled: true, RequirementIDPatterns: []string{"("}}); err == nil || !strings.Contains(err.Error(), "invalid requirement ID pattern") {
+		t.Fatalf("err = %v, want invalid pattern error", err)
+	}
+}
+
+func TestRequirementRulesSkipFencedCode(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", "- REQ-001: a\n```\n- REQ-001: example\nREQ-009\n```\n")
+	if findIssue(result.Issues, "PREFLIGHT-REQID-001") != nil || findIssue(result.Issues, "PREFLIGHT-REQID-003") != nil {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+}
@@ -0,0 +1,396 @@
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+var (
+	// enumHeaderRe matches column headers whose values form an enumeration,
+	// such as "Error code", "State" or "Event".
+	enumHeaderRe   = regexp.MustCompile(`(?i)\b(code|status|state|event|reason|error)(?:e?s)?$`)
+	upperEnumRe    = regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:[_.-][A-Z0-9]+)+$`)
+	upperMentionRe = regexp.MustCompile(`\b[A-Z][A-Z0-9]*(?:[_.-][A-Z0-9]+)+\b`)
+	codeEnumRe     = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+	codeMentionRe  = regexp.MustCompile("`([A-Za-z][A-Za-z0-9_.-]*)`")
+)
+
+// optionalColumns may be left empty.
+var optionalColumns = map[string]bool{
+	"note": true, "notes": true, "comment": true, "comments": true, "remark": true, "remarks": true,
+}
+
+func tableRules() []Rule {
+	return []Rule{emptyTableCellRule(), tableColumnCountRule(), missingEnumValueRule()}
+}
+
+func emptyTableCellRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-TABLE-001",
+		Group:          "table",
+		Title:          "Table cell is empty",
+		Description:    "A table row leaves a column empty.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryUnspecifiedConstraint,
+		Impact:         "Tables often carry the contract, such as error codes, states and fields; an empty cell leaves that part unspecified.",
+		Recommendation: "Fill in the cell, or write an explicit value such as \"none\" or \"n/a\".",
+		Tags:           []string{"table"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, table := range doc.tables() {
+				for r, row := range table.Rows {
+					var empty []string
+					for c, cell := range row.Cells {
+						if c >= len(table.Header) || cell != "" {
+							continue
+						}
+						header := cellText(table.Header[c])
+						if header == "" || optionalColumns[strings.ToLower(header)] {
+							continue
+						}
+						empty = append(empty, quoted(header))
+					}
+					if len(empty) == 0 {
+						continue
+					}
+					findings = append(findings, Finding{
+						LineStart:   row.Line,
+						Description: fmt.Sprintf("Row %d of %s leaves %s empty.", r+1, tableLabel(table), joinList(empty)),
+						Tags:        []string{rule.Group},
+					})
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func tableColumnCountRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-TABLE-002",
+		Group:          "table",
+		Title:          "Table row has the wrong number of cells",
+		Description:    "A table row does not have one cell per header column.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Renderers drop extra cells and pad missing ones, so values can appear under the wrong column or disappear.",
+		Recommendation: "Give every row exactly one cell per header column; escape literal pipes as \\|.",
+		Tags:           []string{"table"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			for _, table := range doc.tables() {
+				columns := len(table.Header)
+				if table.Delimiter != columns {
+					findings = append(findings, Finding{
+						LineStart:   table.Line,
+						LineEnd:     table.Line + 1,
+						Description: fmt.Sprintf("The header of %s has %d columns but its delimiter row has %d.", tableLabel(table), columns, table.Delimiter),
+						Tags:        []string{rule.Group},
+					})
+				}
+				for r, row := range table.Rows {
+					cells := len(row.Cells)
+					if cells == columns {
+						continue
+					}
+					description := fmt.Sprintf("Row %d of %s has %d cells; the header has %d columns.", r+1, tableLabel(table), cells, columns)
+					if cells < columns {
+						var missing []string
+						for _, header := range table.Header[cells:] {
+							missing = append(missing, quoted(cellText(header)))
+						}
+						verb := "is"
+						if len(missing) > 1 {
+							verb = "are"
+						}
+						description = fmt.Sprintf("Row %d of %s has %d cells; the header has %d columns, so %s %s missing.", r+1, tableLabel(table), cells, columns, joinList(missing), verb)
+					}
+					findings = append(findings, Finding{
+						LineStart:   row.Line,
+						Description: description,
+						Tags:        []string{rule.Group},
+					})
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func missingEnumValueRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-TABLE-003",
+		Group:          "table",
+		Title:          "Enumerated value is missing from its table",
+		Description:    "The spec uses a value that the table enumerating those values does not list.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryUndefinedInterface,
+		Impact:         "Implementers working from the table will not handle the missing value.",
+		Recommendation: "Add a row for the value, or correct the reference.",
+		Tags:           []string{"table"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			enums := tableEnums(doc.tables())
+			if len(enums) == 0 {
+				return nil
+			}
+			text := mentionLines(doc.Lines)
+			sections := doc.sections()
+			var findings []Finding
+			for _, enum := range enums {
+				table := enum.Tables[0]
+				for _, m := range enum.missing(text, sections) {
+					findings = append(findings, Finding{
+						LineStart:   m.Line,
+						Description: fmt.Sprintf("%q is used on line %d but is not listed in the %q column of %s (line %d).", m.Value, m.Line, enum.Header, tableLabel(table), table.Line),
+						Tags:        []string{rule.Group, "value:" + m.Value},
+						Related:     []Location{{LineStart: table.Line}},
+					})
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+// tableEnum is the set of values that the enumeration columns with one
+// header list. Upper-case values such as ERR_TIMEOUT are found anywhere they
+// share the columns' prefix; other values only as inline code next to the
+// header's noun, as in "the `refunded` state", and only in the sections
+// holding the tables.
+type tableEnum struct {
+	Header string
+	Noun   string
+	Upper  bool
+	Prefix string
+	Values map[string]bool
+	Tables []markdownTable
+}
+
+type enumMention struct {
+	Value string
+	Line  int
+}
+
+// tableEnums returns the enumeration columns of tables, merging columns
+// with the same header.
+func tableEnums(tables []markdownTable) []*tableEnum {
+	byHeader := map[string]*tableEnum{}
+	var enums []*tableEnum
+	for _, table := range tables {
+		for c := range table.Header {
+			values, upper, ok := enumColumn(table, c)
+			if !ok {
+				continue
+			}
+			header := cellText(table.Header[c])
+			enum := byHeader[strings.ToLower(header)]
+			if enum == nil {
+				enum = &tableEnum{Header: header, Noun: strings.ToLower(enumHeaderRe.FindStringSubmatch(header)[1]), Upper: upper, Values: map[string]bool{}}
+				byHeader[strings.ToLower(header)] = enum
+				enums = append(enums, enum)
+			} else if enum.Upper != upper {
+				continue
+			}
+			for _, value := range values {
+				enum.Values[value] = true
+			}
+			enum.Tables = append(enum.Tables, table)
+		}
+	}
+	for _, enum := range enums {
+		if enum.Upper {
+			values := make([]string, 0, len(enum.Values))
+			for value := range enum.Values {
+				values = append(values, value)
+			}
+			enum.Prefix = commonPrefix(values)
+		}
+	}
+	return enums
+}
+
+// enumColumn returns the values in column c and whether they are upper-case
+// constants. ok is false when the header does not name an enumeration or
+// fewer than two values look like identifiers.
+func enumColumn(table markdownTable, c int) (values []string, upper, ok bool) {
+	if !enumHeaderRe.MatchString(cellText(table.Header[c])) || len(table.Rows) < 2 {
+		return nil, false, false
+	}
+	uppers, codes := 0, 0
+	for _, row := range table.Rows {
+		if c >= len(row.Cells) || row.Cells[c] == "" {
+			continue
+		}
+		value := cellText(row.Cells[c])
+		values = append(values, value)
+		if upperEnumRe.MatchString(value) {
+			uppers++
+		}
+		if codeEnumRe.MatchString(value) && strings.Contains(row.Cells[c], "`") {
+			codes++
+		}
+	}
+	switch {
+	case uppers >= 2 && uppers*3 >= len(values)*2:
+		return values, true, true
+	case codes >= 2 && codes*3 >= len(values)*2:
+		return values, false, true
+	}
+	return nil, false, false
+}
+
+// missing returns the first use of each value absent from the enumeration,
+// outside its tables.
+func (e *tableEnum) missing(text []string, sections []int) []enumMention {
+	inTable := map[int]bool{}
+	inSection := map[int]bool{}
+	for _, table := range e.Tables {
+		inTable[table.Line], inTable[table.Line+1] = true, true
+		for _, row := range table.Rows {
+			inTable[row.Line] = true
+		}
+		inSection[sections[table.Line-1]] = true
+	}
+	seen := map[string]bool{}
+	var out []enumMention
+	for i, line := range text {
+		if inTable[i+1] || (!e.Upper && !inSection[sections[i]]) {
+			continue
+		}
+		for _, value := range e.mentions(line) {
+			if e.Values[value] || seen[value] {
+				continue
+			}
+			seen[value] = true
+			out = append(out, enumMention{Value: value, Line: i + 1})
+		}
+	}
+	return out
+}
+
+// lineSections returns, for each line, the line number of the heading whose
+// section it is in, or 0 before the first heading.
+func lineSections(lines []string) []int {
+	fenced := fencedLines(lines)
+	sections := make([]int, len(lines))
+	current := 0
+	for i, line := range lines {
+		if !fenced[i] && isMarkdownHeading(line) {
+			current = i + 1
+		}
+		sections[i] = current
+	}
+	return sections
+}
+
+func (e *tableEnum) mentions(line string) []string {
+	var out []string
+	if e.Upper {
+		if e.Prefix == "" {
+			return nil
+		}
+		for _, value := range upperMentionRe.FindAllString(line, -1) {
+			if strings.HasPrefix(value, e.Prefix) {
+				out = append(out, value)
+			}
+		}
+		return out
+	}
+	lower := strings.ToLower(line)
+	for _, loc := range codeMentionRe.FindAllStringSubmatchIndex(line, -1) {
+		before := strings.Fields(lower[:loc[0]])
+		after := strings.Fields(lower[loc[1]:])
+		if (len(before) > 0 && isEnumNoun(before[len(before)-1], e.Noun)) || (len(after) > 0 && isEnumNoun(after[0], e.Noun)) {
+			out = append(out, line[loc[2]:loc[3]])
+		}
+	}
+	return out
+}
+
+func isEnumNoun(word, noun string) bool {
+	word = strings.Trim(word, ".,;:()*_")
+	return word == noun || word == noun+"s" || word == noun+"es"
+}
+
+// commonPrefix returns the longest prefix, ending in a separator, that all
+// values share, such as "ERR_".
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if i := strings.LastIndexAny(prefix, "_.-"); i >= 0 {
+		return prefix[:i+1]
+	}
+	return ""
+}
+
+// mentionLines returns the document with fenced code and example sections
+// blanked. Unlike proseLines it keeps inline code, where enumerated values
+// are usually written.
+func mentionLines(lines []string) []string {
+	fenced := fencedLines(lines)
+	out := make([]string, len(lines))
+	inExample := false
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if isMarkdownHeading(line) {
+			inExample = isExampleHeading(line)
+		}
+		if !inExample {
+			out[i] = line
+		}
+	}
+	return out
+}
+
+// tableCellPlaceholders describes the cells of a table row that hold the
+// placeholder term, or returns "" when line is not a table body row.
+func tableCellPlaceholders(tables []markdownTable, line int, term string) string {
+	pattern := compileLiteralPatterns([]string{term})[0]
+	for _, table := range tables {
+		for r, row := range table.Rows {
+			if row.Line != line {
+				continue
+			}
+			var columns []string
+			for c, cell := range row.Cells {
+				if c < len(table.Header) && pattern.match(cell, strings.ToLower(cell)) {
+					columns = append(columns, quoted(cellText(table.Header[c])))
+				}
+			}
+			if len(columns) == 0 {
+				return ""
+			}
+			noun := "column"
+			if len(columns) > 1 {
+				noun = "columns"
+			}
+			return fmt.Sprintf("Row %d of %s has placeholder text %q in the %s %s.", r+1, tableLabel(table), term, joinList(columns), noun)
+		}
+	}
+	return ""
+}
+
+func tableLabel(table markdownTable) string {
+	if table.Heading == "" {
+		return fmt.Sprintf("the table at line %d", table.Line)
+	}
+	return fmt.Sprintf("the %q table", table.Heading)
+}
+
+// cellText strips emphasis and code markers from a cell.
+func cellText(cell string) string {
+	return strings.TrimSpace(strings.Trim(cell, "*_` "))
+}
+
+func quoted(s string) string {
+	return fmt.Sprintf("%q", s)
+}
@@ -0,0 +1,121 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func TestTableRulesReportRowAndColumn(t *testing.T) {
+	text := strings.Join([]string{
+		"## Error Codes",
+		"| Error code | Condition | HTTP status | Notes |",
+		"|------------|-----------|-------------|-------|",
+		"| `ERR_AUTH` | Token expired | 401 | |",
+		"| `ERR_RATE` | | 429 | |",
+		"| `ERR_CONFLICT` | Version mismatch | TBD | |",
+		"| `ERR_PIPE` | Uses a `a|b` filter \\| or | 400 | |",
+		"| `ERR_SHORT` | Missing status |",
+		"",
+		"Requests return `ERR_TIMEOUT` after 30 seconds; `ERR_AUTH` is retried once.",
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+
+	empty := requireIssue(t, result.Issues, "PREFLIGHT-TABLE-001", schema.SeverityWarn, 5)
+	if empty.Description != `Row 2 of the "Error Codes" table leaves "Condition" empty.` {
+		t.Fatalf("description = %q", empty.Description)
+	}
+	if n := countIssues(result.Issues, "PREFLIGHT-TABLE-001"); n != 1 {
+		t.Fatalf("empty cell issues = %d, want 1 (Notes is optional)", n)
+	}
+
+	columns := requireIssue(t, result.Issues, "PREFLIGHT-TABLE-002", schema.SeverityWarn, 8)
+	if columns.Description != `Row 5 of the "Error Codes" table has 2 cells; the header has 4 columns, so "HTTP status" and "Notes" are missing.` {
+		t.Fatalf("description = %q", columns.Description)
+	}
+	if n := countIssues(result.Issues, "PREFLIGHT-TABLE-002"); n != 1 {
+		t.Fatalf("column count issues = %d, want 1 (escaped and code pipes do not split)", n)
+	}
+
+	placeholder := requireIssueAt(t, result.Issues, "PREFLIGHT-TODO-001", 6)
+	if placeholder.Description != `Row 3 of the "Error Codes" table has placeholder text "TBD" in the "HTTP status" column.` || !hasTag(placeholder.Tags, "table") {
+		t.Fatalf("issue = %#v", placeholder)
+	}
+
+	missing := requireIssueAt(t, result.Issues, "PREFLIGHT-TABLE-003", 10)
+	if missing.Category != schema.CategoryUndefinedInterface || len(missing.Evidence) != 2 || missing.Evidence[1].LineStart != 2 {
+		t.Fatalf("issue = %#v", missing)
+	}
+	if missing.Description != `"ERR_TIMEOUT" is used on line 10 but is not listed in the "Error code" column of the "Error Codes" table (line 2).` {
+		t.Fatalf("description = %q", missing.Description)
+	}
+}
+
+func TestMissingEnumValueRuleMergesTablesAndScopesStates(t *testing.T) {
+	text := strings.Join([]string{
+		"## Order States",
+		"| State | Meaning |",
+		"|-------|---------|",
+		"| `pending` | Created. |",
+		"| `paid` | Captured. |",
+		"",
+		"| State | Meaning |",
+		"|-------|---------|",
+		"| `refunded` | Returned. |",
+		"| `void` | Cancelled. |",
+		"",
+		"A paid order moves to the `refunded` state, then to the `archived` state.",
+		"## Reporting",
+		"The export job reports state `archived` for old rows.",
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	if n := countIssues(result.Issues, "PREFLIGHT-TABLE-003"); n != 1 {
+		t.Fatalf("missing value issues = %d, want 1 in %#v", n, result.Issues)
+	}
+	issue := requireIssueAt(t, result.Issues, "PREFLIGHT-TABLE-003", 12)
+	if !hasTag(issue.Tags, "value:archived") {
+		t.Fatalf("issue = %#v", issue)
+	}
+}
+
+func TestTableRulesIgnoreFencesExamplesAndMismatchedDelimiters(t *testing.T) {
+	text := strings.Join([]string{
+		"```md",
+		"| A | B |",
+		"|---|---|",
+		"| | |",
+		"```",
+		"## Examples",
+		"| A | B |",
+		"|---|---|",
+		"| 1 | |",
+		"## Data",
+		"| Name | Type |",
+		"|------|",
+		"| id | string |",
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	if issue := findIssue(result.Issues, "PREFLIGHT-TABLE-001"); issue != nil {
+		t.Fatalf("unexpected issue %#v", issue)
+	}
+	issue := requireIssueAt(t, result.Issues, "PREFLIGHT-TABLE-002", 11)
+	if issue.Description != `The header of the "Data" table has 2 columns but its delimiter row has 1.` || issue.Evidence[0].LineEnd != 12 {
+		t.Fatalf("issue = %#v", issue)
+	}
+}
+
+func TestTableCells(t *testing.T) {
+	for line, want := range map[string][]string{
+		"| a | b |":       {"a", "b"},
+		"a | b":           {"a", "b"},
+		"| `x|y` | z |":   {"`x|y`", "z"},
+		`| a \| b | c |`:  {`a \| b`, "c"},
+		"| it`s | open |": {"it`s", "open"},
+		"| | |":           {"", ""},
+	} {
+		if got := tableCells(line); strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+			t.Fatalf("tableCells(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// builtinSynonyms are term groups that commonly drift within one spec.
+var builtinSynonyms = compileSynonyms([][]string{
+	{"sign in", "log in"},
+	{"sign out", "log out"},
+	{"sign up", "register"},
+	{"user account", "customer account", "customer profile"},
+})
+
+var (
+	termTokenRe     = regexp.MustCompile(`[A-Za-z][A-Za-z0-9]*(?:-[A-Za-z0-9]+)*`)
+	glossaryTermEnd = regexp.MustCompile(`\s*(:|\||\s-\s|\s–\s|\s—\s|\()`)
+)
+
+// termStopwords never form compound-word variants, so "every day" and
+// "everyday" are not reported as drift.
+var termStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "not": true, "any": true, "all": true, "each": true,
+	"every": true, "some": true, "may": true, "can": true, "must": true, "shall": true, "should": true,
+	"will": true, "with": true, "from": true, "into": true, "onto": true, "this": true, "that": true,
+	"are": true, "was": true, "were": true, "has": true, "have": true, "its": true, "our": true,
+	"your": true, "their": true, "one": true, "who": true, "what": true, "when": true, "where": true,
+}
+
+func terminologyRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-TERM-001",
+		Group:          "terminology",
+		Title:          "Term is used in inconsistent forms",
+		Description:    "The spec refers to one concept with several terms or spellings.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryTerminologyInconsistent,
+		Impact:         "Readers may treat the variants as different concepts and implement them separately.",
+		Recommendation: "Pick one term, define it in the glossary, and use it throughout.",
+		Tags:           []string{"terminology"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			lines := doc.prose()
+			glossary := glossaryTerms(doc.Lines)
+			var findings []Finding
+			for _, cluster := range spellingClusters(lines, glossary) {
+				// Spelling variants are usually harmless; synonyms are not.
+				findings = append(findings, termFinding(rule, cluster, glossary, schema.SeverityInfo))
+			}
+			groups := append(append([]synonymGroup(nil), builtinSynonyms...), compileSynonyms(cfg.Synonyms)...)
+			for _, cluster := range synonymClusters(lines, groups) {
+				findings = append(findings, termFinding(rule, cluster, glossary, ""))
+			}
+			return findings
+		}),
+	}
+}
+
+// termVariant is one surface form of a term and where it is first used.
+type termVariant struct {
+	Shape string
+	Line  int
+	Quote string
+	Count int
+}
+
+// spellingClusters groups words that differ only in hyphenation, spacing or
+// internal capitalization, such as "e-mail"/"email" or "data set"/"dataset".
+// Plurals, capitalized first letters and hyphen-versus-space alone are not
+// variants, except that a term the glossary defines is used in exactly the
+// defined form, so "user-account" drifts from a defined "User account".
+func spellingClusters(lines []string, glossary map[string]string) [][]termVariant {
+	variants := make(map[string]map[string]*termVariant)
+	add := func(key, surface string, line int) {
+		shape := termShape(surface)
+		if variants[key] == nil {
+			variants[key] = make(map[string]*termVariant)
+		}
+		if v := variants[key][shape]; v != nil {
+			v.Count++
+			return
+		}
+		variants[key][shape] = &termVariant{Shape: shape, Line: line, Quote: surface, Count: 1}
+	}
+	var pairs []struct {
+		key, surface string
+		line         int
+	}
+	for i, line := range lines {
+		locs := termTokenRe.FindAllStringIndex(line, -1)
+		for j, loc := range locs {
+			token := line[loc[0]:loc[1]]
+			key := singular(strings.ToLower(strings.ReplaceAll(token, "-", "")))
+			add(key, token, i+1)
+			if j == 0 {
+				continue
+			}
+			prev := locs[j-1]
+			first := line[prev[0]:prev[1]]
+			if line[prev[1]:loc[0]] != " " || !compoundPart(first) || !compoundPart(token) {
+				continue
+			}
+			pairs = append(pairs, struct {
+				key, surface string
+				line         int
+			}{singular(strings.ToLower(first + token)), first + " " + token, i + 1})
+		}
+	}
+	// A two-word phrase is a variant only when the joined form also appears.
+	for _, pair := range pairs {
+		if variants[pair.key] != nil {
+			add(pair.key, pair.surface, pair.line)
+		}
+	}
+	var clusters [][]termVariant
+	for _, forms := range variants {
+		cluster := make([]termVariant, 0, len(forms))
+		for _, v := range forms {
+			cluster = append(cluster, *v)
+		}
+		if len(cluster) < 2 || acronymCaseOnly(cluster) || hyphenationOnly(cluster) && !definedTerm(cluster, glossary) {
+			continue
+		}
+		clusters = append(clusters, sortVariants(cluster))
+	}
+	return sortClusters(clusters)
+}
+
+// synonymGroup is one group of synonyms with a pattern per member. Words
+// holds the lowercase first word of each member, so lines without any of
+// them are skipped before the patterns run.
+type synonymGroup struct {
+	Terms    []string
+	Patterns []*regexp.Regexp
+	Words    []string
+}
+
+func compileSynonyms(groups [][]string) []synonymGroup {
+	out := make([]synonymGroup, 0, len(groups))
+	for _, terms := range groups {
+		var group synonymGroup
+		for _, term := range terms {
+			fields := strings.Fields(strings.ToLower(term))
+			if len(fields) == 0 {
+				continue
+			}
+			group.Terms = append(group.Terms, term)
+			group.Patterns = append(group.Patterns, synonymPattern(term))
+			group.Words = append(group.Words, fields[0])
+		}
+		out = append(out, group)
+	}
+	return out
+}
+
+// synonymClusters finds the groups with more than one member in use. The
+// longest member wins where members overlap, so "user account" is not also
+// counted as "account".
+func synonymClusters(lines []string, groups []synonymGroup) [][]termVariant {
+	found := make([]map[int]*termVariant, len(groups))
+	for g := range groups {
+		found[g] = make(map[int]*termVariant)
+	}
+	type match struct{ start, end, member int }
+	var matches []match
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+		for g, group := range groups {
+			matches = matches[:0]
+			for i, pattern := range group.Patterns {
+				if !strings.Contains(lower, group.Words[i]) {
+					continue
+				}
+				for _, loc := range pattern.FindAllStringSubmatchIndex(line, -1) {
+					matches = append(matches, match{loc[2], loc[3], i})
+				}
+			}
+			sort.Slice(matches, func(i, j int) bool {
+				if matches[i].start != matches[j].start {
+					return matches[i].start < matches[j].start
+				}
+				return matches[i].end > matches[j].end
+			})
+			covered := -1
+			for _, m := range matches {
+				if m.start < covered {
+					continue
+				}
+				covered = m.end
+				if v := found[g][m.member]; v != nil {
+					v.Count++
+					continue
+				}
+				found[g][m.member] = &termVariant{Shape: strings.ToLower(group.Terms[m.member]), Line: n + 1, Quote: line[m.start:m.end], Count: 1}
+			}
+		}
+	}
+	var clusters [][]termVariant
+	for g := range groups {
+		if len(found[g]) < 2 {
+			continue
+		}
+		cluster := make([]termVariant, 0, len(found[g]))
+		for _, v := range found[g] {
+			cluster = append(cluster, *v)
+		}
+		clusters = append(clusters, sortVariants(cluster))
+	}
+	return sortClusters(clusters)
+}
+
+func synonymPattern(term string) *regexp.Regexp {
+	words := strings.Fields(term)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(`(?i)(?:^|[^[:alnum:]_-])(` + strings.Join(words, `[\s-]+`) + `(?:s|es)?)(?:[^[:alnum:]_-]|$)`)
+}
+
+// termFinding reports a cluster with evidence at each variant's first use.
+// The glossary's variant is canonical; otherwise the most used one is.
+func termFinding(rule Rule, cluster []termVariant, glossary map[string]string, severity schema.Severity) Finding {
+	canonical := cluster[0]
+	defined := false
+	for _, v := range cluster {
+		shape, ok := glossary[glossaryKey(v.Shape)]
+		if !ok {
+			if !defined && v.Count > canonical.Count {
+				canonical = v
+			}
+			continue
+		}
+		// Prefer the variant written as the glossary writes it.
+		if !defined || v.Shape == shape {
+			canonical, defined = v, true
+		}
+		if v.Shape == shape {
+			break
+		}
+	}
+	var others []string
+	for _, v := range cluster {
+		if v.Shape != canonical.Shape {
+			others = append(others, fmt.Sprintf("%q", v.Quote))
+		}
+	}
+	source := "is used most often"
+	if defined {
+		source = "is defined in the glossary"
+	}
+	finding := Finding{
+		LineStart:      cluster[0].Line,
+		Description:    fmt.Sprintf("%s and %s refer to the same concept; %q %s.", strings.Join(others, ", "), fmt.Sprintf("%q", canonical.Quote), canonical.Quote, source),
+		Severity:       severity,
+		Recommendation: fmt.Sprintf("Use %q consistently.", canonical.Quote),
+		Tags:           []string{rule.Group, "term:" + canonical.Shape},
+	}
+	for _, v := range cluster[1:] {
+		finding.Related = append(finding.Related, Location{LineStart: v.Line})
+	}
+	return finding
+}
+
+// glossaryTerms returns the terms defined in glossary or definitions
+// sections, mapping each term's glossaryKey to its shape.
+func glossaryTerms(lines []string) map[string]string {
+	terms := make(map[string]string)
+	inGlossary := false
+	for _, line := range lines {
+		if isMarkdownHeading(line) {
+			heading := normalizeHeading(line)
+			inGlossary = strings.Contains(heading, "glossary") || strings.Contains(heading, "definitions") || strings.Contains(heading, "terminology")
+			continue
+		}
+		if !inGlossary {
+			continue
+		}
+		text := strings.TrimSpace(listMarkerRe.ReplaceAllString(line, ""))
+		text = strings.TrimLeft(text, "| ")
+		if loc := glossaryTermEnd.FindStringIndex(text); loc != nil {
+			text = text[:loc[0]]
+		}
+		text = strings.Trim(text, "*_` ")
+		if text != "" {
+			terms[glossaryKey(text)] = termShape(text)
+		}
+	}
+	return terms
+}
+
+// glossaryKey normalizes a term across case, hyphenation and plural, so
+// "User Accounts" and "user-account" find a defined "User account".
+func glossaryKey(term string) string {
+	return singular(strings.ToLower(strings.ReplaceAll(term, "-", " ")))
+}
+
+// definedTerm reports whether the glossary defines any form of the
+// cluster's term.
+func definedTerm(cluster []termVariant, glossary map[string]string) bool {
+	for _, v := range cluster {
+		if _, ok := glossary[glossaryKey(v.Shape)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// termShape normalizes a surface form for comparison: the first letter of
+// each word is lowered and the last word is singular.
+func termShape(surface string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range surface {
+		if start {
+			r = unicode.ToLower(r)
+		}
+		start = r == ' ' || r == '-'
+		b.WriteRune(r)
+	}
+	return singular(b.String())
+}
+
+// protocolNames end in "s" without being plurals.
+var protocolNames = map[string]bool{
+	"https": true, "wss": true, "ftps": true, "smtps": true, "imaps": true,
+	"pop3s": true, "ldaps": true, "mqtts": true, "amqps": true, "dns": true, "nfs": true,
+}
+
+// singular strips a plural suffix. Words ending in "ps" and protocol names
+// are left alone, so "https" does not become "http".
+func singular(word string) string {
+	switch {
+	case protocolNames[strings.ToLower(word)] || strings.HasSuffix(word, "ps"):
+		return word
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+func compoundPart(word string) bool {
+	return len(word) >= 3 && !strings.Contains(word, "-") && !termStopwords[strings.ToLower(word)]
+}
+
+// acronymCaseOnly reports clusters that differ only between an all-caps
+// acronym and a lowercase word, such as "GET" and "get" or "CODE" and
+// "codes". Forms are compared singular, since the cluster key is.
+func acronymCaseOnly(cluster []termVariant) bool {
+	for _, v := range cluster {
+		if strings.ContainsAny(v.Quote, " -") {
+			return false
+		}
+		if v.Quote != strings.ToUpper(v.Quote) && termShape(v.Quote) != singular(strings.ToLower(v.Quote)) {
+			return false
+		}
+	}
+	return true
+}
+
+// hyphenationOnly reports clusters whose forms differ only between a hyphen
+// and a space, as in "strict-mode rules" and "strict mode", which is
+// ordinary compound-modifier grammar.
+func hyphenationOnly(cluster []termVariant) bool {
+	for _, v := range cluster[1:] {
+		if strings.ReplaceAll(v.Shape, "-", " ") != strings.ReplaceAll(cluster[0].Shape, "-", " ") {
+			return false
+		}
+	}
+	return true
+}
+
+func sortVariants(cluster []termVariant) []termVariant {
+	sort.Slice(cluster, func(i, j int) bool {
+		if cluster[i].Line != cluster[j].Line {
+			return cluster[i].Line < cluster[j].Line
+		}
+		return cluster[i].Shape < cluster[j].Shape
+	})
+	return cluster
+}
+
+func sortClusters(clusters [][]termVariant) [][]termVariant {
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i][0].Line != clusters[j][0].Line {
+			return clusters[i][0].Line < clusters[j][0].Line
+		}
+		return clusters[i][0].Shape < clusters[j][0].Shape
+	})
+	return clusters
+}
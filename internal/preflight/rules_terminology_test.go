@@ -0,0 +1,155 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+func TestTerminologyRuleFlagsSpellingVariants(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The service sends an e-mail on signup.",
+		"Each email includes a link.",
+		"The data set is exported nightly. Every dataset is versioned.",
+		"Emails are retried.",
+	}, "\n"))
+	var found []schema.Issue
+	for _, issue := range result.Issues {
+		if issue.ID == "PREFLIGHT-TERM-001" {
+			found = append(found, issue)
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("term issues = %#v, want e-mail and data set clusters", found)
+	}
+	email := found[0]
+	if email.Severity != schema.SeverityInfo || email.Category != schema.CategoryTerminologyInconsistent {
+		t.Fatalf("issue = %#v", email)
+	}
+	if len(email.Evidence) != 2 || email.Evidence[0].LineStart != 1 || email.Evidence[1].LineStart != 2 {
+		t.Fatalf("evidence = %#v, want first use of each variant", email.Evidence)
+	}
+	if !strings.Contains(email.Description, `"email" is used most often`) || !hasTag(email.Tags, "term:email") {
+		t.Fatalf("issue = %#v", email)
+	}
+}
+
+func TestTerminologyRuleIgnoresGrammaticalVariants(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"## Strict Mode",
+		"Strict-mode rules escalate findings; strict mode is off by default.",
+		"Send a GET request to get the file.",
+		"Users upload files. A user uploads one file.",
+		"Every day the job runs. Everyday usage is low.",
+		"```",
+		"e-mail",
+		"```",
+		"Send an email.",
+	}, "\n"))
+	if issue := findIssue(result.Issues, "PREFLIGHT-TERM-001"); issue != nil {
+		t.Fatalf("unexpected term issue %#v", issue)
+	}
+}
+
+func TestTerminologyRuleIgnoresAcronymsWithPluralWords(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", strings.Join([]string{
+		"The HTTP client follows redirects to https endpoints.",
+		"The INTERFACE section lists interfaces.",
+		"A CONTRADICTION issue groups contradictions.",
+		"The CODE field holds error codes.",
+	}, "\n"))
+	if issue := findIssue(result.Issues, "PREFLIGHT-TERM-001"); issue != nil {
+		t.Fatalf("unexpected term issue %#v", issue)
+	}
+}
+
+func TestSingularKeepsProtocolNames(t *testing.T) {
+	for word, want := range map[string]string{"https": "https", "pop3s": "pop3s", "codes": "code", "policies": "policy", "classes": "class"} {
+		if got := singular(word); got != want {
+			t.Errorf("singular(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTerminologyRuleFlagsSynonymsWithGlossaryCanonical(t *testing.T) {
+	text := strings.Join([]string{
+		"## Glossary",
+		"- **Customer profile**: the record for a paying customer.",
+		"## Requirements",
+		"Users sign in with a password.",
+		"A user account is locked after 5 failures.",
+		"Locked customer profiles are listed for support.",
+		"Users log in again after 15 minutes.",
+	}, "\n")
+	result := runBuiltin(t, "SPEC.md", text)
+	var accounts, login *schema.Issue
+	for i, issue := range result.Issues {
+		switch {
+		case issue.ID == "PREFLIGHT-TERM-001" && hasTag(issue.Tags, "term:customer profile"):
+			accounts = &result.Issues[i]
+		case issue.ID == "PREFLIGHT-TERM-001" && hasTag(issue.Tags, "term:sign in"):
+			login = &result.Issues[i]
+		}
+	}
+	if accounts == nil || login == nil {
+		t.Fatalf("issues = %#v, want account and sign-in clusters", result.Issues)
+	}
+	if accounts.Severity != schema.SeverityWarn || !strings.Contains(accounts.Description, "is defined in the glossary") {
+		t.Fatalf("accounts = %#v", accounts)
+	}
+	if len(accounts.Evidence) != 2 || accounts.Evidence[0].LineStart != 2 || accounts.Evidence[1].LineStart != 5 {
+		t.Fatalf("accounts evidence = %#v", accounts.Evidence)
+	}
+	if len(login.Evidence) != 2 || login.Evidence[0].LineStart != 4 || login.Evidence[1].LineStart != 7 {
+		t.Fatalf("login evidence = %#v", login.Evidence)
+	}
+}
+
+func TestTerminologyRuleNormalizesGlossaryTerms(t *testing.T) {
+	text := strings.Join([]string{
+		"## Glossary",
+		"- User account: the login record of one person.",
+		"## Requirements",
+		"A user-account is created on signup.",
+		"User Accounts are listed by name.",
+	}, "\n")
+	issue := requireIssueAt(t, runBuiltin(t, "SPEC.md", text).Issues, "PREFLIGHT-TERM-001", 2)
+	if !hasTag(issue.Tags, "term:user account") || !strings.Contains(issue.Description, `"user-account" and "User account"`) || !strings.Contains(issue.Description, "is defined in the glossary") {
+		t.Fatalf("issue = %#v", issue)
+	}
+	if len(issue.Evidence) != 2 || issue.Evidence[1].LineStart != 4 {
+		t.Fatalf("evidence = %#v", issue.Evidence)
+	}
+
+	// Without a glossary entry, hyphen versus space is compound-modifier
+	// grammar rather than drift.
+	undefined := strings.Join(strings.Split(text, "\n")[2:], "\n")
+	if issue := findIssue(runBuiltin(t, "SPEC.md", undefined).Issues, "PREFLIGHT-TERM-001"); issue != nil {
+		t.Fatalf("unexpected term issue %#v", issue)
+	}
+}
+
+func TestTerminologyRuleUsesConfiguredSynonyms(t *testing.T) {
+	text := "A tenant owns projects.\nEach workspace has a quota of 10 GB.\n"
+	result, err := Run(spec.New("SPEC.md", text), Config{Enabled: true, Profile: "general", Synonyms: [][]string{{"tenant", "workspace"}}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	issue := requireIssueAt(t, result.Issues, "PREFLIGHT-TERM-001", 1)
+	if len(issue.Evidence) != 2 || !hasTag(issue.Tags, "term:tenant") {
+		t.Fatalf("issue = %#v", issue)
+	}
+}
+
+func requireIssueAt(t *testing.T, issues []schema.Issue, id string, line int) schema.Issue {
+	t.Helper()
+	for _, issue := range issues {
+		if issue.ID == id && issue.Evidence[0].LineStart == line {
+			return issue
+		}
+	}
+	t.Fatalf("missing %s at line %d in %#v", id, line, issues)
+	return schema.Issue{}
+}
@@ -26,6 +26,15 @@ func BuiltinRules() []Rule {
 	rules := []Rule{placeholderRule(), vagueRule(), weakRequirementRule()}
 	rules = append(rules, structuralRules()...)
 	rules = append(rules, contextRules()...)
+	rules = append(rules, normativeRules()...)
+	rules = append(rules, requirementRules()...)
+	rules = append(rules, terminologyRule())
+	rules = append(rules, crossReferenceRules()...)
+	rules = append(rules, quantityRules()...)
+	rules = append(rules, fenceRules()...)
+	rules = append(rules, tableRules()...)
+	rules = append(rules, duplicateRules()...)
+	rules = append(rules, passiveVoiceRule())
 	return rules
 }
 
@@ -40,7 +49,22 @@ func placeholderRule() Rule {
 		Impact:         "The requirement is incomplete and cannot be safely implemented.",
 		Recommendation: "Replace the placeholder with concrete behavior, constraints, or an explicit non-goal.",
 		Tags:           []string{"placeholder"},
-		Matcher:        linePatternMatcher(placeholderPatterns, false, nil),
+		Fixable:        true,
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			tables := markdownTables(doc.Lines, false)
+			return linePatternMatcher(placeholderPatterns, false, func(f Finding, cfg Config) (Finding, bool) {
+				term := strings.TrimPrefix(f.Tags[1], "term:")
+				// Placeholders in table rows name the row and column.
+				if description := tableCellPlaceholders(tables, f.LineStart, term); description != "" {
+					f.Description = description
+					f.Tags = append(f.Tags, "table")
+				}
+				if term == "TBD" {
+					f.Fixes = openDecisionFixes(f.LineStart, doc.Lines[f.LineStart-1])
+				}
+				return f, true
+			}).Find(doc, rule, cfg)
+		}),
 	}
 }
 
@@ -70,17 +94,35 @@ func weakRequirementRule() Rule {
 		Impact:         "The implementation cannot know whether the behavior is required.",
 		Recommendation: "Use mandatory language such as must, or explicitly mark the behavior as optional with consequences.",
 		Tags:           []string{"weak-requirement"},
-		Matcher: linePatternMatcher(weakPatterns, true, func(f Finding, cfg Config) Finding {
+		Matcher: linePatternMatcher(weakPatterns, true, func(f Finding, cfg Config) (Finding, bool) {
 			if cfg.Strict {
 				f.Severity = schema.SeverityCritical
 				f.Blocking = true
 			}
-			return f
+			return f, true
 		}),
 	}
 }
 
-func linePatternMatcher(patterns []textPattern, suppressExamples bool, adjust func(Finding, Config) Finding) Matcher {
+var tbdRe = regexp.MustCompile(`(?i)\bTBD\b`)
+
+// openDecisionMarker is the text fixes insert for a decision the spec has
+// yet to make.
+const openDecisionMarker = "OPEN DECISION"
+
+// openDecisionFixes turn each TBD outside inline code into an explicit
+// OPEN DECISION marker, the form completion suggestions use.
+func openDecisionFixes(n int, line string) []Fix {
+	var fixes []Fix
+	for _, loc := range tbdRe.FindAllStringIndex(blankInlineCode(line), -1) {
+		fixes = append(fixes, Fix{Line: n, Start: loc[0], End: loc[1], After: openDecisionMarker})
+	}
+	return fixes
+}
+
+// linePatternMatcher reports each line matching a pattern. adjust may
+// rewrite a finding, or drop it by returning false.
+func linePatternMatcher(patterns []textPattern, suppressExamples bool, adjust func(Finding, Config) (Finding, bool)) Matcher {
 	return MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
 		var findings []Finding
 		inSuppressedSection := false
@@ -102,7 +144,10 @@ func linePatternMatcher(patterns []textPattern, suppressExamples bool, adjust fu
 					Tags:      []string{rule.Group, "term:" + pattern.term},
 				}
 				if adjust != nil {
-					finding = adjust(finding, cfg)
+					var keep bool
+					if finding, keep = adjust(finding, cfg); !keep {
+						continue
+					}
 				}
 				findings = append(findings, finding)
 			}
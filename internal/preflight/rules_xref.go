@@ -0,0 +1,330 @@
+package preflight
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/speccritic/internal/chunk"
+	ctxpkg "github.com/dshills/speccritic/internal/context"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+var (
+	inlineLinkRe     = regexp.MustCompile(`(!?)\[[^\]]*\]\(\s*<?([^()\s<>]+)>?(?:\s+["'(][^)]*)?\)`)
+	linkDefinitionRe = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*<?([^\s<>]+)>?`)
+	urlSchemeRe      = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+	htmlAnchorRe     = regexp.MustCompile(`(?i)<[a-z][^>]*\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+	headingIDRe      = regexp.MustCompile(`\s*\{#([^}\s]+)\}\s*$`)
+	closingHashesRe  = regexp.MustCompile(`\s+#+\s*$`)
+	headingLinkRe    = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	headingNumberRe  = regexp.MustCompile(`(?i)^(?:(?:section|§)\s*)?(\d+(?:\.\d+)*)\.?(?:\s|$)`)
+	sectionRefRe     = regexp.MustCompile(`(?i)(?:\bsections?\s+|§\s*)(\d+(?:\.\d+)*)\b`)
+	externalPrefixRe = regexp.MustCompile(`(?i)(?:\b(?:rfc|iso|iec|ieee|bcp)[\s-]*\d+[\d-]*|\S+\.[a-z]{1,5})[,\s]*$`)
+	externalSuffixRe = regexp.MustCompile(`(?i)^\s+(?:of|in)\b`)
+	namedSectionRes  = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bsections?\s+["“]([^"”]{2,80})["”]`),
+		regexp.MustCompile(`(?i)["“]([^"”]{2,80})["”]\s+sections?\b`),
+		regexp.MustCompile(`\b[Ss]ee\s+(?:the\s+)?((?:[A-Z][\w/-]*\s+){1,6})[Ss]ection\b`),
+	}
+)
+
+func crossReferenceRules() []Rule {
+	return []Rule{brokenAnchorRule(), missingSectionNumberRule(), missingSectionNameRule(), missingContextFileRule()}
+}
+
+func brokenAnchorRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-XREF-001",
+		Group:          "cross-reference",
+		Title:          "Link points to a missing anchor",
+		Description:    "An internal link names an anchor that no heading in the spec produces.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Readers cannot find the section the requirement depends on.",
+		Recommendation: "Point the link at an existing heading or restore the missing section.",
+		Tags:           []string{"cross-reference"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			anchors := documentAnchors(doc.Lines)
+			var findings []Finding
+			for _, link := range doc.links() {
+				file, fragment := splitLinkTarget(link.Target)
+				if fragment == "" || (file != "" && !isSpecFile(doc, file)) {
+					continue
+				}
+				if anchors[strings.ToLower(fragment)] {
+					continue
+				}
+				findings = append(findings, Finding{
+					LineStart:   link.Line,
+					Description: fmt.Sprintf("No heading in the spec has the anchor #%s.", fragment),
+					Tags:        []string{rule.Group, "anchor:" + fragment},
+				})
+			}
+			return findings
+		}),
+	}
+}
+
+func missingSectionNumberRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-XREF-002",
+		Group:          "cross-reference",
+		Title:          "Reference to a missing section number",
+		Description:    "The spec refers to a numbered section that does not exist.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Readers cannot find the section the requirement depends on.",
+		Recommendation: "Correct the section number or restore the missing section.",
+		Tags:           []string{"cross-reference"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			numbers := sectionNumbers(doc.headings())
+			// Specs without numbered headings cite other documents' sections.
+			if len(numbers) == 0 {
+				return nil
+			}
+			var findings []Finding
+			for i, line := range doc.prose() {
+				if isMarkdownHeading(line) {
+					continue
+				}
+				for _, loc := range sectionRefRe.FindAllStringSubmatchIndex(line, -1) {
+					number := line[loc[2]:loc[3]]
+					// "RFC 9110 Section 8.8", "SPEC.md §12" and "Section 3 of ..."
+					// cite other documents.
+					if numbers[number] || externalPrefixRe.MatchString(line[:loc[0]]) || externalSuffixRe.MatchString(line[loc[1]:]) {
+						continue
+					}
+					findings = append(findings, Finding{
+						LineStart:   i + 1,
+						Description: fmt.Sprintf("No heading is numbered %s.", number),
+						Tags:        []string{rule.Group, "section:" + number},
+					})
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func missingSectionNameRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-XREF-003",
+		Group:          "cross-reference",
+		Title:          "Reference to a missing section name",
+		Description:    "The spec refers to a section by a name no heading uses.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAmbiguousBehavior,
+		Impact:         "Readers cannot find the section the requirement depends on.",
+		Recommendation: "Use the referenced heading's exact name or restore the missing section.",
+		Tags:           []string{"cross-reference"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			names := sectionNames(doc.headings())
+			var findings []Finding
+			for i, line := range doc.prose() {
+				if isMarkdownHeading(line) {
+					continue
+				}
+				for _, re := range namedSectionRes {
+					for _, match := range re.FindAllStringSubmatch(line, -1) {
+						name := strings.TrimSpace(match[1])
+						if names[sectionNameKey(name)] {
+							continue
+						}
+						findings = append(findings, Finding{
+							LineStart:   i + 1,
+							Description: fmt.Sprintf("No heading is named %q.", name),
+							Tags:        []string{rule.Group},
+						})
+					}
+				}
+			}
+			return findings
+		}),
+	}
+}
+
+func missingContextFileRule() Rule {
+	return Rule{
+		ID:             "PREFLIGHT-XREF-004",
+		Group:          "cross-reference",
+		Title:          "Linked document was not supplied as context",
+		Description:    "The spec links to a local file that is not part of the spec or its context files.",
+		Severity:       schema.SeverityWarn,
+		Category:       schema.CategoryAssumptionRequired,
+		Impact:         "The review cannot see the linked document, so requirements that depend on it are checked blind.",
+		Recommendation: "Pass the linked file with --context, or inline the parts the spec depends on.",
+		Tags:           []string{"cross-reference"},
+		Matcher: MatcherFunc(func(doc Document, rule Rule, cfg Config) []Finding {
+			var findings []Finding
+			seen := make(map[string]bool)
+			for _, link := range doc.links() {
+				file, _ := splitLinkTarget(link.Target)
+				if file == "" || isSpecFile(doc, file) || isContextFile(cfg.Context, file) || seen[file] {
+					continue
+				}
+				seen[file] = true
+				findings = append(findings, Finding{
+					LineStart:   link.Line,
+					Description: fmt.Sprintf("%s is linked but was not supplied as a context file.", file),
+					Tags:        []string{rule.Group, "file:" + file},
+				})
+			}
+			return findings
+		}),
+	}
+}
+
+type docLink struct {
+	Line   int
+	Target string
+}
+
+// documentLinks returns the targets of inline links and link reference
+// definitions in the prose lines of a document, which leave out code and
+// example sections. Images and links with a URL scheme are skipped.
+func documentLinks(prose []string) []docLink {
+	var links []docLink
+	for i, line := range prose {
+		var targets []string
+		for _, match := range inlineLinkRe.FindAllStringSubmatch(line, -1) {
+			if match[1] == "" {
+				targets = append(targets, match[2])
+			}
+		}
+		if match := linkDefinitionRe.FindStringSubmatch(line); match != nil {
+			targets = append(targets, match[1])
+		}
+		for _, target := range targets {
+			if urlSchemeRe.MatchString(target) || strings.HasPrefix(target, "//") {
+				continue
+			}
+			links = append(links, docLink{Line: i + 1, Target: target})
+		}
+	}
+	return links
+}
+
+// splitLinkTarget splits a link target into its unescaped file path and
+// fragment.
+func splitLinkTarget(target string) (string, string) {
+	file, fragment, _ := strings.Cut(target, "#")
+	if unescaped, err := url.PathUnescape(file); err == nil {
+		file = unescaped
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	file, _, _ = strings.Cut(file, "?")
+	if file != "" {
+		file = path.Clean(file)
+	}
+	return file, fragment
+}
+
+func isSpecFile(doc Document, file string) bool {
+	if path.Base(file) == path.Base(doc.Path) {
+		return true
+	}
+	for _, specFile := range doc.Files {
+		if path.Base(file) == path.Base(specFile) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContextFile matches a linked file against the supplied context files by
+// base name, since links are relative to the spec while context paths are
+// relative to wherever the command ran.
+func isContextFile(contextFiles []ctxpkg.ContextFile, file string) bool {
+	for _, contextFile := range contextFiles {
+		if path.Base(strings.ReplaceAll(contextFile.Path, `\`, "/")) == path.Base(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentAnchors returns the lowercased anchors the document defines:
+// heading slugs, explicit {#id} heading attributes and HTML id or name
+// attributes.
+func documentAnchors(lines []string) map[string]bool {
+	fenced := fencedLines(lines)
+	anchors := make(map[string]bool)
+	used := make(map[string]int)
+	for _, h := range headings(lines, fenced) {
+		if match := headingIDRe.FindStringSubmatch(h.Text); match != nil {
+			anchors[strings.ToLower(match[1])] = true
+		}
+		slug := headingSlug(h.Text)
+		if n := used[slug]; n > 0 {
+			anchors[fmt.Sprintf("%s-%d", slug, n)] = true
+		} else {
+			anchors[slug] = true
+		}
+		used[slug]++
+	}
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		for _, match := range htmlAnchorRe.FindAllStringSubmatch(line, -1) {
+			anchors[strings.ToLower(match[1])] = true
+		}
+	}
+	return anchors
+}
+
+// headingSlug generates a heading's anchor the way GitHub and most
+// renderers do: link text is kept, the text is lowercased, punctuation other
+// than hyphens and underscores is dropped and spaces become hyphens.
+// Duplicate slugs get -1, -2 and so on, which documentAnchors adds.
+func headingSlug(text string) string {
+	text = headingIDRe.ReplaceAllString(text, "")
+	text = closingHashesRe.ReplaceAllString(text, "")
+	text = headingLinkRe.ReplaceAllString(text, "$1")
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// sectionNumbers returns the numbers of headings such as "4.2 Retries" or
+// "Section 4.2: Retries".
+func sectionNumbers(hs []chunk.Heading) map[string]bool {
+	numbers := make(map[string]bool)
+	for _, h := range hs {
+		if match := headingNumberRe.FindStringSubmatch(h.Text); match != nil {
+			numbers[match[1]] = true
+		}
+	}
+	return numbers
+}
+
+// sectionNames returns heading names keyed with and without their section
+// numbers.
+func sectionNames(hs []chunk.Heading) map[string]bool {
+	names := make(map[string]bool)
+	for _, h := range hs {
+		text := headingIDRe.ReplaceAllString(h.Text, "")
+		names[sectionNameKey(text)] = true
+		names[sectionNameKey(headingNumberRe.ReplaceAllString(text, ""))] = true
+	}
+	return names
+}
+
+func sectionNameKey(name string) string {
+	key := normalizeText(name)
+	key = strings.TrimPrefix(key, "the ")
+	return strings.TrimSuffix(key, " section")
+}
@@ -0,0 +1,112 @@
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	ctxpkg "github.com/dshills/speccritic/internal/context"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/spec"
+)
+
+const crossReferenceSpec = `# Upload Service
+
+## 1. Overview
+
+Uploads follow [the limits](#2-limits) and [error codes](#3-error-codes).
+Retries are described in [retries](#retry-policy) and [duplicates](#notes-1).
+
+## 2. Limits {#limits}
+
+Files larger than 10 MB are rejected; see Section 3 and Section 4.2.
+RFC 9110 Section 15.5 defines 413. Section 9 of the platform guide covers quotas.
+See the Error Codes section and the "Retry Policy" section.
+
+## 3. Error Codes
+
+Codes are listed in [the catalog](errors.md) and [the API](./api/openapi.yaml#/paths).
+
+## Notes
+
+## Notes
+
+` + "```" + `
+[example](#nowhere) see Section 8
+` + "```" + `
+`
+
+func TestCrossReferenceRulesFlagBrokenAnchors(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", crossReferenceSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-XREF-001", schema.SeverityWarn, 6)
+	if !hasTag(issue.Tags, "anchor:retry-policy") || countIssues(result.Issues, "PREFLIGHT-XREF-001") != 1 {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+}
+
+func TestCrossReferenceRulesFlagMissingSectionNumbers(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", crossReferenceSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-XREF-002", schema.SeverityWarn, 10)
+	if issue.Description != "No heading is numbered 4.2." || countIssues(result.Issues, "PREFLIGHT-XREF-002") != 1 {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+
+	unnumbered := runBuiltin(t, "SPEC.md", "## Limits\nSee Section 4.2.\n")
+	if findIssue(unnumbered.Issues, "PREFLIGHT-XREF-002") != nil {
+		t.Fatal("section numbers checked in a spec without numbered headings")
+	}
+}
+
+func TestCrossReferenceRulesFlagMissingSectionNames(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", crossReferenceSpec)
+	issue := requireIssue(t, result.Issues, "PREFLIGHT-XREF-003", schema.SeverityWarn, 12)
+	if issue.Description != `No heading is named "Retry Policy".` || countIssues(result.Issues, "PREFLIGHT-XREF-003") != 1 {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+}
+
+func TestCrossReferenceRulesFlagMissingContextFiles(t *testing.T) {
+	result := runBuiltin(t, "SPEC.md", crossReferenceSpec)
+	if n := countIssues(result.Issues, "PREFLIGHT-XREF-004"); n != 2 {
+		t.Fatalf("context file findings = %d, want 2", n)
+	}
+	issue := findIssue(result.Issues, "PREFLIGHT-XREF-004")
+	if issue.Category != schema.CategoryAssumptionRequired || !hasTag(issue.Tags, "file:errors.md") {
+		t.Fatalf("issue = %#v", issue)
+	}
+
+	supplied, err := Run(spec.New("SPEC.md", crossReferenceSpec), Config{Enabled: true, Profile: "general", Context: []ctxpkg.ContextFile{{Path: "docs/errors.md"}, {Path: "openapi.yaml"}}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if findIssue(supplied.Issues, "PREFLIGHT-XREF-004") != nil {
+		t.Fatalf("issues = %#v", supplied.Issues)
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	for text, want := range map[string]string{
+		"2. Limits":                "2-limits",
+		"Error Codes":              "error-codes",
+		"API & `Auth` Flow":        "api--auth-flow",
+		"[Retry](#x) Policy ##":    "retry-policy",
+		"Snake_case and-hyphens!":  "snake_case-and-hyphens",
+		"Überprüfung der Eingaben": "überprüfung-der-eingaben",
+	} {
+		if got := headingSlug(text); got != want {
+			t.Fatalf("headingSlug(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestCrossReferenceRulesResolveCompositeSpecFiles(t *testing.T) {
+	text := strings.Join([]string{"# Overview", "See [auth](auth.md#tokens).", "## Tokens"}, "\n")
+	s := spec.New("specs", text)
+	s.Files = []spec.File{{Path: "specs/overview.md"}, {Path: "specs/auth.md"}}
+	result, err := Run(s, Config{Enabled: true, Profile: "general"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if findIssue(result.Issues, "PREFLIGHT-XREF-001") != nil || findIssue(result.Issues, "PREFLIGHT-XREF-004") != nil {
+		t.Fatalf("issues = %#v", result.Issues)
+	}
+}
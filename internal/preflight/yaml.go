@@ -0,0 +1,582 @@
+package preflight
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML support here covers what specs and OpenAPI documents use: block
+// mappings and sequences, flow collections, quoted and plain scalars, block
+// scalars and comments. Anchors and tags are dropped, aliases are read as
+// plain text and only the first document of a stream is parsed. Complex
+// "? key" entries are not supported; parseYAML returns errYAMLUnsupported for
+// them, which is not a syntax error and is not reported.
+
+// yamlError is a YAML syntax error at a 1-based line of the parsed text.
+type yamlError struct {
+	Line int
+	Msg  string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var (
+	yamlIntRe   = regexp.MustCompile(`^[-+]?(?:0|[1-9][0-9_]*)$`)
+	yamlFloatRe = regexp.MustCompile(`^[-+]?(?:\d[\d_]*)?\.?\d+(?:[eE][-+]?\d+)?$`)
+	yamlBlockRe = regexp.MustCompile(`^[|>][-+0-9]*$`)
+)
+
+var errYAMLUnsupported = errors.New("unsupported YAML construct")
+
+type yamlLine struct {
+	N      int
+	Indent int
+	Text   string
+	Raw    string
+	Blank  bool
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+	paths map[string]int
+}
+
+// parseYAML parses YAML text and returns the value with the line of each
+// mapping key and sequence item, keyed by JSON pointer.
+func parseYAML(lines []string) (any, map[string]int, error) {
+	p := &yamlParser{paths: map[string]int{"": 1}}
+	started := false
+	for i, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "%") {
+			if started {
+				break
+			}
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.HasPrefix(raw[indent:], "\t") && trimmed != "" {
+			return nil, nil, &yamlError{Line: i + 1, Msg: "tab character in indentation"}
+		}
+		text := strings.TrimSpace(stripYAMLComment(raw[indent:]))
+		if isYAMLComplexKey(strings.TrimLeft(text, "- ")) {
+			return nil, nil, errYAMLUnsupported
+		}
+		p.lines = append(p.lines, yamlLine{N: i + 1, Indent: indent, Text: text, Raw: raw, Blank: text == ""})
+		if text != "" && !started {
+			started = true
+			p.paths[""] = i + 1
+		}
+	}
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil, p.paths, nil
+	}
+	value, err := p.parseNode(p.lines[p.pos].Indent, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		return nil, nil, &yamlError{Line: p.lines[p.pos].N, Msg: "inconsistent indentation"}
+	}
+	return value, p.paths, nil
+}
+
+// stripYAMLComment removes a trailing comment outside quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:-", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].Blank {
+		p.pos++
+	}
+}
+
+func (p *yamlParser) parseNode(indent int, path string) (any, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) || p.lines[p.pos].Indent < indent {
+		return nil, nil
+	}
+	line := p.lines[p.pos]
+	if isYAMLSeqItem(line.Text) {
+		return p.parseSeq(line.Indent, path)
+	}
+	if _, _, ok := splitYAMLKey(line.Text); ok {
+		return p.parseMap(line.Indent, path)
+	}
+	value, err := p.parseInlineValue(line, line.Indent, path)
+	if err != nil {
+		return nil, err
+	}
+	return value, p.checkNext(line.Indent)
+}
+
+// isYAMLComplexKey reports "? key" and ": value" lines of a complex
+// mapping entry.
+func isYAMLComplexKey(text string) bool {
+	return text == "?" || text == ":" || strings.HasPrefix(text, "? ") || strings.HasPrefix(text, ": ")
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseSeq(indent int, path string) (any, error) {
+	var items []any
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].Indent != indent || !isYAMLSeqItem(p.lines[p.pos].Text) {
+			return items, nil
+		}
+		line := p.lines[p.pos]
+		itemPath := fmt.Sprintf("%s/%d", path, len(items))
+		p.paths[itemPath] = line.N
+		rest := strings.TrimSpace(strings.TrimPrefix(line.Text, "-"))
+		var item any
+		var err error
+		switch {
+		case stripYAMLProperties(rest) == "":
+			// An anchor or tag alone applies to the block below it.
+			p.pos++
+			p.skipBlank()
+			if p.pos < len(p.lines) && p.lines[p.pos].Indent > indent {
+				item, err = p.parseNode(p.lines[p.pos].Indent, itemPath)
+			}
+		default:
+			// "- key: value" and "- - item" open a nested block at the
+			// column after the dash.
+			offset := strings.Index(line.Raw, rest)
+			nested := line
+			nested.Indent, nested.Text = offset, rest
+			p.lines[p.pos] = nested
+			if _, _, ok := splitYAMLKey(rest); ok || isYAMLSeqItem(rest) {
+				item, err = p.parseNode(offset, itemPath)
+			} else {
+				item, err = p.parseInlineValue(nested, indent, itemPath)
+				if err == nil {
+					err = p.checkNext(indent)
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if err := p.checkDedent(indent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *yamlParser) parseMap(indent int, path string) (any, error) {
+	out := make(map[string]any)
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].Indent != indent {
+			return out, nil
+		}
+		line := p.lines[p.pos]
+		if isYAMLSeqItem(line.Text) {
+			return nil, &yamlError{Line: line.N, Msg: "sequence item where a mapping key was expected"}
+		}
+		key, rest, ok := splitYAMLKey(line.Text)
+		if !ok {
+			return nil, &yamlError{Line: line.N, Msg: `expected "key: value"`}
+		}
+		if _, dup := out[key]; dup {
+			return nil, &yamlError{Line: line.N, Msg: fmt.Sprintf("duplicate key %q", key)}
+		}
+		keyPath := path + "/" + escapePointer(key)
+		p.paths[keyPath] = line.N
+		var value any
+		var err error
+		if stripYAMLProperties(rest) == "" {
+			p.pos++
+			p.skipBlank()
+			switch {
+			case p.pos >= len(p.lines):
+			case p.lines[p.pos].Indent > indent:
+				value, err = p.parseNode(p.lines[p.pos].Indent, keyPath)
+			case p.lines[p.pos].Indent == indent && isYAMLSeqItem(p.lines[p.pos].Text):
+				value, err = p.parseSeq(indent, keyPath)
+			}
+		} else {
+			line.Text = rest
+			value, err = p.parseInlineValue(line, indent, keyPath)
+			if err == nil {
+				err = p.checkNext(indent)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[key] = value
+		if err := p.checkDedent(indent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// checkNext rejects a more-indented line after a complete inline value.
+func (p *yamlParser) checkNext(indent int) error {
+	p.skipBlank()
+	if p.pos < len(p.lines) && p.lines[p.pos].Indent > indent {
+		return &yamlError{Line: p.lines[p.pos].N, Msg: "unexpected indentation"}
+	}
+	return nil
+}
+
+// checkDedent rejects a line that returns to a column between two open
+// blocks.
+func (p *yamlParser) checkDedent(indent int) error {
+	p.skipBlank()
+	if p.pos < len(p.lines) && p.lines[p.pos].Indent > indent {
+		return &yamlError{Line: p.lines[p.pos].N, Msg: "inconsistent indentation"}
+	}
+	return nil
+}
+
+// splitYAMLKey splits "key: value" outside quotes and flow collections.
+func splitYAMLKey(text string) (string, string, bool) {
+	if text == "" || strings.ContainsRune("[{", rune(text[0])) {
+		return "", "", false
+	}
+	end := -1
+	if text[0] == '"' || text[0] == '\'' {
+		end = closingQuote(text, 0)
+		if end < 0 {
+			return "", "", false
+		}
+		end++
+	}
+	start := max(end, 0)
+	for i := start; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			key := strings.TrimSpace(text[:i])
+			if end > 0 && i != end && strings.TrimSpace(text[end:i]) != "" {
+				return "", "", false
+			}
+			if end > 0 {
+				key = unquoteYAML(key)
+			}
+			return key, strings.TrimSpace(text[i+1:]), true
+		}
+		if end < 0 && (text[i] == '"' || text[i] == '\'') && i > 0 && text[i-1] == ' ' {
+			return "", "", false
+		}
+	}
+	return "", "", false
+}
+
+// parseInlineValue parses the value on line.Text. Block scalars, quoted
+// scalars and flow collections may continue on the following lines.
+func (p *yamlParser) parseInlineValue(line yamlLine, indent int, path string) (any, error) {
+	text := stripYAMLProperties(line.Text)
+	switch {
+	case yamlBlockRe.MatchString(text):
+		p.pos++
+		return p.blockScalar(indent, strings.HasPrefix(text, ">")), nil
+	case strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{"):
+		for !flowBalanced(text) && p.pos+1 < len(p.lines) && p.lines[p.pos+1].Indent > indent {
+			p.pos++
+			text += " " + p.lines[p.pos].Text
+		}
+		p.pos++
+		value, err := parseFlow(text, line.N, path, p.paths)
+		return value, err
+	case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'"):
+		for closingQuote(text, 0) < 0 && p.pos+1 < len(p.lines) && p.lines[p.pos+1].Indent > indent {
+			p.pos++
+			text += " " + strings.TrimSpace(p.lines[p.pos].Raw)
+		}
+		end := closingQuote(text, 0)
+		if end < 0 {
+			return nil, &yamlError{Line: line.N, Msg: "unterminated quoted string"}
+		}
+		if strings.TrimSpace(text[end+1:]) != "" {
+			return nil, &yamlError{Line: line.N, Msg: "unexpected text after quoted string"}
+		}
+		p.pos++
+		return unquoteYAML(text), nil
+	}
+	if _, _, ok := splitYAMLKey(text); ok {
+		return nil, &yamlError{Line: line.N, Msg: "mapping values are not allowed here"}
+	}
+	// Plain scalars fold more-indented continuation lines.
+	p.pos++
+	for p.pos < len(p.lines) && (p.lines[p.pos].Blank || p.lines[p.pos].Indent > indent) {
+		if !p.lines[p.pos].Blank {
+			if _, _, ok := splitYAMLKey(p.lines[p.pos].Text); ok {
+				return nil, &yamlError{Line: p.lines[p.pos].N, Msg: "unexpected indentation"}
+			}
+			text += " " + p.lines[p.pos].Text
+		}
+		p.pos++
+	}
+	return resolveYAMLScalar(text), nil
+}
+
+// blockScalar reads the lines of a | or > scalar, which are all the lines
+// indented past the owning key.
+func (p *yamlParser) blockScalar(indent int, folded bool) string {
+	var parts []string
+	base := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line.Raw) != "" && line.Indent <= indent {
+			break
+		}
+		if base < 0 && strings.TrimSpace(line.Raw) != "" {
+			base = line.Indent
+		}
+		text := ""
+		if len(line.Raw) > base && base >= 0 {
+			text = line.Raw[base:]
+		}
+		parts = append(parts, text)
+		p.pos++
+	}
+	sep := "\n"
+	if folded {
+		sep = " "
+	}
+	return strings.TrimRight(strings.Join(parts, sep), "\n ") + "\n"
+}
+
+// stripYAMLProperties drops anchors and tags before a value.
+func stripYAMLProperties(text string) string {
+	for strings.HasPrefix(text, "&") || strings.HasPrefix(text, "!") {
+		_, rest, found := strings.Cut(text, " ")
+		if !found {
+			return ""
+		}
+		text = strings.TrimSpace(rest)
+	}
+	return text
+}
+
+func closingQuote(text string, start int) int {
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteYAML(text string) string {
+	if strings.HasPrefix(text, "'") {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	if s, err := strconv.Unquote(text); err == nil {
+		return s
+	}
+	return text[1 : len(text)-1]
+}
+
+func resolveYAMLScalar(text string) any {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlIntRe.MatchString(text) || yamlFloatRe.MatchString(text) {
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+func flowBalanced(text string) bool {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			end := closingQuote(text, i)
+			if end < 0 {
+				return false
+			}
+			i = end
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// flowParser reads a single-line flow collection such as [a, b] or
+// {a: 1, b: [2, 3]}.
+type flowParser struct {
+	text  string
+	i     int
+	line  int
+	paths map[string]int
+}
+
+func parseFlow(text string, line int, path string, paths map[string]int) (any, error) {
+	fp := &flowParser{text: text, line: line, paths: paths}
+	value, err := fp.value(path)
+	if err != nil {
+		return nil, err
+	}
+	fp.space()
+	if fp.i < len(fp.text) {
+		return nil, fp.errorf("unexpected %q after flow collection", fp.text[fp.i:])
+	}
+	return value, nil
+}
+
+func (fp *flowParser) errorf(format string, args ...any) error {
+	return &yamlError{Line: fp.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (fp *flowParser) space() {
+	for fp.i < len(fp.text) && fp.text[fp.i] == ' ' {
+		fp.i++
+	}
+}
+
+func (fp *flowParser) value(path string) (any, error) {
+	fp.space()
+	if fp.i >= len(fp.text) {
+		return nil, fp.errorf("unclosed flow collection")
+	}
+	switch fp.text[fp.i] {
+	case '[':
+		fp.i++
+		var items []any
+		for {
+			fp.space()
+			if fp.i < len(fp.text) && fp.text[fp.i] == ']' {
+				fp.i++
+				return items, nil
+			}
+			itemPath := fmt.Sprintf("%s/%d", path, len(items))
+			fp.paths[itemPath] = fp.line
+			item, err := fp.value(itemPath)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if err := fp.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		fp.i++
+		out := make(map[string]any)
+		for {
+			fp.space()
+			if fp.i < len(fp.text) && fp.text[fp.i] == '}' {
+				fp.i++
+				return out, nil
+			}
+			key, err := fp.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			fp.space()
+			if fp.i >= len(fp.text) || fp.text[fp.i] != ':' {
+				return nil, fp.errorf("expected ':' after key %q", fmt.Sprint(key))
+			}
+			fp.i++
+			name := fmt.Sprint(key)
+			if _, dup := out[name]; dup {
+				return nil, fp.errorf("duplicate key %q", name)
+			}
+			keyPath := path + "/" + escapePointer(name)
+			fp.paths[keyPath] = fp.line
+			value, err := fp.value(keyPath)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = value
+			if err := fp.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fp.scalar(false)
+}
+
+// separator consumes a comma, or leaves the closing bracket for the caller.
+func (fp *flowParser) separator(closing byte) error {
+	fp.space()
+	switch {
+	case fp.i >= len(fp.text):
+		return fp.errorf("unclosed flow collection")
+	case fp.text[fp.i] == ',':
+		fp.i++
+		return nil
+	case fp.text[fp.i] == closing:
+		return nil
+	}
+	return fp.errorf("expected ',' or '%c', got %q", closing, fp.text[fp.i:fp.i+1])
+}
+
+func (fp *flowParser) scalar(key bool) (any, error) {
+	fp.space()
+	if fp.i < len(fp.text) && (fp.text[fp.i] == '"' || fp.text[fp.i] == '\'') {
+		end := closingQuote(fp.text, fp.i)
+		if end < 0 {
+			return nil, fp.errorf("unterminated quoted string")
+		}
+		s := unquoteYAML(fp.text[fp.i : end+1])
+		fp.i = end + 1
+		return s, nil
+	}
+	start := fp.i
+	for fp.i < len(fp.text) && !strings.ContainsRune(",]}", rune(fp.text[fp.i])) {
+		if fp.text[fp.i] == ':' && (key || fp.i+1 == len(fp.text) || fp.text[fp.i+1] == ' ') {
+			break
+		}
+		fp.i++
+	}
+	text := strings.TrimSpace(fp.text[start:fp.i])
+	if key {
+		return text, nil
+	}
+	return resolveYAMLScalar(stripYAMLProperties(text)), nil
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// BatchRenderer formats a BatchReport into bytes for output.
+type BatchRenderer interface {
+	RenderBatch(report *schema.BatchReport) ([]byte, error)
+}
+
+// NewBatchRenderer returns a BatchRenderer for the given format string.
+// Supported formats: "json" (default), "md".
+func NewBatchRenderer(format string) (BatchRenderer, error) {
+	switch format {
+	case "json":
+		return &jsonRenderer{}, nil
+	case "md":
+		return &markdownRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q: supported formats are json, md", format)
+	}
+}
+
+func (r *jsonRenderer) RenderBatch(report *schema.BatchReport) ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
+
+type markdownBatchView struct {
+	*schema.BatchReport
+	Reports []markdownBatchReport
+}
+
+type markdownBatchReport struct {
+	SpecFile string
+	Body     string
+}
+
+var mdBatchTemplate = template.Must(template.New("batch").Parse(`# SpecCritic Batch Report
+
+**Verdict:** {{ .Summary.Verdict }}
+**Specs:** {{ .Summary.SpecCount }} | **Failed:** {{ .Summary.FailedCount }}
+**Minimum score:** {{ .Summary.MinScore }}/100 | **Average score:** {{ .Summary.AverageScore }}/100
+**Critical:** {{ .Summary.CriticalCount }} | **Warn:** {{ .Summary.WarnCount }} | **Info:** {{ .Summary.InfoCount }}
+
+| Spec | Verdict | Score | Critical | Warn | Info |
+|------|---------|-------|----------|------|------|
+{{ range .Specs }}{{ if .Error }}| {{ .SpecFile }} | ERROR | — | — | — | — |
+{{ else }}| {{ .SpecFile }} | {{ .Verdict }} | {{ .Score }} | {{ .Report.Summary.CriticalCount }} | {{ .Report.Summary.WarnCount }} | {{ .Report.Summary.InfoCount }} |
+{{ end }}{{ end }}{{ if .Summary.FailedCount }}
+## Failed Specs
+{{ range .Specs }}{{ if .Error }}
+- **{{ .SpecFile }}:** {{ .Error }}{{ end }}{{ end }}
+{{ end }}{{ range .Reports }}
+---
+
+**Spec file:** {{ .SpecFile }}
+
+{{ .Body }}{{ end }}`))
+
+func (r *markdownRenderer) RenderBatch(report *schema.BatchReport) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("rendering markdown: batch report is nil")
+	}
+	view := markdownBatchView{BatchReport: report}
+	for _, entry := range report.Specs {
+		if entry.Report == nil {
+			continue
+		}
+		data, err := r.Render(entry.Report)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", entry.SpecFile, err)
+		}
+		view.Reports = append(view.Reports, markdownBatchReport{SpecFile: entry.SpecFile, Body: string(data)})
+	}
+	var buf bytes.Buffer
+	if err := mdBatchTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("rendering markdown: %w", err)
+	}
+	return buf.Bytes(), nil
+}
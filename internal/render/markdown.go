@@ -3,6 +3,7 @@ package render
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"github.com/dshills/speccritic/internal/schema"
@@ -18,6 +19,16 @@ type markdownView struct {
 	HasIssues         bool
 	HasConvergence    bool
 	HasCompletion     bool
+	NormativeKeywords []string
+	NormativeRows     []normativeRow
+}
+
+// normativeRow is one section of the normative keyword table, with counts in
+// schema.NormativeKeywords order.
+type normativeRow struct {
+	Section string
+	Lines   string
+	Counts  []int
 }
 
 var mdTemplate = template.Must(template.New("report").Parse(`# SpecCritic Report
@@ -39,6 +50,17 @@ var mdTemplate = template.Must(template.New("report").Parse(`# SpecCritic Report
 > {{ . }}
 {{ end }}
 {{ end }}
+{{ if .NormativeRows }}
+---
+
+## Normative Keywords
+{{ if .Meta.Normative.RFC2119 }}
+The spec declares RFC 2119 conventions; only uppercase keywords are counted.
+{{ end }}
+| Section | Lines |{{ range .NormativeKeywords }} {{ . }} |{{ end }}
+|---|---|{{ range .NormativeKeywords }}---:|{{ end }}
+{{ range .NormativeRows }}| {{ .Section }} | {{ .Lines }} |{{ range .Counts }} {{ . }} |{{ end }}
+{{ end }}{{ end }}
 {{ if .HasCompletion }}
 ---
 
@@ -79,7 +101,9 @@ After:
 {{ .Question }}
 
 *Why needed:* {{ .WhyNeeded }}
-{{ range .Evidence }}
+{{ if .Answer }}
+**Answered:** {{ .Answer }}
+{{ end }}{{ range .Evidence }}
 > {{ .Path }} L{{ .LineStart }}–{{ .LineEnd }}: "{{ .Quote }}"
 {{ end }}{{ end }}{{ end }}{{ if .Patches }}
 ---
@@ -146,9 +170,32 @@ func newMarkdownView(report *schema.Report) markdownView {
 			}
 		}
 	}
+	if normative := report.Meta.Normative; normative != nil {
+		view.NormativeKeywords = schema.NormativeKeywords
+		for _, section := range normative.Sections {
+			name := strings.ReplaceAll(section.Heading, "|", `\|`)
+			if name == "" {
+				name = "(before first heading)"
+			}
+			view.NormativeRows = append(view.NormativeRows, normativeRow{
+				Section: name,
+				Lines:   fmt.Sprintf("%d–%d", section.LineStart, section.LineEnd),
+				Counts:  keywordCounts(section.Counts),
+			})
+		}
+		view.NormativeRows = append(view.NormativeRows, normativeRow{Section: "**Total**", Counts: keywordCounts(normative.Counts)})
+	}
 	return view
 }
 
+func keywordCounts(counts map[string]int) []int {
+	out := make([]int, len(schema.NormativeKeywords))
+	for i, keyword := range schema.NormativeKeywords {
+		out[i] = counts[keyword]
+	}
+	return out
+}
+
 func hasTag(tags []string, want string) bool {
 	for _, tag := range tags {
 		if tag == want {
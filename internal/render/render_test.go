@@ -174,6 +174,37 @@ func TestNewRenderer_MarkdownRendersCompletionSuggestions(t *testing.T) {
 	}
 }
 
+func TestNewRenderer_MarkdownRendersNormativeCounts(t *testing.T) {
+	report := sampleReport()
+	report.Meta.Normative = &schema.NormativeMeta{
+		RFC2119: true,
+		Counts:  map[string]int{"MUST": 3, "MAY": 1},
+		Sections: []schema.NormativeSection{
+			{Heading: "Upload | Download", LineStart: 4, LineEnd: 12, Counts: map[string]int{"MUST": 3, "MAY": 1}},
+		},
+	}
+	r, err := NewRenderer("md")
+	if err != nil {
+		t.Fatalf("NewRenderer md: %v", err)
+	}
+	out, err := r.Render(report)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	s := string(out)
+	for _, want := range []string{
+		"## Normative Keywords",
+		"only uppercase keywords are counted",
+		"| Section | Lines | MUST | MUST NOT | SHALL | SHALL NOT | SHOULD | SHOULD NOT | MAY |",
+		`| Upload \| Download | 4–12 | 3 | 0 | 0 | 0 | 0 | 0 | 1 |`,
+		"| **Total** |  | 3 | 0 | 0 | 0 | 0 | 0 | 1 |",
+	} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("markdown missing %q: %s", want, s)
+		}
+	}
+}
+
 func TestNewRenderer_MarkdownOmitsCompletionByDefault(t *testing.T) {
 	r, err := NewRenderer("md")
 	if err != nil {
@@ -218,3 +249,57 @@ func TestNewRenderer_UnknownFormat(t *testing.T) {
 		t.Error("expected error for unknown format, got nil")
 	}
 }
+
+func sampleBatchReport() *schema.BatchReport {
+	return &schema.BatchReport{
+		Tool:    "speccritic",
+		Version: "1.0",
+		Summary: schema.BatchSummary{Verdict: schema.VerdictInvalid, SpecCount: 2, FailedCount: 1, MinScore: 60, AverageScore: 60, CriticalCount: 2, WarnCount: 1},
+		Specs: []schema.BatchEntry{
+			{SpecFile: "billing/SPEC.md", Verdict: schema.VerdictInvalid, Score: 60, Report: sampleReport()},
+			{SpecFile: "search/SPEC.md", Error: "reading spec file: missing"},
+		},
+	}
+}
+
+func TestNewBatchRenderer_JSON(t *testing.T) {
+	r, err := NewBatchRenderer("json")
+	if err != nil {
+		t.Fatalf("NewBatchRenderer: %v", err)
+	}
+	data, err := r.RenderBatch(sampleBatchReport())
+	if err != nil {
+		t.Fatalf("RenderBatch: %v", err)
+	}
+	var decoded schema.BatchReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Summary.Verdict != schema.VerdictInvalid || len(decoded.Specs) != 2 || decoded.Specs[0].Report == nil {
+		t.Fatalf("decoded = %+v", decoded)
+	}
+}
+
+func TestNewBatchRenderer_Markdown(t *testing.T) {
+	r, err := NewBatchRenderer("md")
+	if err != nil {
+		t.Fatalf("NewBatchRenderer: %v", err)
+	}
+	data, err := r.RenderBatch(sampleBatchReport())
+	if err != nil {
+		t.Fatalf("RenderBatch: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"# SpecCritic Batch Report",
+		"| billing/SPEC.md | INVALID | 60 | 2 | 1 | 0 |",
+		"| search/SPEC.md | ERROR |",
+		"- **search/SPEC.md:** reading spec file: missing",
+		"**Spec file:** billing/SPEC.md",
+		"# SpecCritic Report",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
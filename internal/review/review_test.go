@@ -156,3 +156,14 @@ func TestFilterBySeverity_InfoThreshold_ReturnsAll(t *testing.T) {
 		t.Errorf("expected 3 issues with INFO threshold, got %d", len(filtered))
 	}
 }
+
+func TestScoreAndVerdictIgnoreAnsweredQuestions(t *testing.T) {
+	questions := makeQuestions(schema.SeverityCritical)
+	questions[0].Status = schema.QuestionStatusAnswered
+	if got := Score(nil, questions); got != 100 {
+		t.Errorf("Score = %d, want 100", got)
+	}
+	if v := Verdict(nil, questions); v != schema.VerdictValid {
+		t.Errorf("Verdict = %q, want VALID", v)
+	}
+}
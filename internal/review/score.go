@@ -5,8 +5,10 @@ import "github.com/dshills/speccritic/internal/schema"
 // Score computes the deterministic score from all issues and questions.
 // Score is always computed before any --severity-threshold filtering.
 // Start: 100, -20 per CRITICAL, -7 per WARN, -2 per INFO, clamped at 0.
-// Questions contribute the same deductions as issues of equal severity.
+// Open questions contribute the same deductions as issues of equal severity;
+// answered questions are ignored.
 func Score(issues []schema.Issue, questions []schema.Question) int {
+	questions = schema.OpenQuestions(questions)
 	score := 100
 	for _, issue := range issues {
 		switch issue.Severity {
@@ -38,7 +40,9 @@ func Score(issues []schema.Issue, questions []schema.Question) int {
 // CRITICAL questions are treated equivalently to CRITICAL issues: a spec
 // with only CRITICAL questions (and no issues) receives INVALID.
 // Verdict is always computed before any --severity-threshold filtering.
+// Answered questions are ignored.
 func Verdict(issues []schema.Issue, questions []schema.Question) schema.Verdict {
+	questions = schema.OpenQuestions(questions)
 	for _, issue := range issues {
 		if issue.Severity == schema.SeverityCritical {
 			return schema.VerdictInvalid
@@ -20,6 +20,24 @@ type Input struct {
 	Profile           string   `json:"profile"`
 	Strict            bool     `json:"strict"`
 	SeverityThreshold string   `json:"severity_threshold"`
+	// Scope is set when the review was restricted to selected sections.
+	Scope *ReviewScope `json:"scope,omitempty"`
+}
+
+// ReviewScope records which sections of the spec a scoped review covered.
+type ReviewScope struct {
+	Sections        []string     `json:"sections,omitempty"`
+	ExcludeSections []string     `json:"exclude_sections,omitempty"`
+	Ranges          []ScopeRange `json:"ranges"`
+}
+
+// ScopeRange is one contiguous in-scope line range. Path is set for
+// multi-file specs, where the lines are in that file.
+type ScopeRange struct {
+	Path        string   `json:"path,omitempty"`
+	LineStart   int      `json:"line_start"`
+	LineEnd     int      `json:"line_end"`
+	HeadingPath []string `json:"heading_path,omitempty"`
 }
 
 // Summary holds the computed verdict and issue counts.
@@ -32,6 +50,38 @@ type Summary struct {
 	InfoCount     int     `json:"info_count"`
 }
 
+// BatchReport aggregates the reports produced by a multi-spec check run.
+type BatchReport struct {
+	Tool    string       `json:"tool"`
+	Version string       `json:"version"`
+	Summary BatchSummary `json:"summary"`
+	Specs   []BatchEntry `json:"specs"`
+}
+
+// BatchSummary holds the aggregate verdict and counts across all specs.
+// Verdict is the worst verdict of the specs that completed; failed specs are
+// counted separately and do not contribute a verdict.
+type BatchSummary struct {
+	Verdict       Verdict `json:"verdict"`
+	SpecCount     int     `json:"spec_count"`
+	FailedCount   int     `json:"failed_count"`
+	MinScore      int     `json:"min_score"`
+	AverageScore  int     `json:"average_score"`
+	CriticalCount int     `json:"critical_count"`
+	WarnCount     int     `json:"warn_count"`
+	InfoCount     int     `json:"info_count"`
+}
+
+// BatchEntry is one spec's outcome in a batch run. Report is nil and Error is
+// set when the spec could not be checked.
+type BatchEntry struct {
+	SpecFile string  `json:"spec_file"`
+	Verdict  Verdict `json:"verdict,omitempty"`
+	Score    int     `json:"score"`
+	Error    string  `json:"error,omitempty"`
+	Report   *Report `json:"report,omitempty"`
+}
+
 // Meta holds runtime metadata about the LLM call.
 type Meta struct {
 	Model        string           `json:"model"`
@@ -40,6 +90,49 @@ type Meta struct {
 	Incremental  *IncrementalMeta `json:"incremental,omitempty"`
 	Convergence  *ConvergenceMeta `json:"convergence,omitempty"`
 	Completion   *CompletionMeta  `json:"completion,omitempty"`
+	Triage       *TriageMeta      `json:"triage,omitempty"`
+	Normative    *NormativeMeta   `json:"normative,omitempty"`
+	Requirements []Requirement    `json:"requirements,omitempty"`
+}
+
+// Requirement is one requirement ID defined in the spec, with the lines that
+// reference it. Tested is set when an acceptance criteria section references
+// the requirement.
+type Requirement struct {
+	ID         string     `json:"id"`
+	Section    string     `json:"section,omitempty"`
+	Definition Evidence   `json:"definition"`
+	References []Evidence `json:"references,omitempty"`
+	Tested     bool       `json:"tested"`
+}
+
+// NormativeKeywords lists the RFC 2119 keywords counted in NormativeMeta, in
+// display order.
+var NormativeKeywords = []string{"MUST", "MUST NOT", "SHALL", "SHALL NOT", "SHOULD", "SHOULD NOT", "MAY"}
+
+// NormativeMeta counts the RFC 2119 keywords preflight found, overall and per
+// section. RFC2119 is set when the spec declares RFC 2119 conventions, in
+// which case only uppercase keywords are counted.
+type NormativeMeta struct {
+	RFC2119  bool               `json:"rfc2119"`
+	Counts   map[string]int     `json:"counts"`
+	Sections []NormativeSection `json:"sections"`
+}
+
+// NormativeSection holds the keyword counts of one heading's section. Text
+// before the first heading has an empty Heading.
+type NormativeSection struct {
+	Heading   string         `json:"heading"`
+	LineStart int            `json:"line_start"`
+	LineEnd   int            `json:"line_end"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// TriageMeta describes reviewer decisions applied from a triage file.
+type TriageMeta struct {
+	Decisions  int      `json:"decisions"`
+	Matched    int      `json:"matched"`
+	Suppressed []string `json:"suppressed,omitempty"`
 }
 
 // CompletionMeta describes optional profile-specific completion generation.
@@ -145,6 +238,7 @@ type ConvergenceCurrentCounts struct {
 
 type ConvergenceHistoricalCounts struct {
 	Resolved  int `json:"resolved"`
+	Answered  int `json:"answered"`
 	Dropped   int `json:"dropped"`
 	Untracked int `json:"untracked"`
 }
@@ -153,6 +247,7 @@ type ConvergenceCounts struct {
 	New       int `json:"new"`
 	StillOpen int `json:"still_open"`
 	Resolved  int `json:"resolved"`
+	Answered  int `json:"answered"`
 	Dropped   int `json:"dropped"`
 	Untracked int `json:"untracked"`
 }
@@ -249,6 +344,24 @@ type Question struct {
 	WhyNeeded string     `json:"why_needed"`
 	Blocks    []string   `json:"blocks"`
 	Evidence  []Evidence `json:"evidence"`
+	// Status is "answered" when an answers file resolved the question; open
+	// questions leave it empty. Answered questions do not affect the score.
+	Status string `json:"status,omitempty"`
+	Answer string `json:"answer,omitempty"`
+}
+
+// QuestionStatusAnswered marks a question resolved by an answers file.
+const QuestionStatusAnswered = "answered"
+
+// OpenQuestions returns the questions an answers file has not resolved.
+func OpenQuestions(questions []Question) []Question {
+	out := make([]Question, 0, len(questions))
+	for _, q := range questions {
+		if q.Status != QuestionStatusAnswered {
+			out = append(out, q)
+		}
+	}
+	return out
 }
 
 // Evidence links a finding to a specific location in the spec.
@@ -265,4 +378,7 @@ type Patch struct {
 	IssueID string `json:"issue_id"`
 	Before  string `json:"before"`
 	After   string `json:"after"`
+	// Path names the file the patch applies to when the spec spans multiple
+	// files; it is empty for single-file specs.
+	Path string `json:"path,omitempty"`
 }
@@ -0,0 +1,240 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxIncludeDepth bounds nested include directives.
+const maxIncludeDepth = 16
+
+var includeDirective = regexp.MustCompile(`^\s*<!--\s*include:\s*(.+?)\s*-->\s*$`)
+
+// Source maps a run of composite spec lines back to the file they came from.
+type Source struct {
+	Path      string
+	Line      int // first composite line, 1-based
+	FileLine  int // matching line in Path, 1-based
+	LineCount int
+}
+
+// File is one file loaded into a composite spec, with its unmodified text.
+type File struct {
+	Path string
+	Raw  string
+}
+
+// LoadComposite reads a spec file and expands `<!-- include: path -->`
+// directives, resolved relative to the including file. A file without
+// directives loads exactly like Load; otherwise the result keeps a line map
+// so findings can be reported against the original files.
+func LoadComposite(path string) (*Spec, error) {
+	b := newCompositeBuilder()
+	if err := b.add(path, 0); err != nil {
+		return nil, err
+	}
+	if len(b.files) == 1 {
+		return New(path, b.files[0].Raw), nil
+	}
+	return b.spec(path), nil
+}
+
+// LoadDir loads every Markdown file directly inside dir, in lexical order,
+// as one composite spec. Files pulled in by another file's include
+// directive are not loaded a second time.
+func LoadDir(dir string) (*Spec, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading spec directory: %w", err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("spec directory %s contains no .md files", dir)
+	}
+
+	included := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading spec file: %w", err)
+		}
+		for _, target := range includeTargets(path, string(data)) {
+			included[filepath.Clean(target)] = true
+		}
+	}
+
+	b := newCompositeBuilder()
+	for _, path := range paths {
+		if included[filepath.Clean(path)] {
+			continue
+		}
+		if err := b.add(path, 0); err != nil {
+			return nil, err
+		}
+	}
+	return b.spec(dir), nil
+}
+
+// IsComposite reports whether the spec was assembled from more than one file.
+func (s *Spec) IsComposite() bool {
+	return len(s.Sources) > 0
+}
+
+// Locate maps a composite line number to the original file and line. For
+// single-file specs it returns the spec path and the line unchanged.
+func (s *Spec) Locate(line int) (string, int, bool) {
+	if len(s.Sources) == 0 {
+		return s.Path, line, line >= 1 && line <= s.LineCount
+	}
+	i := sort.Search(len(s.Sources), func(i int) bool {
+		return s.Sources[i].Line+s.Sources[i].LineCount > line
+	})
+	if i == len(s.Sources) || line < s.Sources[i].Line {
+		return s.Path, line, false
+	}
+	src := s.Sources[i]
+	return src.Path, src.FileLine + line - src.Line, true
+}
+
+// SourceEnd returns the last composite line of the source run containing line.
+func (s *Spec) SourceEnd(line int) int {
+	for _, src := range s.Sources {
+		if line >= src.Line && line < src.Line+src.LineCount {
+			return src.Line + src.LineCount - 1
+		}
+	}
+	return line
+}
+
+// FileBoundaries returns the composite lines where a different file begins.
+func (s *Spec) FileBoundaries() []int {
+	var out []int
+	for i, src := range s.Sources {
+		if i > 0 && src.Path != s.Sources[i-1].Path {
+			out = append(out, src.Line)
+		}
+	}
+	return out
+}
+
+type compositeBuilder struct {
+	files    []File
+	sources  []Source
+	lines    []string
+	visiting []string
+	loaded   map[string]bool
+}
+
+func newCompositeBuilder() *compositeBuilder {
+	return &compositeBuilder{loaded: make(map[string]bool)}
+}
+
+func (b *compositeBuilder) add(path string, depth int) error {
+	clean := filepath.Clean(path)
+	for _, open := range b.visiting {
+		if open == clean {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(b.visiting, " -> "), clean)
+		}
+	}
+	// A repeated file would appear twice in the composite, so patches
+	// against it could not be mapped back to one place.
+	if b.loaded[clean] {
+		return fmt.Errorf("%s is included more than once", path)
+	}
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("include depth exceeds %d at %s", maxIncludeDepth, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if depth > 0 {
+			return fmt.Errorf("reading included file: %w", err)
+		}
+		return fmt.Errorf("reading spec file: %w", err)
+	}
+	raw := string(data)
+	b.loaded[clean] = true
+	b.files = append(b.files, File{Path: path, Raw: raw})
+	b.visiting = append(b.visiting, clean)
+	defer func() { b.visiting = b.visiting[:len(b.visiting)-1] }()
+
+	inFence := false
+	runStart := -1
+	flush := func(fileLine int) {
+		if runStart < 0 {
+			return
+		}
+		b.sources = append(b.sources, Source{
+			Path:      path,
+			Line:      len(b.lines) - (fileLine - runStart) + 1,
+			FileLine:  runStart,
+			LineCount: fileLine - runStart,
+		})
+		runStart = -1
+	}
+	fileLines := Lines(raw)
+	for i, line := range fileLines {
+		fileLine := i + 1
+		if isFenceLine(line) {
+			inFence = !inFence
+		}
+		if !inFence {
+			if m := includeDirective.FindStringSubmatch(line); m != nil {
+				flush(fileLine)
+				if err := b.add(filepath.Join(filepath.Dir(path), m[1]), depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if runStart < 0 {
+			runStart = fileLine
+		}
+		b.lines = append(b.lines, line)
+	}
+	flush(len(fileLines) + 1)
+	return nil
+}
+
+func (b *compositeBuilder) spec(path string) *Spec {
+	raw := ""
+	if len(b.lines) > 0 {
+		raw = strings.Join(b.lines, "\n") + "\n"
+	}
+	s := New(path, raw)
+	s.Sources = b.sources
+	s.Files = b.files
+	return s
+}
+
+func includeTargets(path, raw string) []string {
+	var out []string
+	inFence := false
+	for _, line := range Lines(raw) {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := includeDirective.FindStringSubmatch(line); m != nil {
+			out = append(out, filepath.Join(filepath.Dir(path), m[1]))
+		}
+	}
+	return out
+}
+
+func isFenceLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
@@ -0,0 +1,116 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSpecFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadCompositeExpandsIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeSpecFiles(t, dir, map[string]string{
+		"SPEC.md":       "# Spec\n<!-- include: parts/auth.md -->\n## Tail\n",
+		"parts/auth.md": "## Auth\nUsers log in.\n",
+	})
+	s, err := LoadComposite(filepath.Join(dir, "SPEC.md"))
+	if err != nil {
+		t.Fatalf("LoadComposite: %v", err)
+	}
+	if s.Raw != "# Spec\n## Auth\nUsers log in.\n## Tail\n" {
+		t.Fatalf("raw = %q", s.Raw)
+	}
+	if !s.IsComposite() || len(s.Files) != 2 {
+		t.Fatalf("files = %#v", s.Files)
+	}
+	path, line, ok := s.Locate(3)
+	if !ok || path != filepath.Join(dir, "parts", "auth.md") || line != 2 {
+		t.Fatalf("Locate(3) = %s:%d %v", path, line, ok)
+	}
+	path, line, ok = s.Locate(4)
+	if !ok || path != filepath.Join(dir, "SPEC.md") || line != 3 {
+		t.Fatalf("Locate(4) = %s:%d %v", path, line, ok)
+	}
+	if got := s.FileBoundaries(); len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Fatalf("boundaries = %v, want [2 4]", got)
+	}
+}
+
+func TestLoadCompositeWithoutIncludesMatchesLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeSpecFiles(t, dir, map[string]string{
+		"SPEC.md": "# Spec\n```\n<!-- include: missing.md -->\n```\n",
+	})
+	s, err := LoadComposite(filepath.Join(dir, "SPEC.md"))
+	if err != nil {
+		t.Fatalf("LoadComposite: %v", err)
+	}
+	if s.IsComposite() {
+		t.Fatalf("fenced include directive should be ignored: %#v", s.Sources)
+	}
+}
+
+func TestLoadCompositeRejectsCyclesAndMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSpecFiles(t, dir, map[string]string{
+		"a.md":    "<!-- include: b.md -->\n",
+		"b.md":    "<!-- include: a.md -->\n",
+		"SPEC.md": "<!-- include: gone.md -->\n",
+	})
+	if _, err := LoadComposite(filepath.Join(dir, "a.md")); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("err = %v, want include cycle", err)
+	}
+	if _, err := LoadComposite(filepath.Join(dir, "SPEC.md")); err == nil || !strings.Contains(err.Error(), "reading included file") {
+		t.Fatalf("err = %v, want missing include", err)
+	}
+}
+
+func TestLoadCompositeRejectsRepeatedIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeSpecFiles(t, dir, map[string]string{
+		"SPEC.md":         "# Spec\n<!-- include: parts/a.md -->\n<!-- include: parts/b.md -->\n",
+		"parts/a.md":      "## A\n<!-- include: common.md -->\n",
+		"parts/b.md":      "## B\n<!-- include: ./common.md -->\n",
+		"parts/common.md": "## Common\n",
+	})
+	_, err := LoadComposite(filepath.Join(dir, "SPEC.md"))
+	if err == nil || !strings.Contains(err.Error(), "included more than once") {
+		t.Fatalf("err = %v, want repeated include", err)
+	}
+}
+
+func TestLoadDirSkipsIncludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSpecFiles(t, dir, map[string]string{
+		"01-intro.md":  "# Intro\n<!-- include: 03-shared.md -->\n",
+		"02-api.md":    "## API\n",
+		"03-shared.md": "## Shared\n",
+		"notes.txt":    "ignored\n",
+	})
+	s, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if s.Raw != "# Intro\n## Shared\n## API\n" {
+		t.Fatalf("raw = %q", s.Raw)
+	}
+	if s.Path != dir || len(s.Files) != 3 {
+		t.Fatalf("path = %q files = %d", s.Path, len(s.Files))
+	}
+	if _, err := LoadDir(t.TempDir()); err == nil {
+		t.Fatal("expected error for directory without .md files")
+	}
+}
@@ -0,0 +1,129 @@
+package spec
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExpandPaths resolves spec arguments into an ordered, de-duplicated list of
+// file paths. Arguments without glob characters are returned unchanged so a
+// missing file surfaces as a normal load error. Glob arguments use
+// filepath.Match syntax per path segment, plus "**" to match any number of
+// directories, and must match at least one regular file.
+func ExpandPaths(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(path string) {
+		key := filepath.Clean(path)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		out = append(out, path)
+	}
+	for _, pattern := range patterns {
+		if !HasGlobMeta(pattern) {
+			add(pattern)
+			continue
+		}
+		matches, err := globFiles(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pattern %q matched no files", pattern)
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+	return out, nil
+}
+
+// HasGlobMeta reports whether path contains filepath.Match metacharacters.
+func HasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+func globFiles(pattern string) ([]string, error) {
+	slashed := filepath.ToSlash(pattern)
+	segments := strings.Split(slashed, "/")
+	rootSegments := 0
+	for rootSegments < len(segments) && !HasGlobMeta(segments[rootSegments]) {
+		rootSegments++
+	}
+	root := strings.Join(segments[:rootSegments], "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(slashed, "/") {
+			root = "/"
+		}
+	}
+	patternSegments := segments[rootSegments:]
+	for _, segment := range patternSegments {
+		if segment == "**" {
+			continue
+		}
+		if _, err := filepath.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == filepath.FromSlash(root) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != filepath.FromSlash(root) && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(root), path)
+		if err != nil {
+			return err
+		}
+		if matchSegments(patternSegments, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("expanding pattern %q: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
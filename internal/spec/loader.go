@@ -17,6 +17,11 @@ type Spec struct {
 	Raw       string // original content
 	Numbered  string // content with "L1: …" prefixes
 	LineCount int
+
+	// Sources and Files are set only for composite specs assembled from
+	// include directives or a spec directory.
+	Sources []Source
+	Files   []File
 }
 
 // Load reads a spec file from disk, computes its hash, and line-numbers its content.
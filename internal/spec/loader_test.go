@@ -2,6 +2,8 @@ package spec
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -81,3 +83,35 @@ func TestLoad_MissingFile(t *testing.T) {
 		t.Error("expected error for missing file, got nil")
 	}
 }
+
+func TestExpandPaths(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{"a/SPEC.md", "a/b/SPEC.md", "c/SPEC.md", "c/notes.md", ".hidden/SPEC.md"} {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	explicit := filepath.Join(root, "c", "notes.md")
+	got, err := ExpandPaths([]string{filepath.Join(root, "**", "SPEC.md"), explicit, filepath.Join(root, "a", "SPEC.md")})
+	if err != nil {
+		t.Fatalf("ExpandPaths: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "a", "SPEC.md"),
+		filepath.Join(root, "a", "b", "SPEC.md"),
+		filepath.Join(root, "c", "SPEC.md"),
+		explicit,
+	}
+	sort.Strings(want[:3])
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("ExpandPaths = %v, want %v", got, want)
+	}
+
+	if _, err := ExpandPaths([]string{filepath.Join(root, "*", "missing.md")}); err == nil {
+		t.Fatal("expected error for glob without matches")
+	}
+}
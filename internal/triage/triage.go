@@ -0,0 +1,314 @@
+// Package triage records reviewer decisions about findings and carries them
+// over to later reviews of the same spec by finding fingerprint.
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dshills/speccritic/internal/convergence"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// Format and Version identify a triage decisions file.
+const (
+	Format  = "speccritic-triage"
+	Version = 1
+)
+
+// TagPrefix marks findings that carry an open decision, e.g. "triage:deferred".
+const TagPrefix = "triage:"
+
+// Decision is a reviewer's verdict on one finding.
+type Decision string
+
+const (
+	// DecisionAccepted acknowledges the finding as a known, accepted gap.
+	DecisionAccepted Decision = "accepted"
+	// DecisionDismissed marks the finding as a false positive.
+	DecisionDismissed Decision = "dismissed"
+	// DecisionDeferred postpones the fix to a later revision.
+	DecisionDeferred Decision = "deferred"
+	// DecisionNeedsFix confirms the finding must be fixed.
+	DecisionNeedsFix Decision = "needs_fix"
+)
+
+var decisions = []Decision{DecisionAccepted, DecisionDismissed, DecisionDeferred, DecisionNeedsFix}
+
+// Decisions returns the valid decisions in display order.
+func Decisions() []Decision {
+	return append([]Decision(nil), decisions...)
+}
+
+// ParseDecision validates a decision name.
+func ParseDecision(raw string) (Decision, error) {
+	d := Decision(strings.TrimSpace(raw))
+	for _, valid := range decisions {
+		if d == valid {
+			return d, nil
+		}
+	}
+	return "", fmt.Errorf("invalid triage decision %q (valid: accepted, dismissed, deferred, needs_fix)", raw)
+}
+
+// Label returns the decision's display name.
+func (d Decision) Label() string {
+	switch d {
+	case DecisionAccepted:
+		return "Accepted"
+	case DecisionDismissed:
+		return "Dismissed (false positive)"
+	case DecisionDeferred:
+		return "Deferred"
+	case DecisionNeedsFix:
+		return "Needs fix"
+	default:
+		return string(d)
+	}
+}
+
+// Suppresses reports whether later reviews should drop findings with this
+// decision instead of reporting them again.
+func (d Decision) Suppresses() bool {
+	return d == DecisionAccepted || d == DecisionDismissed
+}
+
+// Entry is the decision recorded for one finding.
+type Entry struct {
+	FindingID   string          `json:"finding_id"`
+	Kind        string          `json:"kind"`
+	Fingerprint string          `json:"fingerprint"`
+	Severity    schema.Severity `json:"severity"`
+	Title       string          `json:"title"`
+	Decision    Decision        `json:"decision"`
+	Note        string          `json:"note,omitempty"`
+	DecidedAt   time.Time       `json:"decided_at"`
+}
+
+// File is an exported set of triage decisions for one spec.
+type File struct {
+	Format    string  `json:"format"`
+	Version   int     `json:"version"`
+	SpecFile  string  `json:"spec_file,omitempty"`
+	SpecHash  string  `json:"spec_hash,omitempty"`
+	Decisions []Entry `json:"decisions"`
+}
+
+// NewFile wraps entries recorded against report in an exportable file.
+func NewFile(report *schema.Report, entries []Entry) *File {
+	f := &File{Format: Format, Version: Version, Decisions: append([]Entry{}, entries...)}
+	if report != nil {
+		f.SpecFile = report.Input.SpecFile
+		f.SpecHash = report.Input.SpecHash
+	}
+	return f
+}
+
+// Marshal encodes f as indented JSON.
+func Marshal(f *File) ([]byte, error) {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding triage file: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Load reads and validates a triage file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading triage file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a triage file.
+func Parse(data []byte) (*File, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing triage file: %w", err)
+	}
+	if f.Format != Format {
+		return nil, fmt.Errorf("triage file: format is %q, want %q", f.Format, Format)
+	}
+	if f.Version != Version {
+		return nil, fmt.Errorf("triage file: unsupported version %d", f.Version)
+	}
+	for i, entry := range f.Decisions {
+		if _, err := ParseDecision(string(entry.Decision)); err != nil {
+			return nil, fmt.Errorf("triage file: decision %d: %w", i+1, err)
+		}
+		if entry.Fingerprint == "" {
+			return nil, fmt.Errorf("triage file: decision %d has no fingerprint", i+1)
+		}
+	}
+	return &f, nil
+}
+
+// IssueEntry returns an entry for issue with its fingerprint filled in.
+func IssueEntry(issue schema.Issue, decision Decision, note string, at time.Time) Entry {
+	return Entry{
+		FindingID:   issue.ID,
+		Kind:        string(convergence.KindIssue),
+		Fingerprint: IssueFingerprint(issue),
+		Severity:    issue.Severity,
+		Title:       issue.Title,
+		Decision:    decision,
+		Note:        strings.TrimSpace(note),
+		DecidedAt:   at.UTC(),
+	}
+}
+
+// QuestionEntry returns an entry for question with its fingerprint filled in.
+func QuestionEntry(question schema.Question, decision Decision, note string, at time.Time) Entry {
+	return Entry{
+		FindingID:   question.ID,
+		Kind:        string(convergence.KindQuestion),
+		Fingerprint: QuestionFingerprint(question),
+		Severity:    question.Severity,
+		Title:       question.Question,
+		Decision:    decision,
+		Note:        strings.TrimSpace(note),
+		DecidedAt:   at.UTC(),
+	}
+}
+
+// IssueFingerprint is the convergence fingerprint of issue.
+func IssueFingerprint(issue schema.Issue) string {
+	return convergence.Fingerprint(convergence.TrackIssues([]schema.Issue{issue})[0])
+}
+
+// QuestionFingerprint is the convergence fingerprint of question.
+func QuestionFingerprint(question schema.Question) string {
+	return convergence.Fingerprint(convergence.TrackQuestions([]schema.Question{question})[0])
+}
+
+// Upsert replaces the decision for entry's finding, or appends it. An entry
+// with an empty decision clears the finding's decision.
+func Upsert(entries []Entry, entry Entry) []Entry {
+	out := make([]Entry, 0, len(entries)+1)
+	for _, existing := range entries {
+		if existing.FindingID != entry.FindingID {
+			out = append(out, existing)
+		}
+	}
+	if entry.Decision != "" {
+		out = append(out, entry)
+	}
+	return out
+}
+
+// CarryOver maps entries recorded against an earlier report onto report.
+// Entries whose fingerprint matches a finding in report are kept under that
+// finding's ID; the rest are dropped.
+func CarryOver(entries []Entry, report *schema.Report) []Entry {
+	byFingerprint := index(entries)
+	if len(byFingerprint) == 0 || report == nil {
+		return nil
+	}
+	var out []Entry
+	for _, issue := range report.Issues {
+		if entry, ok := byFingerprint[IssueFingerprint(issue)]; ok {
+			entry.FindingID = issue.ID
+			out = append(out, entry)
+		}
+	}
+	for _, question := range report.Questions {
+		if entry, ok := byFingerprint[QuestionFingerprint(question)]; ok {
+			entry.FindingID = question.ID
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// SuppressedFingerprints returns the fingerprints of the findings entries
+// accept or dismiss.
+func SuppressedFingerprints(entries []Entry) map[string]bool {
+	out := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Fingerprint != "" && entry.Decision.Suppresses() {
+			out[entry.Fingerprint] = true
+		}
+	}
+	return out
+}
+
+// Result summarizes what Apply changed.
+type Result struct {
+	// Matched counts findings that had a decision.
+	Matched int
+	// Suppressed lists the IDs of accepted or dismissed findings that were
+	// removed from the report.
+	Suppressed []string
+}
+
+// Apply matches entries to report findings by fingerprint. Accepted and
+// dismissed findings are removed along with their patches; deferred and
+// needs-fix findings stay and are tagged with their decision. Apply does not
+// rescore the report.
+func Apply(report *schema.Report, entries []Entry) Result {
+	var result Result
+	byFingerprint := index(entries)
+	if len(byFingerprint) == 0 || report == nil {
+		return result
+	}
+	suppressed := make(map[string]bool)
+	issues := report.Issues[:0]
+	for _, issue := range report.Issues {
+		entry, ok := byFingerprint[IssueFingerprint(issue)]
+		if !ok {
+			issues = append(issues, issue)
+			continue
+		}
+		result.Matched++
+		if entry.Decision.Suppresses() {
+			suppressed[issue.ID] = true
+			result.Suppressed = append(result.Suppressed, issue.ID)
+			continue
+		}
+		issue.Tags = append(append([]string(nil), issue.Tags...), TagPrefix+string(entry.Decision))
+		issues = append(issues, issue)
+	}
+	report.Issues = issues
+
+	questions := report.Questions[:0]
+	for _, question := range report.Questions {
+		entry, ok := byFingerprint[QuestionFingerprint(question)]
+		if !ok {
+			questions = append(questions, question)
+			continue
+		}
+		result.Matched++
+		if entry.Decision.Suppresses() {
+			result.Suppressed = append(result.Suppressed, question.ID)
+			continue
+		}
+		questions = append(questions, question)
+	}
+	report.Questions = questions
+
+	if len(suppressed) > 0 {
+		patches := report.Patches[:0]
+		for _, p := range report.Patches {
+			if !suppressed[p.IssueID] {
+				patches = append(patches, p)
+			}
+		}
+		report.Patches = patches
+	}
+	return result
+}
+
+func index(entries []Entry) map[string]Entry {
+	out := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		if entry.Fingerprint != "" && entry.Decision != "" {
+			out[entry.Fingerprint] = entry
+		}
+	}
+	return out
+}
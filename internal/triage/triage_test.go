@@ -0,0 +1,114 @@
+package triage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func triageIssue(id, title, quote string) schema.Issue {
+	return schema.Issue{
+		ID:       id,
+		Severity: schema.SeverityWarn,
+		Category: schema.CategoryNonTestableRequirement,
+		Title:    title,
+		Evidence: []schema.Evidence{{Path: "SPEC.md", LineStart: 1, LineEnd: 1, Quote: quote}},
+	}
+}
+
+func TestMarshalParseRoundTrip(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := &schema.Report{Input: schema.Input{SpecFile: "SPEC.md", SpecHash: "sha256:abc"}}
+	entry := IssueEntry(triageIssue("ISSUE-0001", "Vague", "fast"), DecisionDeferred, "  next sprint ", at)
+	data, err := Marshal(NewFile(report, []Entry{entry}))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.SpecFile != "SPEC.md" || len(f.Decisions) != 1 {
+		t.Fatalf("file = %+v", f)
+	}
+	got := f.Decisions[0]
+	if got.Decision != DecisionDeferred || got.Note != "next sprint" || !strings.HasPrefix(got.Fingerprint, "sha256:") || !got.DecidedAt.Equal(at) {
+		t.Fatalf("entry = %+v", got)
+	}
+}
+
+func TestParseRejectsInvalidFiles(t *testing.T) {
+	for name, data := range map[string]string{
+		"format":      `{"format":"other","version":1,"decisions":[]}`,
+		"version":     `{"format":"speccritic-triage","version":2,"decisions":[]}`,
+		"decision":    `{"format":"speccritic-triage","version":1,"decisions":[{"fingerprint":"sha256:x","decision":"maybe"}]}`,
+		"fingerprint": `{"format":"speccritic-triage","version":1,"decisions":[{"decision":"accepted"}]}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestApplySuppressesAndTagsByFingerprint(t *testing.T) {
+	dismissed := triageIssue("ISSUE-0001", "Vague latency", "fast")
+	deferred := triageIssue("ISSUE-0002", "Missing retry limit", "retry")
+	other := triageIssue("ISSUE-0003", "Undefined owner", "owner")
+	entries := []Entry{
+		IssueEntry(dismissed, DecisionDismissed, "", time.Now()),
+		IssueEntry(deferred, DecisionDeferred, "", time.Now()),
+	}
+
+	// A later run renumbers the same findings.
+	dismissed.ID, deferred.ID, other.ID = "ISSUE-0003", "ISSUE-0001", "ISSUE-0002"
+	report := &schema.Report{
+		Issues:  []schema.Issue{deferred, other, dismissed},
+		Patches: []schema.Patch{{IssueID: "ISSUE-0003", Before: "fast", After: "p99 under 200 ms"}, {IssueID: "ISSUE-0001", Before: "retry", After: "retry 3 times"}},
+	}
+	result := Apply(report, entries)
+	if result.Matched != 2 || len(result.Suppressed) != 1 || result.Suppressed[0] != "ISSUE-0003" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(report.Issues) != 2 || report.Issues[0].ID != "ISSUE-0001" || report.Issues[1].ID != "ISSUE-0002" {
+		t.Fatalf("issues = %+v", report.Issues)
+	}
+	if tags := report.Issues[0].Tags; len(tags) != 1 || tags[0] != "triage:deferred" {
+		t.Fatalf("deferred tags = %v", tags)
+	}
+	if len(report.Patches) != 1 || report.Patches[0].IssueID != "ISSUE-0001" {
+		t.Fatalf("patches = %+v", report.Patches)
+	}
+	// The triage tag does not change the fingerprint, so a re-export matches.
+	if IssueFingerprint(report.Issues[0]) != entries[1].Fingerprint {
+		t.Fatal("triage tag changed the fingerprint")
+	}
+}
+
+func TestCarryOverRenumbersMatchingEntries(t *testing.T) {
+	kept := triageIssue("ISSUE-0001", "Vague latency", "fast")
+	gone := triageIssue("ISSUE-0002", "Missing retry limit", "retry")
+	entries := []Entry{
+		IssueEntry(kept, DecisionNeedsFix, "fix it", time.Now()),
+		IssueEntry(gone, DecisionAccepted, "", time.Now()),
+	}
+	kept.ID = "ISSUE-0007"
+	carried := CarryOver(entries, &schema.Report{Issues: []schema.Issue{kept}})
+	if len(carried) != 1 || carried[0].FindingID != "ISSUE-0007" || carried[0].Decision != DecisionNeedsFix || carried[0].Note != "fix it" {
+		t.Fatalf("carried = %+v", carried)
+	}
+}
+
+func TestUpsertReplacesAndClears(t *testing.T) {
+	entries := Upsert(nil, Entry{FindingID: "ISSUE-0001", Decision: DecisionAccepted})
+	entries = Upsert(entries, Entry{FindingID: "ISSUE-0002", Decision: DecisionDeferred})
+	entries = Upsert(entries, Entry{FindingID: "ISSUE-0001", Decision: DecisionNeedsFix})
+	if len(entries) != 2 || entries[1].FindingID != "ISSUE-0001" || entries[1].Decision != DecisionNeedsFix {
+		t.Fatalf("entries = %+v", entries)
+	}
+	entries = Upsert(entries, Entry{FindingID: "ISSUE-0002"})
+	if len(entries) != 1 || entries[0].FindingID != "ISSUE-0001" {
+		t.Fatalf("entries after clear = %+v", entries)
+	}
+}
@@ -28,6 +28,8 @@ type FindingRef struct {
 	Severity    schema.Severity
 	Title       string
 	IsPreflight bool
+	// Status is the finding's convergence status in a comparison view.
+	Status string
 }
 
 func BuildAnnotatedSpec(specText string, report *schema.Report, threshold schema.Severity) (AnnotatedSpec, error) {
@@ -0,0 +1,423 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dshills/speccritic/internal/render"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const apiPrefix = "/api/v1"
+
+// APICheckRequest is the JSON body accepted by POST /api/v1/checks. Option
+// fields match the browser form and are validated by the same rules; empty
+// fields take the browser defaults.
+type APICheckRequest struct {
+	SpecName              string          `json:"spec_name,omitempty"`
+	SpecText              string          `json:"spec_text"`
+	Profile               string          `json:"profile,omitempty"`
+	SeverityThreshold     string          `json:"severity_threshold,omitempty"`
+	Strict                bool            `json:"strict,omitempty"`
+	LLMProvider           string          `json:"llm_provider,omitempty"`
+	LLMModel              string          `json:"llm_model,omitempty"`
+	Temperature           *float64        `json:"temperature,omitempty"`
+	MaxTokens             int             `json:"max_tokens,omitempty"`
+	Preflight             *bool           `json:"preflight,omitempty"`
+	PreflightMode         string          `json:"preflight_mode,omitempty"`
+	PreviousResult        json.RawMessage `json:"previous_result,omitempty"`
+	IncrementalBase       string          `json:"incremental_base,omitempty"`
+	IncrementalMode       string          `json:"incremental_mode,omitempty"`
+	ConvergenceMode       string          `json:"convergence_mode,omitempty"`
+	CompletionSuggestions bool            `json:"completion_suggestions,omitempty"`
+	CompletionMode        string          `json:"completion_mode,omitempty"`
+	CompletionTemplate    string          `json:"completion_template,omitempty"`
+	CompletionMaxPatches  *int            `json:"completion_max_patches,omitempty"`
+}
+
+// APICheck is the status of a submitted check. Once a single-spec check
+// has finished with a result, CheckID is the ID of its stored result.
+// Finished checks are pruned and do not survive a restart, but the stored
+// result lasts as long as the store retains it, so CheckID keeps working in
+// place of the check ID.
+type APICheck struct {
+	ID         string          `json:"id"`
+	CheckID    string          `json:"check_id,omitempty"`
+	Status     string          `json:"status"`
+	Specs      []string        `json:"specs"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Progress   *progressEvent  `json:"progress,omitempty"`
+	Summary    *schema.Summary `json:"summary,omitempty"`
+	Error      *APIError       `json:"error,omitempty"`
+	Links      APICheckLinks   `json:"links"`
+}
+
+// APICheckLinks are the URLs of a check's resources. Report and patch links
+// appear once a single-spec check has finished with a result and use its
+// stored result's ID. The events link appears while the check is retained.
+type APICheckLinks struct {
+	Self   string `json:"self"`
+	Events string `json:"events,omitempty"`
+	Report string `json:"report,omitempty"`
+	Patch  string `json:"patch,omitempty"`
+}
+
+// APICheckList is the response of GET /api/v1/checks.
+type APICheckList struct {
+	Checks []APICheck `json:"checks"`
+}
+
+// APIError is the body of every API error response. Status is the HTTP
+// status a failed check would have returned, when it differs from the
+// response status.
+type APIError struct {
+	Error  string `json:"error"`
+	Status int    `json:"status,omitempty"`
+}
+
+func (s *Server) apiRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("POST "+apiPrefix+"/checks", s.requireAPIToken(s.handleAPICreateCheck))
+	mux.HandleFunc("GET "+apiPrefix+"/checks", s.requireAPIToken(s.handleAPIListChecks))
+	mux.HandleFunc("GET "+apiPrefix+"/checks/{id}", s.requireAPIToken(s.handleAPIGetCheck))
+	mux.HandleFunc("GET "+apiPrefix+"/checks/{id}/events", s.requireAPIToken(s.handleAPIEvents))
+	mux.HandleFunc("GET "+apiPrefix+"/checks/{id}/report", s.requireAPIToken(s.handleAPIReport))
+	mux.HandleFunc("GET "+apiPrefix+"/checks/{id}/patch", s.requireAPIToken(s.handleAPIPatch))
+}
+
+// requireAPIToken admits requests that carry one of the configured tokens
+// as a bearer token. API requests never use the browser session cookies.
+func (s *Server) requireAPIToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !s.validAPIToken(strings.TrimSpace(token)) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="speccritic"`)
+			writeAPIError(w, http.StatusUnauthorized, "missing or invalid API token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) validAPIToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	valid := false
+	for _, want := range s.config.APITokens {
+		if len(want) == len(token) && subtle.ConstantTimeCompare([]byte(want), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+func (s *Server) handleAPICreateCheck(w http.ResponseWriter, r *http.Request) {
+	in, err := s.readAPICheckInput(w, r)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(in.specs) > 1 {
+		writeAPIError(w, http.StatusBadRequest, "the API checks one spec per request")
+		return
+	}
+	reqs, err := s.buildCheckRequests(in)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	j, err := s.jobs.enqueue("", true, reqs)
+	if errors.Is(err, errQueueFull) {
+		w.Header().Set("Retry-After", "30")
+		writeAPIError(w, http.StatusTooManyRequests, "too many checks are waiting")
+		return
+	}
+	if err != nil {
+		log.Printf("queue check: %v", err)
+		writeAPIError(w, http.StatusServiceUnavailable, "check queue unavailable")
+		return
+	}
+	snap, _ := s.jobs.snapshot(j.id)
+	w.Header().Set("Location", apiPrefix+"/checks/"+j.id)
+	writeAPIJSON(w, http.StatusAccepted, s.apiCheck(snap))
+}
+
+// readAPICheckInput reads a JSON or multipart check submission. Multipart
+// bodies use the browser form fields.
+func (s *Server) readAPICheckInput(w http.ResponseWriter, r *http.Request) (checkInput, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes*int64(s.config.MaxBatchFiles)+multipartOverheadLimit)
+	mediaType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	switch mediaType {
+	case "multipart/form-data":
+		if err := s.parseRequestForm(r); err != nil {
+			return checkInput{}, err
+		}
+		return s.readCheckForm(r)
+	case "application/json":
+		var body APICheckRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&body); err != nil {
+			return checkInput{}, fmt.Errorf("invalid JSON body: %w", err)
+		}
+		return s.apiCheckInput(body)
+	default:
+		return checkInput{}, fmt.Errorf("content type must be application/json or multipart/form-data")
+	}
+}
+
+func (s *Server) apiCheckInput(body APICheckRequest) (checkInput, error) {
+	if int64(len(body.SpecText)) > s.config.MaxUploadBytes {
+		return checkInput{}, fmt.Errorf("spec exceeds %d bytes", s.config.MaxUploadBytes)
+	}
+	if err := validateSpecText([]byte(body.SpecText)); err != nil {
+		return checkInput{}, err
+	}
+	if int64(len(body.PreviousResult)) > s.config.MaxUploadBytes || int64(len(body.IncrementalBase)) > s.config.MaxUploadBytes {
+		return checkInput{}, fmt.Errorf("previous result or base spec exceeds %d bytes", s.config.MaxUploadBytes)
+	}
+	name := "SPEC.md"
+	if body.SpecName != "" {
+		name = filepath.Base(body.SpecName)
+	}
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("profile", body.Profile)
+	set("severity_threshold", body.SeverityThreshold)
+	set("strict", strconv.FormatBool(body.Strict))
+	set("llm_provider", body.LLMProvider)
+	set("llm_model", body.LLMModel)
+	if body.Temperature != nil {
+		set("temperature", strconv.FormatFloat(*body.Temperature, 'f', -1, 64))
+	}
+	if body.MaxTokens != 0 {
+		set("max_tokens", strconv.Itoa(body.MaxTokens))
+	}
+	if body.Preflight != nil {
+		set("preflight", strconv.FormatBool(*body.Preflight))
+	}
+	set("preflight_mode", body.PreflightMode)
+	set("incremental_mode", body.IncrementalMode)
+	set("convergence_mode", body.ConvergenceMode)
+	set("completion_suggestions", strconv.FormatBool(body.CompletionSuggestions))
+	set("completion_mode", body.CompletionMode)
+	set("completion_template", body.CompletionTemplate)
+	if body.CompletionMaxPatches != nil {
+		set("completion_max_patches", strconv.Itoa(*body.CompletionMaxPatches))
+	}
+	previous := ""
+	if len(body.PreviousResult) > 0 && string(body.PreviousResult) != "null" {
+		previous = string(body.PreviousResult)
+	}
+	return checkInput{
+		specs:           []uploadedSpec{{name: name, text: body.SpecText}},
+		previousReport:  previous,
+		incrementalBase: body.IncrementalBase,
+		values:          values,
+	}, nil
+}
+
+func (s *Server) handleAPIListChecks(w http.ResponseWriter, r *http.Request) {
+	list := APICheckList{Checks: []APICheck{}}
+	for _, snap := range s.jobs.list() {
+		if snap.API {
+			list.Checks = append(list.Checks, s.apiCheck(snap))
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleAPIGetCheck(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if snap, ok := s.apiSnapshot(id); ok {
+		writeAPIJSON(w, http.StatusOK, s.apiCheck(snap))
+		return
+	}
+	check, ok := s.store.Get(id)
+	if !ok || check.Result == nil || check.Result.Report == nil {
+		writeAPIError(w, http.StatusNotFound, "check not found")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, storedAPICheck(check))
+}
+
+func (s *Server) handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	snap, ok := s.apiSnapshot(r.PathValue("id"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "check not found")
+		return
+	}
+	s.streamJobEvents(w, r, snap.ID)
+}
+
+func (s *Server) handleAPIReport(w http.ResponseWriter, r *http.Request) {
+	check, ok := s.apiStoredCheck(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	renderer, err := render.NewRenderer("json")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	data, err := renderer.Render(check.Result.Report)
+	if err != nil {
+		log.Printf("render api report: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleAPIPatch(w http.ResponseWriter, r *http.Request) {
+	check, ok := s.apiStoredCheck(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if check.Result.PatchDiff == "" {
+		writeAPIError(w, http.StatusNotFound, "check has no patch")
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, check.Result.PatchDiff)
+}
+
+// apiSnapshot returns the status of a check submitted through the API.
+// Checks submitted from browser sessions are not visible to API clients.
+func (s *Server) apiSnapshot(id string) (jobSnapshot, bool) {
+	snap, ok := s.jobs.snapshot(id)
+	if !ok || !snap.API {
+		return jobSnapshot{}, false
+	}
+	return snap, true
+}
+
+// apiStoredCheck resolves a finished single-spec check, or the ID of its
+// stored result, to the stored result, writing the error response when
+// there is none.
+func (s *Server) apiStoredCheck(w http.ResponseWriter, id string) (*StoredCheck, bool) {
+	snap, ok := s.apiSnapshot(id)
+	if !ok {
+		check, ok := s.store.Get(id)
+		if !ok || check.Result == nil || check.Result.Report == nil {
+			writeAPIError(w, http.StatusNotFound, "check not found")
+			return nil, false
+		}
+		return check, true
+	}
+	if snap.State != jobDone {
+		writeAPIError(w, http.StatusConflict, fmt.Sprintf("check is %s", snap.State))
+		return nil, false
+	}
+	if len(snap.CheckIDs) != 1 {
+		writeAPIError(w, http.StatusConflict, "check has no single report")
+		return nil, false
+	}
+	check, ok := s.store.Get(snap.CheckIDs[0])
+	if !ok || check.Result == nil || check.Result.Report == nil {
+		writeAPIError(w, http.StatusNotFound, "check result expired")
+		return nil, false
+	}
+	return check, true
+}
+
+func (s *Server) apiCheck(snap jobSnapshot) APICheck {
+	self := apiPrefix + "/checks/" + snap.ID
+	out := APICheck{
+		ID:        snap.ID,
+		Status:    string(snap.State),
+		Specs:     snap.Specs,
+		CreatedAt: snap.CreatedAt,
+		Progress:  snap.Progress,
+		Links: APICheckLinks{
+			Self:   self,
+			Events: self + "/events",
+		},
+	}
+	if out.Specs == nil {
+		out.Specs = []string{}
+	}
+	if !snap.FinishedAt.IsZero() {
+		finished := snap.FinishedAt
+		out.FinishedAt = &finished
+	}
+	if snap.Failure != nil {
+		out.Error = &APIError{Error: snap.Failure.Message, Status: snap.Failure.Status}
+	}
+	if snap.State == jobDone && len(snap.CheckIDs) == 1 {
+		if check, ok := s.store.Get(snap.CheckIDs[0]); ok && check.Result != nil && check.Result.Report != nil {
+			addStoredResult(&out, check)
+		}
+	}
+	return out
+}
+
+// storedAPICheck describes a stored result whose check is no longer
+// retained, such as after a server restart.
+func storedAPICheck(check *StoredCheck) APICheck {
+	out := APICheck{
+		ID:        check.ID,
+		Status:    string(jobDone),
+		Specs:     []string{},
+		CreatedAt: check.CreatedAt,
+		Links:     APICheckLinks{Self: apiPrefix + "/checks/" + check.ID},
+	}
+	if name := check.Result.Report.Input.SpecFile; name != "" {
+		out.Specs = []string{name}
+	}
+	addStoredResult(&out, check)
+	return out
+}
+
+// addStoredResult adds a finished check's stored result ID, summary and
+// result links.
+func addStoredResult(out *APICheck, check *StoredCheck) {
+	summary := check.Result.Report.Summary
+	out.CheckID = check.ID
+	out.Summary = &summary
+	stored := apiPrefix + "/checks/" + check.ID
+	out.Links.Report = stored + "/report"
+	if check.Result.PatchDiff != "" {
+		out.Links.Patch = stored + "/patch"
+	}
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, openAPIDocument())
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("write api response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, APIError{Error: message})
+}
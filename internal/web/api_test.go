@@ -0,0 +1,318 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const testAPIToken = "test-token-0123456789"
+
+func newAPIServer(t *testing.T, checker checker) *Server {
+	t.Helper()
+	config := DefaultConfig()
+	config.APITokens = []string{testAPIToken}
+	server, err := NewServerWithChecker(config, checker)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func apiRequest(t *testing.T, server *Server, method, path, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+testAPIToken)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func waitForAPICheck(t *testing.T, server *Server, path string) APICheck {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := apiRequest(t, server, http.MethodGet, path, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %s = %d: %s", path, rec.Code, rec.Body.String())
+		}
+		var check APICheck
+		if err := json.Unmarshal(rec.Body.Bytes(), &check); err != nil {
+			t.Fatalf("decode check: %v", err)
+		}
+		if check.Status != string(jobQueued) && check.Status != string(jobRunning) {
+			return check
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("check %s still %s", check.ID, check.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAPICreateJSONCheckAndFetchReport(t *testing.T) {
+	checker := &fakeChecker{}
+	server := newAPIServer(t, checker)
+
+	body, _ := json.Marshal(APICheckRequest{
+		SpecName:          "billing.md",
+		SpecText:          "The system must work.",
+		Profile:           "backend-api",
+		SeverityThreshold: "warn",
+		LLMModel:          "gpt-5",
+	})
+	rec := apiRequest(t, server, http.MethodPost, "/api/v1/checks", "application/json", body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created APICheck
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode created: %v", err)
+	}
+	if created.ID == "" || rec.Header().Get("Location") != created.Links.Self || len(created.Specs) != 1 || created.Specs[0] != "billing.md" {
+		t.Fatalf("created = %+v location %q", created, rec.Header().Get("Location"))
+	}
+
+	check := waitForAPICheck(t, server, created.Links.Self)
+	if check.Status != string(jobDone) || check.Summary == nil || check.Summary.Verdict != schema.VerdictInvalid {
+		t.Fatalf("check = %+v", check)
+	}
+	if checker.req.Profile != "backend-api" || checker.req.SeverityThreshold != "warn" || checker.req.LLMProvider != "openai" || !checker.req.Preflight {
+		t.Fatalf("checker request = %+v", checker.req)
+	}
+
+	report := apiRequest(t, server, http.MethodGet, check.Links.Report, "", nil)
+	if report.Code != http.StatusOK {
+		t.Fatalf("report status = %d: %s", report.Code, report.Body.String())
+	}
+	var decoded schema.Report
+	if err := json.Unmarshal(report.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if decoded.Tool != "speccritic" || len(decoded.Issues) == 0 {
+		t.Fatalf("report = %+v", decoded)
+	}
+	patch := apiRequest(t, server, http.MethodGet, check.Links.Patch, "", nil)
+	if patch.Code != http.StatusOK || !strings.HasPrefix(patch.Header().Get("Content-Type"), "text/x-diff") || patch.Body.String() != "# patch\n" {
+		t.Fatalf("patch status = %d type %q body %q", patch.Code, patch.Header().Get("Content-Type"), patch.Body.String())
+	}
+
+	list := apiRequest(t, server, http.MethodGet, "/api/v1/checks", "", nil)
+	var checks APICheckList
+	if err := json.Unmarshal(list.Body.Bytes(), &checks); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(checks.Checks) != 1 || checks.Checks[0].ID != created.ID {
+		t.Fatalf("list = %+v", checks)
+	}
+}
+
+func TestAPICreateMultipartCheck(t *testing.T) {
+	checker := &fakeChecker{}
+	server := newAPIServer(t, checker)
+
+	body, contentType := multipartSpecRequest(t, "The system must work.", map[string]string{"preflight": "false"})
+	rec := apiRequest(t, server, http.MethodPost, "/api/v1/checks", contentType, body.Bytes())
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	check := waitForAPICheck(t, server, rec.Header().Get("Location"))
+	if check.Status != string(jobDone) || checker.req.Preflight {
+		t.Fatalf("check = %+v preflight %t", check, checker.req.Preflight)
+	}
+}
+
+func TestAPIServesStoredResultsAfterRestart(t *testing.T) {
+	config := DefaultConfig()
+	config.APITokens = []string{testAPIToken}
+	config.StoreDir = t.TempDir()
+	server, err := NewServerWithChecker(config, &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	body, _ := json.Marshal(APICheckRequest{SpecName: "billing.md", SpecText: "The system must work."})
+	rec := apiRequest(t, server, http.MethodPost, "/api/v1/checks", "application/json", body)
+	done := waitForAPICheck(t, server, rec.Header().Get("Location"))
+	server.Close()
+	if done.CheckID == "" || done.Links.Report != "/api/v1/checks/"+done.CheckID+"/report" {
+		t.Fatalf("check = %+v", done)
+	}
+
+	restarted, err := NewServerWithChecker(config, &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(restarted.Close)
+	if rec := apiRequest(t, restarted, http.MethodGet, done.Links.Self, "", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("job status after restart = %d, want 404", rec.Code)
+	}
+	check := waitForAPICheck(t, restarted, "/api/v1/checks/"+done.CheckID)
+	if check.ID != done.CheckID || check.Status != string(jobDone) || check.Summary == nil || check.Links.Report != done.Links.Report {
+		t.Fatalf("stored check = %+v", check)
+	}
+	for _, path := range []string{done.Links.Report, done.Links.Patch} {
+		if rec := apiRequest(t, restarted, http.MethodGet, path, "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("GET %s after restart = %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestAPIHidesBrowserChecks(t *testing.T) {
+	server := newAPIServer(t, &fakeChecker{})
+	j, err := server.jobs.enqueue("session", false, []app.CheckRequest{{SpecName: "SPEC.md", SpecText: "# Spec\n"}})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	list := apiRequest(t, server, http.MethodGet, "/api/v1/checks", "", nil)
+	var checks APICheckList
+	if err := json.Unmarshal(list.Body.Bytes(), &checks); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(checks.Checks) != 0 {
+		t.Fatalf("list = %+v, want no browser checks", checks)
+	}
+	for _, path := range []string{"/api/v1/checks/" + j.id, "/api/v1/checks/" + j.id + "/events", "/api/v1/checks/" + j.id + "/report"} {
+		if rec := apiRequest(t, server, http.MethodGet, path, "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("GET %s = %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestAPIRejectsInvalidSubmissions(t *testing.T) {
+	server := newAPIServer(t, &fakeChecker{})
+
+	for name, tc := range map[string]struct {
+		contentType string
+		body        string
+		want        string
+	}{
+		"empty spec":      {"application/json", `{"spec_text":"  "}`, "spec is empty"},
+		"unknown field":   {"application/json", `{"spec_text":"x","bogus":1}`, "invalid JSON body"},
+		"invalid profile": {"application/json", `{"spec_text":"x","profile":"nope"}`, `invalid profile "nope"`},
+		"content type":    {"text/plain", "x", "content type"},
+	} {
+		rec := apiRequest(t, server, http.MethodPost, "/api/v1/checks", tc.contentType, []byte(tc.body))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d: %s", name, rec.Code, rec.Body.String())
+		}
+		var apiErr APIError
+		if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil || !strings.Contains(apiErr.Error, tc.want) {
+			t.Fatalf("%s: error = %q (%v), want %q", name, apiErr.Error, err, tc.want)
+		}
+	}
+
+	files, contentType := multipartSpecFilesRequest(t, map[string]string{"a.md": "A must work.", "b.md": "B must work."})
+	rec := apiRequest(t, server, http.MethodPost, "/api/v1/checks", contentType, files.Bytes())
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "one spec per request") {
+		t.Fatalf("multi-spec status = %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAPIRequiresToken(t *testing.T) {
+	server := newAPIServer(t, &fakeChecker{})
+
+	for _, auth := range []string{"", "Bearer wrong-token-0123456789", testAPIToken} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/checks", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		// Browser session cookies do not authenticate API calls.
+		addSessionCookies(req)
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+			t.Fatalf("auth %q: status = %d", auth, rec.Code)
+		}
+	}
+
+	rec := apiRequest(t, server, http.MethodGet, "/api/v1/checks/ffff", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown check status = %d", rec.Code)
+	}
+}
+
+func TestAPIDisabledWithoutTokens(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("openapi served with API disabled: %s", rec.Body.String())
+	}
+}
+
+func TestConfigRejectsShortAPITokens(t *testing.T) {
+	config := DefaultConfig()
+	config.APITokens = []string{"short"}
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected short token to be rejected")
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	server := newAPIServer(t, &fakeChecker{})
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI    string                    `json:"openapi"`
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+				Required   []string       `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode openapi: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("openapi version = %q", doc.OpenAPI)
+	}
+	for _, path := range []string{"/api/v1/checks", "/api/v1/checks/{id}", "/api/v1/checks/{id}/report", "/api/v1/checks/{id}/patch"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Fatalf("missing path %s", path)
+		}
+	}
+	report, ok := doc.Components.Schemas["Report"]
+	if !ok {
+		t.Fatal("missing Report schema")
+	}
+	for _, field := range []string{"tool", "summary", "issues", "questions", "patches"} {
+		if _, ok := report.Properties[field]; !ok {
+			t.Fatalf("Report schema missing %s", field)
+		}
+	}
+	if _, ok := doc.Components.Schemas["Issue"]; !ok {
+		t.Fatal("missing Issue schema")
+	}
+	request := doc.Components.Schemas["APICheckRequest"]
+	if len(request.Required) != 1 || request.Required[0] != "spec_text" {
+		t.Fatalf("APICheckRequest required = %v", request.Required)
+	}
+	progress := doc.Components.Schemas["ProgressEvent"]
+	for _, field := range []string{"spec", "stage", "done", "total"} {
+		if _, ok := progress.Properties[field]; !ok {
+			t.Fatalf("ProgressEvent schema missing %s", field)
+		}
+	}
+}
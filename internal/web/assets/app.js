@@ -28,22 +28,31 @@
     } else {
       options.body = body;
     }
+    // Quiet forms, such as triage decisions, keep their target in place
+    // while saving instead of showing the check progress indicator.
+    var quiet = form.hasAttribute("data-quiet-submit");
     var started = Date.now();
-    var timer = startRunningState(form, target, started);
+    var timer = startRunningState(form, quiet ? null : target, started);
 
     fetch(url, options).then(function (response) {
       return response.text().then(function (text) {
         if (!response.ok) {
           throw new Error(text || response.statusText || "Request failed.");
         }
-        return text;
+        return { status: response.status, text: text };
       });
-    }).then(function (text) {
-      if (target) {
-        if (replaceTarget(target, text)) {
-          showDoneState(target, started);
-        }
+    }).then(function (reply) {
+      if (!target || !replaceTarget(target, reply.text)) {
+        return null;
       }
+      var job = target.querySelector("[data-job-events]");
+      if (reply.status === 202 && job) {
+        return followJob(job, target, started);
+      }
+      if (!quiet) {
+        showDoneState(target, started);
+      }
+      return null;
     }).catch(function (error) {
       if (target) {
         showFailedState(target, started, error.message);
@@ -53,6 +62,105 @@
     });
   }
 
+  // followJob streams a queued check's progress and swaps in the result
+  // when the job finishes. The promise settles on the job's terminal event.
+  function followJob(job, target, started) {
+    return new Promise(function (resolve, reject) {
+      if (!window.EventSource) {
+        reject(new Error("This browser cannot follow check progress."));
+        return;
+      }
+      var source = new EventSource(job.getAttribute("data-job-events"));
+      var progress = job.querySelector("[data-job-progress]");
+      var cancel = job.querySelector("[data-job-cancel-button]");
+      if (cancel) {
+        cancel.addEventListener("click", function () {
+          cancel.disabled = true;
+          cancelJob(job.getAttribute("data-job-cancel"));
+        });
+      }
+      source.addEventListener("state", function (event) {
+        if (progress && event.data === "running") {
+          progress.textContent = "Running";
+        }
+      });
+      source.addEventListener("progress", function (event) {
+        if (!progress) {
+          return;
+        }
+        try {
+          progress.textContent = progressText(JSON.parse(event.data));
+        } catch (e) {
+          progress.textContent = "Running";
+        }
+      });
+      source.addEventListener("result", function (event) {
+        source.close();
+        if (replaceTarget(target, event.data)) {
+          showDoneState(target, started);
+        }
+        resolve();
+      });
+      source.addEventListener("failed", function (event) {
+        source.close();
+        var failure = {};
+        try {
+          failure = JSON.parse(event.data);
+        } catch (e) {
+          failure = {};
+        }
+        reject(new Error(failure.message || "Check failed."));
+      });
+      source.addEventListener("canceled", function (event) {
+        source.close();
+        target.replaceChildren(statusNode("check-status check-status-failed", event.data || "Check canceled."));
+        resolve();
+      });
+      source.onerror = function () {
+        if (source.readyState === EventSource.CLOSED) {
+          reject(new Error("Lost connection to the check."));
+        }
+      };
+    });
+  }
+
+  function progressText(p) {
+    var label;
+    switch (p.stage) {
+      case "preflight":
+        label = "Preflight done";
+        break;
+      case "review":
+        label = "Reviewing";
+        break;
+      case "chunk":
+        label = "Reviewed chunk " + p.done + "/" + p.total;
+        break;
+      case "synthesis":
+        label = "Synthesizing";
+        break;
+      case "convergence":
+        label = "Comparing with previous result";
+        break;
+      default:
+        label = "Running";
+    }
+    return p.spec ? p.spec + ": " + label : label;
+  }
+
+  function cancelJob(url) {
+    var token = document.querySelector('input[name="csrf_token"]');
+    var body = new FormData();
+    body.append("csrf_token", token ? token.value : "");
+    return fetch(url, {
+      method: "POST",
+      credentials: "same-origin",
+      body: body
+    }).catch(function () {
+      return null;
+    });
+  }
+
   function loadLink(link, target) {
     return fetch(link.getAttribute("hx-get"), {
       method: "GET",
@@ -178,7 +286,7 @@
   function replaceTarget(target, text) {
     if (window.DOMPurify) {
       target.replaceChildren(window.DOMPurify.sanitize(text, {
-        ADD_ATTR: ["hx-get", "hx-post", "hx-target", "data-modal-target"],
+        ADD_ATTR: ["hx-get", "hx-post", "hx-target", "data-modal-target", "target"],
         RETURN_DOM_FRAGMENT: true
       }));
       return true;
@@ -339,6 +447,11 @@
     if (!form || !form.getAttribute || !(form.getAttribute("hx-post") || form.getAttribute("hx-get"))) {
       return;
     }
+    // Buttons marked data-native-submit post the form normally, e.g. to
+    // download a file, instead of swapping a response into the page.
+    if (event.submitter && event.submitter.hasAttribute("data-native-submit")) {
+      return;
+    }
     event.preventDefault();
     submitForm(form);
   });
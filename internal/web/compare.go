@@ -0,0 +1,265 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/dshills/speccritic/internal/convergence"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffOp classifies one row of a side-by-side line diff.
+type diffOp string
+
+const (
+	diffSame    diffOp = "same"
+	diffChanged diffOp = "changed"
+	diffRemoved diffOp = "removed"
+	diffAdded   diffOp = "added"
+)
+
+// compareView renders two stored checks side by side. Finding refs carry
+// their convergence status: current findings are new or still open, previous
+// findings are still open, resolved, answered, dropped, or untracked.
+type compareView struct {
+	Previous *StoredCheck
+	Current  *StoredCheck
+	Status   convergence.Status
+	Notes    []string
+	Summary  convergence.Summary
+	Rows     []compareRow
+}
+
+// compareRow pairs a previous line with a current line. Either side is nil
+// when the line exists on one side only.
+type compareRow struct {
+	Op       diffOp
+	Previous *AnnotatedLine
+	Current  *AnnotatedLine
+}
+
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	if !s.validSessionCookies(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	previous, ok := s.store.Get(query.Get("previous"))
+	if !ok || previous.Result == nil || previous.Result.Report == nil {
+		http.NotFound(w, r)
+		return
+	}
+	current, ok := s.store.Get(query.Get("current"))
+	if !ok || current.Result == nil || current.Result.Report == nil {
+		http.NotFound(w, r)
+		return
+	}
+	view, err := buildCompareView(previous, current)
+	if err != nil {
+		log.Printf("build compare view: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "compare.html", view); err != nil {
+		log.Printf("render compare: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func buildCompareView(previous, current *StoredCheck) (compareView, error) {
+	prevReport := previous.Result.Report
+	curReport := current.Result.Report
+
+	cfg := convergence.DefaultConfig()
+	cfg.Report = true
+	cfg.Profile = curReport.Input.Profile
+	cfg.ReviewStrict = curReport.Input.Strict
+	cfg.SeverityThreshold = curReport.Input.SeverityThreshold
+	cfg.CurrentReviewCoverage = reviewCoverage(curReport)
+	if curReport.Input.Scope != nil {
+		cfg.Scope = curReport.Input.Scope.Ranges
+	}
+	compat := convergence.CheckCompatibility(&convergence.PreviousReport{Report: prevReport}, cfg)
+	result := convergence.CompareReports(prevReport, curReport, cfg, compat)
+
+	prevSpec, err := buildAnnotatedSpec(previous.Result.OriginalSpec, prevReport, parseSeverity(prevReport.Input.SeverityThreshold))
+	if err != nil {
+		return compareView{}, fmt.Errorf("annotating previous check: %w", err)
+	}
+	curSpec, err := buildAnnotatedSpec(current.Result.OriginalSpec, curReport, parseSeverity(curReport.Input.SeverityThreshold))
+	if err != nil {
+		return compareView{}, fmt.Errorf("annotating current check: %w", err)
+	}
+
+	prevStatus := make(map[string]string)
+	curStatus := make(map[string]string)
+	for _, f := range result.Current {
+		curStatus[f.Finding.ID] = string(f.Status)
+		if f.PreviousID != "" {
+			prevStatus[f.PreviousID] = string(f.Status)
+		}
+	}
+	for _, f := range result.Previous {
+		prevStatus[f.Finding.ID] = string(f.Status)
+	}
+	setRefStatuses(prevSpec.Lines, prevStatus)
+	setRefStatuses(curSpec.Lines, curStatus)
+
+	return compareView{
+		Previous: previous,
+		Current:  current,
+		Status:   result.Status,
+		Notes:    result.Notes,
+		Summary:  result.Summary,
+		Rows:     alignLines(prevSpec.Lines, curSpec.Lines),
+	}, nil
+}
+
+// reviewCoverage infers how completely a stored report reviewed its spec,
+// which decides whether a missing previous finding counts as resolved.
+func reviewCoverage(report *schema.Report) convergence.ReviewCoverage {
+	switch {
+	case report.Meta.Model == "preflight":
+		return convergence.CoveragePreflightOnly
+	case report.Meta.Incremental != nil && !report.Meta.Incremental.Fallback:
+		return convergence.CoverageIncremental
+	default:
+		return convergence.CoverageFull
+	}
+}
+
+func setRefStatuses(lines []AnnotatedLine, statuses map[string]string) {
+	for i := range lines {
+		for k := range lines[i].FindingRefs {
+			lines[i].FindingRefs[k].Status = statuses[lines[i].FindingRefs[k].ID]
+		}
+	}
+}
+
+// alignLines diffs two specs line by line. Each distinct line is mapped to
+// one rune so the rune diff is a line diff. A removal followed by an
+// insertion is paired row by row as a change.
+func alignLines(previous, current []AnnotatedLine) []compareRow {
+	codes := make(map[string]rune)
+	encode := func(lines []AnnotatedLine) []rune {
+		out := make([]rune, len(lines))
+		for i, line := range lines {
+			code, ok := codes[line.Text]
+			if !ok {
+				code = lineRune(len(codes))
+				codes[line.Text] = code
+			}
+			out[i] = code
+		}
+		return out
+	}
+	prevRunes := encode(previous)
+	curRunes := encode(current)
+	diffs := diffmatchpatch.New().DiffMainRunes(prevRunes, curRunes, false)
+
+	var rows []compareRow
+	var removed []*AnnotatedLine
+	flush := func() {
+		for _, line := range removed {
+			rows = append(rows, compareRow{Op: diffRemoved, Previous: line})
+		}
+		removed = nil
+	}
+	p, c := 0, 0
+	for _, d := range diffs {
+		n := utf8.RuneCountInString(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			flush()
+			for i := 0; i < n; i++ {
+				rows = append(rows, compareRow{Op: diffSame, Previous: &previous[p], Current: &current[c]})
+				p++
+				c++
+			}
+		case diffmatchpatch.DiffDelete:
+			for i := 0; i < n; i++ {
+				removed = append(removed, &previous[p])
+				p++
+			}
+		case diffmatchpatch.DiffInsert:
+			for i := 0; i < n; i++ {
+				if len(removed) > 0 {
+					rows = append(rows, compareRow{Op: diffChanged, Previous: removed[0], Current: &current[c]})
+					removed = removed[1:]
+				} else {
+					rows = append(rows, compareRow{Op: diffAdded, Current: &current[c]})
+				}
+				c++
+			}
+		}
+	}
+	flush()
+	return rows
+}
+
+// lineRune returns the rune standing for the nth distinct line, skipping
+// the surrogate range so every code is a valid rune.
+func lineRune(n int) rune {
+	r := rune(n + 1)
+	if r >= 0xD800 {
+		r += 0x800
+	}
+	return r
+}
+
+// statusLabel formats a convergence status for display.
+func statusLabel(status string) string {
+	return strings.ReplaceAll(status, "_", " ")
+}
+
+// uploadHistory remembers each session's latest check of each spec name, so
+// a result can link to a comparison with the previous upload. Only the most
+// recently used max keys are kept.
+type uploadHistory struct {
+	mu     sync.Mutex
+	max    int
+	latest map[string]string
+	order  []string
+}
+
+func newUploadHistory(max int) *uploadHistory {
+	return &uploadHistory{max: max, latest: make(map[string]string)}
+}
+
+// record stores checkID as the latest check of specName for owner and
+// returns the check it replaced, if any.
+func (h *uploadHistory) record(owner, specName, checkID string) string {
+	if owner == "" {
+		return ""
+	}
+	key := owner + "\x00" + specName
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous, ok := h.latest[key]
+	if ok {
+		for i, k := range h.order {
+			if k == key {
+				h.order = append(h.order[:i], h.order[i+1:]...)
+				break
+			}
+		}
+	}
+	h.latest[key] = checkID
+	h.order = append(h.order, key)
+	for len(h.order) > h.max {
+		delete(h.latest, h.order[0])
+		h.order = h.order[1:]
+	}
+	return previous
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+func compareIssue(id, title string, line int, quote string) schema.Issue {
+	return schema.Issue{
+		ID:          id,
+		Severity:    schema.SeverityWarn,
+		Category:    schema.CategoryNonTestableRequirement,
+		Title:       title,
+		Description: "desc",
+		Evidence:    []schema.Evidence{{LineStart: line, LineEnd: line, Quote: quote}},
+	}
+}
+
+func saveCompareCheck(t *testing.T, server *Server, specText string, issues ...schema.Issue) *StoredCheck {
+	t.Helper()
+	stored, err := server.store.Save(&app.CheckResult{
+		OriginalSpec: specText,
+		Report: &schema.Report{
+			Tool:    "speccritic",
+			Input:   schema.Input{SpecFile: "SPEC.md", SeverityThreshold: "info"},
+			Summary: schema.Summary{Verdict: schema.VerdictValidWithGaps, Score: 70},
+			Issues:  issues,
+			Meta:    schema.Meta{Model: "openai:gpt-5"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("save check: %v", err)
+	}
+	return stored
+}
+
+func TestCompareViewShowsConvergenceStatus(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	previous := saveCompareCheck(t, server,
+		"# Spec\nThe system must work.\nLatency must be fast.\n",
+		compareIssue("ISSUE-0001", "Undefined success criteria", 2, "The system must work."),
+		compareIssue("ISSUE-0002", "Vague latency target", 3, "Latency must be fast."),
+	)
+	current := saveCompareCheck(t, server,
+		"# Spec\nThe system must work.\nLatency must be under 200 ms.\nRetries are unlimited.\n",
+		compareIssue("ISSUE-0001", "Undefined success criteria", 2, "The system must work."),
+		compareIssue("ISSUE-0002", "Unbounded retries", 4, "Retries are unlimited."),
+	)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/compare?previous="+previous.ID+"&current="+current.ID, nil)
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`class="line-finding status-still_open" href="/checks/` + previous.ID + `/issues/ISSUE-0001"`,
+		`class="line-finding status-resolved" href="/checks/` + previous.ID + `/issues/ISSUE-0002"`,
+		`class="line-finding status-still_open" href="/checks/` + current.ID + `/issues/ISSUE-0001"`,
+		`class="line-finding status-new" href="/checks/` + current.ID + `/issues/ISSUE-0002"`,
+		`<tr class="diff-changed">`,
+		`<tr class="diff-added">`,
+		"still open ISSUE-0001",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("compare page missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestCompareRequiresSessionAndKnownChecks(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	stored := saveCompareCheck(t, server, "The system must work.\n")
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/compare?previous="+stored.ID+"&current="+stored.ID, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("no session status = %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/compare?previous=ffff&current="+stored.ID, nil)
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown check status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAlignLinesPairsChanges(t *testing.T) {
+	lines := func(texts ...string) []AnnotatedLine {
+		out := make([]AnnotatedLine, len(texts))
+		for i, text := range texts {
+			out[i] = AnnotatedLine{Number: i + 1, Text: text}
+		}
+		return out
+	}
+	rows := alignLines(lines("a", "b", "c", "d"), lines("a", "B", "c", "e", "f"))
+	var got []string
+	for _, row := range rows {
+		entry := string(row.Op) + ":"
+		if row.Previous != nil {
+			entry += row.Previous.Text
+		}
+		entry += "/"
+		if row.Current != nil {
+			entry += row.Current.Text
+		}
+		got = append(got, entry)
+	}
+	want := "same:a/a,changed:b/B,same:c/c,changed:d/e,added:/f"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("rows = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestResultLinksToPreviousUpload(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	submit := func() string {
+		body, contentType := multipartSpecRequest(t, "The system must work.")
+		req := httptest.NewRequest(http.MethodPost, "/checks", body)
+		req.Header.Set("Content-Type", contentType)
+		addSessionCookies(req)
+		rec := submitCheck(t, server, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+	checkID := regexp.MustCompile(`<p>Check ([0-9a-f]+)</p>`)
+
+	first := submit()
+	if strings.Contains(first, "Compare with previous upload") {
+		t.Fatalf("first upload links to a comparison: %s", first)
+	}
+	firstID := checkID.FindStringSubmatch(first)
+	second := submit()
+	secondID := checkID.FindStringSubmatch(second)
+	if firstID == nil || secondID == nil {
+		t.Fatal("result missing check ID")
+	}
+	want := `href="/compare?previous=` + firstID[1] + `&amp;current=` + secondID[1] + `"`
+	if !strings.Contains(second, want) {
+		t.Fatalf("second upload missing %s: %s", want, second)
+	}
+}
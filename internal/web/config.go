@@ -5,12 +5,26 @@ import (
 	"time"
 )
 
+const minAPITokenLen = 16
+
 type Config struct {
 	Addr              string
 	RequestTimeout    time.Duration
 	MaxUploadBytes    int64
+	MaxBatchFiles     int
 	MaxRetainedChecks int
 	RetainedCheckTTL  time.Duration
+	// CheckWorkers bounds how many submitted checks run at once.
+	CheckWorkers int
+	// MaxQueuedChecks bounds how many submitted checks may wait for a worker;
+	// further submissions are rejected with 429 Too Many Requests.
+	MaxQueuedChecks int
+	// APITokens are the bearer tokens accepted by the /api/v1 endpoints. The
+	// API is disabled when no tokens are configured.
+	APITokens []string
+	// StoreDir persists checks as files in this directory when set; otherwise
+	// checks are kept in memory.
+	StoreDir string
 }
 
 func DefaultConfig() Config {
@@ -18,8 +32,11 @@ func DefaultConfig() Config {
 		Addr:              "127.0.0.1:8080",
 		RequestTimeout:    10 * time.Minute,
 		MaxUploadBytes:    1 << 20,
+		MaxBatchFiles:     10,
 		MaxRetainedChecks: 25,
 		RetainedCheckTTL:  30 * time.Minute,
+		CheckWorkers:      2,
+		MaxQueuedChecks:   8,
 	}
 }
 
@@ -33,11 +50,25 @@ func (c Config) Validate() error {
 	if c.MaxUploadBytes <= 0 {
 		return fmt.Errorf("max upload bytes must be > 0")
 	}
+	if c.MaxBatchFiles <= 0 {
+		return fmt.Errorf("max batch files must be > 0")
+	}
 	if c.MaxRetainedChecks <= 0 {
 		return fmt.Errorf("max retained checks must be > 0")
 	}
 	if c.RetainedCheckTTL <= 0 {
 		return fmt.Errorf("retained check TTL must be > 0")
 	}
+	if c.CheckWorkers <= 0 {
+		return fmt.Errorf("check workers must be > 0")
+	}
+	if c.MaxQueuedChecks <= 0 {
+		return fmt.Errorf("max queued checks must be > 0")
+	}
+	for _, token := range c.APITokens {
+		if len(token) < minAPITokenLen {
+			return fmt.Errorf("API tokens must be at least %d characters", minAPITokenLen)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,379 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/triage"
+)
+
+const (
+	fileStoreExt = ".json"
+	// retentionFile records the retention setting the server last used for
+	// the directory, so restarts and purge apply the same expiry.
+	retentionFile = "retention.json"
+)
+
+var checkIDPattern = regexp.MustCompile(`^[0-9a-f]{1,64}$`)
+
+// FileStore is a CheckStore that keeps one JSON file per check in a
+// directory, so retained checks survive server restarts.
+type FileStore struct {
+	mu    sync.Mutex
+	dir   string
+	max   int
+	ttl   time.Duration
+	now   func() time.Time
+	newID func() (string, error)
+}
+
+// storedRecord is the on-disk form of a StoredCheck.
+type storedRecord struct {
+	ID           string         `json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Report       *schema.Report `json:"report"`
+	PatchDiff    string         `json:"patch_diff,omitempty"`
+	OriginalSpec string         `json:"original_spec"`
+	LineCount    int            `json:"line_count"`
+	Model        string         `json:"model"`
+	Triage       []triage.Entry `json:"triage,omitempty"`
+}
+
+// Retention is a file store's retention setting.
+type Retention struct {
+	MaxRetainedChecks int
+	RetainedCheckTTL  time.Duration
+}
+
+// retentionRecord is the on-disk form of a Retention.
+type retentionRecord struct {
+	MaxRetainedChecks int    `json:"max_retained_checks"`
+	RetainedCheckTTL  string `json:"retained_check_ttl"`
+}
+
+// DefaultStoreRetention is the retention used for a new store directory
+// when no retention flags are given. Stored checks are meant to outlive
+// restarts, so it is longer than the in-memory default.
+func DefaultStoreRetention() Retention {
+	return Retention{MaxRetainedChecks: 5000, RetainedCheckTTL: 14 * 24 * time.Hour}
+}
+
+// LoadRetention reads the retention setting recorded in a store directory.
+// ok is false when the directory has none.
+func LoadRetention(dir string) (Retention, bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, retentionFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Retention{}, false, nil
+	}
+	if err != nil {
+		return Retention{}, false, fmt.Errorf("reading retention setting: %w", err)
+	}
+	var rec retentionRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return Retention{}, false, fmt.Errorf("decoding retention setting: %w", err)
+	}
+	ttl, err := time.ParseDuration(rec.RetainedCheckTTL)
+	if err != nil || ttl <= 0 || rec.MaxRetainedChecks <= 0 {
+		return Retention{}, false, fmt.Errorf("invalid retention setting in %s", filepath.Join(dir, retentionFile))
+	}
+	return Retention{MaxRetainedChecks: rec.MaxRetainedChecks, RetainedCheckTTL: ttl}, true, nil
+}
+
+// storedEntry is a stored check's listing metadata.
+type storedEntry struct {
+	ID        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// NewFileStore opens or creates a file-backed store in dir.
+func NewFileStore(dir string, max int, ttl time.Duration) (*FileStore, error) {
+	if max <= 0 {
+		return nil, fmt.Errorf("max must be > 0")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be > 0")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating store directory: %w", err)
+	}
+	return &FileStore{
+		dir:   dir,
+		max:   max,
+		ttl:   ttl,
+		now:   time.Now,
+		newID: randomID,
+	}, nil
+}
+
+// RecordRetention writes the store's retention setting to its directory.
+func (s *FileStore) RecordRetention() error {
+	data, err := json.Marshal(retentionRecord{MaxRetainedChecks: s.max, RetainedCheckTTL: s.ttl.String()})
+	if err != nil {
+		return fmt.Errorf("encoding retention setting: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, retentionFile), data, 0o600); err != nil {
+		return fmt.Errorf("writing retention setting: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Save(result *app.CheckResult) (*StoredCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepExpiredLocked()
+
+	id, err := s.uniqueIDLocked()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	check := &StoredCheck{
+		ID:        id,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+		Result:    result,
+	}
+	if err := s.writeLocked(check); err != nil {
+		return nil, err
+	}
+	s.evictLocked()
+	return check, nil
+}
+
+func (s *FileStore) Get(id string) (*StoredCheck, bool) {
+	if !checkIDPattern.MatchString(id) {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	check, err := s.readLocked(id)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("read stored check %s: %v", id, err)
+		}
+		return nil, false
+	}
+	if !s.now().Before(check.ExpiresAt) {
+		return nil, false
+	}
+	return check, true
+}
+
+func (s *FileStore) UpdateTriage(id string, update func([]triage.Entry) []triage.Entry) (*StoredCheck, error) {
+	if !checkIDPattern.MatchString(id) {
+		return nil, ErrCheckNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	check, err := s.readLocked(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCheckNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(check.ExpiresAt) {
+		return nil, ErrCheckNotFound
+	}
+	check.Triage = update(check.Triage)
+	if err := s.writeLocked(check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func (s *FileStore) SweepExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepExpiredLocked()
+}
+
+// Purge deletes expired checks, or every check when all is true, and returns
+// the number of checks removed.
+func (s *FileStore) Purge(all bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.listLocked()
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	removed := 0
+	for _, rec := range records {
+		if !all && now.Before(rec.ExpiresAt) {
+			continue
+		}
+		if err := s.removeLocked(rec.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (s *FileStore) uniqueIDLocked() (string, error) {
+	for i := 0; i < 8; i++ {
+		id, err := s.newID()
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(s.path(id)); errors.Is(err, fs.ErrNotExist) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("generating unique check id: too many collisions")
+}
+
+func (s *FileStore) writeLocked(check *StoredCheck) error {
+	rec := storedRecord{ID: check.ID, CreatedAt: check.CreatedAt, Triage: check.Triage}
+	if result := check.Result; result != nil {
+		rec.Report = result.Report
+		rec.PatchDiff = result.PatchDiff
+		rec.OriginalSpec = result.OriginalSpec
+		rec.LineCount = result.LineCount
+		rec.Model = result.Model
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encoding stored check: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".check-*")
+	if err != nil {
+		return fmt.Errorf("writing stored check: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing stored check: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing stored check: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(check.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing stored check: %w", err)
+	}
+	if err := os.Chtimes(s.path(check.ID), check.CreatedAt, check.CreatedAt); err != nil {
+		return fmt.Errorf("writing stored check: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) readLocked(id string) (*StoredCheck, error) {
+	info, err := os.Stat(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+	var rec storedRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("decoding stored check: %w", err)
+	}
+	// Expiry follows the current retention setting, not the one in effect
+	// when the check was saved, and is measured from the file modification
+	// time, as listing does, so reads, sweeps and purges agree.
+	return &StoredCheck{
+		ID:        rec.ID,
+		CreatedAt: rec.CreatedAt,
+		ExpiresAt: info.ModTime().Add(s.ttl),
+		Result: &app.CheckResult{
+			Report:       rec.Report,
+			PatchDiff:    rec.PatchDiff,
+			OriginalSpec: rec.OriginalSpec,
+			LineCount:    rec.LineCount,
+			Model:        rec.Model,
+		},
+		Triage: rec.Triage,
+	}, nil
+}
+
+// listLocked returns the stored checks, oldest first. A check's file
+// modification time is its creation time, so listing never decodes reports.
+func (s *FileStore) listLocked() ([]storedEntry, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing stored checks: %w", err)
+	}
+	var records []storedEntry
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), fileStoreExt)
+		if !ok || !entry.Type().IsRegular() || !checkIDPattern.MatchString(id) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		created := info.ModTime()
+		records = append(records, storedEntry{ID: id, CreatedAt: created, ExpiresAt: created.Add(s.ttl)})
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.Before(records[j].CreatedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records, nil
+}
+
+func (s *FileStore) evictLocked() {
+	records, err := s.listLocked()
+	if err != nil {
+		log.Printf("evict stored checks: %v", err)
+		return
+	}
+	for i := 0; i < len(records)-s.max; i++ {
+		if err := s.removeLocked(records[i].ID); err != nil {
+			log.Printf("evict stored check %s: %v", records[i].ID, err)
+		}
+	}
+}
+
+func (s *FileStore) sweepExpiredLocked() {
+	records, err := s.listLocked()
+	if err != nil {
+		log.Printf("sweep stored checks: %v", err)
+		return
+	}
+	now := s.now()
+	for _, rec := range records {
+		if now.Before(rec.ExpiresAt) {
+			continue
+		}
+		if err := s.removeLocked(rec.ID); err != nil {
+			log.Printf("sweep stored check %s: %v", rec.ID, err)
+		}
+	}
+}
+
+func (s *FileStore) removeLocked(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing stored check: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+fileStoreExt)
+}
@@ -11,6 +11,7 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -20,10 +21,10 @@ import (
 
 	"github.com/dshills/speccritic/internal/app"
 	"github.com/dshills/speccritic/internal/chunk"
-	"github.com/dshills/speccritic/internal/incremental"
 	"github.com/dshills/speccritic/internal/llm"
 	"github.com/dshills/speccritic/internal/render"
 	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/triage"
 )
 
 type indexData struct {
@@ -38,6 +39,7 @@ const maxWebModelNameLen = 120
 const maxWebCompletionPatches = 50
 const multipartMemoryLimit = 1 << 20
 const multipartOverheadLimit = 1 << 20
+const eventKeepalive = 15 * time.Second
 
 type resultView struct {
 	Check         *StoredCheck
@@ -46,13 +48,44 @@ type resultView struct {
 	Questions     []schema.Question
 	ModelProvider string
 	ModelName     string
+	// PreviousID is the session's previous check of the same spec, if it
+	// is still retained.
+	PreviousID     string
+	Triage         map[string]triage.Decision
+	Patches        []patchView
+	SkippedPatches int
+	// Nonce is the CSRF token for the patch review form. Results render on
+	// a worker, so it is the job owner's session ID, which the form cookie
+	// mirrors.
+	Nonce string
+}
+
+type batchResultView struct {
+	Batch   *schema.BatchReport
+	Results []resultView
+}
+
+// jobView is the placeholder rendered for a queued check.
+type jobView struct {
+	ID    string
+	Specs int
+}
+
+type progressEvent struct {
+	Spec string `json:"spec,omitempty"`
+	app.Progress
 }
 
 type findingDetail struct {
 	CheckID           string
+	FindingID         string
 	Issue             *schema.Issue
 	Question          *schema.Question
 	CompletionPatches []schema.Patch
+	Nonce             string
+	Decisions         []triage.Decision
+	Triage            triage.Entry
+	Saved             bool
 }
 
 type modelsResponse struct {
@@ -243,8 +276,11 @@ func handlerSeverityRank(severity schema.Severity) int {
 	}
 }
 
+// handleCheckStub validates a check submission and queues it. The response
+// is a job placeholder; progress and the rendered result arrive on the job's
+// event stream.
 func (s *Server) handleCheckStub(w http.ResponseWriter, r *http.Request) {
-	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes+multipartOverheadLimit)
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes*int64(s.config.MaxBatchFiles)+multipartOverheadLimit)
 	if err := s.parseRequestForm(r); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -256,56 +292,301 @@ func (s *Server) handleCheckStub(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
-	req, err := s.parseCheckRequest(r)
+	reqs, err := s.parseCheckRequests(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), s.config.RequestTimeout)
+	j, err := s.jobs.enqueue(sessionID(r), false, reqs)
+	if errors.Is(err, errQueueFull) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "Too many checks are waiting. Try again shortly.", http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		log.Printf("queue check: %v", err)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "partial_job.html", jobView{ID: j.id, Specs: len(reqs)}); err != nil {
+		log.Printf("render job: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Location", "/checks/"+j.id+"/events")
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// runCheckJob runs a queued job on a worker and records its outcome.
+func (s *Server) runCheckJob(ctx context.Context, j *job) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.RequestTimeout)
 	defer cancel()
 
+	reqs := j.reqs
+	for i := range reqs {
+		name := reqs[i].SpecName
+		reqs[i].Progress = func(p app.Progress) {
+			s.jobs.reportProgress(j, progressEvent{Spec: name, Progress: p})
+		}
+	}
+	var html []byte
+	var checkIDs []string
+	var err error
+	if len(reqs) > 1 {
+		html, checkIDs, err = s.renderBatchCheck(ctx, j.owner, reqs)
+	} else {
+		html, checkIDs, err = s.renderCheck(ctx, j.owner, reqs[0])
+	}
+	switch {
+	case err == nil:
+		s.jobs.complete(j, checkIDs, string(html))
+	case j.ctx.Err() != nil:
+		s.jobs.finish(j, jobCanceled, eventCanceled, "Check canceled.")
+	default:
+		var failure *checkFailure
+		if !errors.As(err, &failure) {
+			failure = errInternal
+		}
+		s.jobs.fail(j, failure)
+	}
+}
+
+// checkFailure is a job failure as shown to the browser: the HTTP status a
+// synchronous request would have returned and a sanitized message.
+type checkFailure struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (f *checkFailure) Error() string { return f.Message }
+
+var errInternal = &checkFailure{Status: http.StatusInternalServerError, Message: "Internal Server Error"}
+
+// renderCheck runs one check, stores it, and renders the result partial.
+func (s *Server) renderCheck(ctx context.Context, owner string, req app.CheckRequest) ([]byte, []string, error) {
 	result, err := s.checker.Check(ctx, req)
 	if err != nil {
 		log.Printf("check failed: %v", err)
-		status := http.StatusInternalServerError
-		var appErr *app.Error
-		if errors.As(err, &appErr) {
-			switch appErr.Kind {
-			case app.ErrorInput:
-				status = http.StatusBadRequest
-			case app.ErrorProvider, app.ErrorModelOutput:
-				status = http.StatusBadGateway
-			}
-		}
-		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			status = http.StatusGatewayTimeout
-		}
-		http.Error(w, sanitizeWebError(err), status)
-		return
+		return nil, nil, &checkFailure{Status: checkErrorStatus(ctx, err), Message: sanitizeWebError(err)}
 	}
-	stored, err := s.store.Save(result)
+	stored, previousID, err := s.saveCheck(owner, req.SpecName, result)
 	if err != nil {
 		log.Printf("store check: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+		return nil, nil, errInternal
 	}
-
 	view, err := s.resultView(stored)
 	if err != nil {
 		log.Printf("build result view: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+		return nil, nil, errInternal
 	}
+	view.PreviousID = previousID
+	view.Nonce = owner
 	var buf bytes.Buffer
 	if err := s.templates.ExecuteTemplate(&buf, "partial_result.html", view); err != nil {
 		log.Printf("render result: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, errInternal
+	}
+	return buf.Bytes(), []string{stored.ID}, nil
+}
+
+// renderBatchCheck checks several uploaded specs and renders an aggregate
+// summary followed by each stored result. Failed specs are listed in the
+// summary; the job fails only when every spec fails.
+func (s *Server) renderBatchCheck(ctx context.Context, owner string, reqs []app.CheckRequest) ([]byte, []string, error) {
+	cfg := app.BatchConfig{Concurrency: app.DefaultBatchConcurrency}
+	var items []app.BatchItem
+	if batcher, ok := s.checker.(batchChecker); ok {
+		items = batcher.CheckBatch(ctx, reqs, cfg)
+	} else {
+		items = app.RunBatch(ctx, s.checker.Check, reqs, cfg)
+	}
+
+	view := batchResultView{}
+	var checkIDs []string
+	var firstErr error
+	for i := range items {
+		if items[i].Err != nil {
+			log.Printf("check %s failed: %v", reqs[i].SpecName, items[i].Err)
+			if firstErr == nil {
+				firstErr = items[i].Err
+			}
+			// Keep provider details out of the page, as for single checks.
+			items[i].Err = errors.New(sanitizeWebError(items[i].Err))
+			continue
+		}
+		stored, previousID, err := s.saveCheck(owner, reqs[i].SpecName, items[i].Result)
+		if err != nil {
+			log.Printf("store check: %v", err)
+			return nil, nil, errInternal
+		}
+		checkIDs = append(checkIDs, stored.ID)
+		result, err := s.resultView(stored)
+		if err != nil {
+			log.Printf("build result view: %v", err)
+			return nil, nil, errInternal
+		}
+		result.PreviousID = previousID
+		result.Nonce = owner
+		view.Results = append(view.Results, result)
+	}
+	if len(view.Results) == 0 {
+		return nil, nil, &checkFailure{Status: checkErrorStatus(ctx, firstErr), Message: sanitizeWebError(firstErr)}
+	}
+	view.Batch = app.BuildBatchReport("", items)
+
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "partial_batch_result.html", view); err != nil {
+		log.Printf("render batch result: %v", err)
+		return nil, nil, errInternal
+	}
+	return buf.Bytes(), checkIDs, nil
+}
+
+// saveCheck stores a finished check and records it as the owner's latest
+// upload of specName. It returns the previous upload's ID while the store
+// still retains it, after carrying that check's triage decisions over to
+// matching findings of the new one.
+func (s *Server) saveCheck(owner, specName string, result *app.CheckResult) (*StoredCheck, string, error) {
+	stored, err := s.store.Save(result)
+	if err != nil {
+		return nil, "", err
+	}
+	previousID := s.uploads.record(owner, specName, stored.ID)
+	if previousID == "" {
+		return stored, "", nil
+	}
+	previous, ok := s.store.Get(previousID)
+	if !ok {
+		return stored, "", nil
+	}
+	carried := triage.CarryOver(previous.Triage, stored.Result.Report)
+	if len(carried) == 0 {
+		return stored, previousID, nil
+	}
+	updated, err := s.store.UpdateTriage(stored.ID, func([]triage.Entry) []triage.Entry { return carried })
+	if err != nil {
+		log.Printf("carry over triage decisions: %v", err)
+		return stored, previousID, nil
+	}
+	return updated, previousID, nil
+}
+
+// handleCheckEvents streams a job's events as server-sent events. Each event
+// carries its index as the event ID, so a reconnecting client resumes after
+// Last-Event-ID. The stream ends after the job's terminal event. Only the
+// browser session that submitted a job may stream it; API jobs are streamed
+// through the API.
+func (s *Server) handleCheckEvents(w http.ResponseWriter, r *http.Request) {
+	if !s.validSessionCookies(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	snap, ok := s.jobs.snapshot(r.PathValue("id"))
+	if !ok || snap.API || snap.Owner != sessionID(r) {
+		http.NotFound(w, r)
+		return
+	}
+	s.streamJobEvents(w, r, snap.ID)
+}
+
+// streamJobEvents streams the events of job id, which the caller has
+// checked the request may read.
+func (s *Server) streamJobEvents(w http.ResponseWriter, r *http.Request, id string) {
+	j, ok := s.jobs.get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	next := 0
+	if last, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil && last > 0 {
+		next = last
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(buf.Bytes())
+
+	keepalive := time.NewTicker(eventKeepalive)
+	defer keepalive.Stop()
+	for {
+		events, changed, finished := s.jobs.eventsSince(j, next)
+		for _, ev := range events {
+			next++
+			writeEvent(w, next, ev)
+		}
+		flusher.Flush()
+		if finished {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		case <-keepalive.C:
+			_, _ = io.WriteString(w, ": keepalive\n\n")
+		}
+	}
+}
+
+// handleCheckCancel cancels a queued or running job.
+func (s *Server) handleCheckCancel(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, multipartOverheadLimit)
+	if err := s.parseRequestForm(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if !s.validNonce(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	found, active := s.jobs.cancelJob(r.PathValue("id"))
+	switch {
+	case !found:
+		http.NotFound(w, r)
+	case !active:
+		http.Error(w, "Check already finished.", http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func writeEvent(w io.Writer, id int, ev jobEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\n", id, ev.Name)
+	for _, line := range strings.Split(ev.Data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	_, _ = io.WriteString(w, "\n")
+}
+
+func checkErrorStatus(ctx context.Context, err error) int {
+	status := http.StatusInternalServerError
+	var appErr *app.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Kind {
+		case app.ErrorInput:
+			status = http.StatusBadRequest
+		case app.ErrorProvider, app.ErrorModelOutput:
+			status = http.StatusBadGateway
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	}
+	return status
 }
 
 func (s *Server) handleIssueDetail(w http.ResponseWriter, r *http.Request) {
@@ -324,7 +605,19 @@ func (s *Server) handleIssueDetail(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	detail := findingDetail{CheckID: id}
+	detail, ok := newFindingDetail(check, findingID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	detail.Nonce = formNonce(r)
+	s.writeFindingDetail(w, detail)
+}
+
+// newFindingDetail looks up a finding of a stored check and its triage
+// decision.
+func newFindingDetail(check *StoredCheck, findingID string) (findingDetail, bool) {
+	detail := findingDetail{CheckID: check.ID, FindingID: findingID, Decisions: triage.Decisions()}
 	for i := range check.Result.Report.Issues {
 		if check.Result.Report.Issues[i].ID == findingID {
 			detail.Issue = &check.Result.Report.Issues[i]
@@ -340,10 +633,18 @@ func (s *Server) handleIssueDetail(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if detail.Issue == nil && detail.Question == nil {
-		http.NotFound(w, r)
-		return
+		return findingDetail{}, false
 	}
 	detail.CompletionPatches = relatedCompletionPatches(check.Result.Report, findingID)
+	for _, entry := range check.Triage {
+		if entry.FindingID == findingID {
+			detail.Triage = entry
+		}
+	}
+	return detail, true
+}
+
+func (s *Server) writeFindingDetail(w http.ResponseWriter, detail findingDetail) {
 	var buf bytes.Buffer
 	if err := s.templates.ExecuteTemplate(&buf, "partial_issue_detail.html", detail); err != nil {
 		log.Printf("render issue detail: %v", err)
@@ -416,13 +717,21 @@ func (s *Server) resultView(check *StoredCheck) (resultView, error) {
 		return resultView{}, err
 	}
 	provider, model := splitModelDisplay(check.Result.Report.Meta.Model)
+	patches, skipped := buildPatchViews(check.Result)
+	decisions := make(map[string]triage.Decision, len(check.Triage))
+	for _, entry := range check.Triage {
+		decisions[entry.FindingID] = entry.Decision
+	}
 	return resultView{
-		Check:         check,
-		Annotated:     annotated,
-		Issues:        filterIssues(check.Result.Report.Issues, threshold),
-		Questions:     filterQuestions(check.Result.Report.Questions, threshold),
-		ModelProvider: provider,
-		ModelName:     model,
+		Check:          check,
+		Triage:         decisions,
+		Annotated:      annotated,
+		Issues:         filterIssues(check.Result.Report.Issues, threshold),
+		Questions:      filterQuestions(check.Result.Report.Questions, threshold),
+		ModelProvider:  provider,
+		ModelName:      model,
+		Patches:        patches,
+		SkippedPatches: skipped,
 	}, nil
 }
 
@@ -502,95 +811,102 @@ func (s *Server) parseRequestForm(r *http.Request) error {
 	return nil
 }
 
-func (s *Server) parseCheckRequest(r *http.Request) (app.CheckRequest, error) {
+// checkInput is a check submission before validation: the spec texts, the
+// optional previous result and base spec, and the option fields.
+type checkInput struct {
+	specs           []uploadedSpec
+	previousReport  string
+	incrementalBase string
+	values          url.Values
+}
+
+func (s *Server) parseCheckRequests(r *http.Request) ([]app.CheckRequest, error) {
+	in, err := s.readCheckForm(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildCheckRequests(in)
+}
+
+// readCheckForm reads a multipart check submission.
+func (s *Server) readCheckForm(r *http.Request) (checkInput, error) {
 	isMultipart := strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
 	if !isMultipart {
-		return app.CheckRequest{}, fmt.Errorf("uploaded spec file is required")
-	}
-	specText := ""
-	specName := "SPEC.md"
-	var file multipart.File
-	var header *multipart.FileHeader
-	hasFile := false
-	var fileErr error
-	file, header, fileErr = r.FormFile("spec_file")
-	hasFile = fileErr == nil
-	if fileErr != nil && !errors.Is(fileErr, http.ErrMissingFile) {
-		return app.CheckRequest{}, fmt.Errorf("reading uploaded file: %w", fileErr)
-	}
-	if hasFile {
-		defer file.Close()
-		data, err := readUploadedSpec(file, s.config.MaxUploadBytes)
-		if err != nil {
-			return app.CheckRequest{}, err
-		}
-		if len(bytes.TrimSpace(data)) == 0 {
-			return app.CheckRequest{}, fmt.Errorf("spec is empty")
-		}
-		if !utf8.Valid(data) {
-			return app.CheckRequest{}, fmt.Errorf("uploaded file must be UTF-8 text")
-		}
-		specText = string(data)
-		if header != nil && header.Filename != "" {
-			specName = filepath.Base(header.Filename)
-		}
+		return checkInput{}, fmt.Errorf("uploaded spec file is required")
 	}
-	if specText == "" {
-		return app.CheckRequest{}, fmt.Errorf("uploaded spec file is required")
+	specs, err := s.readUploadedSpecs(r)
+	if err != nil {
+		return checkInput{}, err
 	}
 	previousReport, err := readOptionalUploadText(r, "previous_result", s.config.MaxUploadBytes)
 	if err != nil {
-		return app.CheckRequest{}, fmt.Errorf("reading previous result: %w", err)
+		return checkInput{}, fmt.Errorf("reading previous result: %w", err)
 	}
-	previousReport = strings.TrimSpace(previousReport)
 	incrementalBase, err := readOptionalUploadText(r, "incremental_base_file", s.config.MaxUploadBytes)
 	if err != nil {
-		return app.CheckRequest{}, fmt.Errorf("reading incremental base spec: %w", err)
+		return checkInput{}, fmt.Errorf("reading incremental base spec: %w", err)
 	}
-	incrementalMode := r.FormValue("incremental_mode")
+	return checkInput{
+		specs:           specs,
+		previousReport:  previousReport,
+		incrementalBase: incrementalBase,
+		values:          r.Form,
+	}, nil
+}
+
+// buildCheckRequests validates a submission and expands it into one check
+// request per spec.
+func (s *Server) buildCheckRequests(in checkInput) ([]app.CheckRequest, error) {
+	specs := in.specs
+	previousReport := strings.TrimSpace(in.previousReport)
+	incrementalBase := in.incrementalBase
+	incrementalMode := in.values.Get("incremental_mode")
 	if incrementalMode == "" {
 		incrementalMode = "auto"
 	}
 	switch incrementalMode {
 	case "auto", "on", "off":
 	default:
-		return app.CheckRequest{}, fmt.Errorf("invalid incremental mode %q", incrementalMode)
+		return nil, fmt.Errorf("invalid incremental mode %q", incrementalMode)
 	}
-	convergenceMode := r.FormValue("convergence_mode")
+	convergenceMode := in.values.Get("convergence_mode")
 	if convergenceMode == "" {
 		convergenceMode = "auto"
 	}
 	switch convergenceMode {
 	case "auto", "on", "off":
 	default:
-		return app.CheckRequest{}, fmt.Errorf("invalid convergence mode %q", convergenceMode)
+		return nil, fmt.Errorf("invalid convergence mode %q", convergenceMode)
 	}
 	if convergenceMode == "on" && previousReport == "" {
-		return app.CheckRequest{}, fmt.Errorf("previous result is required when convergence mode is on")
+		return nil, fmt.Errorf("previous result is required when convergence mode is on")
+	}
+	if len(specs) > 1 && (previousReport != "" || incrementalBase != "") {
+		return nil, fmt.Errorf("previous result and previous spec cannot be used with multiple spec files")
 	}
 
-	profile := r.FormValue("profile")
+	profile := in.values.Get("profile")
 	if profile == "" {
 		profile = "general"
 	}
 	switch profile {
 	case "general", "backend-api", "regulated-system", "event-driven":
 	default:
-		return app.CheckRequest{}, fmt.Errorf("invalid profile %q", profile)
+		return nil, fmt.Errorf("invalid profile %q", profile)
 	}
 
-	severity := r.FormValue("severity_threshold")
+	severity := in.values.Get("severity_threshold")
 	if severity == "" {
 		severity = "info"
 	}
 	switch severity {
 	case "info", "warn", "critical":
 	default:
-		return app.CheckRequest{}, fmt.Errorf("invalid severity threshold %q", severity)
+		return nil, fmt.Errorf("invalid severity threshold %q", severity)
 	}
 
-	llmProvider := strings.ToLower(strings.TrimSpace(r.FormValue("llm_provider")))
-	llmModel := strings.TrimSpace(r.FormValue("llm_model"))
+	llmProvider := strings.ToLower(strings.TrimSpace(in.values.Get("llm_provider")))
+	llmModel := strings.TrimSpace(in.values.Get("llm_model"))
 	if llmProvider == "" {
 		if inferred := llm.ProviderForModel(llmModel); inferred != "" {
 			llmProvider = inferred
@@ -599,54 +915,54 @@ func (s *Server) parseCheckRequest(r *http.Request) (app.CheckRequest, error) {
 		}
 	}
 	if !llm.IsSupportedProvider(llmProvider) {
-		return app.CheckRequest{}, webInputError("invalid provider %q", llmProvider)
+		return nil, webInputError("invalid provider %q", llmProvider)
 	}
 	if llmModel == "" {
 		llmModel = defaultModelForProvider(llmProvider)
 	}
 	if len(llmModel) > maxWebModelNameLen {
-		return app.CheckRequest{}, webInputError("model name is too long")
+		return nil, webInputError("model name is too long")
 	}
 
 	temperature := 0.2
-	if raw := r.FormValue("temperature"); raw != "" {
+	if raw := in.values.Get("temperature"); raw != "" {
 		v, err := strconv.ParseFloat(raw, 64)
 		if err != nil || v < 0 || v > 2 {
-			return app.CheckRequest{}, fmt.Errorf("invalid temperature")
+			return nil, fmt.Errorf("invalid temperature")
 		}
 		temperature = v
 	}
 
 	maxTokens := 8192
-	if raw := r.FormValue("max_tokens"); raw != "" {
+	if raw := in.values.Get("max_tokens"); raw != "" {
 		v, err := strconv.Atoi(raw)
 		if err != nil || v <= 0 || v > maxWebTokens {
-			return app.CheckRequest{}, fmt.Errorf("invalid max tokens")
+			return nil, fmt.Errorf("invalid max tokens")
 		}
 		maxTokens = v
 	}
-	completionSuggestions := formBoolDefault(r, "completion_suggestions", false)
-	completionMode := r.FormValue("completion_mode")
+	completionSuggestions := formBoolDefault(in.values, "completion_suggestions", false)
+	completionMode := in.values.Get("completion_mode")
 	if completionMode == "" {
 		completionMode = schema.CompletionModeAuto
 	}
 	switch completionMode {
 	case schema.CompletionModeAuto, schema.CompletionModeOn, schema.CompletionModeOff:
 	default:
-		return app.CheckRequest{}, fmt.Errorf("invalid completion mode %q", completionMode)
+		return nil, fmt.Errorf("invalid completion mode %q", completionMode)
 	}
-	completionTemplate := r.FormValue("completion_template")
+	completionTemplate := in.values.Get("completion_template")
 	if completionTemplate == "" {
 		completionTemplate = schema.CompletionTemplateProfile
 	}
 	if !schema.IsCompletionInputTemplateName(completionTemplate) {
-		return app.CheckRequest{}, fmt.Errorf("invalid completion template %q", completionTemplate)
+		return nil, fmt.Errorf("invalid completion template %q", completionTemplate)
 	}
 	completionMaxPatches := 8
-	if raw := r.FormValue("completion_max_patches"); raw != "" {
+	if raw := in.values.Get("completion_max_patches"); raw != "" {
 		v, err := strconv.Atoi(raw)
 		if err != nil || v < 0 || v > maxWebCompletionPatches {
-			return app.CheckRequest{}, fmt.Errorf("invalid completion max patches")
+			return nil, fmt.Errorf("invalid completion max patches")
 		}
 		completionMaxPatches = v
 	}
@@ -654,57 +970,98 @@ func (s *Server) parseCheckRequest(r *http.Request) (app.CheckRequest, error) {
 	if llmProvider == "gemini" {
 		chunkConcurrency = 1
 	}
-	preflightEnabled := formBoolDefault(r, "preflight", true)
-	preflightMode := r.FormValue("preflight_mode")
+	preflightEnabled := formBoolDefault(in.values, "preflight", true)
+	preflightMode := in.values.Get("preflight_mode")
 	if preflightMode == "" {
 		preflightMode = "warn"
 	}
 	switch preflightMode {
 	case "warn", "gate", "only":
 	default:
-		return app.CheckRequest{}, fmt.Errorf("invalid preflight mode %q", preflightMode)
-	}
-
-	incrementalDefaults := incremental.DefaultConfig()
-	return app.CheckRequest{
-		SpecName:                        specName,
-		SpecText:                        specText,
-		Profile:                         profile,
-		Strict:                          r.FormValue("strict") == "true",
-		SeverityThreshold:               severity,
-		LLMProvider:                     llmProvider,
-		LLMModel:                        llmModel,
-		Temperature:                     temperature,
-		MaxTokens:                       maxTokens,
-		Preflight:                       preflightEnabled,
-		PreflightMode:                   preflightMode,
-		PreflightProfile:                profile,
-		Chunking:                        string(chunk.ModeAuto),
-		ChunkLines:                      chunk.DefaultChunkLines,
-		ChunkOverlap:                    chunk.DefaultChunkOverlap,
-		ChunkMinLines:                   chunk.DefaultChunkMinLines,
-		ChunkTokenThreshold:             chunk.DefaultChunkTokenThreshold,
-		ChunkConcurrency:                chunkConcurrency,
-		SynthesisLineThreshold:          chunk.DefaultSynthesisLineThreshold,
-		IncrementalFromText:             previousReport,
-		IncrementalBaseText:             incrementalBase,
-		IncrementalMode:                 incrementalMode,
-		IncrementalMaxChangeRatio:       incrementalDefaults.MaxChangeRatio,
-		IncrementalMaxRemapFailureRatio: incrementalDefaults.MaxRemapFailureRatio,
-		IncrementalContextLines:         incrementalDefaults.ContextLines,
-		IncrementalStrictReuse:          true,
-		IncrementalReport:               true,
-		ConvergenceFromText:             previousReport,
-		ConvergenceMode:                 convergenceMode,
-		ConvergenceReport:               previousReport != "" && convergenceMode != "off",
-		CompletionSuggestions:           completionSuggestions,
-		CompletionMode:                  completionMode,
-		CompletionTemplate:              completionTemplate,
-		CompletionMaxPatches:            completionMaxPatches,
-		CompletionOpenDecisions:         true,
-		Source:                          app.SourceWeb,
-		ErrWriter:                       io.Discard,
-	}, nil
+		return nil, fmt.Errorf("invalid preflight mode %q", preflightMode)
+	}
+
+	base := app.DefaultRequest(app.SourceWeb)
+	base.Profile = profile
+	base.Strict = in.values.Get("strict") == "true"
+	base.SeverityThreshold = severity
+	base.LLMProvider = llmProvider
+	base.LLMModel = llmModel
+	base.Temperature = temperature
+	base.MaxTokens = maxTokens
+	base.Preflight = preflightEnabled
+	base.PreflightMode = preflightMode
+	base.PreflightProfile = profile
+	base.ChunkConcurrency = chunkConcurrency
+	base.IncrementalFromText = previousReport
+	base.IncrementalBaseText = incrementalBase
+	base.IncrementalMode = incrementalMode
+	base.IncrementalReport = true
+	base.ConvergenceFromText = previousReport
+	base.ConvergenceMode = convergenceMode
+	base.ConvergenceReport = previousReport != "" && convergenceMode != "off"
+	base.CompletionSuggestions = completionSuggestions
+	base.CompletionMode = completionMode
+	base.CompletionTemplate = completionTemplate
+	base.CompletionMaxPatches = completionMaxPatches
+	reqs := make([]app.CheckRequest, len(specs))
+	for i, uploaded := range specs {
+		reqs[i] = base
+		reqs[i].SpecName = uploaded.name
+		reqs[i].SpecText = uploaded.text
+	}
+	return reqs, nil
+}
+
+type uploadedSpec struct {
+	name string
+	text string
+}
+
+// readUploadedSpecs reads every spec_file part. Each file is held to the
+// single-upload size limit and the count to MaxBatchFiles.
+func (s *Server) readUploadedSpecs(r *http.Request) ([]uploadedSpec, error) {
+	var headers []*multipart.FileHeader
+	if r.MultipartForm != nil {
+		headers = r.MultipartForm.File["spec_file"]
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("uploaded spec file is required")
+	}
+	if len(headers) > s.config.MaxBatchFiles {
+		return nil, fmt.Errorf("too many spec files: at most %d allowed", s.config.MaxBatchFiles)
+	}
+	specs := make([]uploadedSpec, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading uploaded file: %w", err)
+		}
+		data, err := readUploadedSpec(file, s.config.MaxUploadBytes)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if err := validateSpecText(data); err != nil {
+			return nil, err
+		}
+		name := "SPEC.md"
+		if header.Filename != "" {
+			name = filepath.Base(header.Filename)
+		}
+		specs = append(specs, uploadedSpec{name: name, text: string(data)})
+	}
+	return specs, nil
+}
+
+func validateSpecText(data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("spec is empty")
+	}
+	if !utf8.Valid(data) {
+		return fmt.Errorf("uploaded file must be UTF-8 text")
+	}
+	return nil
 }
 
 func relatedCompletionPatches(report *schema.Report, findingID string) []schema.Patch {
@@ -761,11 +1118,8 @@ func readOptionalUploadText(r *http.Request, field string, limit int64) (string,
 	return string(data), nil
 }
 
-func formBoolDefault(r *http.Request, name string, def bool) bool {
-	values, ok := r.PostForm[name]
-	if !ok && r.MultipartForm != nil {
-		values, ok = r.MultipartForm.Value[name]
-	}
+func formBoolDefault(form url.Values, name string, def bool) bool {
+	values, ok := form[name]
 	if !ok {
 		return def
 	}
@@ -808,6 +1162,24 @@ func (s *Server) validNonce(r *http.Request) bool {
 	return subtle.ConstantTimeCompare([]byte(submitted), []byte(formCookie.Value)) == 1
 }
 
+// sessionID returns the browser session a validated request belongs to.
+func sessionID(r *http.Request) string {
+	cookie, err := r.Cookie("speccritic_session")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// formNonce returns the CSRF token forms rendered for r must submit.
+func formNonce(r *http.Request) string {
+	cookie, err := r.Cookie("speccritic_form")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
 func (s *Server) validSessionCookies(r *http.Request) bool {
 	sessionCookie, err := r.Cookie("speccritic_session")
 	if err != nil || sessionCookie.Value == "" {
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/dshills/speccritic/internal/app"
@@ -170,16 +173,15 @@ func createStoredCheck(t *testing.T, server *Server) string {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 	if rec.Code != http.StatusOK {
 		t.Fatalf("post status = %d", rec.Code)
 	}
-	if len(server.store.order) == 0 {
+	if len(server.store.(*Store).order) == 0 {
 		t.Fatal("stored check ID missing")
 	}
-	return server.store.order[len(server.store.order)-1]
+	return server.store.(*Store).order[len(server.store.(*Store).order)-1]
 }
 
 func TestIndex(t *testing.T) {
@@ -313,7 +315,7 @@ func TestCheckStubRequiresNonce(t *testing.T) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "right"})
 	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "right"})
-	server.Handler().ServeHTTP(rec, req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusForbidden {
 		t.Fatalf("status = %d, want 403", rec.Code)
@@ -331,9 +333,8 @@ func TestCheckStubAcceptedNonce(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -381,9 +382,8 @@ func TestCheckStubAcceptsProviderAndModel(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -414,9 +414,8 @@ func TestCheckStubAcceptsIncrementalUploads(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", &body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d body=%s", rec.Code, rec.Body.String())
@@ -451,9 +450,8 @@ func TestCheckStubAcceptsCompletionOptions(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
@@ -482,9 +480,8 @@ func TestCheckStubRejectsInvalidCompletionMaxPatches(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("status = %d, want 400", rec.Code)
@@ -506,9 +503,8 @@ func TestCheckStubRequiresPreviousUploadForConvergenceOn(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("status = %d body=%s", rec.Code, rec.Body.String())
@@ -528,9 +524,8 @@ func TestCheckStubDefaultsModelForSelectedProvider(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -553,9 +548,8 @@ func TestCheckStubUsesSerialChunkingForGemini(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -578,9 +572,8 @@ func TestCheckStubInfersProviderFromModel(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -643,9 +636,8 @@ func TestCheckStubRequiresUploadedSpec(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("status = %d, want 400", rec.Code)
@@ -669,9 +661,8 @@ func TestCheckStubAllowsDisablingPreflight(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -695,9 +686,8 @@ func TestCheckStubAcceptsPreflightMode(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
@@ -720,17 +710,16 @@ func TestIssueDetail(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/checks", body)
 	req.Header.Set("Content-Type", contentType)
-	req.AddCookie(&http.Cookie{Name: "speccritic_session", Value: "session"})
-	req.AddCookie(&http.Cookie{Name: "speccritic_form", Value: "same"})
-	server.Handler().ServeHTTP(rec, req)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
 	if rec.Code != http.StatusOK {
 		t.Fatalf("post status = %d", rec.Code)
 	}
 
-	if len(server.store.order) == 0 {
+	if len(server.store.(*Store).order) == 0 {
 		t.Fatal("stored check ID missing")
 	}
-	id := server.store.order[0]
+	id := server.store.(*Store).order[0]
 
 	detail := httptest.NewRecorder()
 	detailReq := httptest.NewRequest(http.MethodGet, "/checks/"+id+"/issues/ISSUE-0001", nil)
@@ -786,3 +775,129 @@ func writeMultipartFile(t *testing.T, writer *multipart.Writer, field, name, tex
 		t.Fatalf("write %s file part: %v", field, err)
 	}
 }
+
+type serialChecker struct {
+	mu    sync.Mutex
+	fake  fakeChecker
+	names []string
+	fail  map[string]error
+}
+
+func (c *serialChecker) Check(ctx context.Context, req app.CheckRequest) (*app.CheckResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = append(c.names, req.SpecName)
+	if err := c.fail[req.SpecName]; err != nil {
+		return nil, err
+	}
+	return c.fake.Check(ctx, req)
+}
+
+func multipartSpecFilesRequest(t *testing.T, files map[string]string, fields ...map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("csrf_token", "same"); err != nil {
+		t.Fatalf("write csrf field: %v", err)
+	}
+	for _, group := range fields {
+		for name, value := range group {
+			if err := writer.WriteField(name, value); err != nil {
+				t.Fatalf("write %s field: %v", name, err)
+			}
+		}
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeMultipartFile(t, writer, "spec_file", name, files[name])
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+func TestCheckStubMultipleSpecsRendersBatchSummary(t *testing.T) {
+	checker := &serialChecker{fail: map[string]error{
+		"broken.md": &app.Error{Kind: app.ErrorProvider, Err: errors.New("secret provider detail")},
+	}}
+	server, err := NewServerWithChecker(DefaultConfig(), checker)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	body, contentType := multipartSpecFilesRequest(t, map[string]string{
+		"billing.md": "The billing system must work.",
+		"broken.md":  "The broken system must work.",
+		"search.md":  "The search system must work.",
+	})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/checks", body)
+	req.Header.Set("Content-Type", contentType)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if len(checker.names) != 3 {
+		t.Fatalf("checked specs = %v", checker.names)
+	}
+	out := rec.Body.String()
+	for _, want := range []string{"3 specs", "billing.md", "search.md", "broken.md", "LLM provider error.", "ISSUE-0001"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("response missing %q: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret provider detail") {
+		t.Fatalf("response leaked provider error: %s", out)
+	}
+	if got := len(server.store.(*Store).order); got != 2 {
+		t.Fatalf("stored checks = %d, want 2", got)
+	}
+}
+
+func TestCheckStubRejectsTooManySpecs(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxBatchFiles = 1
+	server, err := NewServerWithChecker(config, &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	body, contentType := multipartSpecFilesRequest(t, map[string]string{"a.md": "A must work.", "b.md": "B must work."})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/checks", body)
+	req.Header.Set("Content-Type", contentType)
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "too many spec files") {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCheckStubRejectsPreviousResultWithMultipleSpecs(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("csrf_token", "same")
+	writeMultipartFile(t, writer, "spec_file", "a.md", "A must work.")
+	writeMultipartFile(t, writer, "spec_file", "b.md", "B must work.")
+	writeMultipartFile(t, writer, "previous_result", "previous.json", `{"tool":"speccritic"}`)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/checks", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	addSessionCookies(req)
+	rec = submitCheck(t, server, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,374 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+)
+
+type jobState string
+
+const (
+	jobQueued   jobState = "queued"
+	jobRunning  jobState = "running"
+	jobDone     jobState = "done"
+	jobFailed   jobState = "failed"
+	jobCanceled jobState = "canceled"
+)
+
+// Job event names streamed to clients. A job ends with exactly one of
+// result, failed, or canceled.
+const (
+	eventState    = "state"
+	eventProgress = "progress"
+	eventResult   = "result"
+	eventFailed   = "failed"
+	eventCanceled = "canceled"
+)
+
+var (
+	errQueueFull   = errors.New("check queue is full")
+	errQueueClosed = errors.New("check queue is closed")
+)
+
+type jobEvent struct {
+	Name string
+	Data string
+}
+
+// job is one queued check submission. Its fields are guarded by the owning
+// queue's mutex.
+type job struct {
+	id         string
+	owner      string
+	api        bool
+	reqs       []app.CheckRequest
+	specs      []string
+	ctx        context.Context
+	cancel     context.CancelFunc
+	state      jobState
+	events     []jobEvent
+	changed    chan struct{}
+	progress   *progressEvent
+	checkIDs   []string
+	failure    *checkFailure
+	createdAt  time.Time
+	finishedAt time.Time
+}
+
+// jobSnapshot is a consistent copy of a job's status fields.
+type jobSnapshot struct {
+	ID         string
+	Owner      string
+	API        bool
+	State      jobState
+	Specs      []string
+	Progress   *progressEvent
+	CheckIDs   []string
+	Failure    *checkFailure
+	CreatedAt  time.Time
+	FinishedAt time.Time
+}
+
+func (j *job) finished() bool {
+	return j.state == jobDone || j.state == jobFailed || j.state == jobCanceled
+}
+
+// jobQueue runs check jobs on a fixed pool of workers. Submissions beyond
+// the pending limit are rejected rather than buffered without bound.
+// Finished jobs stay readable for ttl so late subscribers can replay them.
+type jobQueue struct {
+	mu          sync.Mutex
+	jobs        map[string]*job
+	pending     chan *job
+	run         func(context.Context, *job)
+	ttl         time.Duration
+	maxFinished int
+	now         func() time.Time
+	newID       func() (string, error)
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+func newJobQueue(workers, maxPending, maxFinished int, ttl time.Duration, run func(context.Context, *job)) *jobQueue {
+	q := &jobQueue{
+		jobs:        make(map[string]*job),
+		pending:     make(chan *job, maxPending),
+		run:         run,
+		ttl:         ttl,
+		maxFinished: maxFinished,
+		now:         time.Now,
+		newID:       randomID,
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+func (q *jobQueue) work() {
+	defer q.wg.Done()
+	for j := range q.pending {
+		q.mu.Lock()
+		if j.state != jobQueued {
+			q.mu.Unlock()
+			continue
+		}
+		j.state = jobRunning
+		q.publishLocked(j, eventState, string(jobRunning))
+		q.mu.Unlock()
+		q.run(j.ctx, j)
+	}
+}
+
+// enqueue registers a job for reqs and hands it to the workers. owner
+// identifies the browser session that submitted the job, or is empty for API
+// submissions; api marks jobs submitted through /api/v1.
+func (q *jobQueue) enqueue(owner string, api bool, reqs []app.CheckRequest) (*job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, errQueueClosed
+	}
+	q.pruneLocked()
+
+	var id string
+	for {
+		var err error
+		id, err = q.newID()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := q.jobs[id]; !exists {
+			break
+		}
+	}
+	specs := make([]string, len(reqs))
+	for i, req := range reqs {
+		specs[i] = req.SpecName
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		id:        id,
+		owner:     owner,
+		api:       api,
+		reqs:      reqs,
+		specs:     specs,
+		ctx:       ctx,
+		cancel:    cancel,
+		state:     jobQueued,
+		changed:   make(chan struct{}),
+		createdAt: q.now(),
+	}
+	select {
+	case q.pending <- j:
+	default:
+		cancel()
+		return nil, errQueueFull
+	}
+	q.jobs[id] = j
+	q.publishLocked(j, eventState, string(jobQueued))
+	return j, nil
+}
+
+func (q *jobQueue) get(id string) (*job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	return j, ok
+}
+
+// snapshot returns the status of one job.
+func (q *jobQueue) snapshot(id string) (jobSnapshot, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return jobSnapshot{}, false
+	}
+	return j.snapshotLocked(), true
+}
+
+// list returns the status of every retained job, newest first.
+func (q *jobQueue) list() []jobSnapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]jobSnapshot, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		out = append(out, j.snapshotLocked())
+	}
+	sort.Slice(out, func(i, k int) bool {
+		if !out[i].CreatedAt.Equal(out[k].CreatedAt) {
+			return out[i].CreatedAt.After(out[k].CreatedAt)
+		}
+		return out[i].ID < out[k].ID
+	})
+	return out
+}
+
+func (j *job) snapshotLocked() jobSnapshot {
+	return jobSnapshot{
+		ID:         j.id,
+		Owner:      j.owner,
+		API:        j.api,
+		State:      j.state,
+		Specs:      append([]string(nil), j.specs...),
+		Progress:   j.progress,
+		CheckIDs:   append([]string(nil), j.checkIDs...),
+		Failure:    j.failure,
+		CreatedAt:  j.createdAt,
+		FinishedAt: j.finishedAt,
+	}
+}
+
+// cancelJob stops a queued or running job. It reports whether the job exists
+// and whether it was still active.
+func (q *jobQueue) cancelJob(id string) (bool, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return false, false
+	}
+	if j.finished() {
+		return true, false
+	}
+	if j.state == jobQueued {
+		q.finishLocked(j, jobCanceled, eventCanceled, "Check canceled.")
+	}
+	j.cancel()
+	return true, true
+}
+
+// publish appends an event to a running job.
+func (q *jobQueue) publish(j *job, name, data string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.finished() {
+		return
+	}
+	q.publishLocked(j, name, data)
+}
+
+// reportProgress records a progress notification and publishes it.
+func (q *jobQueue) reportProgress(j *job, p progressEvent) {
+	data, _ := json.Marshal(p)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.finished() {
+		return
+	}
+	j.progress = &p
+	q.publishLocked(j, eventProgress, string(data))
+}
+
+// complete finishes a job with its stored checks and rendered result.
+func (q *jobQueue) complete(j *job, checkIDs []string, html string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.finished() {
+		return
+	}
+	j.checkIDs = checkIDs
+	q.finishLocked(j, jobDone, eventResult, html)
+}
+
+// fail finishes a job with a failure.
+func (q *jobQueue) fail(j *job, failure *checkFailure) {
+	data, _ := json.Marshal(failure)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.finished() {
+		return
+	}
+	j.failure = failure
+	q.finishLocked(j, jobFailed, eventFailed, string(data))
+}
+
+// finish records the job's terminal event unless it has already finished.
+func (q *jobQueue) finish(j *job, state jobState, name, data string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.finished() {
+		return
+	}
+	q.finishLocked(j, state, name, data)
+}
+
+// eventsSince returns the job's events from index from, a channel closed on
+// the next change, and whether the job has finished.
+func (q *jobQueue) eventsSince(j *job, from int) ([]jobEvent, <-chan struct{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var events []jobEvent
+	if from < len(j.events) {
+		events = append(events, j.events[from:]...)
+	}
+	return events, j.changed, j.finished()
+}
+
+// close stops accepting jobs, cancels queued and running jobs, and waits for
+// the workers to exit.
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	for _, j := range q.jobs {
+		if j.state == jobQueued {
+			q.finishLocked(j, jobCanceled, eventCanceled, "Server is shutting down.")
+		}
+		j.cancel()
+	}
+	close(q.pending)
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *jobQueue) publishLocked(j *job, name, data string) {
+	j.events = append(j.events, jobEvent{Name: name, Data: data})
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (q *jobQueue) finishLocked(j *job, state jobState, name, data string) {
+	j.state = state
+	j.finishedAt = q.now()
+	q.publishLocked(j, name, data)
+	j.reqs = nil
+}
+
+// pruneLocked drops finished jobs past their TTL, then the oldest finished
+// jobs beyond maxFinished.
+func (q *jobQueue) pruneLocked() {
+	now := q.now()
+	var finished []*job
+	for id, j := range q.jobs {
+		if !j.finished() {
+			continue
+		}
+		if !now.Before(j.finishedAt.Add(q.ttl)) {
+			delete(q.jobs, id)
+			continue
+		}
+		finished = append(finished, j)
+	}
+	for len(finished) > q.maxFinished {
+		oldest := 0
+		for i := range finished {
+			if finished[i].finishedAt.Before(finished[oldest].finishedAt) {
+				oldest = i
+			}
+		}
+		delete(q.jobs, finished[oldest].id)
+		finished = append(finished[:oldest], finished[oldest+1:]...)
+	}
+}
@@ -0,0 +1,343 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+)
+
+type streamedEvent struct {
+	ID   int
+	Name string
+	Data string
+}
+
+// submitCheck posts a check and, when it is queued, follows the job's event
+// stream to completion. The returned recorder holds the rendered result, or
+// the failure status and message, as a synchronous handler would.
+func submitCheck(t *testing.T, server *Server, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		return rec
+	}
+	events := readJobEvents(t, server, rec.Header().Get("Location"), "")
+	last := events[len(events)-1]
+	out := httptest.NewRecorder()
+	switch last.Name {
+	case eventResult:
+		out.WriteHeader(http.StatusOK)
+		_, _ = out.WriteString(last.Data)
+	case eventFailed:
+		var failure checkFailure
+		if err := json.Unmarshal([]byte(last.Data), &failure); err != nil {
+			t.Fatalf("decode failure event: %v", err)
+		}
+		out.WriteHeader(failure.Status)
+		_, _ = out.WriteString(failure.Message)
+	default:
+		t.Fatalf("job ended with %q event: %s", last.Name, last.Data)
+	}
+	return out
+}
+
+func readJobEvents(t *testing.T, server *Server, path, lastEventID string) []streamedEvent {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	addSessionCookies(req)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("events status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("events content type = %q", got)
+	}
+	return parseEventStream(t, rec.Body.String())
+}
+
+func parseEventStream(t *testing.T, body string) []streamedEvent {
+	t.Helper()
+	var events []streamedEvent
+	for _, block := range strings.Split(body, "\n\n") {
+		var ev streamedEvent
+		var data []string
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id, err := strconv.Atoi(strings.TrimPrefix(line, "id: "))
+				if err != nil {
+					t.Fatalf("event id %q: %v", line, err)
+				}
+				ev.ID = id
+			case strings.HasPrefix(line, "event: "):
+				ev.Name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = append(data, strings.TrimPrefix(line, "data: "))
+			}
+		}
+		if ev.Name == "" {
+			continue
+		}
+		ev.Data = strings.Join(data, "\n")
+		events = append(events, ev)
+	}
+	if len(events) == 0 {
+		t.Fatalf("event stream has no events: %q", body)
+	}
+	return events
+}
+
+type progressChecker struct {
+	fakeChecker
+}
+
+func (c *progressChecker) Check(ctx context.Context, req app.CheckRequest) (*app.CheckResult, error) {
+	req.Progress(app.Progress{Stage: app.ProgressPreflight})
+	req.Progress(app.Progress{Stage: app.ProgressChunk, Done: 1, Total: 2})
+	req.Progress(app.Progress{Stage: app.ProgressChunk, Done: 2, Total: 2})
+	req.Progress(app.Progress{Stage: app.ProgressSynthesis})
+	return c.fakeChecker.Check(ctx, req)
+}
+
+func TestCheckJobStreamsProgressAndResult(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &progressChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	body, contentType := multipartSpecRequest(t, "The system must work.")
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/checks", body)
+	req.Header.Set("Content-Type", contentType)
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	location := rec.Header().Get("Location")
+	if !strings.Contains(rec.Body.String(), `data-job-events="`+location+`"`) {
+		t.Fatalf("job placeholder missing events URL %q: %s", location, rec.Body.String())
+	}
+
+	events := readJobEvents(t, server, location, "")
+	var names []string
+	for _, ev := range events {
+		names = append(names, ev.Name)
+	}
+	want := "state,state,progress,progress,progress,progress,result"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+	if events[0].Data != "queued" || events[1].Data != "running" {
+		t.Fatalf("state events = %q, %q", events[0].Data, events[1].Data)
+	}
+	var chunk progressEvent
+	if err := json.Unmarshal([]byte(events[3].Data), &chunk); err != nil {
+		t.Fatalf("decode progress: %v", err)
+	}
+	if chunk.Spec != "SPEC.md" || chunk.Stage != app.ProgressChunk || chunk.Done != 1 || chunk.Total != 2 {
+		t.Fatalf("chunk progress = %+v", chunk)
+	}
+	result := events[len(events)-1]
+	if !strings.Contains(result.Data, "ISSUE-0001") || !strings.Contains(result.Data, "INVALID") {
+		t.Fatalf("result event missing rendered result: %s", result.Data)
+	}
+
+	resumed := readJobEvents(t, server, location, strconv.Itoa(result.ID-1))
+	if len(resumed) != 1 || resumed[0].Name != eventResult || resumed[0].ID != result.ID {
+		t.Fatalf("resumed events = %+v", resumed)
+	}
+}
+
+func TestCheckJobFailureIsSanitized(t *testing.T) {
+	checker := &fakeChecker{err: &app.Error{Kind: app.ErrorProvider, Err: errors.New("secret provider detail")}}
+	server, err := NewServerWithChecker(DefaultConfig(), checker)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	body, contentType := multipartSpecRequest(t, "The system must work.")
+	req := httptest.NewRequest(http.MethodPost, "/checks", body)
+	req.Header.Set("Content-Type", contentType)
+	addSessionCookies(req)
+	rec := submitCheck(t, server, req)
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "secret provider detail") {
+		t.Fatalf("failure leaked provider error: %s", rec.Body.String())
+	}
+}
+
+type blockingChecker struct {
+	started chan string
+}
+
+func (c *blockingChecker) Check(ctx context.Context, req app.CheckRequest) (*app.CheckResult, error) {
+	c.started <- req.SpecText
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCheckQueueLimitAndCancel(t *testing.T) {
+	config := DefaultConfig()
+	config.CheckWorkers = 1
+	config.MaxQueuedChecks = 1
+	checker := &blockingChecker{started: make(chan string, 1)}
+	server, err := NewServerWithChecker(config, checker)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	post := func(specText string) *httptest.ResponseRecorder {
+		body, contentType := multipartSpecRequest(t, specText)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/checks", body)
+		req.Header.Set("Content-Type", contentType)
+		addSessionCookies(req)
+		server.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	cancel := func(location string) int {
+		path := strings.TrimSuffix(location, "/events") + "/cancel"
+		form := url.Values{"csrf_token": {"same"}}
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addSessionCookies(req)
+		server.Handler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	running := post("first")
+	if running.Code != http.StatusAccepted {
+		t.Fatalf("first status = %d", running.Code)
+	}
+	select {
+	case <-checker.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("first check did not start")
+	}
+	queued := post("second")
+	if queued.Code != http.StatusAccepted {
+		t.Fatalf("second status = %d", queued.Code)
+	}
+	rejected := post("third")
+	if rejected.Code != http.StatusTooManyRequests || rejected.Header().Get("Retry-After") == "" {
+		t.Fatalf("third status = %d retry-after %q", rejected.Code, rejected.Header().Get("Retry-After"))
+	}
+
+	for _, rec := range []*httptest.ResponseRecorder{queued, running} {
+		location := rec.Header().Get("Location")
+		if code := cancel(location); code != http.StatusAccepted {
+			t.Fatalf("cancel %s status = %d", location, code)
+		}
+		events := readJobEvents(t, server, location, "")
+		if last := events[len(events)-1]; last.Name != eventCanceled {
+			t.Fatalf("%s ended with %q", location, last.Name)
+		}
+		if code := cancel(location); code != http.StatusConflict {
+			t.Fatalf("second cancel %s status = %d, want 409", location, code)
+		}
+	}
+	if code := cancel("/checks/ffff/events"); code != http.StatusNotFound {
+		t.Fatalf("cancel unknown status = %d, want 404", code)
+	}
+	select {
+	case spec := <-checker.started:
+		t.Fatalf("canceled queued check %q ran", spec)
+	default:
+	}
+}
+
+func TestCheckEventsRequireSession(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/checks/abc/events", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/checks/abc/events", nil)
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+
+	other, err := server.jobs.enqueue("other", false, nil)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	api, err := server.jobs.enqueue("", true, nil)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	for _, id := range []string{other.id, api.id} {
+		rec = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/checks/"+id+"/events", nil)
+		addSessionCookies(req)
+		server.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("events of job %s status = %d, want 404", id, rec.Code)
+		}
+	}
+}
+
+func TestJobQueuePrunesFinishedJobs(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := newJobQueue(1, 4, 1, time.Minute, func(context.Context, *job) {})
+	t.Cleanup(q.close)
+	q.now = func() time.Time { return now }
+
+	first, err := q.enqueue("", false, nil)
+	if err != nil {
+		t.Fatalf("enqueue first: %v", err)
+	}
+	q.finish(first, jobDone, eventResult, "")
+	now = now.Add(time.Second)
+	second, err := q.enqueue("", false, nil)
+	if err != nil {
+		t.Fatalf("enqueue second: %v", err)
+	}
+	q.finish(second, jobDone, eventResult, "")
+	if _, err := q.enqueue("", false, nil); err != nil {
+		t.Fatalf("enqueue third: %v", err)
+	}
+	if _, ok := q.get(first.id); ok {
+		t.Fatal("oldest finished job should have been pruned")
+	}
+	if _, ok := q.get(second.id); !ok {
+		t.Fatal("newest finished job missing")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := q.enqueue("", false, nil); err != nil {
+		t.Fatalf("enqueue fourth: %v", err)
+	}
+	if _, ok := q.get(second.id); ok {
+		t.Fatal("expired job should have been pruned")
+	}
+}
@@ -0,0 +1,274 @@
+package web
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  map[string]any
+)
+
+// openAPIDocument describes the /api/v1 endpoints. Component schemas are
+// generated from the Go types the handlers encode and decode, so the
+// document follows the wire format.
+func openAPIDocument() map[string]any {
+	openAPIOnce.Do(func() {
+		openAPIDoc = buildOpenAPIDocument()
+	})
+	return openAPIDoc
+}
+
+func buildOpenAPIDocument() map[string]any {
+	g := newSchemaGenerator()
+	checkRef := g.schemaFor(reflect.TypeOf(APICheck{}))
+	listRef := g.schemaFor(reflect.TypeOf(APICheckList{}))
+	requestRef := g.schemaFor(reflect.TypeOf(APICheckRequest{}))
+	reportRef := g.schemaFor(reflect.TypeOf(schema.Report{}))
+	errorRef := g.schemaFor(reflect.TypeOf(APIError{}))
+
+	jsonContent := func(ref map[string]any) map[string]any {
+		return map[string]any{"application/json": map[string]any{"schema": ref}}
+	}
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{"description": description, "content": jsonContent(errorRef)}
+	}
+	idParam := []any{map[string]any{
+		"name":     "id",
+		"in":       "path",
+		"required": true,
+		"schema":   map[string]any{"type": "string"},
+	}}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "SpecCritic API",
+			"version":     "v1",
+			"description": "Submit specs for review and fetch the resulting reports. Checks run asynchronously: create a check, then poll its status or stream its events until it finishes.",
+		},
+		"security": []any{map[string]any{"bearerAuth": []any{}}},
+		"paths": map[string]any{
+			apiPrefix + "/checks": map[string]any{
+				"get": map[string]any{
+					"operationId": "listChecks",
+					"summary":     "List retained checks submitted through the API, newest first.",
+					"responses": map[string]any{
+						"200": map[string]any{"description": "Checks.", "content": jsonContent(listRef)},
+						"401": errorResponse("Missing or invalid API token."),
+					},
+				},
+				"post": map[string]any{
+					"operationId": "createCheck",
+					"summary":     "Queue a check of one spec.",
+					"requestBody": map[string]any{
+						"required": true,
+						"content": map[string]any{
+							"application/json":    map[string]any{"schema": requestRef},
+							"multipart/form-data": map[string]any{"schema": multipartCheckSchema()},
+						},
+					},
+					"responses": map[string]any{
+						"202": map[string]any{"description": "Check queued.", "content": jsonContent(checkRef)},
+						"400": errorResponse("Invalid submission."),
+						"401": errorResponse("Missing or invalid API token."),
+						"429": errorResponse("Too many checks are waiting; retry after the Retry-After delay."),
+					},
+				},
+			},
+			apiPrefix + "/checks/{id}": map[string]any{
+				"parameters": idParam,
+				"get": map[string]any{
+					"operationId": "getCheck",
+					"summary":     "Get a check's status.",
+					"description": "The id is the check's id or, once it has finished, its check_id, which stays valid while the result is retained.",
+					"responses": map[string]any{
+						"200": map[string]any{"description": "Check status.", "content": jsonContent(checkRef)},
+						"401": errorResponse("Missing or invalid API token."),
+						"404": errorResponse("Unknown or expired check."),
+					},
+				},
+			},
+			apiPrefix + "/checks/{id}/events": map[string]any{
+				"parameters": idParam,
+				"get": map[string]any{
+					"operationId": "streamCheckEvents",
+					"summary":     "Stream a check's progress as server-sent events.",
+					"description": "Events are state, progress, and finally one of result, failed, or canceled. Send Last-Event-ID to resume.",
+					"responses": map[string]any{
+						"200": map[string]any{"description": "Event stream.", "content": map[string]any{"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}}}},
+						"401": errorResponse("Missing or invalid API token."),
+						"404": map[string]any{"description": "Unknown or expired check."},
+					},
+				},
+			},
+			apiPrefix + "/checks/{id}/report": map[string]any{
+				"parameters": idParam,
+				"get": map[string]any{
+					"operationId": "getCheckReport",
+					"summary":     "Get a finished check's report.",
+					"responses": map[string]any{
+						"200": map[string]any{"description": "Report.", "content": jsonContent(reportRef)},
+						"401": errorResponse("Missing or invalid API token."),
+						"404": errorResponse("Unknown check or expired result."),
+						"409": errorResponse("The check has not finished with a report."),
+					},
+				},
+			},
+			apiPrefix + "/checks/{id}/patch": map[string]any{
+				"parameters": idParam,
+				"get": map[string]any{
+					"operationId": "getCheckPatch",
+					"summary":     "Get a finished check's patch as a unified diff.",
+					"responses": map[string]any{
+						"200": map[string]any{"description": "Patch.", "content": map[string]any{"text/x-diff": map[string]any{"schema": map[string]any{"type": "string"}}}},
+						"401": errorResponse("Missing or invalid API token."),
+						"404": errorResponse("Unknown check, expired result, or no patch."),
+						"409": errorResponse("The check has not finished with a report."),
+					},
+				},
+			},
+		},
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// multipartCheckSchema describes the browser form fields accepted by
+// createCheck.
+func multipartCheckSchema() map[string]any {
+	props := map[string]any{
+		"spec_file":             map[string]any{"type": "string", "format": "binary"},
+		"previous_result":       map[string]any{"type": "string", "format": "binary"},
+		"incremental_base_file": map[string]any{"type": "string", "format": "binary"},
+	}
+	for _, field := range []string{
+		"profile", "severity_threshold", "strict", "llm_provider", "llm_model",
+		"temperature", "max_tokens", "preflight", "preflight_mode",
+		"incremental_mode", "convergence_mode", "completion_suggestions",
+		"completion_mode", "completion_template", "completion_max_patches",
+	} {
+		props[field] = map[string]any{"type": "string"}
+	}
+	return map[string]any{
+		"type":       "object",
+		"required":   []string{"spec_file"},
+		"properties": props,
+	}
+}
+
+type schemaGenerator struct {
+	schemas map[string]any
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{schemas: make(map[string]any)}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaFor returns the JSON schema of t. Named structs are registered as
+// components and referenced.
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := schemaName(t)
+		ref := map[string]any{"$ref": "#/components/schemas/" + name}
+		if _, ok := g.schemas[name]; ok {
+			return ref
+		}
+		// Register before walking fields so recursive types terminate.
+		g.schemas[name] = map[string]any{}
+		g.schemas[name] = g.structSchema(t)
+		return ref
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.addFields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+func (g *schemaGenerator) addFields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, props, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		props[name] = g.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func schemaName(t reflect.Type) string {
+	name := []rune(t.Name())
+	if len(name) == 0 {
+		return "Object"
+	}
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
@@ -0,0 +1,236 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/patch"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+// patchView is one patch offered in the result's patch review panel.
+type patchView struct {
+	Index      int
+	Patch      schema.Patch
+	Completion bool
+	// Conflicts lists the issue IDs of other patches that edit overlapping
+	// text; at most one of them can be applied.
+	Conflicts []string
+}
+
+// locatePatches locates each patch whose before text occurs exactly once in
+// spec. Patches that match nowhere, match more than once or touch redacted
+// text are counted as skipped, since applying them could edit the wrong
+// occurrence.
+func locatePatches(spec string, patches []schema.Patch) ([]patch.Edit, int) {
+	var edits []patch.Edit
+	skipped := 0
+	for i, p := range patches {
+		if patch.Redacted(p) {
+			skipped++
+			continue
+		}
+		located, err := patch.LocateUnique(spec, []schema.Patch{p})
+		if err != nil {
+			skipped++
+			continue
+		}
+		e := located[0]
+		e.Index = i
+		edits = append(edits, e)
+	}
+	return edits, skipped
+}
+
+// buildPatchViews locates the report's patches in the original spec. Patches
+// that cannot be located uniquely or that touch redacted text are counted as
+// skipped and never offered.
+func buildPatchViews(result *app.CheckResult) ([]patchView, int) {
+	if result == nil || result.Report == nil || len(result.Report.Patches) == 0 {
+		return nil, 0
+	}
+	edits, skipped := locatePatches(result.OriginalSpec, result.Report.Patches)
+	completion := make(map[string]bool)
+	for _, issue := range result.Report.Issues {
+		for _, tag := range issue.Tags {
+			if tag == "completion-suggested" {
+				completion[issue.ID] = true
+			}
+		}
+	}
+	views := make([]patchView, len(edits))
+	for i, e := range edits {
+		views[i] = patchView{Index: e.Index, Patch: e.Patch, Completion: completion[e.Patch.IssueID]}
+		for k, other := range edits {
+			if k != i && e.Overlaps(other) {
+				views[i].Conflicts = append(views[i].Conflicts, other.Patch.IssueID)
+			}
+		}
+	}
+	return views, skipped
+}
+
+// handlePatchedSpec downloads the spec with the selected patches applied.
+func (s *Server) handlePatchedSpec(w http.ResponseWriter, r *http.Request) {
+	check, patched, ok := s.applySelectedPatches(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", contentDisposition(patchedSpecName(check.Result.Report.Input.SpecFile)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(patched))
+}
+
+// handleRecheck queues a check of the spec with the selected patches applied,
+// using the stored check's options. The stored report is the previous result,
+// so the new check tracks convergence and reviews only the changed sections.
+func (s *Server) handleRecheck(w http.ResponseWriter, r *http.Request) {
+	check, patched, ok := s.applySelectedPatches(w, r)
+	if !ok {
+		return
+	}
+	previous, err := json.Marshal(check.Result.Report)
+	if err != nil {
+		log.Printf("encode previous report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	name := check.Result.Report.Input.SpecFile
+	if name == "" {
+		name = "spec.md"
+	}
+	reqs, err := s.buildCheckRequests(checkInput{
+		specs:           []uploadedSpec{{name: name, text: patched}},
+		previousReport:  string(previous),
+		incrementalBase: check.Result.OriginalSpec,
+		values:          recheckValues(check.Result.Report),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	j, err := s.jobs.enqueue(sessionID(r), false, reqs)
+	if errors.Is(err, errQueueFull) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "Too many checks are waiting. Try again shortly.", http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		log.Printf("queue check: %v", err)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "partial_job.html", jobView{ID: j.id, Specs: 1}); err != nil {
+		log.Printf("render job: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Location", "/checks/"+j.id+"/events")
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// applySelectedPatches validates a patch selection form and applies the
+// selected patches to the check's original spec. It writes the error
+// response itself and reports false when the request cannot be served.
+func (s *Server) applySelectedPatches(w http.ResponseWriter, r *http.Request) (*StoredCheck, string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, multipartOverheadLimit)
+	if err := s.parseRequestForm(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if !s.validNonce(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, "", false
+	}
+	check, ok := s.store.Get(r.PathValue("id"))
+	if !ok || check.Result == nil || check.Result.Report == nil {
+		http.NotFound(w, r)
+		return nil, "", false
+	}
+	edits, _ := locatePatches(check.Result.OriginalSpec, check.Result.Report.Patches)
+	byIndex := make(map[int]patch.Edit, len(edits))
+	for _, e := range edits {
+		byIndex[e.Index] = e
+	}
+	var selected []patch.Edit
+	seen := make(map[int]bool)
+	for _, raw := range r.PostForm["patch"] {
+		index, err := strconv.Atoi(raw)
+		e, ok := byIndex[index]
+		if err != nil || !ok {
+			http.Error(w, fmt.Sprintf("patch %q cannot be applied", raw), http.StatusBadRequest)
+			return nil, "", false
+		}
+		if !seen[index] {
+			seen[index] = true
+			selected = append(selected, e)
+		}
+	}
+	if len(selected) == 0 {
+		http.Error(w, "select at least one patch", http.StatusBadRequest)
+		return nil, "", false
+	}
+	patched, err := patch.Apply(check.Result.OriginalSpec, selected)
+	if err != nil {
+		var conflict *patch.ConflictError
+		if errors.As(err, &conflict) {
+			http.Error(w, conflict.Error()+"; select only one of them", http.StatusConflict)
+			return nil, "", false
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	return check, patched, true
+}
+
+// recheckValues reproduces the form options of the check that produced
+// report.
+func recheckValues(report *schema.Report) url.Values {
+	values := url.Values{
+		"profile":            {report.Input.Profile},
+		"severity_threshold": {report.Input.SeverityThreshold},
+		"temperature":        {strconv.FormatFloat(report.Meta.Temperature, 'f', -1, 64)},
+	}
+	if report.Input.Strict {
+		values.Set("strict", "true")
+	}
+	if report.Meta.Model == "preflight" {
+		values.Set("preflight_mode", "only")
+	} else if provider, model, ok := strings.Cut(report.Meta.Model, ":"); ok {
+		values.Set("llm_provider", provider)
+		values.Set("llm_model", model)
+	}
+	if c := report.Meta.Completion; c != nil && c.Enabled {
+		values.Set("completion_suggestions", "true")
+		values.Set("completion_mode", c.Mode)
+		values.Set("completion_template", c.Template)
+	}
+	return values
+}
+
+// patchedSpecName names the download of a patched spec after the original,
+// e.g. SPEC.md becomes SPEC.patched.md.
+func patchedSpecName(specFile string) string {
+	base := filepath.Base(specFile)
+	if specFile == "" || base == "." || base == "/" || base == "-" {
+		return "spec.patched.md"
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + ".patched" + ext
+}
@@ -0,0 +1,153 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/schema"
+)
+
+const patchSpec = "# Spec\nLatency must be fast.\nRetries are unlimited.\n"
+
+func savePatchCheck(t *testing.T, server *Server, patches ...schema.Patch) *StoredCheck {
+	t.Helper()
+	stored, err := server.store.Save(&app.CheckResult{
+		OriginalSpec: patchSpec,
+		Report: &schema.Report{
+			Tool:    "speccritic",
+			Input:   schema.Input{SpecFile: "docs/SPEC.md", Profile: "general", SeverityThreshold: "info"},
+			Summary: schema.Summary{Verdict: schema.VerdictValidWithGaps, Score: 70},
+			Issues: []schema.Issue{
+				compareIssue("ISSUE-0001", "Vague latency target", 2, "Latency must be fast."),
+				compareIssue("ISSUE-0002", "Unbounded retries", 3, "Retries are unlimited."),
+			},
+			Patches: patches,
+			Meta:    schema.Meta{Model: "openai:gpt-5", Temperature: 0.2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("save check: %v", err)
+	}
+	return stored
+}
+
+func postPatchForm(server *Server, path string, form url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResultOffersApplicablePatches(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	stored := savePatchCheck(t, server,
+		schema.Patch{IssueID: "ISSUE-0001", Before: "must be fast", After: "must be under 200 ms"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "Latency must", After: "Latency should"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "api_key=sk-abcdefghijklmnopqrstuvwx", After: "api_key=[REDACTED]"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "not in the spec", After: "x"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "st", After: "sT"},
+	)
+	view, err := server.resultView(stored)
+	if err != nil {
+		t.Fatalf("resultView: %v", err)
+	}
+	view.Nonce = "same"
+	var buf strings.Builder
+	if err := server.templates.ExecuteTemplate(&buf, "partial_result.html", view); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	body := buf.String()
+	for _, want := range []string{
+		`action="/checks/` + stored.ID + `/patched-spec"`,
+		`hx-post="/checks/` + stored.ID + `/recheck"`,
+		`<input type="hidden" name="csrf_token" value="same">`,
+		`<input type="checkbox" name="patch" value="0">`,
+		`<input type="checkbox" name="patch" value="1">`,
+		"Overlaps the patch for ISSUE-0002; accept only one.",
+		"3 patches could not be located exactly once in the spec or touched redacted text",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("result missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `value="2"`) || strings.Contains(body, `value="4"`) || strings.Contains(body, "sk-abcdefghijklmnopqrstuvwx") {
+		t.Fatalf("redaction-affected patch offered:\n%s", body)
+	}
+}
+
+func TestPatchedSpecDownloadAppliesSelection(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	stored := savePatchCheck(t, server,
+		schema.Patch{IssueID: "ISSUE-0001", Before: "must be fast", After: "must be under 200 ms"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "Latency must", After: "Latency should"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "are unlimited", After: "are limited to 3"},
+		schema.Patch{IssueID: "ISSUE-0002", Before: "st", After: "sT"},
+	)
+	path := "/checks/" + stored.ID + "/patched-spec"
+
+	rec := postPatchForm(server, path, url.Values{"csrf_token": {"same"}, "patch": {"0", "2"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, "SPEC.patched.md") {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+	if want := "# Spec\nLatency must be under 200 ms.\nRetries are limited to 3.\n"; rec.Body.String() != want {
+		t.Fatalf("patched spec = %q, want %q", rec.Body.String(), want)
+	}
+
+	for name, tc := range map[string]struct {
+		form url.Values
+		want int
+	}{
+		"nonce":     {url.Values{"csrf_token": {"wrong"}, "patch": {"0"}}, http.StatusForbidden},
+		"empty":     {url.Values{"csrf_token": {"same"}}, http.StatusBadRequest},
+		"unknown":   {url.Values{"csrf_token": {"same"}, "patch": {"9"}}, http.StatusBadRequest},
+		"ambiguous": {url.Values{"csrf_token": {"same"}, "patch": {"3"}}, http.StatusBadRequest},
+		"conflict":  {url.Values{"csrf_token": {"same"}, "patch": {"0", "1"}}, http.StatusConflict},
+	} {
+		if rec := postPatchForm(server, path, tc.form); rec.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d: %s", name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+}
+
+func TestRecheckQueuesPatchedSpecWithPreviousResult(t *testing.T) {
+	checker := &fakeChecker{}
+	server, err := NewServerWithChecker(DefaultConfig(), checker)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	stored := savePatchCheck(t, server, schema.Patch{IssueID: "ISSUE-0001", Before: "must be fast", After: "must be under 200 ms"})
+
+	form := url.Values{"csrf_token": {"same"}, "patch": {"0"}}
+	req := httptest.NewRequest(http.MethodPost, "/checks/"+stored.ID+"/recheck", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addSessionCookies(req)
+	rec := submitCheck(t, server, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	got := checker.req
+	if got.SpecName != "docs/SPEC.md" || !strings.Contains(got.SpecText, "must be under 200 ms") || got.IncrementalBaseText != patchSpec {
+		t.Fatalf("request spec = %q %q base %q", got.SpecName, got.SpecText, got.IncrementalBaseText)
+	}
+	if got.ConvergenceFromText == "" || got.LLMProvider != "openai" || got.LLMModel != "gpt-5" || got.Profile != "general" {
+		t.Fatalf("request options = %+v", got)
+	}
+}
@@ -7,11 +7,21 @@ func (s *Server) routes() http.Handler {
 	mux.HandleFunc("GET /", s.handleIndex)
 	mux.HandleFunc("GET /models", s.handleModels)
 	mux.HandleFunc("POST /checks", s.handleCheckStub)
+	mux.HandleFunc("GET /checks/{id}/events", s.handleCheckEvents)
+	mux.HandleFunc("POST /checks/{id}/cancel", s.handleCheckCancel)
 	mux.HandleFunc("GET /checks/{id}/issues/{finding_id}", s.handleIssueDetail)
+	mux.HandleFunc("POST /checks/{id}/issues/{finding_id}/triage", s.handleTriage)
+	mux.HandleFunc("GET /checks/{id}/triage.json", s.handleExportTriage)
 	mux.HandleFunc("GET /checks/{id}/export.json", s.handleExportJSON)
 	mux.HandleFunc("GET /checks/{id}/export.md", s.handleExportMarkdown)
 	mux.HandleFunc("GET /checks/{id}/patch.diff", s.handleExportPatch)
+	mux.HandleFunc("POST /checks/{id}/patched-spec", s.handlePatchedSpec)
+	mux.HandleFunc("POST /checks/{id}/recheck", s.handleRecheck)
+	mux.HandleFunc("GET /compare", s.handleCompare)
 	mux.Handle("GET /assets/", http.FileServer(http.FS(content)))
+	if len(s.config.APITokens) > 0 {
+		s.apiRoutes(mux)
+	}
 	return securityHeaders(mux)
 }
 
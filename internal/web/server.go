@@ -15,7 +15,9 @@ var content embed.FS
 type Server struct {
 	config    Config
 	checker   checker
-	store     *Store
+	store     CheckStore
+	jobs      *jobQueue
+	uploads   *uploadHistory
 	templates *template.Template
 	handler   http.Handler
 }
@@ -28,6 +30,12 @@ type checker interface {
 	Check(rctx context.Context, req app.CheckRequest) (*app.CheckResult, error)
 }
 
+// batchChecker is implemented by checkers that can share provider state
+// across a multi-spec upload, such as *app.Checker.
+type batchChecker interface {
+	CheckBatch(ctx context.Context, reqs []app.CheckRequest, cfg app.BatchConfig) []app.BatchItem
+}
+
 func NewServerWithChecker(config Config, c checker) (*Server, error) {
 	config = withDefaults(config)
 	if err := config.Validate(); err != nil {
@@ -35,11 +43,12 @@ func NewServerWithChecker(config Config, c checker) (*Server, error) {
 	}
 	tmpl, err := template.New("").Funcs(template.FuncMap{
 		"isPreflight": isPreflightTags,
+		"statusLabel": statusLabel,
 	}).ParseFS(content, "templates/*.html")
 	if err != nil {
 		return nil, err
 	}
-	store, err := NewStore(config.MaxRetainedChecks, config.RetainedCheckTTL)
+	store, err := newCheckStore(config)
 	if err != nil {
 		return nil, err
 	}
@@ -47,12 +56,28 @@ func NewServerWithChecker(config Config, c checker) (*Server, error) {
 		config:    config,
 		checker:   c,
 		store:     store,
+		uploads:   newUploadHistory(config.MaxRetainedChecks),
 		templates: tmpl,
 	}
+	s.jobs = newJobQueue(config.CheckWorkers, config.MaxQueuedChecks, config.MaxRetainedChecks, config.RetainedCheckTTL, s.runCheckJob)
 	s.handler = s.routes()
 	return s, nil
 }
 
+func newCheckStore(config Config) (CheckStore, error) {
+	if config.StoreDir != "" {
+		store, err := NewFileStore(config.StoreDir, config.MaxRetainedChecks, config.RetainedCheckTTL)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.RecordRetention(); err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return NewStore(config.MaxRetainedChecks, config.RetainedCheckTTL)
+}
+
 func withDefaults(config Config) Config {
 	defaults := DefaultConfig()
 	if config.Addr == "" {
@@ -64,15 +89,30 @@ func withDefaults(config Config) Config {
 	if config.MaxUploadBytes == 0 {
 		config.MaxUploadBytes = defaults.MaxUploadBytes
 	}
+	if config.MaxBatchFiles == 0 {
+		config.MaxBatchFiles = defaults.MaxBatchFiles
+	}
 	if config.MaxRetainedChecks == 0 {
 		config.MaxRetainedChecks = defaults.MaxRetainedChecks
 	}
 	if config.RetainedCheckTTL == 0 {
 		config.RetainedCheckTTL = defaults.RetainedCheckTTL
 	}
+	if config.CheckWorkers == 0 {
+		config.CheckWorkers = defaults.CheckWorkers
+	}
+	if config.MaxQueuedChecks == 0 {
+		config.MaxQueuedChecks = defaults.MaxQueuedChecks
+	}
 	return config
 }
 
 func (s *Server) Handler() http.Handler {
 	return s.handler
 }
+
+// Close cancels queued and running checks and waits for the check workers
+// to exit.
+func (s *Server) Close() {
+	s.jobs.close()
+}
@@ -4,13 +4,30 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/triage"
 )
 
+// CheckStore retains completed checks so result pages and shared links can be
+// reopened. Store keeps them in memory; FileStore persists them on disk.
+type CheckStore interface {
+	Save(result *app.CheckResult) (*StoredCheck, error)
+	Get(id string) (*StoredCheck, bool)
+	// UpdateTriage replaces a check's triage decisions with the result of
+	// update, applied atomically to the current decisions.
+	UpdateTriage(id string, update func([]triage.Entry) []triage.Entry) (*StoredCheck, error)
+	SweepExpired()
+}
+
+// ErrCheckNotFound is returned for unknown or expired checks.
+var ErrCheckNotFound = errors.New("check not found")
+
+// Store is the in-memory CheckStore. Checks are lost when the server stops.
 type Store struct {
 	mu     sync.RWMutex
 	max    int
@@ -26,6 +43,8 @@ type StoredCheck struct {
 	CreatedAt time.Time
 	ExpiresAt time.Time
 	Result    *app.CheckResult
+	// Triage holds the reviewers' decisions on the check's findings.
+	Triage []triage.Entry
 }
 
 func NewStore(max int, ttl time.Duration) (*Store, error) {
@@ -78,6 +97,22 @@ func (s *Store) Get(id string) (*StoredCheck, bool) {
 	return check, ok
 }
 
+func (s *Store) UpdateTriage(id string, update func([]triage.Entry) []triage.Entry) (*StoredCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	check, ok := s.checks[id]
+	if !ok || !s.now().Before(check.ExpiresAt) {
+		return nil, ErrCheckNotFound
+	}
+	// Replace rather than mutate, so readers holding the old check never
+	// see a partial update.
+	next := *check
+	next.Triage = update(append([]triage.Entry(nil), check.Triage...))
+	s.checks[id] = &next
+	return &next, nil
+}
+
 func (s *Store) SweepExpired() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -2,10 +2,12 @@ package web
 
 import (
 	"context"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/schema"
 )
 
 func TestStoreSaveGetAndEvict(t *testing.T) {
@@ -85,3 +87,120 @@ func TestStoreJanitorStops(t *testing.T) {
 	store.StartJanitor(ctx, time.Millisecond)
 	cancel()
 }
+
+func TestFileStorePersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	saved, err := store.Save(&app.CheckResult{
+		Report:       &schema.Report{Tool: "speccritic", Summary: schema.Summary{Score: 88}},
+		PatchDiff:    "@@ patch",
+		OriginalSpec: "# Spec\n",
+		LineCount:    1,
+		Model:        "fake:model",
+	})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reopened, err := NewFileStore(dir, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore reopen: %v", err)
+	}
+	got, ok := reopened.Get(saved.ID)
+	if !ok {
+		t.Fatal("check missing after reopen")
+	}
+	if got.Result.Report.Summary.Score != 88 || got.Result.OriginalSpec != "# Spec\n" || got.Result.Model != "fake:model" || got.Result.PatchDiff != "@@ patch" {
+		t.Fatalf("result = %#v", got.Result)
+	}
+	if _, ok := reopened.Get("../" + saved.ID); ok {
+		t.Fatal("path-like id should be rejected")
+	}
+}
+
+func TestFileStoreExpiresEvictsAndPurges(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, 2, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	store.now = func() time.Time { return now }
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		check, err := store.Save(&app.CheckResult{})
+		if err != nil {
+			t.Fatalf("Save %d: %v", i, err)
+		}
+		ids = append(ids, check.ID)
+		now = now.Add(time.Minute)
+	}
+	if _, ok := store.Get(ids[0]); ok {
+		t.Fatal("oldest check should have been evicted")
+	}
+	if _, ok := store.Get(ids[2]); !ok {
+		t.Fatal("newest check missing")
+	}
+
+	now = now.Add(time.Hour - 2*time.Minute)
+	if _, ok := store.Get(ids[1]); ok {
+		t.Fatal("expired check still readable")
+	}
+	removed, err := store.Purge(false)
+	if err != nil || removed != 1 {
+		t.Fatalf("Purge(false) = %d, %v; want 1 expired check", removed, err)
+	}
+	removed, err = store.Purge(true)
+	if err != nil || removed != 1 {
+		t.Fatalf("Purge(true) = %d, %v; want 1 remaining check", removed, err)
+	}
+}
+
+func TestFileStoreRecordsRetention(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok, err := LoadRetention(dir); ok || err != nil {
+		t.Fatalf("LoadRetention on empty dir = %v, %v", ok, err)
+	}
+	config := DefaultConfig()
+	config.StoreDir = dir
+	config.MaxRetainedChecks = 500
+	config.RetainedCheckTTL = 336 * time.Hour
+	if _, err := newCheckStore(config); err != nil {
+		t.Fatalf("newCheckStore: %v", err)
+	}
+	retention, ok, err := LoadRetention(dir)
+	if err != nil || !ok {
+		t.Fatalf("LoadRetention = %v, %v", ok, err)
+	}
+	if retention.MaxRetainedChecks != 500 || retention.RetainedCheckTTL != 336*time.Hour {
+		t.Fatalf("retention = %#v", retention)
+	}
+}
+
+func TestFileStoreReadAndPurgeShareExpiry(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	check, err := store.Save(&app.CheckResult{})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Backdate the file: listing and reads must both treat it as expired.
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(store.path(check.ID), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get(check.ID); ok {
+		t.Fatal("check readable after its file expired")
+	}
+	removed, err := store.Purge(false)
+	if err != nil || removed != 1 {
+		t.Fatalf("Purge(false) = %d, %v; want 1", removed, err)
+	}
+}
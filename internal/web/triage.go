@@ -0,0 +1,104 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dshills/speccritic/internal/triage"
+)
+
+const maxTriageNoteLen = 2000
+
+// handleTriage records a reviewer's decision on one finding and re-renders
+// its detail.
+func (s *Server) handleTriage(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, multipartOverheadLimit)
+	if err := s.parseRequestForm(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if !s.validNonce(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	var decision triage.Decision
+	if raw := r.PostFormValue("decision"); raw != "" {
+		var err error
+		if decision, err = triage.ParseDecision(raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	note := strings.TrimSpace(r.PostFormValue("note"))
+	if utf8.RuneCountInString(note) > maxTriageNoteLen {
+		http.Error(w, fmt.Sprintf("note must be at most %d characters", maxTriageNoteLen), http.StatusBadRequest)
+		return
+	}
+
+	check, ok := s.store.Get(r.PathValue("id"))
+	if !ok || check.Result == nil || check.Result.Report == nil {
+		http.NotFound(w, r)
+		return
+	}
+	detail, ok := newFindingDetail(check, r.PathValue("finding_id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var entry triage.Entry
+	if detail.Issue != nil {
+		entry = triage.IssueEntry(*detail.Issue, decision, note, time.Now())
+	} else {
+		entry = triage.QuestionEntry(*detail.Question, decision, note, time.Now())
+	}
+	if _, err := s.store.UpdateTriage(check.ID, func(entries []triage.Entry) []triage.Entry {
+		return triage.Upsert(entries, entry)
+	}); err != nil {
+		if errors.Is(err, ErrCheckNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("save triage decision: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if decision == "" {
+		entry = triage.Entry{}
+	}
+	detail.Triage = entry
+	detail.Nonce = formNonce(r)
+	detail.Saved = true
+	s.writeFindingDetail(w, detail)
+}
+
+// handleExportTriage downloads the check's triage decisions as a file that
+// `speccritic check --triage` accepts.
+func (s *Server) handleExportTriage(w http.ResponseWriter, r *http.Request) {
+	if !s.validSessionCookies(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	check, ok := s.store.Get(r.PathValue("id"))
+	if !ok || check.Result == nil || check.Result.Report == nil {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := triage.Marshal(triage.NewFile(check.Result.Report, check.Triage))
+	if err != nil {
+		log.Printf("render triage export: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", contentDisposition("speccritic-"+check.ID+"-triage.json"))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,165 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/speccritic/internal/app"
+	"github.com/dshills/speccritic/internal/schema"
+	"github.com/dshills/speccritic/internal/triage"
+)
+
+func postTriage(server *Server, path string, form url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTriageRecordsDecisionAndExports(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	stored := saveCompareCheck(t, server, "Latency must be fast.\n",
+		compareIssue("ISSUE-0001", "Vague latency target", 1, "Latency must be fast."))
+	path := "/checks/" + stored.ID + "/issues/ISSUE-0001/triage"
+
+	rec := postTriage(server, path, url.Values{"csrf_token": {"same"}, "decision": {"dismissed"}, "note": {" not a requirement "}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`<option value="dismissed" selected>`, "not a requirement</textarea>", "Decision saved."} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("detail missing %q:\n%s", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/checks/"+stored.ID+"/triage.json", nil)
+	addSessionCookies(req)
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, "speccritic-"+stored.ID+"-triage.json") {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+	f, err := triage.Parse(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("Parse export: %v", err)
+	}
+	if len(f.Decisions) != 1 || f.Decisions[0].Decision != triage.DecisionDismissed || f.Decisions[0].Note != "not a requirement" ||
+		f.Decisions[0].Fingerprint != triage.IssueFingerprint(stored.Result.Report.Issues[0]) {
+		t.Fatalf("decisions = %+v", f.Decisions)
+	}
+
+	// Choosing Undecided clears the decision.
+	if rec := postTriage(server, path, url.Values{"csrf_token": {"same"}}); rec.Code != http.StatusOK {
+		t.Fatalf("clear status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := server.store.Get(stored.ID); len(got.Triage) != 0 {
+		t.Fatalf("triage after clear = %+v", got.Triage)
+	}
+}
+
+func TestTriageRejectsInvalidRequests(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	stored := saveCompareCheck(t, server, "Latency must be fast.\n",
+		compareIssue("ISSUE-0001", "Vague latency target", 1, "Latency must be fast."))
+	path := "/checks/" + stored.ID + "/issues/ISSUE-0001/triage"
+
+	for name, tc := range map[string]struct {
+		path string
+		form url.Values
+		want int
+	}{
+		"nonce":    {path, url.Values{"csrf_token": {"wrong"}, "decision": {"accepted"}}, http.StatusForbidden},
+		"decision": {path, url.Values{"csrf_token": {"same"}, "decision": {"maybe"}}, http.StatusBadRequest},
+		"note":     {path, url.Values{"csrf_token": {"same"}, "decision": {"accepted"}, "note": {strings.Repeat("x", maxTriageNoteLen+1)}}, http.StatusBadRequest},
+		"finding":  {"/checks/" + stored.ID + "/issues/ISSUE-0099/triage", url.Values{"csrf_token": {"same"}, "decision": {"accepted"}}, http.StatusNotFound},
+		"check":    {"/checks/ffff/issues/ISSUE-0001/triage", url.Values{"csrf_token": {"same"}, "decision": {"accepted"}}, http.StatusNotFound},
+	} {
+		if rec := postTriage(server, tc.path, tc.form); rec.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d", name, rec.Code, tc.want)
+		}
+	}
+	if got, _ := server.store.Get(stored.ID); len(got.Triage) != 0 {
+		t.Fatalf("rejected requests stored triage: %+v", got.Triage)
+	}
+}
+
+func TestTriageCarriesOverToNextUpload(t *testing.T) {
+	server, err := NewServerWithChecker(DefaultConfig(), &fakeChecker{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(server.Close)
+	submit := func() string {
+		body, contentType := multipartSpecRequest(t, "The system must work.")
+		req := httptest.NewRequest(http.MethodPost, "/checks", body)
+		req.Header.Set("Content-Type", contentType)
+		addSessionCookies(req)
+		rec := submitCheck(t, server, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+	checkID := regexp.MustCompile(`<p>Check ([0-9a-f]+)</p>`)
+
+	first := checkID.FindStringSubmatch(submit())
+	if first == nil {
+		t.Fatal("result missing check ID")
+	}
+	if rec := postTriage(server, "/checks/"+first[1]+"/issues/ISSUE-0001/triage", url.Values{"csrf_token": {"same"}, "decision": {"deferred"}}); rec.Code != http.StatusOK {
+		t.Fatalf("triage status = %d: %s", rec.Code, rec.Body.String())
+	}
+	second := submit()
+	if !strings.Contains(second, `<span class="triage-chip triage-deferred">Deferred</span>`) {
+		t.Fatalf("second upload missing carried-over decision: %s", second)
+	}
+}
+
+func TestFileStoreUpdateTriagePersists(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	saved, err := store.Save(&app.CheckResult{Report: &schema.Report{Tool: "speccritic"}})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	entry := triage.Entry{FindingID: "ISSUE-0001", Fingerprint: "sha256:abc", Decision: triage.DecisionAccepted}
+	if _, err := store.UpdateTriage(saved.ID, func(entries []triage.Entry) []triage.Entry {
+		return triage.Upsert(entries, entry)
+	}); err != nil {
+		t.Fatalf("UpdateTriage: %v", err)
+	}
+	if _, err := store.UpdateTriage("ffff", func(entries []triage.Entry) []triage.Entry { return entries }); err != ErrCheckNotFound {
+		t.Fatalf("unknown check err = %v, want ErrCheckNotFound", err)
+	}
+
+	reopened, err := NewFileStore(dir, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore reopen: %v", err)
+	}
+	got, ok := reopened.Get(saved.ID)
+	if !ok || len(got.Triage) != 1 || got.Triage[0] != entry {
+		t.Fatalf("triage after reopen = %+v", got)
+	}
+}
@@ -23,6 +23,8 @@ type Severity = schema.Severity
 type Verdict = schema.Verdict
 type ModelInfo = llm.ModelInfo
 type ContextDocument = app.ContextDocument
+type Progress = app.Progress
+type ProgressStage = app.ProgressStage
 
 type Error = app.Error
 type ErrorKind = app.ErrorKind
@@ -38,6 +40,9 @@ type CheckOptions struct {
 	SpecPath                        string
 	SpecName                        string
 	SpecText                        string
+	SpecDir                         string
+	Sections                        []string
+	ExcludeSections                 []string
 	ContextPaths                    []string
 	ContextDocuments                []ContextDocument
 	Profile                         string
@@ -54,6 +59,10 @@ type CheckOptions struct {
 	PreflightMode                   string
 	PreflightProfile                string
 	PreflightIgnore                 []string
+	PreflightFix                    []string
+	RequirementIDPatterns           []string
+	TermSynonyms                    [][]string
+	SystemActors                    []string
 	Chunking                        string
 	ChunkLines                      int
 	ChunkOverlap                    int
@@ -71,6 +80,10 @@ type CheckOptions struct {
 	IncrementalContextLines         int
 	IncrementalStrictReuse          bool
 	IncrementalReport               bool
+	AnswersPath                     string
+	AnswersText                     string
+	TriagePath                      string
+	TriageText                      string
 	ConvergenceFrom                 string
 	ConvergenceFromText             string
 	ConvergenceMode                 string
@@ -81,7 +94,9 @@ type CheckOptions struct {
 	CompletionTemplate              string
 	CompletionMaxPatches            int
 	CompletionOpenDecisions         bool
+	PatchFormat                     string
 	ErrWriter                       io.Writer
+	Progress                        func(Progress)
 }
 
 type CheckResult struct {
@@ -136,6 +151,13 @@ func Check(ctx context.Context, opts CheckOptions) (*CheckResult, error) {
 		SpecPath:                        opts.SpecPath,
 		SpecName:                        opts.SpecName,
 		SpecText:                        opts.SpecText,
+		SpecDir:                         opts.SpecDir,
+		AnswersPath:                     opts.AnswersPath,
+		AnswersText:                     opts.AnswersText,
+		TriagePath:                      opts.TriagePath,
+		TriageText:                      opts.TriageText,
+		Sections:                        opts.Sections,
+		ExcludeSections:                 opts.ExcludeSections,
 		ContextPaths:                    opts.ContextPaths,
 		ContextDocuments:                toAppContextDocuments(opts.ContextDocuments),
 		Profile:                         opts.Profile,
@@ -152,6 +174,10 @@ func Check(ctx context.Context, opts CheckOptions) (*CheckResult, error) {
 		PreflightMode:                   opts.PreflightMode,
 		PreflightProfile:                opts.PreflightProfile,
 		PreflightIgnore:                 opts.PreflightIgnore,
+		PreflightFix:                    opts.PreflightFix,
+		RequirementIDPatterns:           opts.RequirementIDPatterns,
+		TermSynonyms:                    opts.TermSynonyms,
+		SystemActors:                    opts.SystemActors,
 		Chunking:                        opts.Chunking,
 		ChunkLines:                      opts.ChunkLines,
 		ChunkOverlap:                    opts.ChunkOverlap,
@@ -179,8 +205,10 @@ func Check(ctx context.Context, opts CheckOptions) (*CheckResult, error) {
 		CompletionTemplate:              opts.CompletionTemplate,
 		CompletionMaxPatches:            opts.CompletionMaxPatches,
 		CompletionOpenDecisions:         opts.CompletionOpenDecisions,
+		PatchFormat:                     opts.PatchFormat,
 		Source:                          app.SourceCLI,
 		ErrWriter:                       opts.ErrWriter,
+		Progress:                        opts.Progress,
 	}
 	result, err := app.NewChecker().Check(ctx, req)
 	if err != nil {